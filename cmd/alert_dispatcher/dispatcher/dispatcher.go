@@ -6,7 +6,7 @@ import (
 	"time"
 
 	"github.com/harishhary/blink/internal/broker"
-	"github.com/harishhary/blink/internal/broker/kafka"
+	"github.com/harishhary/blink/internal/broker/factory"
 	"github.com/harishhary/blink/internal/configuration"
 	svcctx "github.com/harishhary/blink/internal/context"
 	"github.com/harishhary/blink/internal/dispatchers"
@@ -28,6 +28,8 @@ var (
 
 type DispatcherService struct {
 	svcctx.ServiceContext
+	broker         broker.Broker
+	topics         []string // read and written topics, checked by Ready
 	reader         broker.Reader
 	dispatcherRepo *dispatchers.DispatcherRepository
 }
@@ -40,11 +42,16 @@ func New(dispatcherRepo *dispatchers.DispatcherRepository) (*DispatcherService,
 	serviceContext.Logger = logger.New(serviceContext.Name(), "dev")
 
 	cfg := serviceContext.Configuration()
-	b := kafka.NewKafkaBroker(cfg.Kafka)
+	b, err := factory.New(cfg)
+	if err != nil {
+		return nil, err
+	}
 	reader := b.NewReader(cfg.Topics.DispatcherTopic, cfg.Topics.DispatcherGroup)
 
 	return &DispatcherService{
 		ServiceContext: serviceContext,
+		broker:         b,
+		topics:         []string{cfg.Topics.DispatcherTopic},
 		reader:         reader,
 		dispatcherRepo: dispatcherRepo,
 	}, nil
@@ -52,6 +59,11 @@ func New(dispatcherRepo *dispatchers.DispatcherRepository) (*DispatcherService,
 
 func (service *DispatcherService) Name() string { return "alert-dispatcher" }
 
+// Ready reports whether Kafka is reachable and the service's topics exist.
+func (service *DispatcherService) Ready(ctx context.Context) error {
+	return service.broker.Ping(ctx, service.topics...)
+}
+
 func (service *DispatcherService) Run(ctx context.Context) errors.Error {
 	for {
 		msgs, err := service.reader.ReadBatch(ctx, 50)
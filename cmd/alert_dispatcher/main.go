@@ -10,16 +10,19 @@ import (
 
 	"github.com/harishhary/blink/cmd/alert_dispatcher/dispatcher"
 	"github.com/harishhary/blink/cmd/alert_dispatcher/sync"
+	"github.com/harishhary/blink/internal/admin"
 	"github.com/harishhary/blink/internal/dispatchers"
+	"github.com/harishhary/blink/internal/readiness"
 	"github.com/harishhary/blink/internal/services"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
+	probe := readiness.New()
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
 		http.HandleFunc("/health/live", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
-		http.HandleFunc("/health/ready", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+		http.Handle("/health/ready", probe)
 		log.Fatal(http.ListenAndServe(":8080", nil))
 	}()
 
@@ -37,10 +40,18 @@ func main() {
 		log.Fatal(err)
 	}
 
+	adminSvc, err := admin.NewService("alert-dispatcher-admin", "BLINK-ALERT-DISPATCHER - ADMIN", nil, nil, nil)
+	if err != nil {
+		log.Fatalf("admin service: %v", err)
+	}
+
+	probe.Add("broker", dispatcherSvc)
+
 	runner := services.New()
 	runner.Register(
 		syncSvc,
 		dispatcherSvc,
+		adminSvc,
 	)
 	runner.Run(ctx)
 	log.Println("Shutting down alert-dispatcher")
@@ -7,11 +7,12 @@ import (
 	"time"
 
 	"github.com/harishhary/blink/internal/broker"
-	"github.com/harishhary/blink/internal/broker/kafka"
+	"github.com/harishhary/blink/internal/broker/factory"
 	"github.com/harishhary/blink/internal/configuration"
 	svcctx "github.com/harishhary/blink/internal/context"
 	"github.com/harishhary/blink/internal/errors"
 	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/pools"
 	"github.com/harishhary/blink/internal/services"
 	"github.com/harishhary/blink/pkg/alerts"
 	enrichcatalog "github.com/harishhary/blink/pkg/enrichments/pool"
@@ -29,6 +30,7 @@ var (
 	alertsDLQ          = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_enricher", Name: "alerts_dlq_total"})
 	enrichmentsApplied = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_enricher", Name: "enrichments_applied_total"}, []string{"enrichment"})
 	enrichmentErrors   = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_enricher", Name: "enrichment_errors_total"}, []string{"enrichment"})
+	enrichmentsShed    = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_enricher", Name: "enrichments_shed_total"}, []string{"enrichment", "reason"})
 	enrichmentLatency  = promauto.NewHistogramVec(prometheus.HistogramOpts{Namespace: "blink", Subsystem: "alert_enricher", Name: "enrichment_latency_seconds", Buckets: prometheus.DefBuckets}, []string{"enrichment"})
 	parseErrors        = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_enricher", Name: "parse_errors_total"})
 	writeErrors        = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_enricher", Name: "write_errors_total"})
@@ -37,6 +39,8 @@ var (
 // EnricherService reads alerts from Kafka, enriches them, and writes to the formatter topic.
 type EnricherService struct {
 	svcctx.ServiceContext
+	broker broker.Broker
+	topics []string // read and written topics, checked by Ready
 	reader broker.Reader
 	writer broker.Writer
 	dlq    broker.Writer
@@ -51,7 +55,10 @@ func NewEnricherService(pool *enrichcatalog.Pool) (*EnricherService, error) {
 	serviceContext.Logger = logger.New(serviceContext.Name(), "dev")
 
 	cfg := serviceContext.Configuration()
-	b := kafka.NewKafkaBroker(cfg.Kafka)
+	b, err := factory.New(cfg)
+	if err != nil {
+		return nil, err
+	}
 	reader := b.NewReader(cfg.Topics.EnricherTopic, cfg.Topics.EnricherGroup)
 	writer := b.NewWriter(cfg.Topics.FormatterTopic)
 
@@ -62,6 +69,8 @@ func NewEnricherService(pool *enrichcatalog.Pool) (*EnricherService, error) {
 
 	return &EnricherService{
 		ServiceContext: serviceContext,
+		broker:         b,
+		topics:         []string{cfg.Topics.EnricherTopic, cfg.Topics.FormatterTopic},
 		reader:         reader,
 		writer:         writer,
 		dlq:            dlq,
@@ -71,6 +80,11 @@ func NewEnricherService(pool *enrichcatalog.Pool) (*EnricherService, error) {
 
 func (service *EnricherService) Name() string { return "alert-enricher" }
 
+// Ready reports whether Kafka is reachable and the service's topics exist.
+func (service *EnricherService) Ready(ctx context.Context) error {
+	return service.broker.Ping(ctx, service.topics...)
+}
+
 // Reads alerts from Kafka, applies enrichments declared by the alert's rule, and writes to the formatter topic.
 func (service *EnricherService) Run(ctx context.Context) errors.Error {
 	return services.RunAlertPipeline(ctx, service.Logger, service.reader, service.writer, service.dlq, 50,
@@ -104,7 +118,16 @@ func (service *EnricherService) Run(ctx context.Context) errors.Error {
 					defer cancel()
 					start := time.Now()
 					absent, removed, err := service.pool.Enrich(cctx, enrName, alert, "")
+					shed, isShed := pools.AsShed(err)
+					if isShed {
+						enrichmentsShed.WithLabelValues(enrName, shed.Reason).Inc()
+					}
 					switch {
+					case isShed && shed.Policy == pools.ShedPassThrough:
+						// Skipped: the alert moves on without this enrichment.
+					case isShed && shed.Policy == pools.ShedDeadLetter:
+						// Retried from the DLQ like a missing enrichment; already-applied ones are kept.
+						anyMissing.Store(true)
 					case removed:
 						anyMissing.Store(true)
 						service.Error(errors.NewF("enrichment %s removed - alert %s missing enrichment", enrName, alert.AlertID))
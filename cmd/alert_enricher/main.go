@@ -9,26 +9,45 @@ import (
 	"syscall"
 
 	"github.com/harishhary/blink/cmd/alert_enricher/enricher"
+	"github.com/harishhary/blink/internal/admin"
+	"github.com/harishhary/blink/internal/ioc"
 	"github.com/harishhary/blink/internal/logger"
 	"github.com/harishhary/blink/internal/pluginmgr"
+	"github.com/harishhary/blink/internal/readiness"
 	"github.com/harishhary/blink/internal/services"
 	"github.com/harishhary/blink/pkg/enrichments"
+	"github.com/harishhary/blink/pkg/hostapi"
 	pools "github.com/harishhary/blink/internal/pools"
 	enrichcatalog "github.com/harishhary/blink/pkg/enrichments/pool"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
+	probe := readiness.New()
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
 		http.HandleFunc("/health/live", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
-		http.HandleFunc("/health/ready", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+		http.Handle("/health/ready", probe)
 		log.Fatal(http.ListenAndServe(":8080", nil))
 	}()
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// Optional: with threat-intel feeds, enrichments can look values up through the host API.
+	var intel *ioc.Watcher
+	var host hostapi.Host
+	if path := os.Getenv("IOC_CONFIG"); path != "" {
+		iocCfg, err := ioc.LoadConfig(path)
+		if err != nil {
+			log.Fatalf("ioc config: %v", err)
+		}
+		if intel, err = ioc.NewWatcher(iocCfg); err != nil {
+			log.Fatalf("ioc watcher: %v", err)
+		}
+		host = intel
+	}
+
 	routingTable := pools.NewRoutingTable()
 	enricherPool := enrichcatalog.NewPool(routingTable, 0)
 
@@ -37,7 +56,9 @@ func main() {
 		"BLINK-ALERT-ENRICHER - SYNC",
 		"ENRICHER_PLUGIN_DIR",
 		func(log *logger.Logger, dir string) pluginmgr.Plugin {
-			return enrichments.NewManager(log, enricherPool.Sync, dir)
+			manager := enrichments.NewManager(log, enricherPool.Sync, dir, host)
+			manager.SetRouting(routingTable, enrichcatalog.DefaultQueue)
+			return manager
 		},
 	)
 	if err != nil {
@@ -48,11 +69,24 @@ func main() {
 		log.Fatalf("enricher service: %v", err)
 	}
 
+	adminSvc, err := admin.NewService("alert-enricher-admin", "BLINK-ALERT-ENRICHER - ADMIN", syncSvc.Plugin(), enricherPool, nil)
+	if err != nil {
+		log.Fatalf("admin service: %v", err)
+	}
+
+	probe.Add("plugins", syncSvc.Plugin())
+	probe.Add("broker", enricherSvc)
+
 	runner := services.New()
 	runner.Register(
 		syncSvc,
 		enricherSvc,
+		adminSvc,
 	)
+	if intel != nil {
+		probe.Add("ioc", intel)
+		runner.Register(intel)
+	}
 	runner.Run(ctx)
 	log.Println("Shutting down alert-enricher")
 }
@@ -4,11 +4,12 @@ import (
 	"context"
 
 	"github.com/harishhary/blink/internal/broker"
-	"github.com/harishhary/blink/internal/broker/kafka"
+	"github.com/harishhary/blink/internal/broker/factory"
 	"github.com/harishhary/blink/internal/configuration"
 	svcctx "github.com/harishhary/blink/internal/context"
 	"github.com/harishhary/blink/internal/errors"
 	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/pools"
 	"github.com/harishhary/blink/internal/services"
 	"github.com/harishhary/blink/pkg/alerts"
 	fmtcatalog "github.com/harishhary/blink/pkg/formatters/pool"
@@ -22,12 +23,15 @@ var (
 	alertsDLQ         = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_formatter", Name: "alerts_dlq_total"})
 	formattersApplied = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_formatter", Name: "formatters_applied_total"}, []string{"formatter"})
 	formatterErrors   = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_formatter", Name: "formatter_errors_total"}, []string{"formatter"})
+	formattersShed    = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_formatter", Name: "formatters_shed_total"}, []string{"formatter", "reason"})
 	parseErrors       = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_formatter", Name: "parse_errors_total"})
 	writeErrors       = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_formatter", Name: "write_errors_total"})
 )
 
 type FormatterService struct {
 	svcctx.ServiceContext
+	broker broker.Broker
+	topics []string // read and written topics, checked by Ready
 	reader broker.Reader
 	writer broker.Writer
 	dlq    broker.Writer
@@ -42,7 +46,10 @@ func NewFormatterService(pool *fmtcatalog.Pool) (*FormatterService, error) {
 	serviceContext.Logger = logger.New(serviceContext.Name(), "dev")
 
 	cfg := serviceContext.Configuration()
-	b := kafka.NewKafkaBroker(cfg.Kafka)
+	b, err := factory.New(cfg)
+	if err != nil {
+		return nil, err
+	}
 	reader := b.NewReader(cfg.Topics.FormatterTopic, cfg.Topics.FormatterGroup)
 	writer := b.NewWriter(cfg.Topics.DispatcherTopic)
 
@@ -53,6 +60,8 @@ func NewFormatterService(pool *fmtcatalog.Pool) (*FormatterService, error) {
 
 	return &FormatterService{
 		ServiceContext: serviceContext,
+		broker:         b,
+		topics:         []string{cfg.Topics.FormatterTopic, cfg.Topics.DispatcherTopic},
 		reader:         reader,
 		writer:         writer,
 		dlq:            dlq,
@@ -62,6 +71,11 @@ func NewFormatterService(pool *fmtcatalog.Pool) (*FormatterService, error) {
 
 func (service *FormatterService) Name() string { return "alert-formatter" }
 
+// Ready reports whether Kafka is reachable and the service's topics exist.
+func (service *FormatterService) Ready(ctx context.Context) error {
+	return service.broker.Ping(ctx, service.topics...)
+}
+
 // Reads alerts from Kafka, applies formatters, and writes to the dispatcher topic.
 func (service *FormatterService) Run(ctx context.Context) errors.Error {
 	return services.RunAlertPipeline(ctx, service.Logger, service.reader, service.writer, service.dlq, 50,
@@ -80,6 +94,23 @@ func (service *FormatterService) Run(ctx context.Context) errors.Error {
 
 			for _, name := range alert.Rule.Formatters() {
 				_, absent, removed, err := service.pool.Format(ctx, name, alert, "")
+				if shed, ok := pools.AsShed(err); ok {
+					formattersShed.WithLabelValues(name, shed.Reason).Inc()
+					switch shed.Policy {
+					case pools.ShedPassThrough:
+						continue
+					case pools.ShedDeadLetter:
+						if restored, uerr := alerts.Unmarshal(snapshot); uerr == nil {
+							*alert = *restored
+						}
+						alert.Attempts++
+						if alert.Attempts < services.MaxPluginAttempts {
+							return false, true
+						}
+						service.Info("alert %s passed through after %d attempts (formatter %s overloaded)", alert.AlertID, alert.Attempts, name)
+						continue
+					}
+				}
 				switch {
 				case removed || absent:
 					label := "not found"
@@ -9,8 +9,10 @@ import (
 	"syscall"
 
 	"github.com/harishhary/blink/cmd/alert_formatter/formatter"
+	"github.com/harishhary/blink/internal/admin"
 	"github.com/harishhary/blink/internal/logger"
 	"github.com/harishhary/blink/internal/pluginmgr"
+	"github.com/harishhary/blink/internal/readiness"
 	"github.com/harishhary/blink/internal/services"
 	"github.com/harishhary/blink/pkg/formatters"
 	pools "github.com/harishhary/blink/internal/pools"
@@ -19,10 +21,11 @@ import (
 )
 
 func main() {
+	probe := readiness.New()
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
 		http.HandleFunc("/health/live", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
-		http.HandleFunc("/health/ready", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+		http.Handle("/health/ready", probe)
 		log.Fatal(http.ListenAndServe(":8080", nil))
 	}()
 
@@ -37,7 +40,9 @@ func main() {
 		"BLINK-ALERT-FORMATTER - SYNC",
 		"FORMATTER_PLUGIN_DIR",
 		func(log *logger.Logger, dir string) pluginmgr.Plugin {
-			return formatters.NewManager(log, formatterPool.Sync, dir)
+			manager := formatters.NewManager(log, formatterPool.Sync, dir)
+			manager.SetRouting(routingTable, fmtcatalog.DefaultQueue)
+			return manager
 		},
 	)
 	if err != nil {
@@ -48,10 +53,19 @@ func main() {
 		log.Fatalf("formatter service: %v", err)
 	}
 
+	adminSvc, err := admin.NewService("alert-formatter-admin", "BLINK-ALERT-FORMATTER - ADMIN", syncSvc.Plugin(), formatterPool, nil)
+	if err != nil {
+		log.Fatalf("admin service: %v", err)
+	}
+
+	probe.Add("plugins", syncSvc.Plugin())
+	probe.Add("broker", formatterSvc)
+
 	runner := services.New()
 	runner.Register(
 		syncSvc,
 		formatterSvc,
+		adminSvc,
 	)
 	runner.Run(ctx)
 	log.Println("Shutting down alert-formatter")
@@ -9,15 +9,18 @@ import (
 	"syscall"
 
 	"github.com/harishhary/blink/cmd/alert_merger/merger"
+	"github.com/harishhary/blink/internal/admin"
+	"github.com/harishhary/blink/internal/readiness"
 	"github.com/harishhary/blink/internal/services"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
+	probe := readiness.New()
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
 		http.HandleFunc("/health/live", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
-		http.HandleFunc("/health/ready", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+		http.Handle("/health/ready", probe)
 		log.Fatal(http.ListenAndServe(":8080", nil))
 	}()
 
@@ -29,8 +32,15 @@ func main() {
 		log.Fatalf("merger service: %v", err)
 	}
 
+	adminSvc, err := admin.NewService("alert-merger-admin", "BLINK-ALERT-MERGER - ADMIN", nil, nil, nil)
+	if err != nil {
+		log.Fatalf("admin service: %v", err)
+	}
+
+	probe.Add("broker", mergerSvc)
+
 	runner := services.New()
-	runner.Register(mergerSvc)
+	runner.Register(mergerSvc, adminSvc)
 	runner.Run(ctx)
 	log.Println("Shutting down alert-merger")
 }
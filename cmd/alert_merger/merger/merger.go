@@ -9,7 +9,7 @@ import (
 	"time"
 
 	"github.com/harishhary/blink/internal/broker"
-	"github.com/harishhary/blink/internal/broker/kafka"
+	"github.com/harishhary/blink/internal/broker/factory"
 	"github.com/harishhary/blink/internal/configuration"
 	svcctx "github.com/harishhary/blink/internal/context"
 	"github.com/harishhary/blink/internal/errors"
@@ -38,6 +38,8 @@ type mergeGroup struct {
 // MergerService reads alerts from Kafka, merges related alerts within their time window, and writes merged (or pass-through) alerts to the tuner topic.
 type MergerService struct {
 	svcctx.ServiceContext
+	broker broker.Broker
+	topics []string // read and written topics, checked by Ready
 	reader broker.Reader
 	writer broker.Writer
 	mu     sync.Mutex
@@ -52,12 +54,17 @@ func NewMergerService() (*MergerService, error) {
 	serviceContext.Logger = logger.New(serviceContext.Name(), "dev")
 
 	cfg := serviceContext.Configuration()
-	b := kafka.NewKafkaBroker(cfg.Kafka)
+	b, err := factory.New(cfg)
+	if err != nil {
+		return nil, err
+	}
 	reader := b.NewReader(cfg.Topics.MergerTopic, cfg.Topics.MergerGroup)
 	writer := b.NewWriter(cfg.Topics.TunerTopic)
 
 	return &MergerService{
 		ServiceContext: serviceContext,
+		broker:         b,
+		topics:         []string{cfg.Topics.MergerTopic, cfg.Topics.TunerTopic},
 		reader:         reader,
 		writer:         writer,
 		groups:         make(map[string]*mergeGroup),
@@ -66,6 +73,11 @@ func NewMergerService() (*MergerService, error) {
 
 func (s *MergerService) Name() string { return "alert-merger" }
 
+// Ready reports whether Kafka is reachable and the service's topics exist.
+func (s *MergerService) Ready(ctx context.Context) error {
+	return s.broker.Ping(ctx, s.topics...)
+}
+
 // Reads alerts from MergerTopic, accumulates related alerts into merge groups, flushes expired groups to TunerTopic, and commits Kafka offsets.
 func (s *MergerService) Run(ctx context.Context) errors.Error {
 	// Periodic flush: every 10s, flush any merge group whose window has expired.
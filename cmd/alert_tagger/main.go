@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/harishhary/blink/cmd/alert_tagger/tagger"
+	"github.com/harishhary/blink/internal/admin"
+	"github.com/harishhary/blink/internal/assets"
+	"github.com/harishhary/blink/internal/readiness"
+	"github.com/harishhary/blink/internal/services"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func main() {
+	probe := readiness.New()
+	go func() {
+		http.Handle("/metrics", promhttp.Handler())
+		http.HandleFunc("/health/live", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+		http.Handle("/health/ready", probe)
+		log.Fatal(http.ListenAndServe(":8080", nil))
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	path := os.Getenv("ASSET_CONFIG")
+	if path == "" {
+		log.Fatal("ASSET_CONFIG is required")
+	}
+	assetCfg, err := assets.LoadConfig(path)
+	if err != nil {
+		log.Fatalf("asset config: %v", err)
+	}
+	inventory, err := assets.NewWatcher(assetCfg)
+	if err != nil {
+		log.Fatalf("asset watcher: %v", err)
+	}
+
+	taggerSvc, err := tagger.NewTaggerService(inventory)
+	if err != nil {
+		log.Fatalf("tagger service: %v", err)
+	}
+
+	adminSvc, err := admin.NewService("alert-tagger-admin", "BLINK-ALERT-TAGGER - ADMIN", nil, nil, nil)
+	if err != nil {
+		log.Fatalf("admin service: %v", err)
+	}
+
+	probe.Add("assets", inventory)
+	probe.Add("broker", taggerSvc)
+
+	runner := services.New()
+	runner.Register(
+		inventory,
+		taggerSvc,
+		adminSvc,
+	)
+	runner.Run(ctx)
+	log.Println("Shutting down alert-tagger")
+}
@@ -0,0 +1,120 @@
+package tagger
+
+import (
+	"context"
+
+	"github.com/harishhary/blink/internal/assets"
+	"github.com/harishhary/blink/internal/broker"
+	"github.com/harishhary/blink/internal/broker/factory"
+	"github.com/harishhary/blink/internal/configuration"
+	svcctx "github.com/harishhary/blink/internal/context"
+	"github.com/harishhary/blink/internal/errors"
+	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/services"
+	"github.com/harishhary/blink/pkg/alerts"
+	"github.com/harishhary/blink/pkg/events"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	alertsIn        = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_tagger", Name: "alerts_in_total"})
+	alertsOut       = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_tagger", Name: "alerts_out_total"})
+	alertsTagged    = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_tagger", Name: "alerts_tagged_total", Help: "Alerts involving at least one inventory asset."})
+	severityChanged = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_tagger", Name: "severity_changed_total", Help: "Alerts whose severity the asset policy changed, by criticality of the asset that decided it."}, []string{"criticality"})
+	parseErrors     = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_tagger", Name: "parse_errors_total"})
+	writeErrors     = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "alert_tagger", Name: "write_errors_total"})
+)
+
+// TaggerService reads alerts from Kafka, attaches the inventory assets their events mention, adjusts
+// their severity by the asset policy, and writes them to the enricher topic.
+type TaggerService struct {
+	svcctx.ServiceContext
+	broker    broker.Broker
+	topics    []string // read and written topics, checked by Ready
+	reader    broker.Reader
+	writer    broker.Writer
+	inventory *assets.Watcher
+}
+
+func NewTaggerService(inventory *assets.Watcher) (*TaggerService, error) {
+	serviceContext := svcctx.New("BLINK-ALERT-TAGGER - TAGGER")
+	if err := configuration.LoadFromEnvironment(&serviceContext); err != nil {
+		return nil, err
+	}
+	serviceContext.Logger = logger.New(serviceContext.Name(), "dev")
+
+	cfg := serviceContext.Configuration()
+	if cfg.Topics.TaggerTopic == "" || cfg.Topics.TaggerGroup == "" {
+		return nil, errors.New("KAFKA_TOPIC_TAGGER and KAFKA_GROUP_TAGGER are required by the alert tagger")
+	}
+	b, err := factory.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	reader := b.NewReader(cfg.Topics.TaggerTopic, cfg.Topics.TaggerGroup)
+	writer := b.NewWriter(cfg.Topics.EnricherTopic)
+
+	return &TaggerService{
+		ServiceContext: serviceContext,
+		broker:         b,
+		topics:         []string{cfg.Topics.TaggerTopic, cfg.Topics.EnricherTopic},
+		reader:         reader,
+		writer:         writer,
+		inventory:      inventory,
+	}, nil
+}
+
+func (service *TaggerService) Name() string { return "alert-tagger" }
+
+// Ready reports whether Kafka is reachable and the service's topics exist.
+func (service *TaggerService) Ready(ctx context.Context) error {
+	return service.broker.Ping(ctx, service.topics...)
+}
+
+func (service *TaggerService) Run(ctx context.Context) errors.Error {
+	return services.RunAlertPipeline(ctx, service.Logger, service.reader, service.writer, nil, 50,
+		services.PipelineCounters{
+			In: alertsIn.Inc, Out: alertsOut.Inc,
+			ParseError: parseErrors.Inc, WriteError: writeErrors.Inc,
+		},
+		func(_ context.Context, _ []byte, alert *alerts.Alert) (skip bool, deadLetter bool) {
+			service.tag(alert)
+			return false, false
+		},
+	)
+}
+
+// tag looks the alert's event up in the current inventory. A merged alert's event no longer has the
+// original fields, so it keeps the assets the event matcher attached to them, if they were common to
+// every merged event.
+func (service *TaggerService) tag(alert *alerts.Alert) {
+	if alert.Event == nil {
+		return
+	}
+	attached, ok := alert.Event[events.AssetsKey]
+	matches := service.inventory.Current().Tag(alert.Event, service.inventory.Fields())
+	if len(matches) == 0 && ok {
+		alert.Event[events.AssetsKey] = attached
+		matches = assets.Attached(alert.Event)
+	}
+	if len(matches) == 0 {
+		return
+	}
+	alertsTagged.Inc()
+
+	before := alert.Severity
+	base := alert.Severity
+	if alert.Rule != nil {
+		base = max(base, alert.Rule.Severity()) // alerts leave the rule executor without a severity of their own
+	}
+	alert.Severity = service.inventory.Policy().Apply(base, matches)
+	if alert.Severity != before {
+		highest := assets.CriticalityUnknown
+		for _, m := range matches {
+			highest = max(highest, m.Criticality)
+		}
+		severityChanged.WithLabelValues(highest.String()).Inc()
+		service.Info("alert %s severity %s -> %s (asset criticality %s)", alert.AlertID, before, alert.Severity, highest)
+	}
+}
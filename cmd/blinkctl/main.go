@@ -0,0 +1,231 @@
+// blinkctl is a small client for the admin API every blink service serves on ADMIN_ADDR.
+//
+//	blinkctl [-addr URL] [-token TOKEN] [-json] <command> [args]
+//
+// Commands:
+//
+//	plugins              list running plugins
+//	pools                list pools with their role, size and load
+//	registry             show the active rule registry generation
+//	reconcile            rescan the plugin directory now
+//	unquarantine <binary>
+//	                     let a quarantined plugin binary start again
+//	promote <plugin-id>  graduate the pending canary/shadow pool
+//	rollback <plugin-id> discard the pending canary/shadow pool
+//	kill <plugin-id> on|off
+//	                     toggle the operator kill switch
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/harishhary/blink/internal/admin"
+	"github.com/harishhary/blink/internal/pools"
+)
+
+func main() {
+	addr := flag.String("addr", envOr("BLINK_ADMIN_ADDR", "http://localhost:8081"), "admin API base URL (env BLINK_ADMIN_ADDR)")
+	token := flag.String("token", os.Getenv("BLINK_ADMIN_TOKEN"), "bearer token (env BLINK_ADMIN_TOKEN)")
+	raw := flag.Bool("json", false, "print the raw JSON response")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: blinkctl [flags] plugins|pools|registry|reconcile|unquarantine <binary>|promote <id>|rollback <id>|kill <id> on|off")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	c := &client{base: strings.TrimSuffix(*addr, "/"), token: *token, raw: *raw, http: &http.Client{Timeout: 30 * time.Second}}
+	if err := c.run(flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "blinkctl:", err)
+		os.Exit(1)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+type client struct {
+	base  string
+	token string
+	raw   bool
+	http  *http.Client
+}
+
+func (c *client) run(args []string) error {
+	if len(args) == 0 {
+		flag.Usage()
+		return fmt.Errorf("missing command")
+	}
+	arg := func(i int) (string, error) {
+		if len(args) <= i {
+			return "", fmt.Errorf("%s: missing argument", args[0])
+		}
+		return args[i], nil
+	}
+
+	switch args[0] {
+	case "plugins":
+		var out []admin.Plugin
+		return c.get("/admin/v1/plugins", &out, func() { printPlugins(out) })
+	case "pools":
+		var out []pools.PoolStatus
+		return c.get("/admin/v1/pools", &out, func() { printPools(out) })
+	case "registry":
+		var out admin.Registry
+		return c.get("/admin/v1/registry", &out, func() {
+			fmt.Printf("generation %d: %d rule(s), loaded %s\n", out.Generation, out.Rules, out.LoadedAt.Format(time.RFC3339))
+		})
+	case "reconcile":
+		return c.do(http.MethodPost, "/admin/v1/plugins/reconcile", nil, nil)
+	case "unquarantine":
+		name, err := arg(1)
+		if err != nil {
+			return err
+		}
+		return c.do(http.MethodDelete, "/admin/v1/plugins/"+url.PathEscape(name)+"/quarantine", nil, nil)
+	case "promote", "rollback":
+		id, err := arg(1)
+		if err != nil {
+			return err
+		}
+		return c.do(http.MethodPost, "/admin/v1/pools/"+url.PathEscape(id)+"/"+args[0], nil, nil)
+	case "kill":
+		id, err := arg(1)
+		if err != nil {
+			return err
+		}
+		state, err := arg(2)
+		if err != nil {
+			return err
+		}
+		if state != "on" && state != "off" {
+			return fmt.Errorf("kill: state must be on or off, got %q", state)
+		}
+		return c.do(http.MethodPut, "/admin/v1/pools/"+url.PathEscape(id)+"/kill-switch", admin.KillSwitch{On: state == "on"}, nil)
+	default:
+		flag.Usage()
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// get fetches path into out and prints it with print, or as raw JSON with -json.
+func (c *client) get(path string, out any, print func()) error {
+	var body []byte
+	if err := c.do(http.MethodGet, path, nil, &body); err != nil {
+		return err
+	}
+	if c.raw {
+		_, err := os.Stdout.Write(body)
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	print()
+	return nil
+}
+
+func (c *client) do(method, path string, in any, out *[]byte) error {
+	var reqBody io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, c.base+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &e) == nil && e.Error != "" {
+			return fmt.Errorf("%s: %s", resp.Status, e.Error)
+		}
+		return fmt.Errorf("%s", resp.Status)
+	}
+	if out != nil {
+		*out = body
+	}
+	return nil
+}
+
+func printPlugins(ps []admin.Plugin) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tID\tNAME\tVERSION\tPROTO\tHASH\tWORKERS\tHEALTHY\tKILLED\tNOTE")
+	for _, p := range ps {
+		note := ""
+		switch {
+		case p.Quarantined:
+			note = fmt.Sprintf("quarantined since %s: %s", p.QuarantinedAt.Format(time.RFC3339), p.QuarantineReason)
+		case p.Restarting:
+			note = "restarting"
+		case p.StartFailures > 0:
+			note = fmt.Sprintf("%d start failure(s), retry %s", p.StartFailures, p.NextRetry.Format(time.RFC3339))
+		case p.Adopted:
+			note = "adopted (debug)"
+		}
+		name := p.Name
+		if name == "" {
+			name = filepath.Base(p.Path) // not running: the binary name is what unquarantine takes
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%d\t%t\t%s\n", p.Type, p.ID, name, short(p.Version), protocol(p.Protocol), short(p.Hash), p.Workers, p.Healthy, p.KillSwitch, note)
+	}
+	tw.Flush()
+}
+
+func printPools(ps []pools.PoolStatus) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PLUGIN\tVERSION\tROLE\tMODE\tPCT\tKILLED\tSIZE\tINFLIGHT\tWAITING")
+	for _, p := range ps {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%g\t%t\t%d\t%d\t%d\n", p.PluginID, short(p.Version), p.Role, p.Mode, p.RolloutPct, p.KillSwitch, p.Size, p.Inflight, p.Waiting)
+	}
+	tw.Flush()
+}
+
+// protocol formats a negotiated protocol version; plugins that are not running have none.
+func protocol(v int) string {
+	if v == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("v%d", v)
+}
+
+// short trims sha256 hashes to a readable prefix.
+func short(s string) string {
+	if len(s) > 12 {
+		return s[:12]
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+package ingestor
+
+import (
+	"os"
+	"slices"
+
+	"github.com/harishhary/blink/internal/errors"
+	"go.yaml.in/yaml/v4"
+)
+
+// Config is the ingestor config file: the sources to run, keyed by name.
+//
+//	sources:
+//	  azure-signins:
+//	    type: eventhub
+//	    log_type: azure:signin
+//	    key_field: userPrincipalName
+//	    config:
+//	      host: blink.servicebus.windows.net
+//	      event_hub: signins
+//	      username: ingestor
+//	      password: { env: EVENTHUB_PASSWORD }
+//	      checkpoints: { dir: /var/lib/blink/eventhub } # every partition; or partition: "0" for just one
+//	  firewall:
+//	    type: eventhub
+//	    schemas: ["fortigate:traffic", "fortigate:event"] # classified against INGESTOR_SCHEMA_DIR
+//	    config: { ... }
+type Config struct {
+	Sources map[string]SourceConfig `yaml:"sources"`
+}
+
+// SourceConfig is one source entry.
+type SourceConfig struct {
+	// Type selects the source constructor, e.g. "eventhub".
+	Type string `yaml:"type"`
+	// LogType is set on every record the source itself does not assign one, replacing any log_type
+	// the record carries.
+	LogType string `yaml:"log_type"`
+	// TrustLogType keeps the log_type a record carries when neither the source nor LogType sets one,
+	// instead of classifying the record. Only for sources whose producers may pick the rules that run
+	// on their records; otherwise a record's own log_type is kept as _ingest.claimed_log_type.
+	TrustLogType bool `yaml:"trust_log_type"`
+	// Schemas lists the log schemas to classify this source's records against, in the order to try
+	// them. Without LogType or Schemas every loaded schema is tried in priority order.
+	Schemas []string `yaml:"schemas"`
+	// KeyField names the event field whose value keys the record on the matcher topic, so related
+	// events stay in order on one partition. Without it the source's own partition key is used.
+	KeyField string `yaml:"key_field"`
+	// Topic overrides KAFKA_TOPIC_MATCHER for this source, e.g. to feed a per-log_type matcher deployment.
+	Topic string `yaml:"topic"`
+	// Config is passed to the source constructor.
+	Config map[string]any `yaml:"config"`
+}
+
+// LoadConfig reads and validates the ingestor config file.
+func LoadConfig(path string) (Config, errors.Error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, errors.NewF("failed to read ingestor config: %s", err)
+	}
+	var cfg Config
+	if err := yaml.Load(data, &cfg, yaml.WithKnownFields()); err != nil {
+		return Config{}, errors.NewF("failed to parse ingestor config: %s", err)
+	}
+	if len(cfg.Sources) == 0 {
+		return Config{}, errors.NewF("ingestor config %s lists no sources", path)
+	}
+	for name, src := range cfg.Sources {
+		if src.Type == "" {
+			return Config{}, errors.NewF("source %s has no type", name)
+		}
+		if src.LogType != "" && len(src.Schemas) > 0 {
+			return Config{}, errors.NewF("source %s sets both log_type and schemas", name)
+		}
+		if src.LogType != "" && src.TrustLogType {
+			return Config{}, errors.NewF("source %s sets both log_type and trust_log_type", name)
+		}
+	}
+	return cfg, nil
+}
+
+// Names returns the source names in a stable order.
+func (c Config) Names() []string {
+	names := make([]string, 0, len(c.Sources))
+	for name := range c.Sources {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,359 @@
+package ingestor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	bkr "github.com/harishhary/blink/internal/broker"
+	"github.com/harishhary/blink/internal/classifier"
+	"github.com/harishhary/blink/internal/configuration"
+	ctx "github.com/harishhary/blink/internal/context"
+	"github.com/harishhary/blink/internal/errors"
+	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/sources"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	recordsIn           = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "records_in_total"}, []string{"source"})
+	recordsPublished    = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "records_published_total"}, []string{"source"})
+	recordsUnclassified = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "records_unclassified_total", Help: "Records published without a log_type; the matcher routes them to no rule."}, []string{"source"})
+	recordsClassified   = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "records_classified_total"}, []string{"source", "log_type"})
+	recordsPoisoned     = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "records_poisoned_total", Help: "Records no schema accepted, sent to the poison topic or dropped if it is unset."}, []string{"source"})
+	readErrors          = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "read_errors_total"}, []string{"source"})
+	writeErrors         = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "write_errors_total"}, []string{"source"})
+	checkpointErrors    = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "checkpoint_errors_total"}, []string{"source"})
+	checkpointTime      = promauto.NewGaugeVec(prometheus.GaugeOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "checkpoint_timestamp_seconds", Help: "Unix time of the last checkpoint saved per source."}, []string{"source"})
+	batchSize           = promauto.NewHistogramVec(prometheus.HistogramOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "batch_size", Buckets: []float64{1, 10, 50, 100, 250, 500, 1000}}, []string{"source"})
+	publishDuration     = promauto.NewHistogramVec(prometheus.HistogramOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "publish_duration_seconds", Buckets: prometheus.DefBuckets}, []string{"source"})
+)
+
+const (
+	defaultBatchSize = 500
+	retryBase        = time.Second
+	retryMax         = 30 * time.Second
+)
+
+var hostname, _ = os.Hostname()
+
+// IngestorService runs one configured source: it reads batches of raw records, attaches log_type and
+// ingest metadata, publishes them to the matcher topic and then checkpoints the batch.
+type IngestorService struct {
+	ctx.ServiceContext
+	source      string
+	spec        SourceConfig
+	src         sources.Source
+	writer      bkr.Writer
+	checkpoints sources.CheckpointStore
+	batchSize   int
+
+	// schemas classifies records when the source has no fixed log_type; nil disables classification.
+	schemas *classifier.Watcher
+	// poison receives the records schemas rejects; nil drops them.
+	poison bkr.Writer
+
+	mu      sync.Mutex
+	readErr error // last Read error, cleared by the next successful Read; reported by Ready
+}
+
+// NewIngestorService builds the service for one source. schemas and poison may be nil; see IngestorService.
+func NewIngestorService(name string, spec SourceConfig, writer bkr.Writer, checkpoints sources.CheckpointStore, schemas *classifier.Watcher, poison bkr.Writer) (*IngestorService, error) {
+	serviceContext := ctx.New("BLINK-EVENT-INGESTOR - " + strings.ToUpper(name))
+	if err := configuration.LoadFromEnvironment(&serviceContext); err != nil {
+		return nil, err
+	}
+	serviceContext.Logger = logger.New(serviceContext.Name(), "dev")
+
+	src, err := sources.New(spec.Type, name, spec.Config)
+	if err != nil {
+		return nil, err
+	}
+	size := serviceContext.Configuration().Ingestor.BatchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+	if len(spec.Schemas) > 0 && schemas == nil {
+		return nil, errors.NewF("source %s lists schemas but INGESTOR_SCHEMA_DIR is not set", name)
+	}
+	return &IngestorService{
+		ServiceContext: serviceContext,
+		source:         name,
+		spec:           spec,
+		src:            src,
+		writer:         writer,
+		checkpoints:    checkpoints,
+		batchSize:      size,
+		schemas:        schemas,
+		poison:         poison,
+	}, nil
+}
+
+func (service *IngestorService) Name() string { return "event-ingestor-" + service.source }
+
+// Ready reports the source's last read error, if the last read failed.
+func (service *IngestorService) Ready(context.Context) error {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if service.readErr != nil {
+		return fmt.Errorf("source %s: %w", service.source, service.readErr)
+	}
+	return nil
+}
+
+func (service *IngestorService) setReadErr(err error) {
+	service.mu.Lock()
+	service.readErr = err
+	service.mu.Unlock()
+}
+
+// Run resumes the source from its saved checkpoint and publishes until ctx is cancelled.
+// A batch is checkpointed only once it has been published, so delivery is at-least-once.
+func (service *IngestorService) Run(ctx context.Context) errors.Error {
+	committer, _ := service.src.(sources.Committer)
+	checkpoint := ""
+	if committer == nil {
+		var err error
+		if checkpoint, err = service.checkpoints.Load(service.source); err != nil {
+			return errors.NewE(err)
+		}
+		service.Info("source %s (%s) starting from checkpoint %q", service.source, service.spec.Type, checkpoint)
+	} else {
+		service.Info("source %s (%s) resuming from the checkpoints in its own store", service.source, service.spec.Type)
+	}
+	switch {
+	case service.spec.LogType != "":
+	case service.schemas != nil:
+		service.Info("source %s records without a log_type are classified against the schemas in %s", service.source, service.Configuration().Ingestor.SchemaDir)
+	default:
+		service.Info("source %s has no log_type; records without their own are routed to no rule", service.source)
+	}
+	defer service.src.Close()
+
+	failures := 0
+	for {
+		batch, err := service.src.Read(ctx, checkpoint, service.batchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			readErrors.WithLabelValues(service.source).Inc()
+			service.setReadErr(err)
+			service.Error(errors.NewE(err))
+			failures++
+			if !sleep(ctx, backoff(failures)) {
+				return nil
+			}
+			continue
+		}
+		failures = 0
+		service.setReadErr(nil)
+
+		if len(batch.Messages) > 0 {
+			recordsIn.WithLabelValues(service.source).Add(float64(len(batch.Messages)))
+			batchSize.WithLabelValues(service.source).Observe(float64(len(batch.Messages)))
+			if !service.publish(ctx, batch.Messages) {
+				return nil
+			}
+		}
+		if batch.Checkpoint == checkpoint {
+			continue
+		}
+		if err := service.save(ctx, committer, batch.Checkpoint); err != nil {
+			// The source has moved on regardless; a restart before the next save replays this batch.
+			checkpointErrors.WithLabelValues(service.source).Inc()
+			service.Error(errors.NewE(err))
+		} else {
+			checkpointTime.WithLabelValues(service.source).SetToCurrentTime()
+		}
+		checkpoint = batch.Checkpoint
+	}
+}
+
+// save records a published batch's checkpoint with the source if it keeps its own, else in the checkpoint store.
+func (service *IngestorService) save(ctx context.Context, committer sources.Committer, checkpoint string) error {
+	if committer != nil {
+		return committer.Commit(ctx, checkpoint)
+	}
+	return service.checkpoints.Save(service.source, checkpoint)
+}
+
+// publish writes the batch to the matcher topic, and records that failed classification to the poison
+// topic, retrying until both succeed: the source has already moved past these records, so giving up
+// would lose them. It returns false if ctx was cancelled first.
+func (service *IngestorService) publish(ctx context.Context, batch []sources.Message) bool {
+	now := time.Now()
+	var registry *classifier.Registry
+	if service.schemas != nil {
+		registry = service.schemas.Current() // one registry per batch, even if a reload lands mid-batch
+	}
+	msgs := make([]bkr.Message, 0, len(batch))
+	var poisoned []bkr.Message
+	for _, m := range batch {
+		evt, claimed, err := service.event(registry, m)
+		var msg bkr.Message
+		if unclassified, ok := err.(*classifier.UnclassifiedError); ok {
+			recordsPoisoned.WithLabelValues(service.source).Inc()
+			if service.poison == nil {
+				service.Logger.Debug("dropping record from %s: %v", service.source, unclassified)
+				continue
+			}
+			if msg, err = service.poisonMessage(m, now, unclassified); err == nil {
+				poisoned = append(poisoned, msg)
+				continue
+			}
+		}
+		if err == nil {
+			msg, err = service.message(evt, claimed, m, now)
+		}
+		if err != nil {
+			writeErrors.WithLabelValues(service.source).Inc()
+			service.Error(errors.NewE(err))
+			continue
+		}
+		logType, classified := evt["log_type"].(string)
+		if !classified {
+			recordsUnclassified.WithLabelValues(service.source).Inc()
+		} else if service.spec.LogType == "" && m.LogType == "" {
+			recordsClassified.WithLabelValues(service.source, logType).Inc()
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if !service.write(ctx, service.writer, "matcher", msgs) {
+		return false
+	}
+	recordsPublished.WithLabelValues(service.source).Add(float64(len(msgs)))
+	return len(poisoned) == 0 || service.write(ctx, service.poison, "poison", poisoned)
+}
+
+// write retries WriteMessages with backoff until it succeeds or ctx is cancelled.
+func (service *IngestorService) write(ctx context.Context, writer bkr.Writer, topic string, msgs []bkr.Message) bool {
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		err := writer.WriteMessages(ctx, msgs...)
+		if err == nil {
+			publishDuration.WithLabelValues(service.source).Observe(time.Since(start).Seconds())
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		writeErrors.WithLabelValues(service.source).Inc()
+		service.ErrorF("publish %d record(s) from %s to the %s topic (attempt %d): %v", len(msgs), service.source, topic, attempt, err)
+		if !sleep(ctx, backoff(attempt)) {
+			return false
+		}
+	}
+}
+
+// event decodes a raw record and sets its log_type, which picks the rules that run on it. The log_type
+// the source assigned the record comes first, then the source's configured one; without either the
+// record is classified against registry, which returns a *classifier.UnclassifiedError if no schema
+// accepts it. A log_type the record carries itself is only used for sources with trust_log_type: it is
+// otherwise replaced, or dropped if nothing sets one, and returned as claimed. Records that are neither
+// JSON objects nor classified are wrapped as {"message": "<raw>"}.
+func (service *IngestorService) event(registry *classifier.Registry, m sources.Message) (evt map[string]any, claimed string, err error) {
+	if json.Unmarshal(m.Data, &evt) != nil {
+		evt = nil
+	}
+	claimed, _ = evt["log_type"].(string)
+	logType := m.LogType
+	if logType == "" {
+		logType = service.spec.LogType
+	}
+	if logType == "" && service.spec.TrustLogType && claimed != "" {
+		return evt, "", nil
+	}
+	if logType == "" && registry != nil {
+		if evt, _, err = registry.Classify(service.spec.Schemas, m.Data); err != nil {
+			return nil, "", err
+		}
+		logType, _ = evt["log_type"].(string)
+	}
+	if evt == nil {
+		evt = map[string]any{"message": string(m.Data)}
+	}
+	if logType != "" {
+		evt["log_type"] = logType
+	} else {
+		delete(evt, "log_type")
+	}
+	if claimed == logType {
+		claimed = ""
+	}
+	return evt, claimed, nil
+}
+
+func (service *IngestorService) ingestMeta(m sources.Message, now time.Time) map[string]any {
+	ingest := map[string]any{
+		"source":      service.source,
+		"source_type": service.spec.Type,
+		"host":        hostname,
+		"ingested_at": now.UTC().Format(time.RFC3339Nano),
+	}
+	if !m.Time.IsZero() {
+		ingest["received_at"] = m.Time.UTC().Format(time.RFC3339Nano)
+	}
+	for k, v := range m.Meta {
+		ingest[k] = v
+	}
+	return ingest
+}
+
+// message turns a decoded event into the matcher topic message. claimed is the log_type the record
+// carried itself, if event did not use it.
+func (service *IngestorService) message(evt map[string]any, claimed string, m sources.Message, now time.Time) (bkr.Message, error) {
+	ingest := service.ingestMeta(m, now)
+	if claimed != "" {
+		ingest["claimed_log_type"] = claimed
+	}
+	evt["_ingest"] = ingest
+
+	key := m.Key
+	if service.spec.KeyField != "" {
+		key = nil
+		if v, ok := evt[service.spec.KeyField]; ok && v != nil {
+			key = []byte(fmt.Sprint(v))
+		}
+	}
+	value, err := json.Marshal(evt)
+	if err != nil {
+		return bkr.Message{}, err
+	}
+	return bkr.Message{Key: key, Value: value}, nil
+}
+
+// poisonMessage wraps a record no schema accepted with the reason, so it can be inspected and replayed.
+func (service *IngestorService) poisonMessage(m sources.Message, now time.Time, unclassified *classifier.UnclassifiedError) (bkr.Message, error) {
+	value, err := json.Marshal(map[string]any{
+		"reason":   unclassified.Error(),
+		"attempts": unclassified.Attempts,
+		"raw":      string(m.Data),
+		"_ingest":  service.ingestMeta(m, now),
+	})
+	if err != nil {
+		return bkr.Message{}, err
+	}
+	return bkr.Message{Key: m.Key, Value: value}, nil
+}
+
+// backoff doubles from retryBase up to retryMax.
+func backoff(attempt int) time.Duration {
+	return min(retryBase<<min(attempt-1, 5), retryMax)
+}
+
+// sleep waits for d and reports false if ctx ended first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
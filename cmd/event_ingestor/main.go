@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/harishhary/blink/cmd/event_ingestor/ingestor"
+	"github.com/harishhary/blink/internal/admin"
+	bkr "github.com/harishhary/blink/internal/broker"
+	"github.com/harishhary/blink/internal/broker/factory"
+	"github.com/harishhary/blink/internal/classifier"
+	"github.com/harishhary/blink/internal/configuration"
+	svcctx "github.com/harishhary/blink/internal/context"
+	"github.com/harishhary/blink/internal/readiness"
+	"github.com/harishhary/blink/internal/services"
+	"github.com/harishhary/blink/internal/sources"
+	"github.com/harishhary/blink/internal/sources/azure_storage"
+	_ "github.com/harishhary/blink/internal/sources/eventhub"
+	_ "github.com/harishhary/blink/internal/sources/file"
+	_ "github.com/harishhary/blink/internal/sources/syslog"
+	_ "github.com/harishhary/blink/internal/sources/webhook"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func main() {
+	probe := readiness.New()
+	go func() {
+		http.Handle("/metrics", promhttp.Handler())
+		http.HandleFunc("/health/live", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+		http.Handle("/health/ready", probe)
+		log.Fatal(http.ListenAndServe(":8080", nil))
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	sc := svcctx.New("BLINK-EVENT-INGESTOR")
+	if err := configuration.LoadFromEnvironment(&sc); err != nil {
+		log.Fatalf("configuration: %v", err)
+	}
+	cfg := sc.Configuration()
+	if cfg.Ingestor.ConfigFile == "" {
+		log.Fatal("INGESTOR_CONFIG is required")
+	}
+	ingestCfg, loadErr := ingestor.LoadConfig(cfg.Ingestor.ConfigFile)
+	if loadErr != nil {
+		log.Fatalf("ingestor config: %v", loadErr)
+	}
+	var checkpoints sources.CheckpointStore
+	if container := cfg.Ingestor.CheckpointContainer; container != "" {
+		var storage azure_storage.Configuration
+		if err := configuration.LoadFromEnvironment(&storage); err != nil {
+			log.Fatalf("checkpoint storage: %v", err)
+		}
+		checkpoints = azure_storage.NewBlobCheckpoints(azure_storage.New(storage, container), "event-ingestor")
+	} else {
+		checkpointDir := cfg.Ingestor.CheckpointDir
+		if checkpointDir == "" {
+			checkpointDir = filepath.Join(os.TempDir(), "blink-checkpoints")
+		}
+		fileCheckpoints, err := sources.NewFileCheckpoints(checkpointDir)
+		if err != nil {
+			log.Fatalf("checkpoints: %v", err)
+		}
+		checkpoints = fileCheckpoints
+	}
+
+	broker, err := factory.New(cfg)
+	if err != nil {
+		log.Fatalf("broker: %v", err)
+	}
+	writers := make(map[string]bkr.Writer) // sources publishing to the same topic share its writer
+	var topics []string
+
+	runner := services.New()
+	var schemas *classifier.Watcher
+	if cfg.Ingestor.SchemaDir != "" {
+		var err error
+		if schemas, err = classifier.NewWatcher(cfg.Ingestor.SchemaDir); err != nil {
+			log.Fatalf("schemas: %v", err)
+		}
+		probe.Add("schemas", schemas)
+		runner.Register(schemas)
+	}
+	var poison bkr.Writer
+	if cfg.Topics.IngestPoisonTopic != "" {
+		poison = broker.NewWriter(cfg.Topics.IngestPoisonTopic)
+		defer poison.Close()
+		topics = append(topics, cfg.Topics.IngestPoisonTopic)
+	}
+
+	for _, name := range ingestCfg.Names() {
+		spec := ingestCfg.Sources[name]
+		topic := spec.Topic
+		if topic == "" {
+			topic = cfg.Topics.MatcherTopic
+		}
+		writer, ok := writers[topic]
+		if !ok {
+			writer = broker.NewWriter(topic)
+			defer writer.Close()
+			writers[topic] = writer
+			topics = append(topics, topic)
+		}
+		svc, err := ingestor.NewIngestorService(name, spec, writer, checkpoints, schemas, poison)
+		if err != nil {
+			log.Fatalf("source %s: %v", name, err)
+		}
+		probe.Add("source:"+name, svc)
+		runner.Register(svc)
+	}
+
+	adminSvc, err := admin.NewService("event-ingestor-admin", "BLINK-EVENT-INGESTOR - ADMIN", nil, nil, nil)
+	if err != nil {
+		log.Fatalf("admin service: %v", err)
+	}
+	probe.Add("broker", readiness.CheckFunc(func(ctx context.Context) error { return broker.Ping(ctx, topics...) }))
+
+	runner.Register(adminSvc)
+	runner.Run(ctx)
+	log.Println("Shutting down event-ingestor")
+}
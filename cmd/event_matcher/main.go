@@ -9,8 +9,13 @@ import (
 	"syscall"
 
 	"github.com/harishhary/blink/cmd/event_matcher/matcher"
+	"github.com/harishhary/blink/internal/admin"
+	"github.com/harishhary/blink/internal/assets"
+	"github.com/harishhary/blink/internal/ioc"
 	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/normalize"
 	"github.com/harishhary/blink/internal/pluginmgr"
+	"github.com/harishhary/blink/internal/readiness"
 	"github.com/harishhary/blink/internal/services"
 	"github.com/harishhary/blink/pkg/matchers"
 	pools "github.com/harishhary/blink/internal/pools"
@@ -20,10 +25,11 @@ import (
 )
 
 func main() {
+	probe := readiness.New()
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
 		http.HandleFunc("/health/live", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
-		http.HandleFunc("/health/ready", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+		http.Handle("/health/ready", probe)
 		log.Fatal(http.ListenAndServe(":8080", nil))
 	}()
 
@@ -39,6 +45,39 @@ func main() {
 		log.Fatalf("config watcher: %v", err)
 	}
 
+	// Optional: without mappings, rules that opt into the normalized view match nothing.
+	var mappings *normalize.Watcher
+	if dir := os.Getenv("NORMALIZE_MAPPING_DIR"); dir != "" {
+		if mappings, err = normalize.NewWatcher(dir); err != nil {
+			log.Fatalf("mapping watcher: %v", err)
+		}
+	}
+
+	// Optional: without an inventory, events reach the rules without asset context.
+	var inventory *assets.Watcher
+	if path := os.Getenv("ASSET_CONFIG"); path != "" {
+		assetCfg, err := assets.LoadConfig(path)
+		if err != nil {
+			log.Fatalf("asset config: %v", err)
+		}
+		if inventory, err = assets.NewWatcher(assetCfg); err != nil {
+			log.Fatalf("asset watcher: %v", err)
+		}
+	}
+
+	// Optional: with threat-intel feeds, events are matched against them and the built-in ioc-hit matcher
+	// routes those that hit to rules such as ioc-match.
+	var intel *ioc.Watcher
+	if path := os.Getenv("IOC_CONFIG"); path != "" {
+		iocCfg, err := ioc.LoadConfig(path)
+		if err != nil {
+			log.Fatalf("ioc config: %v", err)
+		}
+		if intel, err = ioc.NewWatcher(iocCfg); err != nil {
+			log.Fatalf("ioc watcher: %v", err)
+		}
+	}
+
 	routingTable := pools.NewRoutingTable()
 	matcherPool := matchcatalog.NewPool(routingTable, 0)
 
@@ -47,23 +86,62 @@ func main() {
 		"BLINK-EVENT-MATCHER - SYNC",
 		"MATCHER_PLUGIN_DIR",
 		func(log *logger.Logger, dir string) pluginmgr.Plugin {
-			return matchers.NewManager(log, matcherPool.Sync, dir)
+			manager := matchers.NewManager(log, matcherPool.Sync, dir)
+			manager.SetRouting(routingTable, matchcatalog.DefaultQueue)
+			if intel != nil {
+				if err := manager.Register(ioc.NewMatcher, 1, 1); err != nil {
+					log.ErrorF("register %s: %v", ioc.MatcherName, err)
+				}
+			}
+			return manager
 		},
 	)
 	if err != nil {
 		log.Fatalf("sync service: %v", err)
 	}
-	matcherSvc, err := matcher.NewMatcherService(matcherPool, cfgWatcherSvc)
+	matcherSvc, err := matcher.NewMatcherService(matcherPool, cfgWatcherSvc, mappings, inventory, intel)
 	if err != nil {
 		log.Fatalf("matcher service: %v", err)
 	}
 
+	adminSvc, err := admin.NewService("event-matcher-admin", "BLINK-EVENT-MATCHER - ADMIN", syncSvc.Plugin(), matcherPool, cfgWatcherSvc)
+	if err != nil {
+		log.Fatalf("admin service: %v", err)
+	}
+
+	probe.Add("config", cfgWatcherSvc)
+	probe.Add("plugins", syncSvc.Plugin())
+	probe.Add("broker", matcherSvc)
+	// Every matcher referenced by an enabled rule must be registered, or its events would go unmatched.
+	probe.Add("matchers", readiness.Required("matcher plugins", func() []string {
+		var ids []string
+		if reg := cfgWatcherSvc.Current(); reg != nil {
+			for _, m := range reg.Enabled() {
+				ids = append(ids, m.Matchers()...)
+			}
+		}
+		return ids
+	}, matcherPool.Registered))
+
 	runner := services.New()
 	runner.Register(
 		cfgWatcherSvc,
 		syncSvc,
 		matcherSvc,
+		adminSvc,
 	)
+	if mappings != nil {
+		probe.Add("mappings", mappings)
+		runner.Register(mappings)
+	}
+	if inventory != nil {
+		probe.Add("assets", inventory)
+		runner.Register(inventory)
+	}
+	if intel != nil {
+		probe.Add("ioc", intel)
+		runner.Register(intel)
+	}
 	runner.Run(ctx)
 	log.Println("Shutting down event-matcher")
 }
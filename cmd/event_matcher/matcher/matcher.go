@@ -5,13 +5,18 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/harishhary/blink/internal/assets"
 	bkr "github.com/harishhary/blink/internal/broker"
-	"github.com/harishhary/blink/internal/broker/kafka"
+	"github.com/harishhary/blink/internal/broker/factory"
 	"github.com/harishhary/blink/internal/configuration"
 	ctx "github.com/harishhary/blink/internal/context"
 	"github.com/harishhary/blink/internal/errors"
 	execpb "github.com/harishhary/blink/internal/exec/pb"
+	"github.com/harishhary/blink/internal/ioc"
 	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/normalize"
+	"github.com/harishhary/blink/internal/pools"
+	"github.com/harishhary/blink/pkg/events"
 	matchcatalog "github.com/harishhary/blink/pkg/matchers/pool"
 	"github.com/harishhary/blink/pkg/rules/config"
 	"github.com/prometheus/client_golang/prometheus"
@@ -21,13 +26,18 @@ import (
 )
 
 var (
-	eventsIn        = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "events_in_total"})
-	eventsForwarded = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "events_forwarded_total"})
-	readErrors      = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "read_errors_total"})
-	parseErrors     = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "parse_errors_total"})
-	writeErrors     = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "write_errors_total"})
-	matchDuration   = promauto.NewHistogram(prometheus.HistogramOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "match_duration_seconds", Buckets: prometheus.DefBuckets})
-	rulesRouted     = promauto.NewHistogram(prometheus.HistogramOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "rules_routed_per_event", Buckets: []float64{0, 1, 5, 10, 25, 50, 100}})
+	eventsIn          = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "events_in_total"})
+	eventsForwarded   = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "events_forwarded_total"})
+	readErrors        = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "read_errors_total"})
+	parseErrors       = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "parse_errors_total"})
+	writeErrors       = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "write_errors_total"})
+	matchDuration     = promauto.NewHistogram(prometheus.HistogramOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "match_duration_seconds", Buckets: prometheus.DefBuckets})
+	matchersShed      = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "matchers_shed_total"}, []string{"matcher", "reason"})
+	eventsNormalized  = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "events_normalized_total"}, []string{"log_type"})
+	normalizeErrors   = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "normalize_errors_total", Help: "Mapping steps that failed; the normalized view omits their fields."}, []string{"log_type"})
+	eventsAssetTagged = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "events_asset_tagged_total", Help: "Events that mention at least one inventory asset."}, []string{"log_type"})
+	iocHits           = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "ioc_hits_total", Help: "Threat-intel indicators hit by event fields."}, []string{"log_type", "type"})
+	rulesRouted       = promauto.NewHistogram(prometheus.HistogramOpts{Namespace: "blink", Subsystem: "event_matcher", Name: "rules_routed_per_event", Buckets: []float64{0, 1, 5, 10, 25, 50, 100}})
 )
 
 // MatcherService routes incoming events to eligible rules and publishes ExecMessages
@@ -40,20 +50,28 @@ var (
 // unnecessary subprocess invocations for rules that don't apply to this event.
 type MatcherService struct {
 	ctx.ServiceContext
+	broker     bkr.Broker
+	topics     []string // read and written topics, checked by Ready
 	reader     bkr.Reader
 	writer     bkr.Writer
 	cfgWatcher *config.Watcher
 	pool       *matchcatalog.Pool
+	mappings   *normalize.Watcher // nil when no mapping directory is configured
+	assets     *assets.Watcher    // nil when no asset inventory is configured
+	intel      *ioc.Watcher       // nil when no threat-intel feeds are configured
 }
 
-func NewMatcherService(pool *matchcatalog.Pool, cfgWatcher *config.Watcher) (*MatcherService, error) {
+func NewMatcherService(pool *matchcatalog.Pool, cfgWatcher *config.Watcher, mappings *normalize.Watcher, inventory *assets.Watcher, intel *ioc.Watcher) (*MatcherService, error) {
 	serviceContext := ctx.New("BLINK-EVENT-MATCHER - MATCHER")
 	if err := configuration.LoadFromEnvironment(&serviceContext); err != nil {
 		return nil, err
 	}
 	serviceContext.Logger = logger.New(serviceContext.Name(), "dev")
 
-	b := kafka.NewKafkaBroker(serviceContext.Configuration().Kafka)
+	b, err := factory.New(serviceContext.Configuration())
+	if err != nil {
+		return nil, err
+	}
 	readr := b.NewReader(
 		serviceContext.Configuration().Topics.MatcherTopic,
 		serviceContext.Configuration().Topics.MatcherGroup,
@@ -62,15 +80,25 @@ func NewMatcherService(pool *matchcatalog.Pool, cfgWatcher *config.Watcher) (*Ma
 
 	return &MatcherService{
 		ServiceContext: serviceContext,
+		broker:         b,
+		topics:         []string{serviceContext.Configuration().Topics.MatcherTopic, serviceContext.Configuration().Topics.ExecTopic},
 		reader:         readr,
 		writer:         writer,
 		cfgWatcher:     cfgWatcher,
 		pool:           pool,
+		mappings:       mappings,
+		assets:         inventory,
+		intel:          intel,
 	}, nil
 }
 
 func (service *MatcherService) Name() string { return "event-matcher" }
 
+// Ready reports whether Kafka is reachable and the service's topics exist.
+func (service *MatcherService) Ready(ctx context.Context) error {
+	return service.broker.Ping(ctx, service.topics...)
+}
+
 // Run reads raw events, routes them to eligible rules via matcher checks, and writes ExecMessages to blink-exec.
 func (service *MatcherService) Run(ctx context.Context) errors.Error {
 	for {
@@ -97,6 +125,10 @@ func (service *MatcherService) Run(ctx context.Context) errors.Error {
 			continue
 		}
 
+		service.normalize(evt, logType)
+		service.tagAssets(evt, logType)
+		service.matchIndicators(evt, logType)
+
 		start := time.Now()
 		ruleIDs := service.route(ctx, evt, logType)
 		matchDuration.Observe(time.Since(start).Seconds())
@@ -127,6 +159,43 @@ func (service *MatcherService) Run(ctx context.Context) errors.Error {
 	}
 }
 
+// normalize attaches the normalized view of evt when its log_type has a mapping. Any view it arrived
+// with is dropped, even with no mappings. Matchers and rules see both the vendor fields and the view.
+func (service *MatcherService) normalize(evt map[string]any, logType string) {
+	delete(evt, events.NormalizedKey)
+	if service.mappings == nil {
+		return
+	}
+	mapping, ok := service.mappings.Current().Get(logType)
+	if !ok {
+		return
+	}
+	view, errs := mapping.Apply(evt)
+	for _, err := range errs {
+		normalizeErrors.WithLabelValues(logType).Inc()
+		service.Logger.Debug("normalize log_type=%s: %v", logType, err)
+	}
+	evt[events.NormalizedKey] = map[string]any(view)
+	eventsNormalized.WithLabelValues(logType).Inc()
+}
+
+// tagAssets attaches the inventory assets evt mentions, looked up after normalization so the common
+// schema's fields can name them. Assets the event arrived with are dropped, even with no inventory.
+func (service *MatcherService) tagAssets(evt map[string]any, logType string) {
+	if matches := service.assets.Tag(evt); len(matches) > 0 {
+		eventsAssetTagged.WithLabelValues(logType).Inc()
+	}
+}
+
+// matchIndicators attaches the threat-intel indicators hit by the fields configured for logType, before
+// routing so the ioc-hit matcher can see them. Indicators the event arrived with are dropped, even with
+// no feeds.
+func (service *MatcherService) matchIndicators(evt map[string]any, logType string) {
+	for _, hit := range service.intel.Tag(evt, logType) {
+		iocHits.WithLabelValues(logType, string(hit.Indicator.Type)).Inc()
+	}
+}
+
 // returns the IDs of rules that are eligible for this event based on:
 //  1. log_type matching (rules with empty log_types match all)
 //  2. matcher plugin checks (rules with no matchers match all)
@@ -145,9 +214,17 @@ func (service *MatcherService) route(ctx context.Context, evt map[string]any, lo
 
 // applyMatchers runs the named matcher plugins against the event via the pool.
 // Returns true when all matchers pass (or when there are no matchers).
+// A matcher shed under the pass-through policy counts as passed; any other shed fails the rule.
 func (service *MatcherService) applyMatchers(ctx context.Context, evt map[string]any, matcherNames []string) bool {
 	for _, name := range matcherNames {
 		ok, err := service.pool.Match(ctx, name, evt, "")
+		if shed, isShed := pools.AsShed(err); isShed {
+			matchersShed.WithLabelValues(name, shed.Reason).Inc()
+			if shed.Policy == pools.ShedPassThrough {
+				continue
+			}
+			return false
+		}
 		if err != nil || !ok {
 			return false
 		}
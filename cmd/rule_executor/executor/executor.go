@@ -6,13 +6,15 @@ import (
 	"time"
 
 	"github.com/harishhary/blink/internal/broker"
-	"github.com/harishhary/blink/internal/broker/kafka"
+	"github.com/harishhary/blink/internal/broker/factory"
 	"github.com/harishhary/blink/internal/configuration"
 	ctx "github.com/harishhary/blink/internal/context"
 	"github.com/harishhary/blink/internal/errors"
 	execpb "github.com/harishhary/blink/internal/exec/pb"
 	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/pools"
 	"github.com/harishhary/blink/pkg/alerts"
+	"github.com/harishhary/blink/pkg/events"
 	"github.com/harishhary/blink/pkg/rules"
 	"github.com/harishhary/blink/pkg/rules/config"
 	rulecatalog "github.com/harishhary/blink/pkg/rules/pool"
@@ -28,6 +30,7 @@ var (
 	alertsOut      = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "rule_executor", Name: "alerts_out_total"})
 	ruleEvalHist   = promauto.NewHistogramVec(prometheus.HistogramOpts{Namespace: "blink", Subsystem: "rule_executor", Name: "rule_evaluation_seconds"}, []string{"rule"})
 	ruleEvalErrors = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "rule_executor", Name: "rule_evaluation_errors_total"}, []string{"rule"})
+	ruleEvalShed   = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "rule_executor", Name: "rule_evaluations_shed_total"}, []string{"rule", "reason"})
 
 	readBatchErrors   = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "rule_executor", Name: "read_batch_errors_total"})
 	readBatchDuration = promauto.NewHistogram(prometheus.HistogramOpts{Namespace: "blink", Subsystem: "rule_executor", Name: "read_batch_seconds"})
@@ -43,13 +46,16 @@ var (
 	alertsWriteErrors   = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "rule_executor", Name: "alerts_write_errors_total"})
 	alertsWriteDuration = promauto.NewHistogram(prometheus.HistogramOpts{Namespace: "blink", Subsystem: "rule_executor", Name: "alerts_write_seconds"})
 
-	ruleMatches   = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "rule_executor", Name: "rule_matches_total"}, []string{"rule"})
-	rulesPerEvent = promauto.NewHistogram(prometheus.HistogramOpts{Namespace: "blink", Subsystem: "rule_executor", Name: "rules_per_event"})
+	ruleMatches        = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "rule_executor", Name: "rule_matches_total"}, []string{"rule"})
+	rulesNotNormalized = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "rule_executor", Name: "rules_skipped_not_normalized_total", Help: "Evaluations skipped because a rule wants the normalized view and the event has none."}, []string{"rule"})
+	rulesPerEvent      = promauto.NewHistogram(prometheus.HistogramOpts{Namespace: "blink", Subsystem: "rule_executor", Name: "rules_per_event"})
 )
 
 // Reads ExecMessages from blink-exec, applies the routed rules, and writes alerts to blink-merger.
 type ExecutorService struct {
 	ctx.ServiceContext
+	broker     broker.Broker
+	topics     []string // read and written topics, checked by Ready
 	reader     broker.Reader
 	writer     broker.Writer
 	pool       *rulecatalog.Pool
@@ -66,7 +72,10 @@ func NewExecutorService(pool *rulecatalog.Pool, cfgWatcher *config.Watcher) (*Ex
 	}
 	serviceContext.Logger = logger.New(serviceContext.Name(), "dev")
 
-	b := kafka.NewKafkaBroker(serviceContext.Configuration().Kafka)
+	b, err := factory.New(serviceContext.Configuration())
+	if err != nil {
+		return nil, err
+	}
 	reader := b.NewReader(
 		serviceContext.Configuration().Topics.ExecTopic,
 		serviceContext.Configuration().Topics.ExecGroup,
@@ -89,6 +98,8 @@ func NewExecutorService(pool *rulecatalog.Pool, cfgWatcher *config.Watcher) (*Ex
 
 	return &ExecutorService{
 		ServiceContext: serviceContext,
+		broker:         b,
+		topics:         []string{serviceContext.Configuration().Topics.ExecTopic, serviceContext.Configuration().Topics.MergerTopic},
 		reader:         reader,
 		writer:         writer,
 		pool:           pool,
@@ -101,6 +112,11 @@ func NewExecutorService(pool *rulecatalog.Pool, cfgWatcher *config.Watcher) (*Ex
 
 func (service *ExecutorService) Name() string { return "rule-executor" }
 
+// Ready reports whether Kafka is reachable and the service's topics exist.
+func (service *ExecutorService) Ready(ctx context.Context) error {
+	return service.broker.Ping(ctx, service.topics...)
+}
+
 func (service *ExecutorService) Run(ctx context.Context) errors.Error {
 	for {
 		batchStart := time.Now()
@@ -191,9 +207,27 @@ func (service *ExecutorService) processOne(ctx context.Context, m broker.Message
 			continue
 		}
 
+		// Rules that opt into the normalized view see only that; the alert keeps the whole event.
+		input := events.Event(event)
+		if meta.Normalized() {
+			view, ok := input.Normalized()
+			if !ok {
+				rulesNotNormalized.WithLabelValues(meta.Name()).Inc()
+				continue
+			}
+			input = view
+		}
+
 		startEval := time.Now()
-		passed, err := service.pool.Evaluate(ctx, meta.Id(), event, tenantID)
+		passed, err := service.pool.Evaluate(ctx, meta.Id(), input, tenantID)
 		ruleEvalHist.WithLabelValues(meta.Name()).Observe(time.Since(startEval).Seconds())
+		if shed, ok := pools.AsShed(err); ok {
+			ruleEvalShed.WithLabelValues(meta.Name(), shed.Reason).Inc()
+			// The executor has no dead-letter topic, so dead-letter behaves like pass-through: the rule is skipped for this event.
+			if shed.Policy != pools.ShedFailFast {
+				continue
+			}
+		}
 		if err != nil {
 			ruleEvalErrors.WithLabelValues(meta.Name()).Inc()
 			service.Error(err)
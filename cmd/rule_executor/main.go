@@ -6,11 +6,15 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 
 	"github.com/harishhary/blink/cmd/rule_executor/executor"
+	"github.com/harishhary/blink/internal/admin"
+	"github.com/harishhary/blink/internal/ioc"
 	"github.com/harishhary/blink/internal/logger"
 	"github.com/harishhary/blink/internal/pluginmgr"
+	"github.com/harishhary/blink/internal/readiness"
 	"github.com/harishhary/blink/internal/services"
 	"github.com/harishhary/blink/pkg/rules"
 	"github.com/harishhary/blink/pkg/rules/config"
@@ -19,10 +23,11 @@ import (
 )
 
 func main() {
+	probe := readiness.New()
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
 		http.HandleFunc("/health/live", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
-		http.HandleFunc("/health/ready", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+		http.Handle("/health/ready", probe)
 		log.Fatal(http.ListenAndServe(":8080", nil))
 	}()
 
@@ -48,7 +53,36 @@ func main() {
 		"BLINK-RULE-EXECUTOR - SYNC",
 		"RULE_PLUGIN_DIR",
 		func(log *logger.Logger, dir string) pluginmgr.Plugin {
-			return rules.NewManager(log, rulePool.Sync, dir, cfgWatcher)
+			manager := rules.NewManager(log, rulePool.Sync, dir, cfgWatcher)
+			// The built-in ioc-match rule has no binary; its sidecar alone enables it. It is registered
+			// while the sidecar exists, with the worker bounds the sidecar holds at each autoscale tick.
+			limits := func() (int, int) {
+				if meta := cfgWatcher.Current().ByFileName(ioc.RuleFileName); meta != nil {
+					return meta.MinProcs(), meta.MaxProcs()
+				}
+				return 0, 0
+			}
+			var mu sync.Mutex
+			var registered string // the name the rule is registered under, while it is
+			syncIOCRule := func(reg *config.Registry) {
+				mu.Lock()
+				defer mu.Unlock()
+				meta := reg.ByFileName(ioc.RuleFileName)
+				switch {
+				case meta != nil && registered == "":
+					if err := manager.RegisterWithLimits(ioc.NewRule(cfgWatcher), limits); err != nil {
+						log.ErrorF("register %s: %v", ioc.RuleFileName, err)
+						return
+					}
+					registered = meta.Name()
+				case meta == nil && registered != "":
+					manager.Unregister(registered)
+					registered = ""
+				}
+			}
+			cfgWatcher.OnReload(syncIOCRule)
+			syncIOCRule(cfgWatcher.Current())
+			return manager
 		},
 	)
 	if err != nil {
@@ -60,11 +94,31 @@ func main() {
 		log.Fatalf("executor service: %v", err)
 	}
 
+	adminSvc, err := admin.NewService("rule-executor-admin", "BLINK-RULE-EXECUTOR - ADMIN", syncSvc.Plugin(), rulePool, cfgWatcher)
+	if err != nil {
+		log.Fatalf("admin service: %v", err)
+	}
+
+	probe.Add("config", cfgWatcher)
+	probe.Add("plugins", syncSvc.Plugin())
+	probe.Add("broker", executorSvc)
+	// Every enabled rule must have a registered plugin before events are worth consuming.
+	probe.Add("rules", readiness.Required("rule plugins", func() []string {
+		var ids []string
+		if reg := cfgWatcher.Current(); reg != nil {
+			for _, m := range reg.Enabled() {
+				ids = append(ids, m.Id())
+			}
+		}
+		return ids
+	}, rulePool.Registered))
+
 	runner := services.New()
 	runner.Register(
 		cfgWatcher,
 		syncSvc,
 		executorSvc,
+		adminSvc,
 	)
 	runner.Run(ctx)
 	log.Println("Shutting down rule-executor")
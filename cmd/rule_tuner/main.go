@@ -9,8 +9,10 @@ import (
 	"syscall"
 
 	"github.com/harishhary/blink/cmd/rule_tuner/tuner"
+	"github.com/harishhary/blink/internal/admin"
 	"github.com/harishhary/blink/internal/logger"
 	"github.com/harishhary/blink/internal/pluginmgr"
+	"github.com/harishhary/blink/internal/readiness"
 	"github.com/harishhary/blink/internal/services"
 	"github.com/harishhary/blink/pkg/tuning_rules"
 	pools "github.com/harishhary/blink/internal/pools"
@@ -19,10 +21,11 @@ import (
 )
 
 func main() {
+	probe := readiness.New()
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
 		http.HandleFunc("/health/live", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
-		http.HandleFunc("/health/ready", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+		http.Handle("/health/ready", probe)
 		log.Fatal(http.ListenAndServe(":8080", nil))
 	}()
 
@@ -37,7 +40,9 @@ func main() {
 		"BLINK-RULE-TUNER - SYNC",
 		"TUNER_PLUGIN_DIR",
 		func(log *logger.Logger, dir string) pluginmgr.Plugin {
-			return tuning_rules.NewManager(log, tuningPool.Sync, dir)
+			manager := tuning_rules.NewManager(log, tuningPool.Sync, dir)
+			manager.SetRouting(routingTable, tuningcatalog.DefaultQueue)
+			return manager
 		},
 	)
 	if err != nil {
@@ -48,10 +53,19 @@ func main() {
 		log.Fatalf("tuner service: %v", err)
 	}
 
+	adminSvc, err := admin.NewService("rule-tuner-admin", "BLINK-RULE-TUNER - ADMIN", syncSvc.Plugin(), tuningPool, nil)
+	if err != nil {
+		log.Fatalf("admin service: %v", err)
+	}
+
+	probe.Add("plugins", syncSvc.Plugin())
+	probe.Add("broker", tunerSvc)
+
 	runner := services.New()
 	runner.Register(
 		syncSvc,
 		tunerSvc,
+		adminSvc,
 	)
 	runner.Run(ctx)
 	log.Println("Shutting down rule-tuner")
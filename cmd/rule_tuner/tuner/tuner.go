@@ -5,7 +5,7 @@ import (
 	stderrors "errors"
 
 	"github.com/harishhary/blink/internal/broker"
-	"github.com/harishhary/blink/internal/broker/kafka"
+	"github.com/harishhary/blink/internal/broker/factory"
 	"github.com/harishhary/blink/internal/configuration"
 	svcctx "github.com/harishhary/blink/internal/context"
 	"github.com/harishhary/blink/internal/errors"
@@ -27,6 +27,7 @@ var (
 	alertsIgnored     = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "rule_tuner", Name: "alerts_ignored_total"})
 	confidenceChanged = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "rule_tuner", Name: "confidence_changed_total"})
 	tuningErrors      = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "rule_tuner", Name: "errors_total"})
+	tuningShed        = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "rule_tuner", Name: "tuning_rules_shed_total"}, []string{"tuning_rule", "reason"})
 	parseErrors       = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "rule_tuner", Name: "parse_errors_total"})
 	writeErrors       = promauto.NewCounter(prometheus.CounterOpts{Namespace: "blink", Subsystem: "rule_tuner", Name: "write_errors_total"})
 )
@@ -38,9 +39,12 @@ type tuneResult struct {
 	applies    bool
 }
 
-// TunerService reads alerts from Kafka, applies tuning rules, and writes to the enricher topic.
+// TunerService reads alerts from Kafka, applies tuning rules, and writes to the tagger topic if one is
+// configured and the enricher topic otherwise.
 type TunerService struct {
 	svcctx.ServiceContext
+	broker broker.Broker
+	topics []string // read and written topics, checked by Ready
 	reader broker.Reader
 	writer broker.Writer
 	dlq    broker.Writer
@@ -55,9 +59,16 @@ func NewTunerService(pool *tuningcatalog.Pool) (*TunerService, error) {
 	serviceContext.Logger = logger.New(serviceContext.Name(), "dev")
 
 	cfg := serviceContext.Configuration()
-	b := kafka.NewKafkaBroker(cfg.Kafka)
+	b, err := factory.New(cfg)
+	if err != nil {
+		return nil, err
+	}
 	reader := b.NewReader(cfg.Topics.TunerTopic, cfg.Topics.TunerGroup)
-	writer := b.NewWriter(cfg.Topics.EnricherTopic)
+	next := cfg.Topics.EnricherTopic
+	if cfg.Topics.TaggerTopic != "" {
+		next = cfg.Topics.TaggerTopic
+	}
+	writer := b.NewWriter(next)
 
 	var dlq broker.Writer
 	if cfg.Topics.TunerDLQTopic != "" {
@@ -66,6 +77,8 @@ func NewTunerService(pool *tuningcatalog.Pool) (*TunerService, error) {
 
 	return &TunerService{
 		ServiceContext: serviceContext,
+		broker:         b,
+		topics:         []string{cfg.Topics.TunerTopic, next},
 		reader:         reader,
 		writer:         writer,
 		dlq:            dlq,
@@ -75,6 +88,11 @@ func NewTunerService(pool *tuningcatalog.Pool) (*TunerService, error) {
 
 func (service *TunerService) Name() string { return "rule-tuner" }
 
+// Ready reports whether Kafka is reachable and the service's topics exist.
+func (service *TunerService) Ready(ctx context.Context) error {
+	return service.broker.Ping(ctx, service.topics...)
+}
+
 func (service *TunerService) Run(ctx context.Context) errors.Error {
 	return services.RunAlertPipeline(ctx, service.Logger, service.reader, service.writer, service.dlq, 50,
 		services.PipelineCounters{
@@ -100,6 +118,20 @@ func (service *TunerService) Run(ctx context.Context) errors.Error {
 					res.applies = applies
 					return nil
 				}); err != nil {
+					if shed, ok := pools.AsShed(err); ok {
+						tuningShed.WithLabelValues(name, shed.Reason).Inc()
+						switch shed.Policy {
+						case pools.ShedPassThrough:
+							continue
+						case pools.ShedDeadLetter:
+							alert.Attempts++
+							if alert.Attempts < services.MaxPluginAttempts {
+								return false, true
+							}
+							service.Info("alert %s passed through after %d attempts (tuning rule %s overloaded)", alert.AlertID, alert.Attempts, name)
+							continue
+						}
+					}
 					if stderrors.Is(err, pools.ErrPluginRemoved) || stderrors.Is(err, pools.ErrPluginNotFound) {
 						label := "not found"
 						if stderrors.Is(err, pools.ErrPluginRemoved) {
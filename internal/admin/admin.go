@@ -0,0 +1,258 @@
+// Package admin serves the operator API every service exposes next to its metrics endpoint:
+// inspect running plugins and pools, promote or roll back canaries, toggle kill switches,
+// force a plugin reconcile and read the active rule registry generation.
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/harishhary/blink/internal/configuration"
+	svcctx "github.com/harishhary/blink/internal/context"
+	"github.com/harishhary/blink/internal/errors"
+	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/pluginmgr"
+	"github.com/harishhary/blink/internal/pools"
+	"github.com/harishhary/blink/pkg/rules/config"
+)
+
+const defaultAddr = ":8081"
+
+// Pool is the part of a ProcessPool the admin API drives. Every pkg/<type>/pool wrapper satisfies it.
+type Pool interface {
+	Status() []pools.PoolStatus
+	Promote(pluginID string) bool
+	Rollback(pluginID string) bool
+	SetKillSwitch(pluginID string, on bool)
+}
+
+// Plugin is one entry of GET /admin/v1/plugins: the manager's view joined with the pool's.
+type Plugin struct {
+	pluginmgr.PluginStatus
+	Version    string `json:"version,omitempty"` // version of the active pool
+	KillSwitch bool   `json:"kill_switch"`
+}
+
+// Registry is the response of GET /admin/v1/registry.
+type Registry struct {
+	Generation uint64    `json:"generation"`
+	Rules      int       `json:"rules"`
+	LoadedAt   time.Time `json:"loaded_at"`
+}
+
+// KillSwitch is the request body of PUT /admin/v1/pools/{id}/kill-switch.
+type KillSwitch struct {
+	On bool `json:"on"`
+}
+
+// Service runs the admin HTTP API. Any of plugins, pool and registry may be nil for services
+// that have no such component; the matching endpoints then report 404.
+type Service struct {
+	svcctx.ServiceContext
+	serviceName string
+	addr        string
+	token       string
+	plugins     pluginmgr.Plugin
+	pool        Pool
+	registry    *config.Watcher
+}
+
+// Creates the admin service. It listens on ADMIN_ADDR and requires ADMIN_TOKEN as a bearer token;
+// without a token the service stays idle so the API is never exposed unauthenticated.
+func NewService(name, displayName string, plugins pluginmgr.Plugin, pool Pool, registry *config.Watcher) (*Service, error) {
+	sc := svcctx.New(displayName)
+	if err := configuration.LoadFromEnvironment(&sc); err != nil {
+		return nil, err
+	}
+	sc.Logger = logger.New(sc.Name(), "dev")
+
+	addr := sc.Configuration().Admin.Addr
+	if addr == "" {
+		addr = defaultAddr
+	}
+	return &Service{
+		ServiceContext: sc,
+		serviceName:    name,
+		addr:           addr,
+		token:          sc.Configuration().Admin.Token,
+		plugins:        plugins,
+		pool:           pool,
+		registry:       registry,
+	}, nil
+}
+
+func (s *Service) Name() string { return s.serviceName }
+
+// Run serves the API until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) errors.Error {
+	if s.token == "" {
+		s.Info("admin API disabled: ADMIN_TOKEN is not set")
+		<-ctx.Done()
+		return nil
+	}
+
+	srv := &http.Server{Addr: s.addr, Handler: s.Handler(), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	s.Info("admin API listening on %s", s.addr)
+	if err := srv.ListenAndServe(); err != nil && !stderrors.Is(err, http.ErrServerClosed) {
+		return errors.NewE(err)
+	}
+	return nil
+}
+
+// Handler returns the authenticated admin routes.
+func (s *Service) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/v1/plugins", s.listPlugins)
+	mux.HandleFunc("POST /admin/v1/plugins/reconcile", s.reconcile)
+	mux.HandleFunc("DELETE /admin/v1/plugins/{name}/quarantine", s.clearQuarantine)
+	mux.HandleFunc("GET /admin/v1/pools", s.listPools)
+	mux.HandleFunc("POST /admin/v1/pools/{id}/promote", s.promote)
+	mux.HandleFunc("POST /admin/v1/pools/{id}/rollback", s.rollback)
+	mux.HandleFunc("PUT /admin/v1/pools/{id}/kill-switch", s.killSwitch)
+	mux.HandleFunc("GET /admin/v1/registry", s.currentRegistry)
+	return s.authenticate(mux)
+}
+
+func (s *Service) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Service) listPlugins(w http.ResponseWriter, _ *http.Request) {
+	if s.plugins == nil {
+		writeError(w, http.StatusNotFound, "this service runs no plugins")
+		return
+	}
+	active := make(map[string]pools.PoolStatus)
+	if s.pool != nil {
+		for _, p := range s.pool.Status() {
+			if p.Role == pools.RoleActive {
+				active[p.PluginID] = p
+			}
+		}
+	}
+	statuses := s.plugins.Plugins()
+	out := make([]Plugin, 0, len(statuses))
+	for _, st := range statuses {
+		p := active[st.ID]
+		out = append(out, Plugin{PluginStatus: st, Version: p.Version, KillSwitch: p.KillSwitch})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Service) reconcile(w http.ResponseWriter, _ *http.Request) {
+	if s.plugins == nil {
+		writeError(w, http.StatusNotFound, "this service runs no plugins")
+		return
+	}
+	s.Info("admin: reconcile requested")
+	if err := s.plugins.Reconcile(); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// clearQuarantine lifts the quarantine on a crash-looping binary, named by file name, and reconciles
+// so it is started again straight away.
+func (s *Service) clearQuarantine(w http.ResponseWriter, r *http.Request) {
+	if s.plugins == nil {
+		writeError(w, http.StatusNotFound, "this service runs no plugins")
+		return
+	}
+	name := r.PathValue("name")
+	if !s.plugins.ClearQuarantine(name) {
+		writeError(w, http.StatusNotFound, name+" is not quarantined")
+		return
+	}
+	s.Info("admin: quarantine cleared for %s", name)
+	if err := s.plugins.Reconcile(); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Service) listPools(w http.ResponseWriter, _ *http.Request) {
+	if s.pool == nil {
+		writeError(w, http.StatusNotFound, "this service has no plugin pool")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.pool.Status())
+}
+
+func (s *Service) promote(w http.ResponseWriter, r *http.Request) {
+	s.pending(w, r, "promote", func(id string) bool { return s.pool.Promote(id) })
+}
+
+func (s *Service) rollback(w http.ResponseWriter, r *http.Request) {
+	s.pending(w, r, "rollback", func(id string) bool { return s.pool.Rollback(id) })
+}
+
+// pending applies a promote or rollback to the pending pool of the plugin named in the path.
+func (s *Service) pending(w http.ResponseWriter, r *http.Request, action string, apply func(id string) bool) {
+	if s.pool == nil {
+		writeError(w, http.StatusNotFound, "this service has no plugin pool")
+		return
+	}
+	id := r.PathValue("id")
+	if !apply(id) {
+		writeError(w, http.StatusNotFound, "no pending pool for "+id)
+		return
+	}
+	s.Info("admin: %s %s", action, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Service) killSwitch(w http.ResponseWriter, r *http.Request) {
+	if s.pool == nil {
+		writeError(w, http.StatusNotFound, "this service has no plugin pool")
+		return
+	}
+	var body KillSwitch
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+		return
+	}
+	id := r.PathValue("id")
+	s.pool.SetKillSwitch(id, body.On)
+	s.Info("admin: kill switch %s = %t", id, body.On)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Service) currentRegistry(w http.ResponseWriter, _ *http.Request) {
+	if s.registry == nil {
+		writeError(w, http.StatusNotFound, "this service has no rule registry")
+		return
+	}
+	reg := s.registry.Current()
+	writeJSON(w, http.StatusOK, Registry{Generation: reg.Generation(), Rules: reg.Len(), LoadedAt: reg.LoadedAt()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	svcctx "github.com/harishhary/blink/internal/context"
+	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/pools"
+)
+
+func newTestService(pool Pool) *Service {
+	sc := svcctx.New("admin-test")
+	sc.Logger = logger.New(sc.Name(), "dev")
+	return &Service{ServiceContext: sc, serviceName: "admin-test", token: "secret", pool: pool}
+}
+
+func request(t *testing.T, h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminAPI(t *testing.T) {
+	routing := pools.NewRoutingTable()
+	routing.Set("p", pools.PluginRouting{Mode: pools.RolloutModeCanary, RolloutPct: 10})
+	pp := pools.NewProcessPool[int](routing.Config(), nil, nil, time.Second)
+	pp.Register(pools.PoolKey{PluginID: "p", Version: "v1"}, []pools.Worker[int]{{Plugin: 1}}, nil, nil, nil)
+	pp.Register(pools.PoolKey{PluginID: "p", Version: "v2"}, []pools.Worker[int]{{Plugin: 2}}, nil, nil, nil)
+	h := newTestService(pp).Handler()
+
+	for _, token := range []string{"", "wrong"} {
+		if rec := request(t, h, http.MethodGet, "/admin/v1/pools", token, ""); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("token %q: status %d, want 401", token, rec.Code)
+		}
+	}
+
+	rec := request(t, h, http.MethodGet, "/admin/v1/pools", "secret", "")
+	var status []pools.PoolStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil || len(status) != 2 {
+		t.Fatalf("pools: %d %s", rec.Code, rec.Body)
+	}
+	if status[0].Role != pools.RoleActive || status[1].Role != pools.RolePending {
+		t.Fatalf("roles = %s, %s; want active, pending", status[0].Role, status[1].Role)
+	}
+
+	if rec := request(t, h, http.MethodPost, "/admin/v1/pools/p/promote", "secret", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("promote: status %d %s", rec.Code, rec.Body)
+	}
+	if rec := request(t, h, http.MethodPost, "/admin/v1/pools/p/rollback", "secret", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("rollback without pending pool: status %d, want 404", rec.Code)
+	}
+
+	if rec := request(t, h, http.MethodPut, "/admin/v1/pools/p/kill-switch", "secret", `{"on":true}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("kill switch: status %d %s", rec.Code, rec.Body)
+	}
+	err := pp.Call(context.Background(), "p", "", func(context.Context, int) error { return nil })
+	if !errors.Is(err, pools.ErrKillSwitched) {
+		t.Fatalf("call after kill switch = %v, want ErrKillSwitched", err)
+	}
+
+	if rec := request(t, h, http.MethodGet, "/admin/v1/registry", "secret", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("registry without watcher: status %d, want 404", rec.Code)
+	}
+}
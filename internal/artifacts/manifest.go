@@ -0,0 +1,90 @@
+// Package artifacts syncs plugin binaries from a remote artifact store into a plugin directory.
+//
+// A desired-state manifest in the store pins every plugin to a version and the sha256 of its binary
+// (and, optionally, of its <name>.yaml sidecar). Artifacts are downloaded into a local cache, verified,
+// and symlinked into the plugin directory, so replicas reading the same manifest converge on the same
+// plugin set. Rolling forward or back is an edit of the manifest.
+//
+// Store layout, relative to the store root:
+//
+//	manifest.yaml
+//	<type>/<name>/<version>/<name>        plugin binary
+//	<type>/<name>/<version>/<name>.yaml   optional sidecar
+//
+// Manifest example:
+//
+//	rule:
+//	  brute_force_login:
+//	    version: "1.2.0"
+//	    sha256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+//	    config_sha256: "60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"
+//	matcher:
+//	  prod-accounts:
+//	    version: "0.3.1"
+//	    sha256: "..."
+package artifacts
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// Manifest maps plugin type (the adapter's PluginKey, e.g. "rule") to plugin name to its pinned artifact.
+type Manifest map[string]map[string]Entry
+
+// Entry pins one plugin.
+type Entry struct {
+	Version string `yaml:"version"`
+	SHA256  string `yaml:"sha256"`
+	// ConfigSHA256 pins the <name>.yaml sidecar. Leave empty for plugins shipped without one.
+	ConfigSHA256 string `yaml:"config_sha256,omitempty"`
+}
+
+// ParseManifest decodes and validates a manifest.
+func ParseManifest(data []byte) (Manifest, error) {
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	for typ, plugins := range m {
+		if !validName(typ) {
+			return nil, fmt.Errorf("manifest: invalid plugin type %q", typ)
+		}
+		for name, e := range plugins {
+			if err := e.validate(name); err != nil {
+				return nil, fmt.Errorf("manifest: %s/%s: %w", typ, name, err)
+			}
+		}
+	}
+	return m, nil
+}
+
+func (e Entry) validate(name string) error {
+	if !validName(name) {
+		return fmt.Errorf("invalid plugin name")
+	}
+	if !validName(e.Version) {
+		return fmt.Errorf("invalid version %q", e.Version)
+	}
+	if !validSHA256(e.SHA256) {
+		return fmt.Errorf("sha256 must be 64 hex characters")
+	}
+	if e.ConfigSHA256 != "" && !validSHA256(e.ConfigSHA256) {
+		return fmt.Errorf("config_sha256 must be 64 hex characters")
+	}
+	return nil
+}
+
+// validName rejects anything that could escape its directory in the store or the plugin dir,
+// and hidden names, which the plugin manager ignores.
+func validName(s string) bool {
+	return s != "" && !strings.HasPrefix(s, ".") && !strings.ContainsAny(s, `/\`) && !strings.ContainsRune(s, 0)
+}
+
+func validSHA256(s string) bool {
+	b, err := hex.DecodeString(s)
+	return err == nil && len(b) == 32
+}
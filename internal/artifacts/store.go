@@ -0,0 +1,85 @@
+package artifacts
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by a Store when the requested object does not exist.
+var ErrNotFound = stderrors.New("artifact not found")
+
+// Store reads objects from an artifact store by slash-separated path relative to its root.
+type Store interface {
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+}
+
+// NewStore returns an HTTPStore for http(s) locations and a DirStore for file:// URLs and plain paths.
+// token, if set, is sent as a bearer token by the HTTPStore.
+func NewStore(location, token string) (Store, error) {
+	u, err := url.Parse(location)
+	if err == nil {
+		switch u.Scheme {
+		case "http", "https":
+			return &HTTPStore{Base: u, Token: token, Client: &http.Client{Timeout: 5 * time.Minute}}, nil
+		case "file":
+			return DirStore(u.Path), nil
+		}
+	}
+	if location == "" {
+		return nil, fmt.Errorf("artifacts: empty store location")
+	}
+	return DirStore(location), nil
+}
+
+// DirStore is a Store backed by a local directory, e.g. a mounted volume or a test fixture.
+type DirStore string
+
+func (d DirStore) Open(_ context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(string(d), filepath.FromSlash(path.Clean("/"+name))))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return f, err
+}
+
+// HTTPStore is a Store served over HTTP: a static file server, or an object store bucket
+// exposed through its HTTP endpoint. Objects are fetched with GET <Base>/<name>.
+type HTTPStore struct {
+	Base   *url.URL
+	Token  string
+	Client *http.Client
+}
+
+func (s *HTTPStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	u := *s.Base
+	u.Path = strings.TrimSuffix(u.Path, "/") + path.Clean("/"+name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	case resp.StatusCode >= 300:
+		resp.Body.Close()
+		return nil, fmt.Errorf("artifacts: GET %s: %s", u.Redacted(), resp.Status)
+	}
+	return resp.Body, nil
+}
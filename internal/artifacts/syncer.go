@@ -0,0 +1,326 @@
+package artifacts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// DefaultManifest is the manifest path used when none is configured.
+const DefaultManifest = "manifest.yaml"
+
+var (
+	syncs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "blink", Subsystem: "plugin_artifacts", Name: "syncs_total",
+		Help: "Artifact syncs by plugin type and result (ok, changed, error).",
+	}, []string{"type", "result"})
+	downloads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "blink", Subsystem: "plugin_artifacts", Name: "downloads_total",
+		Help: "Artifact downloads by plugin type and result (ok, checksum_mismatch, error).",
+	}, []string{"type", "result"})
+)
+
+// Syncer makes a plugin directory match the manifest in a Store. It implements pluginmgr.Source.
+//
+// Verified artifacts live in the cache as <cache>/<type>/<name>/<version>-<sha256 prefix>/<file>
+// and the plugin directory holds symlinks to them. A link is swapped with a rename, so the plugin
+// manager never sees a partially written binary. Files in the plugin directory that are not links
+// into the cache are left alone, so locally installed plugins keep working next to synced ones.
+type Syncer struct {
+	store    Store
+	manifest string
+	cache    string
+	mu       sync.Mutex // syncs share the cache; run them one at a time
+}
+
+// Creates a Syncer that reads manifestPath (DefaultManifest if empty) from store and caches artifacts under cacheDir.
+func NewSyncer(store Store, manifestPath, cacheDir string) (*Syncer, error) {
+	if manifestPath == "" {
+		manifestPath = DefaultManifest
+	}
+	abs, err := filepath.Abs(cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("artifacts: cache dir: %w", err)
+	}
+	return &Syncer{store: store, manifest: manifestPath, cache: abs}, nil
+}
+
+// Sync brings dir in line with the manifest entries for pluginKey and reports whether any link changed.
+// If the manifest cannot be read, dir is left untouched. If one plugin fails to download or verify,
+// its current link (if any) is kept and the others are still synced; the failures are returned joined.
+func (s *Syncer) Sync(ctx context.Context, pluginKey, dir string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, err := s.fetchManifest(ctx)
+	if err != nil {
+		syncs.WithLabelValues(pluginKey, "error").Inc()
+		return false, err
+	}
+	desired := m[pluginKey]
+	typeCache := filepath.Join(s.cache, pluginKey)
+
+	names := make([]string, 0, len(desired))
+	for name := range desired {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	changed := false
+	wanted := make(map[string]struct{}) // link names in dir that the manifest accounts for
+	var errs []error
+	for _, name := range names {
+		e := desired[name]
+		wanted[name] = struct{}{}
+		if e.ConfigSHA256 != "" {
+			wanted[name+".yaml"] = struct{}{}
+		}
+		c, err := s.install(ctx, pluginKey, name, e, dir)
+		changed = changed || c
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s@%s: %w", pluginKey, name, e.Version, err))
+		}
+	}
+
+	c, err := s.unlinkStale(dir, typeCache, wanted)
+	changed = changed || c
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.gc(dir, typeCache); err != nil {
+		errs = append(errs, err)
+	}
+
+	result := "ok"
+	switch {
+	case len(errs) > 0:
+		result = "error"
+	case changed:
+		result = "changed"
+	}
+	syncs.WithLabelValues(pluginKey, result).Inc()
+	return changed, stderrors.Join(errs...)
+}
+
+func (s *Syncer) fetchManifest(ctx context.Context) (Manifest, error) {
+	r, err := s.store.Open(ctx, s.manifest)
+	if err != nil {
+		return nil, fmt.Errorf("artifacts: manifest: %w", err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, 16<<20))
+	if err != nil {
+		return nil, fmt.Errorf("artifacts: manifest: %w", err)
+	}
+	return ParseManifest(data)
+}
+
+// install fetches one plugin's sidecar and binary into the cache and points the links in dir at them.
+// Both files are verified before either link moves, and the sidecar is linked first so a rule's
+// config is in place before its binary is discovered.
+func (s *Syncer) install(ctx context.Context, pluginKey, name string, e Entry, dir string) (bool, error) {
+	remote := path.Join(pluginKey, name, e.Version)
+	local := filepath.Join(s.cache, pluginKey, name, e.Version+"-"+e.SHA256[:12])
+
+	var cfg string
+	if e.ConfigSHA256 != "" {
+		var err error
+		if cfg, err = s.fetch(ctx, pluginKey, path.Join(remote, name+".yaml"), filepath.Join(local, name+".yaml"), e.ConfigSHA256, 0o644); err != nil {
+			return false, err
+		}
+	}
+	bin, err := s.fetch(ctx, pluginKey, path.Join(remote, name), filepath.Join(local, name), e.SHA256, 0o755)
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	if cfg != "" {
+		if changed, err = link(filepath.Join(dir, name+".yaml"), cfg); err != nil {
+			return changed, err
+		}
+	}
+	c, err := link(filepath.Join(dir, name), bin)
+	return changed || c, err
+}
+
+// fetch downloads src to dst unless dst is already cached with sha256 sum; a cached file that no longer
+// matches is downloaded again. The download goes to a temporary file that is only renamed into place once
+// its sha256 matches sum, so the cache never holds an unverified file.
+func (s *Syncer) fetch(ctx context.Context, pluginKey, src, dst, sum string, mode os.FileMode) (string, error) {
+	if got, err := fileSHA256(dst); err == nil && strings.EqualFold(got, sum) {
+		return dst, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return "", err
+	}
+
+	r, err := s.store.Open(ctx, src)
+	if err != nil {
+		downloads.WithLabelValues(pluginKey, "error").Inc()
+		return "", err
+	}
+	defer r.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		downloads.WithLabelValues(pluginKey, "error").Inc()
+		return "", fmt.Errorf("download %s: %w", src, err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, sum) {
+		downloads.WithLabelValues(pluginKey, "checksum_mismatch").Inc()
+		return "", fmt.Errorf("download %s: sha256 %s does not match pinned %s", src, got, sum)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return "", err
+	}
+	downloads.WithLabelValues(pluginKey, "ok").Inc()
+	return dst, nil
+}
+
+func fileSHA256(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// link points linkPath at target by renaming a fresh symlink over it. It refuses to replace anything
+// that is not a symlink, so files installed by hand are never clobbered.
+func link(linkPath, target string) (bool, error) {
+	if fi, err := os.Lstat(linkPath); err == nil {
+		if fi.Mode()&os.ModeSymlink == 0 {
+			return false, fmt.Errorf("refusing to replace unmanaged file %s", linkPath)
+		}
+		if cur, err := os.Readlink(linkPath); err == nil && cur == target {
+			return false, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	// Hidden, so the plugin manager and config watcher skip it while it exists.
+	tmp := filepath.Join(filepath.Dir(linkPath), "."+filepath.Base(linkPath)+".tmp")
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp, linkPath); err != nil {
+		_ = os.Remove(tmp)
+		return false, err
+	}
+	return true, nil
+}
+
+// managedLinks returns the links in dir that point into typeCache, keyed by name, with their targets.
+func managedLinks(dir, typeCache string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	links := make(map[string]string)
+	for _, e := range entries {
+		if e.Type()&os.ModeSymlink == 0 {
+			continue
+		}
+		target, err := os.Readlink(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(target, typeCache+string(filepath.Separator)) {
+			links[e.Name()] = target
+		}
+	}
+	return links, nil
+}
+
+// unlinkStale removes the links this Syncer created for plugins that left the manifest.
+func (s *Syncer) unlinkStale(dir, typeCache string, wanted map[string]struct{}) (bool, error) {
+	links, err := managedLinks(dir, typeCache)
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	var errs []error
+	for name := range links {
+		if _, ok := wanted[name]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		changed = true
+	}
+	return changed, stderrors.Join(errs...)
+}
+
+// gc deletes cached versions no link points at any more. Running subprocesses keep their open
+// executable, so removing an old version under a draining plugin is safe.
+func (s *Syncer) gc(dir, typeCache string) error {
+	links, err := managedLinks(dir, typeCache)
+	if err != nil {
+		return err
+	}
+	inUse := make(map[string]struct{}, len(links))
+	for _, target := range links {
+		inUse[filepath.Dir(target)] = struct{}{}
+	}
+
+	plugins, err := os.ReadDir(typeCache)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, p := range plugins {
+		versions, err := os.ReadDir(filepath.Join(typeCache, p.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, v := range versions {
+			vdir := filepath.Join(typeCache, p.Name(), v.Name())
+			if _, ok := inUse[vdir]; ok {
+				continue
+			}
+			if err := os.RemoveAll(vdir); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return stderrors.Join(errs...)
+}
@@ -0,0 +1,150 @@
+package artifacts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// publish writes an artifact into the store layout and returns its sha256.
+func publish(t *testing.T, store, typ, name, version, file, content string) string {
+	t.Helper()
+	dir := filepath.Join(store, typ, name, version)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeManifest(t *testing.T, store, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(store, DefaultManifest), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readLinked(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestSyncFollowsManifest(t *testing.T) {
+	store, cache, dir := t.TempDir(), t.TempDir(), t.TempDir()
+	s, err := NewSyncer(DirStore(store), "", cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	v1 := publish(t, store, "rule", "brute", "1.0.0", "brute", "binary v1")
+	cfg1 := publish(t, store, "rule", "brute", "1.0.0", "brute.yaml", "name: brute\n")
+	v2 := publish(t, store, "rule", "brute", "2.0.0", "brute", "binary v2")
+
+	// A hand-installed plugin next to the synced ones must be left alone.
+	if err := os.WriteFile(filepath.Join(dir, "local"), []byte("local"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeManifest(t, store, fmt.Sprintf("rule:\n  brute:\n    version: 1.0.0\n    sha256: %s\n    config_sha256: %s\n", v1, cfg1))
+	if changed, err := s.Sync(ctx, "rule", dir); err != nil || !changed {
+		t.Fatalf("initial sync: changed=%v err=%v", changed, err)
+	}
+	if got := readLinked(t, filepath.Join(dir, "brute")); got != "binary v1" {
+		t.Fatalf("brute = %q, want v1", got)
+	}
+	if fi, err := os.Stat(filepath.Join(dir, "brute")); err != nil || fi.Mode()&0o111 == 0 {
+		t.Fatalf("synced binary is not executable: %v %v", fi, err)
+	}
+	if got := readLinked(t, filepath.Join(dir, "brute.yaml")); got != "name: brute\n" {
+		t.Fatalf("sidecar = %q", got)
+	}
+	if changed, err := s.Sync(ctx, "rule", dir); err != nil || changed {
+		t.Fatalf("idempotent sync: changed=%v err=%v", changed, err)
+	}
+
+	// Roll forward without a sidecar: the binary link moves, the sidecar link and the old version go.
+	writeManifest(t, store, fmt.Sprintf("rule:\n  brute:\n    version: 2.0.0\n    sha256: %s\n", v2))
+	if changed, err := s.Sync(ctx, "rule", dir); err != nil || !changed {
+		t.Fatalf("roll forward: changed=%v err=%v", changed, err)
+	}
+	if got := readLinked(t, filepath.Join(dir, "brute")); got != "binary v2" {
+		t.Fatalf("brute = %q, want v2", got)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "brute.yaml")); !os.IsNotExist(err) {
+		t.Fatalf("stale sidecar link still present: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cache, "rule", "brute", "1.0.0-"+v1[:12])); !os.IsNotExist(err) {
+		t.Fatalf("old version not garbage collected: %v", err)
+	}
+
+	// A checksum mismatch keeps the current version serving.
+	writeManifest(t, store, fmt.Sprintf("rule:\n  brute:\n    version: 1.0.0\n    sha256: %s\n", strings.Repeat("0", 64)))
+	if _, err := s.Sync(ctx, "rule", dir); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("want checksum mismatch, got %v", err)
+	}
+	if got := readLinked(t, filepath.Join(dir, "brute")); got != "binary v2" {
+		t.Fatalf("brute = %q after failed sync, want v2", got)
+	}
+
+	// Dropping the plugin from the manifest unlinks it; other types and local files are untouched.
+	writeManifest(t, store, "matcher: {}\n")
+	if changed, err := s.Sync(ctx, "rule", dir); err != nil || !changed {
+		t.Fatalf("removal: changed=%v err=%v", changed, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != "local" {
+		t.Fatalf("dir after removal = %v, want only the local plugin", entries)
+	}
+}
+
+func TestSyncRefetchesCorruptCache(t *testing.T) {
+	store, cache, dir := t.TempDir(), t.TempDir(), t.TempDir()
+	s, err := NewSyncer(DirStore(store), "", cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	v1 := publish(t, store, "rule", "brute", "1.0.0", "brute", "binary v1")
+	writeManifest(t, store, fmt.Sprintf("rule:\n  brute:\n    version: 1.0.0\n    sha256: %s\n", v1))
+	if _, err := s.Sync(ctx, "rule", dir); err != nil {
+		t.Fatal(err)
+	}
+
+	cached := filepath.Join(cache, "rule", "brute", "1.0.0-"+v1[:12], "brute")
+	if err := os.WriteFile(cached, []byte("tampered"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Sync(ctx, "rule", dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := readLinked(t, filepath.Join(dir, "brute")); got != "binary v1" {
+		t.Fatalf("brute = %q after the cached copy changed, want it fetched again", got)
+	}
+}
+
+func TestParseManifestRejectsUnsafeNames(t *testing.T) {
+	sum := strings.Repeat("a", 64)
+	for _, body := range []string{
+		fmt.Sprintf("rule:\n  ../evil:\n    version: 1\n    sha256: %s\n", sum),
+		fmt.Sprintf("rule:\n  ok:\n    version: ../1\n    sha256: %s\n", sum),
+		"rule:\n  ok:\n    version: 1\n    sha256: abc\n",
+	} {
+		if _, err := ParseManifest([]byte(body)); err == nil {
+			t.Errorf("accepted %q", body)
+		}
+	}
+}
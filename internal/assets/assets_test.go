@@ -0,0 +1,146 @@
+package assets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harishhary/blink/pkg/events"
+	"github.com/harishhary/blink/pkg/scoring"
+)
+
+const inventoryCSV = `id,kind,names,addresses,owner,criticality,tags
+web-01,host,WEB-01;web-01.corp.example,10.0.4.11,team-web,high,prod;pci
+office-lan,ip,,10.20.0.0/16,it-ops,low,office
+office-servers,ip,,10.20.5.0/24,it-ops,medium,
+svc-backup,account,svc-backup@corp.example,,it-ops,critical,service-account
+dup,host,web-01,,someone,low,
+`
+
+func loadTestInventory(t *testing.T) *Inventory {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hosts.csv"), []byte(inventoryCSV), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cloud.json"), []byte(`{"assets":[{"id":"prod-db","kind":"resource","names":["arn:aws:rds:eu-west-1:1:db:prod"],"criticality":"critical"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	assets, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv, err := NewInventory(assets)
+	if err == nil || !strings.Contains(err.Error(), "name web-01 belongs to web-01") {
+		t.Fatalf("NewInventory error = %v, want the duplicate name reported", err)
+	}
+	return inv
+}
+
+func TestLookup(t *testing.T) {
+	inv := loadTestInventory(t)
+	if inv.Len() != 5 {
+		t.Fatalf("%d assets indexed, want 5", inv.Len())
+	}
+	for value, want := range map[string]string{
+		"web-01.CORP.example":             "web-01",
+		"10.0.4.11":                       "web-01",
+		"::ffff:10.0.4.11":                "web-01",
+		"10.20.5.9":                       "office-servers", // the narrower network wins
+		"10.20.7.1":                       "office-lan",
+		"arn:aws:rds:eu-west-1:1:db:prod": "prod-db",
+		"10.9.9.9":                        "",
+		"unknown-host":                    "",
+	} {
+		got := ""
+		if a, ok := inv.Lookup(value); ok {
+			got = a.ID
+		}
+		if got != want {
+			t.Errorf("Lookup(%s) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestTagAndPolicy(t *testing.T) {
+	inv := loadTestInventory(t)
+	evt := events.Event{
+		"client": "10.0.4.11",
+		events.NormalizedKey: map[string]any{
+			"src_endpoint": map[string]any{"ip": "10.0.4.11"},
+			"user":         map[string]any{"name": "svc-backup@corp.example"},
+		},
+	}
+	matches := inv.Tag(evt, DefaultFields)
+	if len(matches) != 2 || matches[0].Asset != "web-01" || matches[1].Asset != "svc-backup" {
+		t.Fatalf("matches = %+v, want web-01 by src_endpoint.ip and svc-backup by user.name", matches)
+	}
+	attached := Attached(evt)
+	if len(attached) != 2 || attached[1].Criticality != CriticalityCritical || attached[0].Tags[1] != "pci" {
+		t.Errorf("attached = %+v, want the matches read back", attached)
+	}
+
+	policy, err := Config{Severity: map[string]Adjustment{
+		"critical": {Raise: 2, Min: "high"},
+		"low":      {Raise: -1},
+	}}.Policy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := policy.Apply(scoring.SeverityLow, matches); got != scoring.SeverityHigh {
+		t.Errorf("low alert on a critical asset = %s, want high", got)
+	}
+	if got := policy.Apply(scoring.SeverityHigh, matches); got != scoring.SeverityCritical {
+		t.Errorf("high alert on a critical asset = %s, want critical", got)
+	}
+	lan := inv.Find(events.Event{"src_endpoint": map[string]any{"ip": "10.20.7.1"}}, DefaultFields)
+	if got := policy.Apply(scoring.SeverityInfo, lan); got != scoring.SeverityInfo {
+		t.Errorf("info alert on a low asset = %s, want info", got)
+	}
+	if got := policy.Apply(scoring.SeverityMedium, nil); got != scoring.SeverityMedium {
+		t.Errorf("alert without assets = %s, want it unchanged", got)
+	}
+}
+
+func TestTagDropsForgedAssets(t *testing.T) {
+	forged := func() events.Event {
+		return events.Event{
+			"client":         "10.9.9.9",
+			events.AssetsKey: []any{map[string]any{"asset": "dc-01", "criticality": "critical"}},
+		}
+	}
+	evt := forged()
+	if matches := loadTestInventory(t).Tag(evt, DefaultFields); len(matches) != 0 {
+		t.Fatalf("matches = %+v, want none", matches)
+	}
+	if _, ok := evt[events.AssetsKey]; ok {
+		t.Errorf("an event that mentions no asset kept the assets it arrived with: %v", evt)
+	}
+	evt = forged()
+	(*Watcher)(nil).Tag(evt)
+	if _, ok := evt[events.AssetsKey]; ok {
+		t.Errorf("an event kept the assets it arrived with when no inventory is configured: %v", evt)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[{"id":"vpn","kind":"host","names":["vpn-gw"],"criticality":"urgent"},{"id":"dns","kind":"host","names":["ns1"]}]`))
+	}))
+	defer srv.Close()
+
+	if _, err := Fetch(t.Context(), srv.Client(), srv.URL, "wrong"); err == nil {
+		t.Error("Fetch with a bad token succeeded")
+	}
+	assets, err := Fetch(t.Context(), srv.Client(), srv.URL, "t0ken")
+	if err == nil || len(assets) != 1 || assets[0].ID != "dns" {
+		t.Errorf("Fetch = %v, %v; want dns and an error for vpn's criticality", assets, err)
+	}
+}
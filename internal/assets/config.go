@@ -0,0 +1,129 @@
+package assets
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/harishhary/blink/internal/secrets"
+	"github.com/harishhary/blink/pkg/events"
+	"github.com/harishhary/blink/pkg/scoring"
+	"go.yaml.in/yaml/v4"
+)
+
+const defaultRefresh = 10 * time.Minute
+
+// DefaultFields are the event fields looked up when a config lists none: the common-schema fields
+// that name hosts, addresses, accounts and resources.
+var DefaultFields = []string{
+	"src_endpoint.ip", "src_endpoint.hostname",
+	"dst_endpoint.ip", "dst_endpoint.hostname",
+	"device.ip", "device.hostname",
+	"user.name", "user.uid",
+	"resources[*].uid",
+}
+
+// Config is the ASSET_CONFIG file shared by the services that tag assets:
+//
+//	dir: /etc/blink/assets               # *.csv and *.json inventory files, reloaded on change
+//	api:                                 # and/or an inventory API returning the JSON format
+//	  url: https://cmdb.example.com/api/assets
+//	  token: { env: CMDB_TOKEN }
+//	  refresh: 10m
+//	fields: [src_endpoint.ip, user.name] # event fields to look up; DefaultFields if omitted
+//	severity:                            # alert severity adjustments by the highest asset criticality
+//	  critical: { raise: 2, min: high }
+//	  high:     { raise: 1 }
+//	  low:      { raise: -1 }
+type Config struct {
+	Dir      string                `yaml:"dir"`
+	API      APIConfig             `yaml:"api"`
+	Fields   []string              `yaml:"fields"`
+	Severity map[string]Adjustment `yaml:"severity"`
+}
+
+// APIConfig is an inventory API polled every Refresh (default 10m).
+type APIConfig struct {
+	URL     string        `yaml:"url"`
+	Token   secrets.Ref   `yaml:"token"`
+	Refresh time.Duration `yaml:"refresh"`
+}
+
+// Adjustment changes an alert's severity by Raise levels (lowering it when negative), then lifts it
+// to at least Min. The result stays between info and critical.
+type Adjustment struct {
+	Raise int    `yaml:"raise"`
+	Min   string `yaml:"min"`
+}
+
+// LoadConfig reads and validates an asset config file.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("assets: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Load(data, &cfg, yaml.WithKnownFields()); err != nil {
+		return Config{}, fmt.Errorf("assets: parse %s: %w", path, err)
+	}
+	if cfg.Dir == "" && cfg.API.URL == "" {
+		return Config{}, fmt.Errorf("assets: %s sets neither dir nor api.url", path)
+	}
+	if cfg.API.Refresh <= 0 {
+		cfg.API.Refresh = defaultRefresh
+	}
+	if len(cfg.Fields) == 0 {
+		cfg.Fields = DefaultFields
+	}
+	for _, f := range cfg.Fields {
+		if _, err := events.CompilePath(f); err != nil {
+			return Config{}, fmt.Errorf("assets: field %q: %w", f, err)
+		}
+	}
+	if _, err := cfg.Policy(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Policy is a compiled set of severity adjustments keyed by criticality.
+type Policy map[Criticality]policyStep
+
+type policyStep struct {
+	raise int
+	min   scoring.Severity
+}
+
+// Policy compiles the config's severity adjustments.
+func (c Config) Policy() (Policy, error) {
+	p := make(Policy, len(c.Severity))
+	for name, adj := range c.Severity {
+		crit, err := ParseCriticality(name)
+		if err != nil || crit == CriticalityUnknown {
+			return nil, fmt.Errorf("assets: severity: %q is not a criticality", name)
+		}
+		step := policyStep{raise: adj.Raise}
+		if adj.Min != "" {
+			if step.min, err = scoring.ParseSeverity(adj.Min); err != nil {
+				return nil, fmt.Errorf("assets: severity.%s.min: %w", name, err)
+			}
+		}
+		p[crit] = step
+	}
+	return p, nil
+}
+
+// Apply adjusts severity for the most critical of the assets matched. Without a matching asset, or a
+// step for its criticality, severity is returned unchanged.
+func (p Policy) Apply(severity scoring.Severity, matches []Match) scoring.Severity {
+	highest := CriticalityUnknown
+	for _, m := range matches {
+		highest = max(highest, m.Criticality)
+	}
+	step, ok := p[highest]
+	if !ok {
+		return severity
+	}
+	s := min(max(severity+scoring.Severity(step.raise), scoring.SeverityInfo), scoring.SeverityCritical)
+	return max(s, step.min)
+}
@@ -0,0 +1,195 @@
+// Package assets keeps an inventory of the organisation's hosts, addresses, accounts and cloud
+// resources, with their owner, criticality and tags, and attaches the assets an event mentions to it.
+//
+// The event matcher tags events before matching, so rules and matchers can test asset context, and
+// the alert tagger tags alerts and adjusts their severity from the criticality of the assets involved.
+package assets
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Criticality ranks how much an asset matters. The zero value is an asset of unknown criticality.
+type Criticality int
+
+const (
+	CriticalityUnknown Criticality = iota
+	CriticalityLow
+	CriticalityMedium
+	CriticalityHigh
+	CriticalityCritical
+)
+
+func (c Criticality) String() string {
+	switch c {
+	case CriticalityLow:
+		return "low"
+	case CriticalityMedium:
+		return "medium"
+	case CriticalityHigh:
+		return "high"
+	case CriticalityCritical:
+		return "critical"
+	default:
+		return ""
+	}
+}
+
+// ParseCriticality reads a criticality name; the empty string is CriticalityUnknown.
+func ParseCriticality(s string) (Criticality, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return CriticalityUnknown, nil
+	case "low":
+		return CriticalityLow, nil
+	case "medium":
+		return CriticalityMedium, nil
+	case "high":
+		return CriticalityHigh, nil
+	case "critical":
+		return CriticalityCritical, nil
+	default:
+		return CriticalityUnknown, fmt.Errorf("unknown criticality %q", s)
+	}
+}
+
+// Asset is one inventory entry. It is found by any of its Names (hostnames, account names, cloud
+// resource IDs, compared case-insensitively) or Addresses (IPs, or CIDRs covering every address in
+// them).
+type Asset struct {
+	ID          string      `json:"id"`
+	Kind        string      `json:"kind"` // host, ip, account or resource
+	Names       []string    `json:"names"`
+	Addresses   []string    `json:"addresses"`
+	Owner       string      `json:"owner"`
+	Criticality Criticality `json:"-"`
+	Tags        []string    `json:"tags"`
+}
+
+var kinds = []string{"host", "ip", "account", "resource"}
+
+// Inventory is an immutable, indexed set of assets.
+type Inventory struct {
+	assets   []*Asset
+	byName   map[string]*Asset
+	byAddr   map[netip.Addr]*Asset
+	prefixes []prefix // longest first, so the narrowest network wins
+	loadedAt time.Time
+
+	generation uint64
+}
+
+type prefix struct {
+	net   netip.Prefix
+	asset *Asset
+}
+
+// NewInventory indexes assets. Assets that are invalid, or that reuse another's ID, name or address,
+// are left out and reported in the returned error alongside the inventory of the rest.
+func NewInventory(assets []Asset) (*Inventory, error) {
+	inv := &Inventory{
+		byName:   make(map[string]*Asset),
+		byAddr:   make(map[netip.Addr]*Asset),
+		loadedAt: time.Now(),
+	}
+	ids := make(map[string]bool)
+	var errs []string
+	for i := range assets {
+		a := &assets[i]
+		if err := inv.add(a, ids); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	slices.SortStableFunc(inv.prefixes, func(a, b prefix) int { return b.net.Bits() - a.net.Bits() })
+	if len(errs) > 0 {
+		return inv, fmt.Errorf("assets: %d asset(s) skipped:\n  %s", len(errs), strings.Join(errs, "\n  "))
+	}
+	return inv, nil
+}
+
+func (inv *Inventory) add(a *Asset, ids map[string]bool) error {
+	switch {
+	case a.ID == "":
+		return fmt.Errorf("asset with names %v has no id", a.Names)
+	case ids[a.ID]:
+		return fmt.Errorf("%s: id is used by another asset", a.ID)
+	case !slices.Contains(kinds, a.Kind):
+		return fmt.Errorf("%s: kind must be one of %s", a.ID, strings.Join(kinds, ", "))
+	case len(a.Names) == 0 && len(a.Addresses) == 0:
+		return fmt.Errorf("%s: no names or addresses to find it by", a.ID)
+	}
+
+	names := make([]string, 0, len(a.Names))
+	for _, n := range a.Names {
+		key := strings.ToLower(strings.TrimSpace(n))
+		if other, ok := inv.byName[key]; ok {
+			return fmt.Errorf("%s: name %s belongs to %s", a.ID, n, other.ID)
+		}
+		names = append(names, key)
+	}
+	var addrs []netip.Addr
+	var nets []netip.Prefix
+	for _, s := range a.Addresses {
+		s = strings.TrimSpace(s)
+		if strings.Contains(s, "/") {
+			p, err := netip.ParsePrefix(s)
+			if err != nil {
+				return fmt.Errorf("%s: %s", a.ID, err)
+			}
+			nets = append(nets, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return fmt.Errorf("%s: %s", a.ID, err)
+		}
+		if other, ok := inv.byAddr[addr.Unmap()]; ok {
+			return fmt.Errorf("%s: address %s belongs to %s", a.ID, s, other.ID)
+		}
+		addrs = append(addrs, addr.Unmap())
+	}
+
+	ids[a.ID] = true
+	inv.assets = append(inv.assets, a)
+	for _, n := range names {
+		inv.byName[n] = a
+	}
+	for _, addr := range addrs {
+		inv.byAddr[addr] = a
+	}
+	for _, p := range nets {
+		inv.prefixes = append(inv.prefixes, prefix{net: p, asset: a})
+	}
+	return nil
+}
+
+// Lookup finds the asset an identifier belongs to: an IP by its address or the narrowest network
+// containing it, anything else by name.
+func (inv *Inventory) Lookup(value string) (*Asset, bool) {
+	value = strings.TrimSpace(value)
+	if addr, err := netip.ParseAddr(value); err == nil {
+		addr = addr.Unmap()
+		if a, ok := inv.byAddr[addr]; ok {
+			return a, true
+		}
+		for _, p := range inv.prefixes {
+			if p.net.Contains(addr) {
+				return p.asset, true
+			}
+		}
+		return nil, false
+	}
+	a, ok := inv.byName[strings.ToLower(value)]
+	return a, ok
+}
+
+func (inv *Inventory) Len() int { return len(inv.assets) }
+
+func (inv *Inventory) LoadedAt() time.Time { return inv.loadedAt }
+
+// Generation counts the inventories a Watcher has published, starting at 1; 0 for one built directly.
+func (inv *Inventory) Generation() uint64 { return inv.generation }
@@ -0,0 +1,202 @@
+package assets
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// record is an asset as written in an inventory file or API response. In JSON the list fields are
+// arrays; in CSV they are separated by semicolons:
+//
+//	id,kind,names,addresses,owner,criticality,tags
+//	web-01,host,web-01;web-01.corp.example,10.0.4.11,team-web,high,prod;pci
+//	office-lan,ip,,10.20.0.0/16,it-ops,low,office
+type record struct {
+	ID          string   `json:"id"`
+	Kind        string   `json:"kind"`
+	Names       []string `json:"names"`
+	Addresses   []string `json:"addresses"`
+	Owner       string   `json:"owner"`
+	Criticality string   `json:"criticality"`
+	Tags        []string `json:"tags"`
+}
+
+func (r record) asset() (Asset, error) {
+	c, err := ParseCriticality(r.Criticality)
+	if err != nil {
+		return Asset{}, fmt.Errorf("%s: %s", r.ID, err)
+	}
+	return Asset{
+		ID:          r.ID,
+		Kind:        strings.ToLower(r.Kind),
+		Names:       r.Names,
+		Addresses:   r.Addresses,
+		Owner:       r.Owner,
+		Criticality: c,
+		Tags:        r.Tags,
+	}, nil
+}
+
+// decodeJSON reads a JSON array of records, or an object holding one under "assets".
+func decodeJSON(r io.Reader) ([]record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var records []record
+	if err := json.Unmarshal(data, &records); err == nil {
+		return records, nil
+	}
+	var wrapped struct {
+		Assets []record `json:"assets"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, err
+	}
+	return wrapped.Assets, nil
+}
+
+func decodeCSV(r io.Reader) ([]record, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	col := make(map[string]int)
+	for i, name := range rows[0] {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"id", "kind"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("no %s column", required)
+		}
+	}
+	cell := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+	list := func(row []string, name string) []string {
+		var out []string
+		for _, v := range strings.Split(cell(row, name), ";") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+		return out
+	}
+	records := make([]record, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		records = append(records, record{
+			ID:          cell(row, "id"),
+			Kind:        cell(row, "kind"),
+			Names:       list(row, "names"),
+			Addresses:   list(row, "addresses"),
+			Owner:       cell(row, "owner"),
+			Criticality: cell(row, "criticality"),
+			Tags:        list(row, "tags"),
+		})
+	}
+	return records, nil
+}
+
+// LoadDir reads every *.csv and *.json inventory file in dir, in name order. It returns the assets
+// that loaded together with an error listing the files and records that did not.
+func LoadDir(dir string) ([]Asset, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("assets: read dir %s: %w", dir, err)
+	}
+	assets := []Asset{}
+	var errs []string
+	for _, e := range entries {
+		if e.IsDir() || !isInventory(e.Name()) {
+			continue
+		}
+		loaded, err := loadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", e.Name(), err))
+		}
+		assets = append(assets, loaded...)
+	}
+	if len(errs) > 0 {
+		return assets, fmt.Errorf("assets: %s", strings.Join(errs, "\n  "))
+	}
+	return assets, nil
+}
+
+func loadFile(path string) ([]Asset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var records []record
+	if strings.HasSuffix(path, ".csv") {
+		records, err = decodeCSV(f)
+	} else {
+		records, err = decodeJSON(f)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toAssets(records)
+}
+
+func toAssets(records []record) ([]Asset, error) {
+	assets := make([]Asset, 0, len(records))
+	var errs []string
+	for _, r := range records {
+		a, err := r.asset()
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		assets = append(assets, a)
+	}
+	if len(errs) > 0 {
+		return assets, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return assets, nil
+}
+
+// Fetch reads the inventory from an HTTP API answering GET with the JSON file format. A non-empty
+// token is sent as a bearer token.
+func Fetch(ctx context.Context, client *http.Client, url, token string) ([]Asset, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("assets: GET %s: %s", url, resp.Status)
+	}
+	records, err := decodeJSON(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("assets: GET %s: %w", url, err)
+	}
+	return toAssets(records)
+}
+
+func isInventory(name string) bool {
+	return !strings.HasPrefix(name, ".") && slices.Contains([]string{".csv", ".json"}, filepath.Ext(name))
+}
@@ -0,0 +1,129 @@
+package assets
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/harishhary/blink/pkg/events"
+)
+
+// Match is an asset found in an event, as attached under events.AssetsKey.
+type Match struct {
+	Field       string
+	Value       string
+	Asset       string
+	Kind        string
+	Owner       string
+	Criticality Criticality
+	Tags        []string
+}
+
+func (m Match) toMap() map[string]any {
+	tags := make([]any, len(m.Tags))
+	for i, t := range m.Tags {
+		tags[i] = t
+	}
+	return map[string]any{
+		"field":       m.Field,
+		"value":       m.Value,
+		"id":          m.Asset,
+		"kind":        m.Kind,
+		"owner":       m.Owner,
+		"criticality": m.Criticality.String(),
+		"tags":        tags,
+	}
+}
+
+// Find looks up the values of fields in evt, and in its normalized view, and returns the assets they
+// belong to. An asset mentioned by several fields is returned once, for the first of them.
+func (inv *Inventory) Find(evt events.Event, fields []string) []Match {
+	var out []Match
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		v, ok := evt.Lookup(field)
+		if !ok {
+			continue
+		}
+		values, isList := v.([]any)
+		if !isList {
+			values = []any{v}
+		}
+		for _, value := range values {
+			s, ok := value.(string)
+			if !ok || s == "" {
+				continue
+			}
+			a, ok := inv.Lookup(s)
+			if !ok || seen[a.ID] {
+				continue
+			}
+			seen[a.ID] = true
+			out = append(out, Match{
+				Field:       field,
+				Value:       s,
+				Asset:       a.ID,
+				Kind:        a.Kind,
+				Owner:       a.Owner,
+				Criticality: a.Criticality,
+				Tags:        slices.Clone(a.Tags),
+			})
+		}
+	}
+	return out
+}
+
+// Tag attaches the assets found in evt under events.AssetsKey and returns them. Whatever evt carried
+// under that key before is dropped first, so an event that mentions no known asset leaves without any.
+func (inv *Inventory) Tag(evt events.Event, fields []string) []Match {
+	delete(evt, events.AssetsKey)
+	matches := inv.Find(evt, fields)
+	if len(matches) == 0 {
+		return nil
+	}
+	attached := make([]any, len(matches))
+	for i, m := range matches {
+		attached[i] = m.toMap()
+	}
+	evt[events.AssetsKey] = attached
+	return matches
+}
+
+// Attached reads back the assets attached to evt, e.g. by the event matcher before the event became an
+// alert.
+func Attached(evt events.Event) []Match {
+	list, _ := evt[events.AssetsKey].([]any)
+	out := make([]Match, 0, len(list))
+	for _, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		crit, _ := ParseCriticality(str(m["criticality"]))
+		var tags []string
+		if ts, ok := m["tags"].([]any); ok {
+			for _, t := range ts {
+				tags = append(tags, str(t))
+			}
+		}
+		out = append(out, Match{
+			Field:       str(m["field"]),
+			Value:       str(m["value"]),
+			Asset:       str(m["id"]),
+			Kind:        str(m["kind"]),
+			Owner:       str(m["owner"]),
+			Criticality: crit,
+			Tags:        tags,
+		})
+	}
+	return out
+}
+
+func str(v any) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,180 @@
+package assets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	svcctx "github.com/harishhary/blink/internal/context"
+	"github.com/harishhary/blink/internal/dirwatch"
+	"github.com/harishhary/blink/internal/errors"
+	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/secrets"
+	"github.com/harishhary/blink/pkg/events"
+)
+
+// Watcher keeps the Inventory for a Config current: it reloads the inventory files when they change
+// and polls the API every refresh interval.
+type Watcher struct {
+	svcctx.ServiceContext
+	cfg     Config
+	policy  Policy
+	client  *http.Client
+	current atomic.Pointer[Inventory]
+	gen     atomic.Uint64
+
+	mu          sync.Mutex // serialises rebuilds
+	fromFiles   []Asset
+	fromAPI     []Asset
+	filesLoaded bool
+	apiLoaded   bool
+}
+
+// NewWatcher loads the inventory described by cfg. Files and records that do not load are logged and
+// skipped until fixed, and an API that cannot be reached is retried on every refresh; NewWatcher only
+// fails when nothing loads at all.
+func NewWatcher(cfg Config) (*Watcher, error) {
+	policy, err := cfg.Policy()
+	if err != nil {
+		return nil, err
+	}
+	sc := svcctx.New("asset-watcher")
+	sc.Logger = logger.New(sc.Name(), "dev")
+	w := &Watcher{ServiceContext: sc, cfg: cfg, policy: policy, client: &http.Client{Timeout: 30 * time.Second}}
+
+	var errs []string
+	if cfg.Dir != "" {
+		if err := w.loadFiles(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if cfg.API.URL != "" {
+		if err := w.loadAPI(context.Background()); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 && !w.filesLoaded && !w.apiLoaded {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	for _, e := range errs {
+		w.ErrorF("initial load errors: %s", e)
+	}
+	w.rebuild()
+	return w, nil
+}
+
+// Current returns the most recently loaded Inventory.
+func (w *Watcher) Current() *Inventory {
+	return w.current.Load()
+}
+
+// Fields returns the event fields to look assets up by.
+func (w *Watcher) Fields() []string { return w.cfg.Fields }
+
+// Tag attaches the assets evt mentions in the configured fields, as Inventory.Tag does. A nil Watcher,
+// for when no inventory is configured, only drops the assets evt claims to carry.
+func (w *Watcher) Tag(evt events.Event) []Match {
+	if w == nil {
+		delete(evt, events.AssetsKey)
+		return nil
+	}
+	return w.Current().Tag(evt, w.Fields())
+}
+
+// Policy returns the severity adjustments for alerts.
+func (w *Watcher) Policy() Policy { return w.policy }
+
+// Ready reports an error until an inventory has been loaded.
+func (w *Watcher) Ready(context.Context) error {
+	if w.Current() == nil {
+		return fmt.Errorf("asset inventory not loaded")
+	}
+	return nil
+}
+
+// Run watches the inventory directory and polls the API until ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context) errors.Error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if w.cfg.API.URL != "" {
+		go w.poll(ctx)
+	}
+	if w.cfg.Dir == "" {
+		<-ctx.Done()
+		return nil
+	}
+	return dirwatch.Watch(ctx, w.Logger, w.cfg.Dir, isInventory, func() {
+		if err := w.loadFiles(); err != nil {
+			w.ErrorF("reload error: %v", err)
+		}
+		w.rebuild()
+	})
+}
+
+// poll refetches the API assets every refresh interval until ctx is cancelled.
+func (w *Watcher) poll(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.API.Refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.loadAPI(ctx); err != nil {
+				w.ErrorF("inventory API: %v (keeping the assets it last returned)", err)
+				continue
+			}
+			w.rebuild()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *Watcher) loadFiles() error {
+	assets, err := LoadDir(w.cfg.Dir)
+	if assets == nil && err != nil {
+		return err // the directory is unreadable: keep the assets loaded before
+	}
+	w.mu.Lock()
+	w.fromFiles, w.filesLoaded = assets, true
+	w.mu.Unlock()
+	return err
+}
+
+func (w *Watcher) loadAPI(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	var token string
+	if w.cfg.API.Token != (secrets.Ref{}) {
+		var err error
+		if token, err = secrets.Resolve(ctx, w.cfg.API.Token); err != nil {
+			return err
+		}
+	}
+	assets, err := Fetch(ctx, w.client, w.cfg.API.URL, token)
+	if err != nil && assets == nil {
+		return err
+	}
+	w.mu.Lock()
+	w.fromAPI, w.apiLoaded = assets, true
+	w.mu.Unlock()
+	return err
+}
+
+// rebuild indexes the file and API assets, files first, and publishes the result.
+func (w *Watcher) rebuild() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	all := make([]Asset, 0, len(w.fromFiles)+len(w.fromAPI))
+	all = append(append(all, w.fromFiles...), w.fromAPI...)
+	inv, err := NewInventory(all)
+	if err != nil {
+		w.ErrorF("%v", err)
+	}
+	inv.generation = w.gen.Add(1)
+	w.current.Store(inv)
+	w.Info("loaded %d asset(s) (generation %d)", inv.Len(), inv.generation)
+}
@@ -39,4 +39,6 @@ type Broker interface {
 	NewReader(topic, groupID string) Reader
 	// NewWriter returns a Writer for the given topic.
 	NewWriter(topic string) Writer
+	// Ping checks that a broker is reachable and that every given topic exists.
+	Ping(ctx context.Context, topics ...string) error
 }
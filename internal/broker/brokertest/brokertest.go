@@ -0,0 +1,319 @@
+// Package brokertest is the conformance suite for broker.Broker implementations: the delivery semantics
+// the pipeline stages rely on, checked the same way against every broker.
+package brokertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	bk "github.com/harishhary/blink/internal/broker"
+)
+
+// Suite runs the conformance tests against one implementation.
+type Suite struct {
+	// New returns a broker and the name of a new, empty topic with the given number of partitions.
+	New func(t *testing.T, partitions int) (bk.Broker, string)
+	// Patience bounds the waits expected to run out, e.g. for a batch that cannot fill. It must cover
+	// the implementation's fetch latency, and its group join for a reader's first read.
+	Patience time.Duration
+}
+
+// Run runs every test of the suite as a subtest of t.
+func (s Suite) Run(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		run  func(*testing.T)
+	}{
+		{"WriteRead", s.testWriteRead},
+		{"KeyedPartitions", s.testKeyedPartitions},
+		{"ReadBatchFull", s.testReadBatchFull},
+		{"ReadBatchPartial", s.testReadBatchPartial},
+		{"ReadBatchEmpty", s.testReadBatchEmpty},
+		{"RedeliverUncommitted", s.testRedeliverUncommitted},
+		{"ReadMessageCommits", s.testReadMessageCommits},
+		{"IndependentGroups", s.testIndependentGroups},
+		{"SharedGroup", s.testSharedGroup},
+		{"Ping", s.testPing},
+	} {
+		t.Run(tc.name, tc.run)
+	}
+}
+
+// wait bounds the reads expected to succeed.
+func (s Suite) wait(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(t.Context(), 3*s.Patience+10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// patience bounds the reads expected to run out.
+func (s Suite) patience(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(t.Context(), s.Patience)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func write(t *testing.T, b bk.Broker, topic string, msgs ...bk.Message) {
+	t.Helper()
+	w := b.NewWriter(topic)
+	defer w.Close()
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+	if err := w.WriteMessages(ctx, msgs...); err != nil {
+		t.Fatalf("WriteMessages: %v", err)
+	}
+}
+
+// values returns n messages whose values are prefix0 ... prefix<n-1>, all with key.
+func values(key, prefix string, n int) []bk.Message {
+	msgs := make([]bk.Message, n)
+	for i := range msgs {
+		msgs[i] = bk.Message{Key: []byte(key), Value: []byte(prefix + strconv.Itoa(i))}
+	}
+	return msgs
+}
+
+func readN(t *testing.T, ctx context.Context, r bk.Reader, n int) []bk.Message {
+	t.Helper()
+	var out []bk.Message
+	for len(out) < n {
+		batch, err := r.ReadBatch(ctx, n-len(out))
+		if err != nil {
+			t.Fatalf("ReadBatch after %d of %d messages: %v", len(out), n, err)
+		}
+		out = append(out, batch...)
+	}
+	return out
+}
+
+func valuesOf(msgs []bk.Message) []string {
+	out := make([]string, len(msgs))
+	for i, m := range msgs {
+		out[i] = string(m.Value)
+	}
+	return out
+}
+
+func expect(t *testing.T, got []bk.Message, want ...string) {
+	t.Helper()
+	if g := valuesOf(got); fmt.Sprint(g) != fmt.Sprint(want) {
+		t.Fatalf("read %v, want %v", g, want)
+	}
+}
+
+func group(t *testing.T, name string) string {
+	return fmt.Sprintf("%s-%s-%d", t.Name(), name, time.Now().UnixNano())
+}
+
+func (s Suite) testWriteRead(t *testing.T) {
+	b, topic := s.New(t, 1)
+	write(t, b, topic, values("k", "v", 3)...)
+	r := b.NewReader(topic, group(t, "g"))
+	defer r.Close()
+
+	msgs := readN(t, s.wait(t), r, 3)
+	expect(t, msgs, "v0", "v1", "v2")
+	for i, m := range msgs {
+		if m.Topic != topic || m.Partition != 0 || string(m.Key) != "k" {
+			t.Errorf("message %d = %s/%d key %q, want %s/0 key k", i, m.Topic, m.Partition, m.Key, topic)
+		}
+		if i > 0 && m.Offset != msgs[i-1].Offset+1 {
+			t.Errorf("offsets %d then %d, want consecutive", msgs[i-1].Offset, m.Offset)
+		}
+	}
+}
+
+func (s Suite) testKeyedPartitions(t *testing.T) {
+	b, topic := s.New(t, 4)
+	var msgs []bk.Message
+	for i := range 40 {
+		key := "key-" + strconv.Itoa(i%5)
+		msgs = append(msgs, bk.Message{Key: []byte(key), Value: []byte(key + "/" + strconv.Itoa(i))})
+	}
+	write(t, b, topic, msgs...)
+	r := b.NewReader(topic, group(t, "g"))
+	defer r.Close()
+
+	partitionOf := make(map[string]int)
+	last := make(map[string]int)
+	for _, m := range readN(t, s.wait(t), r, len(msgs)) {
+		key := string(m.Key)
+		if p, ok := partitionOf[key]; ok && p != m.Partition {
+			t.Errorf("key %s on partitions %d and %d, want one", key, p, m.Partition)
+		}
+		partitionOf[key] = m.Partition
+		_, seq, _ := strings.Cut(string(m.Value), "/")
+		n, _ := strconv.Atoi(seq)
+		if prev, ok := last[key]; ok && n < prev {
+			t.Errorf("key %s: %d read after %d, want write order", key, n, prev)
+		}
+		last[key] = n
+	}
+}
+
+func (s Suite) testReadBatchFull(t *testing.T) {
+	b, topic := s.New(t, 1)
+	write(t, b, topic, values("k", "v", 10)...)
+	r := b.NewReader(topic, group(t, "g"))
+	defer r.Close()
+
+	batch, err := r.ReadBatch(s.wait(t), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, batch, "v0", "v1", "v2", "v3")
+}
+
+func (s Suite) testReadBatchPartial(t *testing.T) {
+	b, topic := s.New(t, 1)
+	write(t, b, topic, values("k", "v", 3)...)
+	r := b.NewReader(topic, group(t, "g"))
+	defer r.Close()
+
+	batch, err := r.ReadBatch(s.patience(t), 10)
+	if err != nil {
+		t.Fatalf("ReadBatch = %v, want the partial batch without an error", err)
+	}
+	expect(t, batch, "v0", "v1", "v2")
+}
+
+func (s Suite) testReadBatchEmpty(t *testing.T) {
+	b, topic := s.New(t, 1)
+	r := b.NewReader(topic, group(t, "g"))
+	defer r.Close()
+
+	batch, err := r.ReadBatch(s.patience(t), 10)
+	if !errors.Is(err, context.DeadlineExceeded) || len(batch) != 0 {
+		t.Fatalf("ReadBatch on an empty topic = %d messages, %v; want the context's error", len(batch), err)
+	}
+}
+
+func (s Suite) testRedeliverUncommitted(t *testing.T) {
+	b, topic := s.New(t, 1)
+	write(t, b, topic, values("k", "v", 6)...)
+	g := group(t, "g")
+
+	first := b.NewReader(topic, g)
+	batch := readN(t, s.wait(t), first, 4)
+	expect(t, batch, "v0", "v1", "v2", "v3")
+	if err := first.CommitMessages(s.wait(t), batch[:2]...); err != nil {
+		t.Fatalf("CommitMessages: %v", err)
+	}
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	second := b.NewReader(topic, g)
+	defer second.Close()
+	expect(t, readN(t, s.wait(t), second, 4), "v2", "v3", "v4", "v5")
+}
+
+func (s Suite) testReadMessageCommits(t *testing.T) {
+	b, topic := s.New(t, 1)
+	write(t, b, topic, values("k", "v", 3)...)
+	g := group(t, "g")
+
+	first := b.NewReader(topic, g)
+	m, err := first.ReadMessage(s.wait(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, []bk.Message{m}, "v0")
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	second := b.NewReader(topic, g)
+	defer second.Close()
+	expect(t, readN(t, s.wait(t), second, 2), "v1", "v2")
+}
+
+func (s Suite) testIndependentGroups(t *testing.T) {
+	b, topic := s.New(t, 1)
+	readers := []bk.Reader{b.NewReader(topic, group(t, "a")), b.NewReader(topic, group(t, "b"))}
+	write(t, b, topic, values("k", "v", 3)...)
+	for _, r := range readers {
+		msgs := readN(t, s.wait(t), r, 3)
+		expect(t, msgs, "v0", "v1", "v2")
+		if err := r.CommitMessages(s.wait(t), msgs...); err != nil {
+			t.Fatal(err)
+		}
+		r.Close()
+	}
+}
+
+func (s Suite) testSharedGroup(t *testing.T) {
+	const partitions, total = 2, 20
+	b, topic := s.New(t, partitions)
+	g := group(t, "g")
+	readers := []bk.Reader{b.NewReader(topic, g), b.NewReader(topic, g)}
+	for _, r := range readers {
+		defer r.Close()
+	}
+	var msgs []bk.Message
+	for i := range total {
+		msgs = append(msgs, bk.Message{Key: []byte(strconv.Itoa(i)), Value: []byte(strconv.Itoa(i))})
+	}
+	write(t, b, topic, msgs...)
+
+	type result struct {
+		msgs []bk.Message
+		err  error
+	}
+	results := make(chan result, len(readers))
+	ctx := s.wait(t)
+	for _, r := range readers {
+		go func() {
+			var got []bk.Message
+			for {
+				batch, err := r.ReadBatch(s.patience(t), total)
+				if errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+					results <- result{msgs: got}
+					return
+				}
+				if err != nil {
+					results <- result{err: err}
+					return
+				}
+				if err := r.CommitMessages(ctx, batch...); err != nil {
+					results <- result{err: err}
+					return
+				}
+				got = append(got, batch...)
+			}
+		}()
+	}
+	seen := make(map[string]int)
+	for range readers {
+		res := <-results
+		if res.err != nil {
+			t.Fatal(res.err)
+		}
+		for _, m := range res.msgs {
+			seen[string(m.Value)]++
+		}
+	}
+	if len(seen) != total {
+		t.Fatalf("the group read %d distinct messages, want %d", len(seen), total)
+	}
+	for v, n := range seen {
+		if n != 1 {
+			t.Errorf("message %s read %d times by the group, want once", v, n)
+		}
+	}
+}
+
+func (s Suite) testPing(t *testing.T) {
+	b, topic := s.New(t, 1)
+	if err := b.Ping(s.wait(t), topic); err != nil {
+		t.Errorf("Ping(%s) = %v", topic, err)
+	}
+	if err := b.Ping(s.wait(t), topic, topic+"-missing"); err == nil {
+		t.Error("Ping with a missing topic succeeded")
+	}
+}
@@ -0,0 +1,31 @@
+// Package factory builds the broker.Broker a service is configured for.
+package factory
+
+import (
+	"fmt"
+
+	bk "github.com/harishhary/blink/internal/broker"
+	"github.com/harishhary/blink/internal/broker/kafka"
+	"github.com/harishhary/blink/internal/broker/memory"
+	"github.com/harishhary/blink/internal/configuration"
+)
+
+const (
+	TypeKafka  = "kafka"
+	TypeMemory = "memory"
+)
+
+// New returns the broker selected by cfg.Broker.Type: Kafka at cfg.Kafka.Brokers, or the process-wide
+// in-memory broker.
+func New(cfg configuration.ServiceConfiguration) (bk.Broker, error) {
+	switch cfg.Broker.Type {
+	case "", TypeKafka:
+		if cfg.Kafka.Brokers == "" {
+			return nil, fmt.Errorf("KAFKA_BROKERS is required by the kafka broker")
+		}
+		return kafka.NewKafkaBroker(cfg.Kafka), nil
+	case TypeMemory:
+		return memory.Shared(cfg.Broker.MemoryPartitions, cfg.Broker.MemoryRetain), nil
+	}
+	return nil, fmt.Errorf("BROKER_TYPE %q: want %s or %s", cfg.Broker.Type, TypeKafka, TypeMemory)
+}
@@ -2,6 +2,8 @@ package kafka
 
 import (
 	"context"
+	stderrors "errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -42,18 +44,53 @@ func (kb *kafkaBroker) NewWriter(topic string) bk.Writer {
 	w := kafka.NewWriter(kafka.WriterConfig{
 		Brokers:  kb.brokers,
 		Topic:    topic,
-		Balancer: &kafka.LeastBytes{},
+		Balancer: &kafka.Hash{}, // keyed messages stay in order on one partition; unkeyed go round-robin
 		Dialer:   &kafka.Dialer{Timeout: kb.dialTimeout},
 	})
 	return &writer{w: w}
 }
 
+// Ping dials the first reachable bootstrap broker and checks the topics against its metadata.
+func (kb *kafkaBroker) Ping(ctx context.Context, topics ...string) error {
+	dialer := &kafka.Dialer{Timeout: kb.dialTimeout}
+	var errs []error
+	for _, addr := range kb.brokers {
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		defer conn.Close()
+		if len(topics) == 0 {
+			return nil
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(deadline)
+		}
+		partitions, err := conn.ReadPartitions(topics...)
+		if err != nil {
+			return fmt.Errorf("kafka: topic metadata: %w", err)
+		}
+		found := make(map[string]struct{}, len(partitions))
+		for _, p := range partitions {
+			found[p.Topic] = struct{}{}
+		}
+		for _, t := range topics {
+			if _, ok := found[t]; !ok {
+				return fmt.Errorf("kafka: topic %q not found", t)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("kafka: no broker reachable: %w", stderrors.Join(errs...))
+}
+
 // reader wraps kafka.Reader to implement broker.Reader.
 type reader struct {
 	r *kafka.Reader
 }
 
-// ReadMessage reads the next message from Kafka.
+// ReadMessage reads the next message from Kafka and commits it.
 func (rd *reader) ReadMessage(ctx context.Context) (bk.Message, error) {
 	m, err := rd.r.ReadMessage(ctx)
 	if err != nil {
@@ -68,11 +105,13 @@ func (rd *reader) ReadMessage(ctx context.Context) (bk.Message, error) {
 	}, nil
 }
 
-// ReadBatch reads up to batchSize messages from Kafka.
+// ReadBatch reads up to batchSize messages from Kafka, returning early with what it has when ctx ends.
+// Unlike ReadMessage it does not commit: the caller commits what it has processed with CommitMessages,
+// and the group reads the rest again after a restart or rebalance.
 func (rd *reader) ReadBatch(ctx context.Context, batchSize int) ([]bk.Message, error) {
 	var out []bk.Message
 	for i := 0; i < batchSize; i++ {
-		m, err := rd.r.ReadMessage(ctx)
+		m, err := rd.r.FetchMessage(ctx)
 		if err != nil {
 			if len(out) > 0 {
 				return out, nil
@@ -90,11 +129,12 @@ func (rd *reader) ReadBatch(ctx context.Context, batchSize int) ([]bk.Message, e
 	return out, nil
 }
 
-// CommitMessages commits offsets of the processed messages.
+// CommitMessages commits offsets of the processed messages. kafka-go commits the offset after each
+// message's own, so the group resumes at the first message not passed here.
 func (rd *reader) CommitMessages(ctx context.Context, msgs ...bk.Message) error {
 	var written []kafka.Message
 	for _, m := range msgs {
-		written = append(written, kafka.Message{Topic: m.Topic, Partition: m.Partition, Offset: m.Offset + 1})
+		written = append(written, kafka.Message{Topic: m.Topic, Partition: m.Partition, Offset: m.Offset})
 	}
 	return rd.r.CommitMessages(ctx, written...)
 }
@@ -0,0 +1,58 @@
+package kafka
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	bk "github.com/harishhary/blink/internal/broker"
+	"github.com/harishhary/blink/internal/broker/brokertest"
+	"github.com/harishhary/blink/internal/configuration"
+	"github.com/segmentio/kafka-go"
+)
+
+// TestConformance runs the broker conformance suite against the cluster in BLINK_TEST_KAFKA_BROKERS,
+// creating a topic per test.
+func TestConformance(t *testing.T) {
+	brokers := os.Getenv("BLINK_TEST_KAFKA_BROKERS")
+	if brokers == "" {
+		t.Skip("BLINK_TEST_KAFKA_BROKERS is not set")
+	}
+	b := NewKafkaBroker(configuration.KafkaConfig{Brokers: brokers})
+	brokertest.Suite{
+		New: func(t *testing.T, partitions int) (bk.Broker, string) {
+			topic := fmt.Sprintf("blink-conformance-%d", time.Now().UnixNano())
+			createTopic(t, strings.Split(brokers, ",")[0], topic, partitions)
+			return b, topic
+		},
+		Patience: 20 * time.Second, // the reader's 10s fetch wait, plus a group join
+	}.Run(t)
+}
+
+func createTopic(t *testing.T, addr, topic string, partitions int) {
+	t.Helper()
+	conn, err := kafka.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	controller, err := conn.Controller()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc, err := kafka.Dial("tcp", net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = cc.DeleteTopics(topic)
+		cc.Close()
+	})
+	if err := cc.CreateTopics(kafka.TopicConfig{Topic: topic, NumPartitions: partitions, ReplicationFactor: 1}); err != nil {
+		t.Fatalf("create topic %s: %v", topic, err)
+	}
+}
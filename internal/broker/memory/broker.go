@@ -0,0 +1,434 @@
+// Package memory is an in-process broker.Broker, for running pipeline stages in one process and for
+// testing them without a Kafka cluster. It follows the semantics the stages rely on from Kafka: topics
+// are split into partitions chosen by message key, each consumer group has its own committed offsets,
+// the partitions of a topic are shared out among the readers of a group, and whatever a group has read
+// but not committed is read again once its partitions are handed out anew.
+//
+// Messages are kept until every group reading the topic has committed past them; a group created after
+// that starts at the oldest message still kept. A topic no group reads keeps the latest messages of
+// each partition up to the broker's retention, so a topic only ever written does not grow unbounded.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"slices"
+	"sync"
+
+	bk "github.com/harishhary/blink/internal/broker"
+)
+
+// DefaultPartitions is the number of partitions of a topic the broker creates on first use.
+const DefaultPartitions = 1
+
+// DefaultRetain is the number of messages per partition kept for a topic no group reads.
+const DefaultRetain = 10000
+
+// Broker is an in-memory broker.Broker. Topics are created by CreateTopic or, with the broker's
+// partition count, by the first reader or writer for them. The zero value is not usable; use New.
+type Broker struct {
+	partitions int
+	retain     int
+
+	mu     sync.Mutex
+	topics map[string]*topic
+}
+
+// New returns an empty broker whose topics created on first use have the given number of partitions
+// (DefaultPartitions when not positive), and whose topics no group reads keep the last retain messages
+// of each partition (DefaultRetain when not positive).
+func New(partitions, retain int) *Broker {
+	if partitions <= 0 {
+		partitions = DefaultPartitions
+	}
+	if retain <= 0 {
+		retain = DefaultRetain
+	}
+	return &Broker{partitions: partitions, retain: retain, topics: make(map[string]*topic)}
+}
+
+var (
+	sharedOnce sync.Once
+	shared     *Broker
+)
+
+// Shared returns the process-wide broker, so that every stage of a process reads the topics the others
+// write. partitions and retain size it on the first call and are ignored afterwards.
+func Shared(partitions, retain int) *Broker {
+	sharedOnce.Do(func() { shared = New(partitions, retain) })
+	return shared
+}
+
+// CreateTopic creates a topic with the given number of partitions. It fails if the topic exists.
+func (b *Broker) CreateTopic(name string, partitions int) error {
+	if partitions <= 0 {
+		return fmt.Errorf("memory: topic %q: partitions must be positive", name)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.topics[name]; ok {
+		return fmt.Errorf("memory: topic %q already exists", name)
+	}
+	b.topics[name] = newTopic(name, partitions, b.retain)
+	return nil
+}
+
+// topic returns the named topic, creating it if needed.
+func (b *Broker) topic(name string) *topic {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t, ok := b.topics[name]
+	if !ok {
+		t = newTopic(name, b.partitions, b.retain)
+		b.topics[name] = t
+	}
+	return t
+}
+
+// NewReader returns a Reader that joins groupID on topic. An empty groupID gives the reader a group of
+// its own, starting at the oldest message kept.
+func (b *Broker) NewReader(topic, groupID string) bk.Reader {
+	t := b.topic(topic)
+	r := &reader{topic: t}
+	t.join(groupID, r)
+	return r
+}
+
+// NewWriter returns a Writer for topic.
+func (b *Broker) NewWriter(topic string) bk.Writer {
+	return &writer{topic: b.topic(topic)}
+}
+
+// Ping checks that every given topic exists.
+func (b *Broker) Ping(ctx context.Context, topics ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, name := range topics {
+		if _, ok := b.topics[name]; !ok {
+			return fmt.Errorf("memory: topic %q not found", name)
+		}
+	}
+	return nil
+}
+
+// topic holds the partitions of a topic and the state of the groups reading it. One mutex guards it
+// all; changed is closed and replaced whenever a reader may find something new to fetch.
+type topic struct {
+	name   string
+	retain int // messages kept per partition while no group reads the topic
+
+	mu         sync.Mutex
+	partitions []*partition
+	groups     map[string]*group
+	anonymous  int // for naming the groups of readers without a group ID
+	next       int // round-robin partition for messages without a key
+	changed    chan struct{}
+}
+
+// partition is an append-only log; the message at offset o is msgs[o-base].
+type partition struct {
+	base int64
+	msgs []bk.Message
+}
+
+func (p *partition) end() int64 { return p.base + int64(len(p.msgs)) }
+
+// group is a consumer group. committed holds, per partition, the offset it resumes from; position the
+// offset its current owner fetches next.
+type group struct {
+	id        string
+	anonymous bool
+	committed []int64
+	position  []int64
+	members   []*reader
+	owner     []*reader
+}
+
+func newTopic(name string, partitions, retain int) *topic {
+	t := &topic{name: name, retain: retain, groups: make(map[string]*group), changed: make(chan struct{})}
+	for range partitions {
+		t.partitions = append(t.partitions, &partition{})
+	}
+	return t
+}
+
+// notify wakes the readers waiting for messages. t.mu must be held.
+func (t *topic) notify() {
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// join adds r to the named group and rebalances it.
+func (t *topic) join(groupID string, r *reader) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	anonymous := groupID == ""
+	if anonymous {
+		t.anonymous++
+		groupID = fmt.Sprintf("\x00anonymous-%d", t.anonymous)
+	}
+	g, ok := t.groups[groupID]
+	if !ok {
+		g = &group{
+			id:        groupID,
+			anonymous: anonymous,
+			committed: make([]int64, len(t.partitions)),
+			position:  make([]int64, len(t.partitions)),
+			owner:     make([]*reader, len(t.partitions)),
+		}
+		for i, p := range t.partitions {
+			g.committed[i] = p.base
+		}
+		t.groups[groupID] = g
+	}
+	r.group = g
+	g.members = append(g.members, r)
+	t.rebalance(g)
+}
+
+// leave removes r from its group and rebalances it. A reader's own group goes with it, so that it no
+// longer holds back trimming. t.mu must be held.
+func (t *topic) leave(r *reader) {
+	g := r.group
+	if i := slices.Index(g.members, r); i >= 0 {
+		g.members = slices.Delete(g.members, i, i+1)
+		t.rebalance(g)
+	}
+	if g.anonymous && len(g.members) == 0 {
+		delete(t.groups, g.id)
+		t.trim()
+	}
+}
+
+// rebalance deals the partitions out to the members of g in turn and rewinds each to its committed
+// offset, as Kafka does when a group's membership changes: messages fetched but not committed before
+// are fetched again, possibly by another member. t.mu must be held.
+func (t *topic) rebalance(g *group) {
+	for i := range t.partitions {
+		g.owner[i] = nil
+		if len(g.members) > 0 {
+			g.owner[i] = g.members[i%len(g.members)]
+		}
+		g.position[i] = g.committed[i]
+	}
+	t.notify()
+}
+
+// fetch takes up to n messages for r from the partitions it owns, round-robin across them so that
+// none is starved. t.mu must be held.
+func (t *topic) fetch(r *reader, n int) []bk.Message {
+	g := r.group
+	var out []bk.Message
+	for len(out) < n {
+		progressed := false
+		for range t.partitions {
+			i := r.next % len(t.partitions)
+			r.next++
+			if g.owner[i] != r {
+				continue
+			}
+			p := t.partitions[i]
+			if g.position[i] < p.base {
+				g.position[i] = p.base // trimmed past; resume at the oldest message kept
+			}
+			if g.position[i] >= p.end() {
+				continue
+			}
+			out = append(out, p.msgs[g.position[i]-p.base])
+			g.position[i]++
+			progressed = true
+			if len(out) == n {
+				break
+			}
+		}
+		if !progressed {
+			break
+		}
+	}
+	return out
+}
+
+// commit records msgs as processed by g and drops the messages every group has committed past.
+// Committing never moves a group's offset back. t.mu must be held.
+func (t *topic) commit(g *group, msgs []bk.Message) error {
+	for _, m := range msgs {
+		if m.Topic != t.name || m.Partition < 0 || m.Partition >= len(t.partitions) {
+			return fmt.Errorf("memory: commit: %s/%d is not a partition of topic %q", m.Topic, m.Partition, t.name)
+		}
+		if next := m.Offset + 1; next > g.committed[m.Partition] {
+			g.committed[m.Partition] = next
+		}
+	}
+	t.trim()
+	return nil
+}
+
+// trim drops the messages that every group has committed or, while no group reads the topic, those
+// beyond its retention. t.mu must be held.
+func (t *topic) trim() {
+	for i, p := range t.partitions {
+		low := p.end()
+		if len(t.groups) == 0 {
+			low = max(p.base, p.end()-int64(t.retain))
+		}
+		for _, g := range t.groups {
+			low = min(low, g.committed[i])
+		}
+		if low > p.base {
+			p.msgs = slices.Clone(p.msgs[low-p.base:])
+			p.base = low
+		}
+	}
+}
+
+// append writes msgs to the partitions chosen by their keys.
+func (t *topic) append(msgs []bk.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, m := range msgs {
+		i := t.partitionFor(m.Key)
+		p := t.partitions[i]
+		p.msgs = append(p.msgs, bk.Message{
+			Topic:     t.name,
+			Partition: i,
+			Offset:    p.end(),
+			Key:       slices.Clone(m.Key),
+			Value:     slices.Clone(m.Value),
+		})
+	}
+	t.trim()
+	t.notify()
+}
+
+// partitionFor picks the partition of a key the way the Kafka writer's hash balancer does, so a key
+// lands on the same partition number with either broker. Messages without a key go round-robin.
+// t.mu must be held.
+func (t *topic) partitionFor(key []byte) int {
+	if key == nil {
+		i := t.next % len(t.partitions)
+		t.next++
+		return i
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+	p := int32(h.Sum32()) % int32(len(t.partitions))
+	if p < 0 {
+		p = -p
+	}
+	return int(p)
+}
+
+// reader is a member of a consumer group on one topic.
+type reader struct {
+	topic  *topic
+	group  *group
+	next   int // partition to fetch from first
+	closed bool
+}
+
+// ReadMessage blocks for the next message and commits it, as the Kafka reader does.
+func (r *reader) ReadMessage(ctx context.Context) (bk.Message, error) {
+	msgs, err := r.read(ctx, 1, true)
+	if err != nil {
+		return bk.Message{}, err
+	}
+	return msgs[0], nil
+}
+
+// ReadBatch blocks until batchSize messages are fetched or ctx ends. When ctx ends it returns what was
+// fetched so far, or ctx's error if nothing was. Nothing is committed; see CommitMessages.
+func (r *reader) ReadBatch(ctx context.Context, batchSize int) ([]bk.Message, error) {
+	return r.read(ctx, batchSize, false)
+}
+
+func (r *reader) read(ctx context.Context, n int, commit bool) ([]bk.Message, error) {
+	t := r.topic
+	var out []bk.Message
+	for {
+		t.mu.Lock()
+		if r.closed {
+			t.mu.Unlock()
+			if len(out) > 0 {
+				return out, nil
+			}
+			return nil, io.EOF
+		}
+		out = append(out, t.fetch(r, n-len(out))...)
+		if commit && len(out) > 0 {
+			_ = t.commit(r.group, out)
+		}
+		changed := t.changed
+		t.mu.Unlock()
+		if len(out) == n {
+			return out, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			if len(out) > 0 {
+				return out, nil
+			}
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// CommitMessages commits the offsets of msgs for the reader's group.
+func (r *reader) CommitMessages(_ context.Context, msgs ...bk.Message) error {
+	t := r.topic
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if r.closed {
+		return io.ErrClosedPipe
+	}
+	return t.commit(r.group, msgs)
+}
+
+// Close leaves the group; the partitions it owned are handed to the other members from their
+// committed offsets.
+func (r *reader) Close() error {
+	t := r.topic
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !r.closed {
+		r.closed = true
+		t.leave(r)
+	}
+	return nil
+}
+
+// writer appends to one topic.
+type writer struct {
+	topic *topic
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// WriteMessages appends msgs to the writer's topic. Key and Value are copied.
+func (w *writer) WriteMessages(ctx context.Context, msgs ...bk.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	closed := w.closed
+	w.mu.Unlock()
+	if closed {
+		return io.ErrClosedPipe
+	}
+	w.topic.append(msgs)
+	return nil
+}
+
+// Close stops the writer; later writes fail.
+func (w *writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return nil
+}
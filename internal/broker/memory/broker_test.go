@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	bk "github.com/harishhary/blink/internal/broker"
+	"github.com/harishhary/blink/internal/broker/brokertest"
+)
+
+func TestConformance(t *testing.T) {
+	brokertest.Suite{
+		New: func(t *testing.T, partitions int) (bk.Broker, string) {
+			b := New(1, 0)
+			if err := b.CreateTopic("events", partitions); err != nil {
+				t.Fatal(err)
+			}
+			return b, "events"
+		},
+		Patience: 100 * time.Millisecond,
+	}.Run(t)
+}
+
+func TestTrimCommitted(t *testing.T) {
+	b := New(1, 0)
+	fast, slow := b.NewReader("events", "fast"), b.NewReader("events", "slow")
+	w := b.NewWriter("events")
+	if err := w.WriteMessages(t.Context(), bk.Message{Value: []byte("a")}, bk.Message{Value: []byte("b")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fast.ReadMessage(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	p := b.topic("events").partitions[0]
+	if p.base != 0 || len(p.msgs) != 2 {
+		t.Fatalf("kept offsets %d..%d, want both messages while slow has committed neither", p.base, p.end())
+	}
+	if _, err := slow.ReadMessage(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if p.base != 1 || len(p.msgs) != 1 {
+		t.Fatalf("kept offsets %d..%d, want the message both groups committed dropped", p.base, p.end())
+	}
+}
+
+func TestRetainWithoutGroups(t *testing.T) {
+	b := New(1, 2)
+	w := b.NewWriter("events")
+	for _, v := range []string{"a", "b", "c"} {
+		if err := w.WriteMessages(t.Context(), bk.Message{Value: []byte(v)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := b.topic("events").partitions[0]
+	if p.base != 1 || len(p.msgs) != 2 {
+		t.Fatalf("kept offsets %d..%d, want the last two messages of a topic no group reads", p.base, p.end())
+	}
+
+	r := b.NewReader("events", "g")
+	defer r.Close()
+	if err := w.WriteMessages(t.Context(), bk.Message{Value: []byte("d")}); err != nil {
+		t.Fatal(err)
+	}
+	if p.base != 1 || len(p.msgs) != 3 {
+		t.Fatalf("kept offsets %d..%d, want everything the group has not committed", p.base, p.end())
+	}
+	m, err := r.ReadMessage(t.Context())
+	if err != nil || string(m.Value) != "b" {
+		t.Fatalf("ReadMessage = %q, %v; want the oldest message kept", m.Value, err)
+	}
+}
@@ -0,0 +1,137 @@
+package classifier
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// cefExtensionKey finds the start of each key=value pair in a CEF extension. Values may contain spaces,
+// so a pair ends where the next key begins.
+var cefExtensionKey = regexp.MustCompile(`(?:^|\s)([A-Za-z0-9_.\[\]-]+)=`)
+
+var cefHeader = []string{"cef_version", "device_vendor", "device_product", "device_version", "signature_id", "name", "severity"}
+
+// cefParser accepts ArcSight CEF records, optionally behind a syslog header. Header fields are named as
+// in cefHeader and extension pairs become top-level fields.
+type cefParser struct{}
+
+func (cefParser) Parse(data []byte) (map[string]any, error) {
+	s := strings.TrimRight(string(data), "\r\n")
+	i := strings.Index(s, "CEF:")
+	if i < 0 {
+		return nil, fmt.Errorf("not a CEF record")
+	}
+	parts := splitEscaped(s[i+len("CEF:"):], '|', len(cefHeader)+1)
+	if len(parts) != len(cefHeader)+1 {
+		return nil, fmt.Errorf("CEF header has %d of %d fields", len(parts)-1, len(cefHeader))
+	}
+	m := make(map[string]any)
+	for j, name := range cefHeader {
+		m[name] = parts[j]
+	}
+	ext := parts[len(cefHeader)]
+	locs := cefExtensionKey.FindAllStringSubmatchIndex(ext, -1)
+	for j, loc := range locs {
+		end := len(ext)
+		if j+1 < len(locs) {
+			end = locs[j+1][0]
+		}
+		m[ext[loc[2]:loc[3]]] = cefUnescape(strings.TrimSpace(ext[loc[1]:end]))
+	}
+	return m, nil
+}
+
+// splitEscaped splits s on sep at most n-1 times, ignoring backslash-escaped separators and unescaping
+// \sep and \\ in all but the last part.
+func splitEscaped(s string, sep byte, n int) []string {
+	var parts []string
+	var cur strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case len(parts) == n-1:
+			parts = append(parts, s[i:])
+			return parts
+		case s[i] == '\\' && i+1 < len(s) && (s[i+1] == sep || s[i+1] == '\\'):
+			i++
+			cur.WriteByte(s[i])
+		case s[i] == sep:
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(s[i])
+		}
+	}
+	return append(parts, cur.String())
+}
+
+func cefUnescape(v string) string {
+	if !strings.Contains(v, `\`) {
+		return v
+	}
+	return strings.NewReplacer(`\=`, `=`, `\\`, `\`, `\n`, "\n", `\r`, "\r").Replace(v)
+}
+
+var leefHeader = []string{"leef_version", "vendor", "product", "version", "event_id"}
+
+// leefParser accepts IBM QRadar LEEF 1.0 and 2.0 records, optionally behind a syslog header. Attributes
+// are tab-separated in LEEF 1.0; LEEF 2.0 names its delimiter after the event id, as a character or
+// its hex code (e.g. "^" or "x5E").
+type leefParser struct{}
+
+func (leefParser) Parse(data []byte) (map[string]any, error) {
+	s := strings.TrimRight(string(data), "\r\n")
+	i := strings.Index(s, "LEEF:")
+	if i < 0 {
+		return nil, fmt.Errorf("not a LEEF record")
+	}
+	s = s[i+len("LEEF:"):]
+	n := len(leefHeader) + 1
+	if strings.HasPrefix(s, "2.0|") {
+		n++
+	}
+	parts := strings.SplitN(s, "|", n)
+	if len(parts) != n {
+		return nil, fmt.Errorf("LEEF header has %d of %d fields", len(parts)-1, n-1)
+	}
+	m := make(map[string]any)
+	for j, name := range leefHeader {
+		m[name] = parts[j]
+	}
+	delim := "\t"
+	if n > len(leefHeader)+1 {
+		d, err := leefDelimiter(parts[len(leefHeader)])
+		if err != nil {
+			return nil, err
+		}
+		delim = d
+	}
+	for _, attr := range strings.Split(parts[n-1], delim) {
+		if attr == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(attr, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("LEEF attribute %q is not key=value", attr)
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+func leefDelimiter(d string) (string, error) {
+	switch {
+	case d == "":
+		return "\t", nil
+	case len(d) == 1:
+		return d, nil
+	}
+	// A hex code such as "x5E" or "0x5E".
+	hex, ok := strings.CutPrefix(strings.TrimPrefix(strings.ToLower(d), "0"), "x")
+	code, err := strconv.ParseUint(hex, 16, 8)
+	if !ok || err != nil {
+		return "", fmt.Errorf("invalid LEEF delimiter %q", d)
+	}
+	return string(rune(code)), nil
+}
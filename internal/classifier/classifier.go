@@ -0,0 +1,133 @@
+package classifier
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Registry is an immutable set of schemas loaded from a directory.
+type Registry struct {
+	all        []*Schema // by priority, then name
+	byName     map[string]*Schema
+	loadedAt   time.Time
+	generation uint64
+}
+
+// NewRegistry loads every *.yaml / *.yml schema in dir. Like the rule config registry it returns the
+// schemas that did load together with an error listing the files that did not.
+func NewRegistry(dir string) (*Registry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("classifier: read dir %s: %w", dir, err)
+	}
+	reg := &Registry{byName: make(map[string]*Schema), loadedAt: time.Now()}
+
+	var errs []string
+	for _, e := range entries {
+		if e.IsDir() || !isYAML(e.Name()) {
+			continue
+		}
+		s, err := Load(filepath.Join(dir, e.Name()))
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if prev, ok := reg.byName[s.Name]; ok {
+			errs = append(errs, fmt.Sprintf("%s: schema %s is already defined in %s", e.Name(), s.Name, filepath.Base(prev.file)))
+			continue
+		}
+		reg.byName[s.Name] = s
+		reg.all = append(reg.all, s)
+	}
+	sort.SliceStable(reg.all, func(i, j int) bool {
+		if reg.all[i].Priority != reg.all[j].Priority {
+			return reg.all[i].Priority < reg.all[j].Priority
+		}
+		return reg.all[i].Name < reg.all[j].Name
+	})
+
+	if len(errs) > 0 {
+		return reg, fmt.Errorf("classifier: %d file(s) failed to load:\n  %s", len(errs), strings.Join(errs, "\n  "))
+	}
+	return reg, nil
+}
+
+func (r *Registry) Len() int { return len(r.all) }
+
+// All returns the schemas in priority order.
+func (r *Registry) All() []*Schema { return r.all }
+
+// Get returns the schema for a log type.
+func (r *Registry) Get(name string) (*Schema, bool) {
+	s, ok := r.byName[name]
+	return s, ok
+}
+
+func (r *Registry) LoadedAt() time.Time { return r.loadedAt }
+
+// Generation counts the registries a Watcher has published, starting at 1; 0 for one built directly.
+func (r *Registry) Generation() uint64 { return r.generation }
+
+// Attempt is why one schema rejected a record.
+type Attempt struct {
+	Schema string `json:"schema"`
+	Reason string `json:"reason"`
+}
+
+// UnclassifiedError is returned for a record no schema accepts. Attempts lists every schema tried, in order.
+type UnclassifiedError struct {
+	Attempts []Attempt
+}
+
+func (e *UnclassifiedError) Error() string {
+	if len(e.Attempts) == 0 {
+		return "no schemas to classify against"
+	}
+	parts := make([]string, len(e.Attempts))
+	for i, a := range e.Attempts {
+		parts[i] = a.Schema + ": " + a.Reason
+	}
+	return "no schema matched (" + strings.Join(parts, "; ") + ")"
+}
+
+// Classify parses data with each candidate schema in turn and returns the fields of the first one that
+// validates, normalized to the schema's types and with log_type set to the schema name.
+//
+// schemas names the candidates in the order to try them, as a source's config lists them; when it is
+// empty every schema is tried in priority order. A listed schema that is not loaded counts as a failed
+// attempt, so a deleted schema file shows up in the reason instead of silently changing the order.
+func (r *Registry) Classify(schemas []string, data []byte) (map[string]any, *Schema, error) {
+	candidates := r.all
+	if len(schemas) > 0 {
+		candidates = make([]*Schema, len(schemas))
+		for i, name := range schemas {
+			candidates[i] = r.byName[name]
+		}
+	}
+	unclassified := &UnclassifiedError{}
+	for i, s := range candidates {
+		if s == nil {
+			unclassified.Attempts = append(unclassified.Attempts, Attempt{Schema: schemas[i], Reason: "schema not loaded"})
+			continue
+		}
+		rec, err := s.parser.Parse(data)
+		if err == nil {
+			err = s.validate(rec)
+		}
+		if err != nil {
+			unclassified.Attempts = append(unclassified.Attempts, Attempt{Schema: s.Name, Reason: s.Parser + ": " + err.Error()})
+			continue
+		}
+		rec["log_type"] = s.Name
+		return rec, s, nil
+	}
+	return nil, nil, unclassified
+}
+
+func isYAML(name string) bool {
+	return strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")
+}
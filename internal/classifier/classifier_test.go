@@ -0,0 +1,114 @@
+package classifier
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParsers(t *testing.T) {
+	tests := []struct {
+		parser string
+		opts   ParserOptions
+		input  string
+		want   map[string]any
+	}{
+		{"json", ParserOptions{}, `{"user":"alice","n":2}`, map[string]any{"user": "alice", "n": 2.0}},
+		{"csv", ParserOptions{Headers: []string{"user", "ip"}}, "alice,\"10.0.0.1\"\n", map[string]any{"user": "alice", "ip": "10.0.0.1"}},
+		{"kv", ParserOptions{}, `srcip=10.0.0.1 msg="port scan detected" dstport=22`, map[string]any{"srcip": "10.0.0.1", "msg": "port scan detected", "dstport": "22"}},
+		{"syslog", ParserOptions{}, `<165>1 2003-10-11T22:14:15.003Z host.example evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="App\]"] An event`,
+			map[string]any{"priority": int64(165), "facility": int64(20), "severity": int64(5), "timestamp": "2003-10-11T22:14:15.003Z",
+				"hostname": "host.example", "app_name": "evntslog", "msg_id": "ID47", "message": "An event",
+				"structured_data": map[string]any{"exampleSDID@32473": map[string]any{"iut": "3", "eventSource": "App]"}}}},
+		{"cef", ParserOptions{}, `Sep 19 08:26:10 host CEF:0|Security|threatmanager|1.0|100|worm stopped|10|src=10.0.0.1 msg=Detected a \= sign act=blocked`,
+			map[string]any{"cef_version": "0", "device_vendor": "Security", "device_product": "threatmanager", "device_version": "1.0",
+				"signature_id": "100", "name": "worm stopped", "severity": "10", "src": "10.0.0.1", "msg": "Detected a = sign", "act": "blocked"}},
+		{"leef", ParserOptions{}, "LEEF:2.0|Lancope|StealthWatch|1.0|41|^|src=10.0.1.8^dst=10.0.0.5^sev=5",
+			map[string]any{"leef_version": "2.0", "vendor": "Lancope", "product": "StealthWatch", "version": "1.0", "event_id": "41",
+				"src": "10.0.1.8", "dst": "10.0.0.5", "sev": "5"}},
+		{"regex", ParserOptions{Pattern: `^(?P<method>[A-Z]+) (?P<path>\S+) (?P<status>\d{3})$`}, "GET /login 401",
+			map[string]any{"method": "GET", "path": "/login", "status": "401"}},
+	}
+	for _, tt := range tests {
+		p, err := newParser(tt.parser, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.parser, err)
+		}
+		got, err := p.Parse([]byte(tt.input))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.parser, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got  %v\n want %v", tt.parser, got, tt.want)
+		}
+	}
+}
+
+func TestSyslog3164(t *testing.T) {
+	got, err := syslogParser{}.Parse([]byte("<34>Oct 11 22:14:15 mymachine su[230]: 'su root' failed for lonvick"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]any{"severity": int64(2), "hostname": "mymachine", "app_name": "su", "proc_id": "230", "message": "'su root' failed for lonvick"} {
+		if got[k] != want {
+			t.Errorf("%s = %v, want %v", k, got[k], want)
+		}
+	}
+	if _, err := (syslogParser{rfc: "5424"}).Parse([]byte("<34>Oct 11 22:14:15 mymachine su: x")); err == nil {
+		t.Error("RFC 3164 line accepted by an RFC 5424-only parser")
+	}
+}
+
+func writeSchema(t *testing.T, dir, file, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	dir := t.TempDir()
+	writeSchema(t, dir, "fw.yaml", `
+name: "fw:traffic"
+parser: kv
+priority: 10
+fields: { srcip: string, dstport: integer, sentbyte: integer }
+optional: [sentbyte]
+`)
+	writeSchema(t, dir, "app.yaml", `
+name: "app:login"
+parser: json
+priority: 20
+fields: { user: string, success: boolean }
+`)
+	writeSchema(t, dir, "broken.yaml", "name: x\nparser: csv\n") // csv without headers
+	reg, err := NewRegistry(dir)
+	if err == nil || reg.Len() != 2 {
+		t.Fatalf("NewRegistry = %d schemas, %v; want 2 and an error for broken.yaml", reg.Len(), err)
+	}
+
+	evt, s, err := reg.Classify(nil, []byte("srcip=10.0.0.1 dstport=443"))
+	if err != nil || s.Name != "fw:traffic" {
+		t.Fatalf("Classify = %v, %v", s, err)
+	}
+	if evt["log_type"] != "fw:traffic" || evt["dstport"] != int64(443) {
+		t.Errorf("event not normalized: %v", evt)
+	}
+
+	evt, s, err = reg.Classify(nil, []byte(`{"user":"alice","success":"true"}`))
+	if err != nil || s.Name != "app:login" || evt["success"] != true {
+		t.Fatalf("Classify = %v, %v, %v", evt, s, err)
+	}
+
+	_, _, err = reg.Classify([]string{"app:login", "gone"}, []byte("srcip=10.0.0.1 dstport=https"))
+	var unclassified *UnclassifiedError
+	if !errors.As(err, &unclassified) || len(unclassified.Attempts) != 2 || unclassified.Attempts[1].Reason != "schema not loaded" {
+		t.Fatalf("Classify error = %v", err)
+	}
+	_, _, err = reg.Classify(nil, []byte("srcip=10.0.0.1 dstport=https"))
+	if !errors.As(err, &unclassified) || unclassified.Attempts[0].Reason != "kv: field dstport: https is not an integer" {
+		t.Fatalf("Classify error = %v", err)
+	}
+}
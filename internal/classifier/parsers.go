@@ -0,0 +1,183 @@
+package classifier
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Parser turns one raw record into fields. It returns an error if the record is not in its format.
+type Parser interface {
+	Parse(data []byte) (map[string]any, error)
+}
+
+func newParser(name string, opts ParserOptions) (Parser, error) {
+	switch name {
+	case "json":
+		return jsonParser{}, nil
+	case "csv":
+		if len(opts.Headers) == 0 {
+			return nil, fmt.Errorf("csv parser needs options.headers")
+		}
+		delim := ','
+		if opts.Delimiter != "" {
+			r, size := utf8.DecodeRuneInString(opts.Delimiter)
+			if size != len(opts.Delimiter) {
+				return nil, fmt.Errorf("csv delimiter %q must be a single character", opts.Delimiter)
+			}
+			delim = r
+		}
+		return csvParser{headers: opts.Headers, delimiter: delim}, nil
+	case "kv":
+		p := kvParser{delimiter: opts.Delimiter, separator: opts.Separator}
+		if p.delimiter == "" {
+			p.delimiter = " "
+		}
+		if p.separator == "" {
+			p.separator = "="
+		}
+		return p, nil
+	case "syslog":
+		switch opts.RFC {
+		case "", "3164", "5424":
+			return syslogParser{rfc: opts.RFC}, nil
+		}
+		return nil, fmt.Errorf("syslog rfc must be 3164 or 5424, got %q", opts.RFC)
+	case "cef":
+		return cefParser{}, nil
+	case "leef":
+		return leefParser{}, nil
+	case "regex":
+		if opts.Pattern == "" {
+			return nil, fmt.Errorf("regex parser needs options.pattern")
+		}
+		re, err := regexp.Compile(opts.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		named := false
+		for _, n := range re.SubexpNames() {
+			named = named || n != ""
+		}
+		if !named {
+			return nil, fmt.Errorf("regex pattern has no named groups")
+		}
+		return regexParser{re: re}, nil
+	case "":
+		return nil, fmt.Errorf("no parser")
+	}
+	return nil, fmt.Errorf("unknown parser %q", name)
+}
+
+// jsonParser accepts a JSON object.
+type jsonParser struct{}
+
+func (jsonParser) Parse(data []byte) (map[string]any, error) {
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("not a JSON object: %w", err)
+	}
+	if m == nil {
+		return nil, fmt.Errorf("not a JSON object")
+	}
+	return m, nil
+}
+
+// csvParser accepts a single row with exactly one column per header.
+type csvParser struct {
+	headers   []string
+	delimiter rune
+}
+
+func (p csvParser) Parse(data []byte) (map[string]any, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimRight(data, "\r\n")))
+	r.Comma = p.delimiter
+	r.FieldsPerRecord = len(p.headers)
+	r.ReuseRecord = true
+	row, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("not a %d-column csv row: %w", len(p.headers), err)
+	}
+	if _, err := r.Read(); err == nil {
+		return nil, fmt.Errorf("more than one csv row")
+	}
+	m := make(map[string]any, len(row))
+	for i, h := range p.headers {
+		m[h] = row[i]
+	}
+	return m, nil
+}
+
+// kvParser accepts delimiter-separated key<separator>value pairs. Values may be double-quoted to
+// contain the delimiter.
+type kvParser struct {
+	delimiter string
+	separator string
+}
+
+func (p kvParser) Parse(data []byte) (map[string]any, error) {
+	m := make(map[string]any)
+	for _, pair := range splitQuoted(strings.TrimSpace(string(data)), p.delimiter) {
+		if pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, p.separator)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("%q is not a key%svalue pair", pair, p.separator)
+		}
+		m[k] = unquote(v)
+	}
+	if len(m) == 0 {
+		return nil, fmt.Errorf("no key%svalue pairs", p.separator)
+	}
+	return m, nil
+}
+
+// splitQuoted splits s on delim outside double quotes.
+func splitQuoted(s, delim string) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quoted:
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(s[i:], delim):
+			parts = append(parts, s[start:i])
+			start = i + len(delim)
+			i += len(delim) - 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func unquote(v string) string {
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		return strings.ReplaceAll(v[1:len(v)-1], `\"`, `"`)
+	}
+	return v
+}
+
+// regexParser accepts records the pattern matches; each named group becomes a field.
+type regexParser struct {
+	re *regexp.Regexp
+}
+
+func (p regexParser) Parse(data []byte) (map[string]any, error) {
+	match := p.re.FindSubmatch(bytes.TrimRight(data, "\r\n"))
+	if match == nil {
+		return nil, fmt.Errorf("does not match pattern")
+	}
+	m := make(map[string]any)
+	for i, name := range p.re.SubexpNames() {
+		if name != "" && match[i] != nil {
+			m[name] = string(match[i])
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,225 @@
+// Package classifier assigns a log_type to raw records. Each log type is a YAML schema naming the parser
+// that reads the record and the fields a record of that type must carry:
+//
+//	name: "fortigate:traffic"   # the log_type assigned to matching records
+//	parser: "kv"                # json, csv, kv, syslog, cef, leef or regex
+//	priority: 10                # lower is tried first when a source does not list its schemas
+//	fields:
+//	  srcip: string
+//	  dstport: integer
+//	  sentbyte: integer
+//	  action: string
+//	optional: ["sentbyte"]
+//	options:
+//	  delimiter: " "            # kv pair delimiter; csv column delimiter
+//	  separator: "="            # kv key/value separator
+//
+// A record belongs to the first schema whose parser accepts it and whose required fields are all
+// present and convertible to their declared types. Matching values are normalized to those types,
+// e.g. dstport "443" becomes the integer 443.
+package classifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// FieldType is the declared type of a schema field.
+type FieldType string
+
+const (
+	TypeString  FieldType = "string"
+	TypeInteger FieldType = "integer"
+	TypeFloat   FieldType = "float"
+	TypeBoolean FieldType = "boolean"
+	TypeObject  FieldType = "object"
+	TypeArray   FieldType = "array"
+	TypeAny     FieldType = "any"
+)
+
+// Valid reports whether t is one of the declared field types.
+func (t FieldType) Valid() bool {
+	switch t {
+	case TypeString, TypeInteger, TypeFloat, TypeBoolean, TypeObject, TypeArray, TypeAny:
+		return true
+	}
+	return false
+}
+
+// Convert converts v to t. Text-based parsers produce strings, so numbers and booleans are parsed
+// from their text form; a JSON-encoded string is accepted for object and array fields.
+func (t FieldType) Convert(v any) (any, error) {
+	switch t {
+	case TypeAny:
+		return v, nil
+	case TypeString:
+		switch x := v.(type) {
+		case string:
+			return x, nil
+		case float64, int64, bool:
+			return fmt.Sprint(x), nil
+		}
+	case TypeInteger:
+		switch x := v.(type) {
+		case int64:
+			return x, nil
+		case float64:
+			if x == math.Trunc(x) {
+				return int64(x), nil
+			}
+		case string:
+			if n, err := strconv.ParseInt(strings.TrimSpace(x), 10, 64); err == nil {
+				return n, nil
+			}
+		}
+	case TypeFloat:
+		switch x := v.(type) {
+		case float64:
+			return x, nil
+		case int64:
+			return float64(x), nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(x), 64); err == nil {
+				return f, nil
+			}
+		}
+	case TypeBoolean:
+		switch x := v.(type) {
+		case bool:
+			return x, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(x)); err == nil {
+				return b, nil
+			}
+		}
+	case TypeObject:
+		switch x := v.(type) {
+		case map[string]any:
+			return x, nil
+		case string:
+			var m map[string]any
+			if err := json.Unmarshal([]byte(x), &m); err == nil && m != nil {
+				return m, nil
+			}
+		}
+	case TypeArray:
+		switch x := v.(type) {
+		case []any:
+			return x, nil
+		case string:
+			var a []any
+			if err := json.Unmarshal([]byte(x), &a); err == nil && a != nil {
+				return a, nil
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown type %q", t)
+	}
+	return nil, fmt.Errorf("%v is not %s %s", v, article(t), t)
+}
+
+func article(t FieldType) string {
+	if t == TypeInteger || t == TypeObject || t == TypeArray || t == TypeAny {
+		return "an"
+	}
+	return "a"
+}
+
+// ParserOptions configures a schema's parser. Each parser reads only the options it documents.
+type ParserOptions struct {
+	// Delimiter separates csv columns (default ",") and kv pairs (default " ").
+	Delimiter string `yaml:"delimiter"`
+	// Separator splits a kv pair into key and value (default "=").
+	Separator string `yaml:"separator"`
+	// Headers names the csv columns in order.
+	Headers []string `yaml:"headers"`
+	// Pattern is the regex parser's expression; each named group becomes a field.
+	Pattern string `yaml:"pattern"`
+	// RFC restricts the syslog parser to "3164" or "5424"; both are tried by default.
+	RFC string `yaml:"rfc"`
+}
+
+// Schema is one log type definition loaded from a YAML file.
+type Schema struct {
+	Name     string               `yaml:"name"`
+	Parser   string               `yaml:"parser"`
+	Priority int                  `yaml:"priority"`
+	Fields   map[string]FieldType `yaml:"fields"`
+	Optional []string             `yaml:"optional"`
+	Options  ParserOptions        `yaml:"options"`
+
+	file     string
+	parser   Parser
+	optional map[string]struct{}
+}
+
+// File is the path the schema was loaded from.
+func (s *Schema) File() string { return s.file }
+
+// Load reads and validates one schema file.
+func Load(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Schema
+	if err := yaml.Load(data, &s, yaml.WithKnownFields()); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	s.file = path
+	if err := s.resolve(); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return &s, nil
+}
+
+func (s *Schema) resolve() error {
+	if s.Name == "" {
+		return fmt.Errorf("schema has no name")
+	}
+	p, err := newParser(s.Parser, s.Options)
+	if err != nil {
+		return fmt.Errorf("schema %s: %w", s.Name, err)
+	}
+	s.parser = p
+	for field, t := range s.Fields {
+		if !t.Valid() {
+			return fmt.Errorf("schema %s: field %s has unknown type %q", s.Name, field, t)
+		}
+	}
+	s.optional = make(map[string]struct{}, len(s.Optional))
+	for _, f := range s.Optional {
+		if _, ok := s.Fields[f]; !ok {
+			return fmt.Errorf("schema %s: optional field %s is not declared in fields", s.Name, f)
+		}
+		s.optional[f] = struct{}{}
+	}
+	return nil
+}
+
+// validate checks a parsed record against the schema and normalizes its declared fields in place.
+// Fields the schema does not declare are kept as parsed.
+func (s *Schema) validate(rec map[string]any) error {
+	for field, t := range s.Fields {
+		v, ok := rec[field]
+		if !ok || v == nil {
+			if _, opt := s.optional[field]; opt {
+				continue
+			}
+			return fmt.Errorf("missing field %s", field)
+		}
+		n, err := t.Convert(v)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field, err)
+		}
+		rec[field] = n
+	}
+	return nil
+}
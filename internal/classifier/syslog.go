@@ -0,0 +1,167 @@
+package classifier
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// syslogParser accepts RFC 5424 and RFC 3164 (BSD) syslog lines. Both produce priority, facility,
+// severity, timestamp (RFC 3339), hostname, app_name and message; RFC 5424 lines add proc_id, msg_id
+// and structured_data, RFC 3164 lines add proc_id when the tag carries one. Nil values ("-") are omitted.
+type syslogParser struct {
+	rfc string
+}
+
+// ParseSyslog parses one syslog line into the fields listed on syslogParser. rfc is "5424" or "3164" to
+// accept only that format, or "" for either.
+func ParseSyslog(data []byte, rfc string) (map[string]any, error) {
+	return syslogParser{rfc: rfc}.Parse(data)
+}
+
+func (p syslogParser) Parse(data []byte) (map[string]any, error) {
+	line := strings.TrimRight(string(data), "\r\n")
+	pri, rest, err := syslogPriority(line)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]any{"priority": int64(pri), "facility": int64(pri / 8), "severity": int64(pri % 8)}
+	switch {
+	case p.rfc != "3164" && strings.HasPrefix(rest, "1 "):
+		err = parse5424(rest[2:], m)
+	case p.rfc != "5424":
+		err = parse3164(rest, m, time.Now())
+	default:
+		err = fmt.Errorf("not an RFC 5424 syslog line")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func syslogPriority(line string) (int, string, error) {
+	end := strings.IndexByte(line, '>')
+	if !strings.HasPrefix(line, "<") || end < 2 || end > 4 {
+		return 0, "", fmt.Errorf("no syslog <priority>")
+	}
+	pri, err := strconv.Atoi(line[1:end])
+	if err != nil || pri > 191 {
+		return 0, "", fmt.Errorf("invalid syslog priority %q", line[1:end])
+	}
+	return pri, line[end+1:], nil
+}
+
+// parse5424 reads TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG].
+func parse5424(s string, m map[string]any) error {
+	header := strings.SplitN(s, " ", 6)
+	if len(header) < 6 {
+		return fmt.Errorf("truncated RFC 5424 header")
+	}
+	if header[0] != "-" {
+		ts, err := time.Parse(time.RFC3339Nano, header[0])
+		if err != nil {
+			return fmt.Errorf("invalid RFC 5424 timestamp %q", header[0])
+		}
+		m["timestamp"] = ts.UTC().Format(time.RFC3339Nano)
+	}
+	for i, key := range []string{"hostname", "app_name", "proc_id", "msg_id"} {
+		if v := header[i+1]; v != "-" {
+			m[key] = v
+		}
+	}
+	sd, msg, err := structuredData(header[5])
+	if err != nil {
+		return err
+	}
+	if sd != nil {
+		m["structured_data"] = sd
+	}
+	if msg = strings.TrimPrefix(msg, "\ufeff"); msg != "" {
+		m["message"] = msg
+	}
+	return nil
+}
+
+// structuredData parses "-" or a run of [id name="value" ...] elements and returns the text after it.
+func structuredData(s string) (map[string]any, string, error) {
+	if strings.HasPrefix(s, "-") {
+		return nil, strings.TrimPrefix(s[1:], " "), nil
+	}
+	sd := make(map[string]any)
+	for strings.HasPrefix(s, "[") {
+		end := 1
+		for end < len(s) && s[end] != ' ' && s[end] != ']' {
+			end++
+		}
+		id := s[1:end]
+		s = s[end:]
+		params := make(map[string]any)
+		for {
+			s = strings.TrimPrefix(s, " ")
+			if strings.HasPrefix(s, "]") {
+				s = s[1:]
+				break
+			}
+			name, rest, ok := strings.Cut(s, `="`)
+			if !ok || name == "" {
+				return nil, "", fmt.Errorf("invalid structured data element %s", id)
+			}
+			var value strings.Builder
+			i := 0
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) && strings.IndexByte(`"\]`, rest[i+1]) >= 0 {
+					i++
+				}
+				value.WriteByte(rest[i])
+			}
+			if i == len(rest) {
+				return nil, "", fmt.Errorf("unterminated structured data value %s.%s", id, name)
+			}
+			params[name] = value.String()
+			s = rest[i+1:]
+		}
+		if id == "" {
+			return nil, "", fmt.Errorf("structured data element without an id")
+		}
+		sd[id] = params
+	}
+	if len(sd) == 0 {
+		return nil, "", fmt.Errorf("invalid RFC 5424 structured data")
+	}
+	return sd, strings.TrimPrefix(s, " "), nil
+}
+
+// parse3164 reads "Mmm dd hh:mm:ss HOSTNAME TAG: MSG". The timestamp has no year, so the current one
+// is assumed unless that would put the record more than a day in the future.
+func parse3164(s string, m map[string]any, now time.Time) error {
+	if len(s) < len(time.Stamp)+1 {
+		return fmt.Errorf("not an RFC 3164 syslog line")
+	}
+	ts, err := time.ParseInLocation(time.Stamp, s[:len(time.Stamp)], time.UTC)
+	if err != nil {
+		return fmt.Errorf("invalid RFC 3164 timestamp %q", s[:len(time.Stamp)])
+	}
+	ts = ts.AddDate(now.Year(), 0, 0)
+	if ts.After(now.Add(24 * time.Hour)) {
+		ts = ts.AddDate(-1, 0, 0)
+	}
+	m["timestamp"] = ts.Format(time.RFC3339)
+
+	host, rest, ok := strings.Cut(strings.TrimPrefix(s[len(time.Stamp):], " "), " ")
+	if !ok || host == "" {
+		return fmt.Errorf("RFC 3164 line has no hostname")
+	}
+	m["hostname"] = host
+	if tag, msg, ok := strings.Cut(rest, ": "); ok && tag != "" && !strings.ContainsAny(tag, " ") {
+		if app, pid, ok := strings.Cut(tag, "["); ok && strings.HasSuffix(pid, "]") {
+			m["app_name"], m["proc_id"] = app, strings.TrimSuffix(pid, "]")
+		} else {
+			m["app_name"] = tag
+		}
+		rest = msg
+	}
+	m["message"] = rest
+	return nil
+}
@@ -0,0 +1,37 @@
+package classifier
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/harishhary/blink/internal/dirwatch"
+)
+
+// Watcher keeps the Registry for a schema directory current, reloading it whenever a YAML file changes.
+// Records being classified keep the registry they started with; the next one picks up the reload.
+type Watcher struct {
+	*dirwatch.Watcher[Registry]
+}
+
+// NewWatcher loads dir. It fails only if the directory cannot be read; schema files that do not load
+// are logged and left out until they are fixed.
+func NewWatcher(dir string) (*Watcher, error) {
+	w, err := dirwatch.New("schema-watcher", dir, dirwatch.Loader[Registry]{
+		Match:    isYAML,
+		Load:     NewRegistry,
+		Publish:  func(reg *Registry, gen uint64) { reg.generation = gen },
+		Describe: func(reg *Registry) string { return fmt.Sprintf("%d schema(s)", reg.Len()) },
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{w}, nil
+}
+
+// Ready reports an error until at least one schema has loaded: with none, every record would be poisoned.
+func (w *Watcher) Ready(context.Context) error {
+	if reg := w.Current(); reg == nil || reg.Len() == 0 {
+		return fmt.Errorf("no schemas loaded from %s", w.Dir())
+	}
+	return nil
+}
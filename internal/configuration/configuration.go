@@ -14,9 +14,16 @@ type ServiceConfiguration struct {
 
 	Kubernetes Kubernetes
 	JWT        JWT
+	Broker     BrokerConfig
 	Kafka      KafkaConfig
 	Topics     KafkaTopicsGroups
 	Executor   ExecutorConfig
+	Admin      AdminConfig
+	Artifacts  ArtifactsConfig
+	CrashLoop  CrashLoopConfig
+	Secrets    SecretsConfig
+	Debug      DebugConfig
+	Ingestor   IngestorConfig
 }
 
 // ServiceRole returns the role used by the service to perform operations
@@ -42,9 +49,22 @@ type Kubernetes struct {
 	Port   uint   `env:"KUBERNETES_SERVICE_PORT"`
 }
 
+// BrokerConfig selects the message broker the services read and write their topics through.
+type BrokerConfig struct {
+	// Type is "kafka" (the default) or "memory". The in-memory broker is shared by the stages of one
+	// process and loses every message when it exits: it is for running the pipeline locally or in tests.
+	Type string `env:"BROKER_TYPE,optional"`
+	// MemoryPartitions is the partition count of each in-memory topic (default 1).
+	MemoryPartitions int `env:"BROKER_MEMORY_PARTITIONS,optional"`
+	// MemoryRetain is the number of messages per partition an in-memory topic keeps while no consumer
+	// group reads it (default 10000).
+	MemoryRetain int `env:"BROKER_MEMORY_RETAIN,optional"`
+}
+
 type KafkaConfig struct {
 	// Brokers is a comma-separated list of Kafka bootstrap servers (e.g. "kafka:9092,other:9092").
-	Brokers string `env:"KAFKA_BROKERS"`
+	// Required when the broker type is kafka.
+	Brokers string `env:"KAFKA_BROKERS,optional"`
 }
 
 // KafkaTopicsGroups defines Kafka topic and consumer-group names for each pipeline stage.
@@ -59,6 +79,8 @@ type KafkaTopicsGroups struct {
 	TunerTopic        string `env:"KAFKA_TOPIC_TUNER"`
 	TunerGroup        string `env:"KAFKA_GROUP_TUNER"`
 	TunerDLQTopic     string `env:"KAFKA_TOPIC_TUNER_DLQ,optional"`
+	TaggerTopic       string `env:"KAFKA_TOPIC_TAGGER,optional"` // set to run the alert tagger between tuner and enricher
+	TaggerGroup       string `env:"KAFKA_GROUP_TAGGER,optional"`
 	EnricherTopic     string `env:"KAFKA_TOPIC_ENRICHER"`
 	EnricherGroup     string `env:"KAFKA_GROUP_ENRICHER"`
 	EnricherDLQTopic  string `env:"KAFKA_TOPIC_ENRICHER_DLQ,optional"`
@@ -67,6 +89,10 @@ type KafkaTopicsGroups struct {
 	FormatterDLQTopic string `env:"KAFKA_TOPIC_FORMATTER_DLQ,optional"`
 	DispatcherTopic   string `env:"KAFKA_TOPIC_DISPATCHER"`
 	DispatcherGroup   string `env:"KAFKA_GROUP_DISPATCHER"`
+	// PluginStatusTopic receives plugin lifecycle events (started, crashed, quarantined, ...). Unset disables them.
+	PluginStatusTopic string `env:"KAFKA_TOPIC_PLUGIN_STATUS,optional"`
+	// IngestPoisonTopic receives records the event ingestor could not classify, with the reason. Unset drops them.
+	IngestPoisonTopic string `env:"KAFKA_TOPIC_INGEST_POISON,optional"`
 }
 
 type ExecutorConfig struct {
@@ -77,3 +103,64 @@ type ExecutorConfig struct {
 	// TimeoutSec is the per-event evaluation timeout in seconds.
 	TimeoutSec int `env:"EXECUTOR_TIMEOUT_SEC,optional"`
 }
+
+type AdminConfig struct {
+	// Addr is the listen address of the admin API (default ":8081").
+	Addr string `env:"ADMIN_ADDR,optional"`
+	// Token is the bearer token every admin request must carry. The admin API stays off when it is empty.
+	Token string `env:"ADMIN_TOKEN,optional"`
+}
+
+// ArtifactsConfig points a plugin manager at a remote artifact store. When Store is empty the plugin
+// directory is used as-is.
+type ArtifactsConfig struct {
+	// Store is an http(s) URL or a local directory holding the manifest and the plugin artifacts.
+	Store string `env:"PLUGIN_ARTIFACT_STORE,optional"`
+	// Manifest is the manifest path inside the store (default "manifest.yaml").
+	Manifest string `env:"PLUGIN_ARTIFACT_MANIFEST,optional"`
+	// Token is sent as a bearer token to an http(s) store.
+	Token string `env:"PLUGIN_ARTIFACT_TOKEN,optional"`
+	// CacheDir holds verified downloads (default <tmp>/blink-artifacts).
+	CacheDir string `env:"PLUGIN_ARTIFACT_CACHE_DIR,optional"`
+	// IntervalSec is how often the manifest is re-read (default 30).
+	IntervalSec int `env:"PLUGIN_ARTIFACT_INTERVAL_SEC,optional"`
+}
+
+// CrashLoopConfig decides when a plugin manager quarantines a binary: after MaxFailures crashes or failed
+// starts within WindowSec seconds.
+type CrashLoopConfig struct {
+	// MaxFailures defaults to 5.
+	MaxFailures int `env:"PLUGIN_CRASHLOOP_MAX_FAILURES,optional"`
+	// WindowSec defaults to 600.
+	WindowSec int `env:"PLUGIN_CRASHLOOP_WINDOW_SEC,optional"`
+}
+
+// SecretsConfig tunes how plugin managers resolve the secret references in plugin sidecars.
+type SecretsConfig struct {
+	// RotationSec is how long resolved secrets are reused before they are looked up again, so a rotated
+	// secret reaches running plugins within it (default 300). Sidecar changes and restarts always resolve.
+	RotationSec int `env:"PLUGIN_SECRET_ROTATION_SEC,optional"`
+}
+
+// IngestorConfig configures the event ingestor.
+type IngestorConfig struct {
+	// ConfigFile is the YAML file listing the sources to run. Required by the ingestor.
+	ConfigFile string `env:"INGESTOR_CONFIG,optional"`
+	// CheckpointDir holds one checkpoint file per source (default <tmp>/blink-checkpoints).
+	CheckpointDir string `env:"INGESTOR_CHECKPOINT_DIR,optional"`
+	// CheckpointContainer keeps checkpoints in this Azure Storage container instead of CheckpointDir,
+	// using the KS_SVC_AZURESTORAGE_* account.
+	CheckpointContainer string `env:"INGESTOR_CHECKPOINT_CONTAINER,optional"`
+	// BatchSize is the most records read from a source and published in one batch (default 500).
+	BatchSize int `env:"INGESTOR_BATCH_SIZE,optional"`
+	// SchemaDir holds the log schema YAML files used to classify records from sources without a fixed
+	// log_type. Unset disables classification.
+	SchemaDir string `env:"INGESTOR_SCHEMA_DIR,optional"`
+}
+
+// DebugConfig holds settings for debugging plugins against a running service.
+type DebugConfig struct {
+	// ReattachFile lists plugins started outside the service (see the SDKs' ServeDebug) that the plugin
+	// manager adopts in place of the binaries of the same name. Leave unset in production.
+	ReattachFile string `env:"PLUGIN_REATTACH_FILE,optional"`
+}
@@ -0,0 +1,152 @@
+// Package dirwatch keeps a value loaded from a directory of config files current, reloading it when the
+// files change. Rule configs, schemas, normalization mappings, asset inventories and threat-intel feeds
+// are all mounted this way.
+package dirwatch
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	svcctx "github.com/harishhary/blink/internal/context"
+	"github.com/harishhary/blink/internal/errors"
+	"github.com/harishhary/blink/internal/logger"
+)
+
+// debounce is how long a directory has to stay quiet after a change before it is reloaded, so a burst of
+// writes reloads it once.
+const debounce = 400 * time.Millisecond
+
+// Loader describes how a Watcher loads its directory.
+type Loader[T any] struct {
+	// Match reports whether a change to the file with this base name calls for a reload.
+	Match func(name string) bool
+	// Load reads the directory. An error returned with a value is logged and the value published, so files
+	// that do not load are skipped until they are fixed; an error without one keeps the value loaded before.
+	Load func(dir string) (*T, error)
+	// Publish stamps a value with its generation before readers see it.
+	Publish func(v *T, generation uint64)
+	// Describe says what a value holds, for the log, e.g. "3 schema(s)".
+	Describe func(v *T) string
+}
+
+// Watcher keeps the value a Loader builds from a directory current. Readers keep the value Current gave
+// them; the next call picks up the reload.
+type Watcher[T any] struct {
+	svcctx.ServiceContext
+	dir     string
+	loader  Loader[T]
+	current atomic.Pointer[T]
+	gen     atomic.Uint64
+	mu      sync.Mutex // serialises reloads
+	hooks   []func(v *T)
+}
+
+// New loads dir and returns a Watcher named name for it. It fails only if Load returns no value.
+func New[T any](name, dir string, loader Loader[T]) (*Watcher[T], error) {
+	sc := svcctx.New(name)
+	sc.Logger = logger.New(sc.Name(), "dev")
+
+	w := &Watcher[T]{ServiceContext: sc, dir: dir, loader: loader}
+	v, err := loader.Load(dir)
+	if v == nil {
+		return nil, err
+	}
+	if err != nil {
+		w.ErrorF("initial load errors: %v", err)
+	}
+	w.publish(v)
+	return w, nil
+}
+
+// Dir returns the watched directory.
+func (w *Watcher[T]) Dir() string { return w.dir }
+
+// Current returns the most recently loaded value.
+func (w *Watcher[T]) Current() *T {
+	return w.current.Load()
+}
+
+// OnReload calls fn with every value published from now on, after readers can see it. Reloads are
+// serialised, so fn is never called concurrently with itself.
+func (w *Watcher[T]) OnReload(fn func(v *T)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.hooks = append(w.hooks, fn)
+}
+
+// Reload loads the directory now and publishes the result.
+func (w *Watcher[T]) Reload() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	v, err := w.loader.Load(w.dir)
+	if v == nil {
+		w.ErrorF("reload error: %v (keeping what was loaded before)", err)
+		return
+	}
+	if err != nil {
+		w.ErrorF("reload error: %v", err)
+	}
+	w.publish(v)
+}
+
+func (w *Watcher[T]) publish(v *T) {
+	gen := w.gen.Add(1)
+	if w.loader.Publish != nil {
+		w.loader.Publish(v, gen)
+	}
+	w.current.Store(v)
+	w.Info("loaded %s from %s (generation %d)", w.loader.Describe(v), w.dir, gen)
+	for _, fn := range w.hooks {
+		fn(v)
+	}
+}
+
+// Run watches the directory until ctx is cancelled.
+func (w *Watcher[T]) Run(ctx context.Context) errors.Error {
+	return Watch(ctx, w.Logger, w.dir, w.loader.Match, w.Reload)
+}
+
+// Watch calls reload after files in dir whose base names match change, debouncing bursts of changes into
+// one call. It blocks until ctx is cancelled.
+func Watch(ctx context.Context, log *logger.Logger, dir string, match func(name string) bool, reload func()) errors.Error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.NewE(err)
+	}
+	defer fsw.Close()
+	if err := fsw.Add(dir); err != nil {
+		return errors.NewE(err)
+	}
+
+	var timer *time.Timer
+	for {
+		select {
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+			// A mounted ConfigMap updates by swapping its ..data symlink rather than writing the files.
+			if name := filepath.Base(event.Name); match(name) || strings.HasPrefix(name, "..data") {
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(debounce, reload)
+			}
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			log.ErrorF("fsnotify error: %v", err)
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package dirwatch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// lines is a value loaded from the *.txt files in a directory; a file holding "bad" does not load.
+type lines struct {
+	text       []string
+	generation uint64
+}
+
+func loadLines(dir string) (*lines, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	v := &lines{}
+	var bad []string
+	for _, e := range entries {
+		if !isText(e.Name()) {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil || string(b) == "bad" {
+			bad = append(bad, e.Name())
+			continue
+		}
+		v.text = append(v.text, string(b))
+	}
+	if len(bad) > 0 {
+		return v, fmt.Errorf("did not load %s", strings.Join(bad, ", "))
+	}
+	return v, nil
+}
+
+func isText(name string) bool { return strings.HasSuffix(name, ".txt") }
+
+func TestWatcherReloads(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.txt", "a")
+
+	w, err := New("test-watcher", dir, Loader[lines]{
+		Match:    isText,
+		Load:     loadLines,
+		Publish:  func(v *lines, gen uint64) { v.generation = gen },
+		Describe: func(v *lines) string { return fmt.Sprintf("%d line(s)", len(v.text)) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := w.Current(); len(v.text) != 1 || v.generation != 1 {
+		t.Fatalf("initial load = %+v, want one line at generation 1", v)
+	}
+
+	reloaded := make(chan uint64, 2)
+	w.OnReload(func(v *lines) { reloaded <- v.generation })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := w.Run(ctx); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer func() { cancel(); <-done }()
+	time.Sleep(50 * time.Millisecond) // let the watch start
+
+	write("ignored.md", "x")
+	write("b.txt", "b")
+	write("c.txt", "bad")
+	deadline := time.Now().Add(5 * time.Second)
+	for w.Current().generation != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the reload")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if v := w.Current(); len(v.text) != 2 {
+		t.Fatalf("reload = %+v, want the two files that load", v)
+	}
+	if gen := <-reloaded; gen != 2 {
+		t.Fatalf("OnReload hook saw generation %d, want 2", gen)
+	}
+
+	// With the directory gone there is nothing to load, so the last value stays.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	w.Reload()
+	if v := w.Current(); len(v.text) != 2 || v.generation != 2 {
+		t.Fatalf("after a failed reload = %+v, want generation 2 kept", v)
+	}
+	if len(reloaded) != 0 {
+		t.Fatal("OnReload hook called for a reload that published nothing")
+	}
+}
@@ -0,0 +1,67 @@
+package ioc
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/harishhary/blink/internal/errors"
+	"github.com/harishhary/blink/pkg/events"
+	"github.com/harishhary/blink/pkg/matchers"
+	"github.com/harishhary/blink/pkg/rules"
+	"github.com/harishhary/blink/pkg/rules/config"
+)
+
+const (
+	// MatcherName is the built-in matcher that passes events carrying indicator hits. Listing it under a
+	// rule's matchers routes only those events to the rule.
+	MatcherName = "ioc-hit"
+	// RuleFileName is the file_name of the YAML sidecar that configures the built-in ioc-match rule.
+	RuleFileName = "ioc-match"
+	// builtinVersion versions the built-in matcher and rule for the plugin pools.
+	builtinVersion = "1"
+)
+
+// NewMatcher returns the built-in ioc-hit matcher, for registering with the event matcher's plugin
+// manager.
+func NewMatcher() (matchers.Matcher, error) { return hitMatcher{}, nil }
+
+type hitMatcher struct{}
+
+func (hitMatcher) Id() string          { return MatcherName }
+func (hitMatcher) Name() string        { return MatcherName }
+func (hitMatcher) Description() string { return "Passes events that hit a threat-intel indicator." }
+func (hitMatcher) Enabled() bool       { return true }
+func (hitMatcher) Checksum() string    { return builtinVersion }
+func (hitMatcher) String() string      { return "Matcher 'ioc-hit' (built-in)" }
+
+func (hitMatcher) Match(_ context.Context, event events.Event) (bool, errors.Error) {
+	hits, _ := event[events.IOCKey].([]any)
+	return len(hits) > 0, nil
+}
+
+// NewRule returns the built-in ioc-match rule, for registering with the rule executor's plugin manager.
+// It fires on events carrying an indicator hit at least as confident as its min_confidence param
+// (default 0), so one feed can be loaded for enrichment lookups while only its surest entries alert.
+// The rule executor registers it while its sidecar, ioc-match.yaml, exists.
+func NewRule(watcher *config.Watcher) func() (rules.Rule, error) {
+	return func() (rules.Rule, error) {
+		return rules.NewBuiltin(RuleFileName, watcher, builtinVersion, evaluate), nil
+	}
+}
+
+func evaluate(_ context.Context, cfg *config.RuleMetadata, event events.Event) (bool, errors.Error) {
+	threshold := 0
+	if v, ok := cfg.Params()["min_confidence"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return false, errors.NewF("ioc-match: min_confidence param %q is not a number", v)
+		}
+		threshold = n
+	}
+	for _, h := range Attached(event) {
+		if h.Indicator.Confidence >= threshold {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,122 @@
+package ioc
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/harishhary/blink/pkg/events"
+	"go.yaml.in/yaml/v4"
+)
+
+// AllLogTypes is the fields key whose fields are matched in events of every log_type.
+const AllLogTypes = "*"
+
+// Config is the IOC_CONFIG file shared by the services that match or query indicators:
+//
+//	dir: /etc/blink/ioc     # *.csv, STIX and MISP *.json feeds, reloaded on change
+//	default_ttl: 720h       # lifetime from when last seen for indicators the feed gives no expiry
+//	min_confidence: 40      # indicators rated lower are not loaded
+//	fields:                 # event fields to match, by log_type, with the type of indicator they hold
+//	  "*":
+//	    src_endpoint.ip: ip
+//	    dst_endpoint.ip: ip
+//	  proxy:
+//	    request.url: url
+//	    request.host: domain
+//	  email:
+//	    sender: email
+//	    attachments[*].sha256: hash
+//
+// A field of type any is looked up as whatever its value looks like. Indicators with neither an expiry
+// nor a date never expire.
+type Config struct {
+	Dir           string                       `yaml:"dir"`
+	DefaultTTL    time.Duration                `yaml:"default_ttl"`
+	MinConfidence int                          `yaml:"min_confidence"`
+	Fields        map[string]map[string]string `yaml:"fields"`
+
+	compiled map[string][]Field
+}
+
+// Field is an event field and the type of indicator its values are looked up as.
+type Field struct {
+	Path string
+	Type Type
+}
+
+// LoadConfig reads and validates an IOC config file.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("ioc: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Load(data, &cfg, yaml.WithKnownFields()); err != nil {
+		return Config{}, fmt.Errorf("ioc: parse %s: %w", path, err)
+	}
+	if cfg.Dir == "" {
+		return Config{}, fmt.Errorf("ioc: %s sets no dir", path)
+	}
+	if err := cfg.compile(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) compile() error {
+	if c.MinConfidence < 0 || c.MinConfidence > 100 {
+		return fmt.Errorf("ioc: min_confidence %d is not between 0 and 100", c.MinConfidence)
+	}
+	if c.DefaultTTL < 0 {
+		return fmt.Errorf("ioc: default_ttl is negative")
+	}
+	c.compiled = make(map[string][]Field, len(c.Fields))
+	for logType, fields := range c.Fields {
+		for _, path := range slices.Sorted(maps.Keys(fields)) {
+			t, err := ParseType(fields[path])
+			if err != nil || t == TypeCIDR {
+				return fmt.Errorf("ioc: fields.%s.%s: %q is not a field type", logType, path, fields[path])
+			}
+			if _, err := events.CompilePath(path); err != nil {
+				return fmt.Errorf("ioc: fields.%s.%s: %w", logType, path, err)
+			}
+			c.compiled[logType] = append(c.compiled[logType], Field{Path: path, Type: t})
+		}
+	}
+	return nil
+}
+
+// FieldsFor returns the fields to match in events of logType: those listed for it, then those listed
+// for every log_type.
+func (c Config) FieldsFor(logType string) []Field {
+	own, all := c.compiled[logType], c.compiled[AllLogTypes]
+	if logType == AllLogTypes || len(all) == 0 {
+		return own
+	}
+	out := slices.Clip(own)
+	for _, f := range all {
+		if !slices.ContainsFunc(own, func(o Field) bool { return o.Path == f.Path }) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// prepare drops the indicators rated below MinConfidence and gives the undated-expiry ones DefaultTTL
+// from when they were last seen.
+func (c Config) prepare(indicators []Indicator) []Indicator {
+	out := indicators[:0]
+	for _, ind := range indicators {
+		if ind.Confidence < c.MinConfidence {
+			continue
+		}
+		if ind.Expires.IsZero() && !ind.Seen.IsZero() && c.DefaultTTL > 0 {
+			ind.Expires = ind.Seen.Add(c.DefaultTTL)
+		}
+		out = append(out, ind)
+	}
+	return out
+}
@@ -0,0 +1,34 @@
+package ioc
+
+import (
+	"context"
+
+	"github.com/harishhary/blink/pkg/hostapi"
+)
+
+// LookupIndicators implements hostapi.Host, answering plugins' indicator lookups from the current store.
+// Each value is looked up as the type its form suggests.
+func (w *Watcher) LookupIndicators(_ context.Context, values ...string) ([]hostapi.IndicatorHit, error) {
+	store := w.Current()
+	if store == nil {
+		return nil, hostapi.ErrNoIndicators
+	}
+	var hits []hostapi.IndicatorHit
+	for _, v := range values {
+		ind, ok := store.Lookup(TypeAny, v)
+		if !ok {
+			continue
+		}
+		hits = append(hits, hostapi.IndicatorHit{
+			Value:       v,
+			Type:        string(ind.Type),
+			Indicator:   ind.Value,
+			Confidence:  ind.Confidence,
+			Source:      ind.Source,
+			Expires:     ind.Expires,
+			Description: ind.Description,
+			Labels:      ind.Labels,
+		})
+	}
+	return hits, nil
+}
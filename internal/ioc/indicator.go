@@ -0,0 +1,150 @@
+package ioc
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Type is the kind of observable an indicator matches.
+type Type string
+
+const (
+	TypeIP     Type = "ip"
+	TypeCIDR   Type = "cidr"
+	TypeDomain Type = "domain" // also matches every subdomain
+	TypeURL    Type = "url"
+	TypeHash   Type = "hash" // MD5, SHA-1, SHA-256 or SHA-512, hex encoded
+	TypeEmail  Type = "email"
+	TypeAny    Type = "any" // a field type only: the value's form decides what it is looked up as
+)
+
+// DefaultConfidence is given to indicators whose feed does not rate them, the middle of the 0-100 scale.
+const DefaultConfidence = 50
+
+// ParseType reads an indicator or field type. "ipv4", "ipv6" and "network" are accepted as aliases.
+func ParseType(s string) (Type, error) {
+	switch t := Type(strings.ToLower(strings.TrimSpace(s))); t {
+	case TypeIP, TypeCIDR, TypeDomain, TypeURL, TypeHash, TypeEmail, TypeAny:
+		return t, nil
+	case "ipv4", "ipv6":
+		return TypeIP, nil
+	case "network":
+		return TypeCIDR, nil
+	case "":
+		return TypeAny, nil
+	default:
+		return "", fmt.Errorf("unknown indicator type %q", s)
+	}
+}
+
+// Indicator is one indicator of compromise from a feed.
+type Indicator struct {
+	Type        Type
+	Value       string // canonical form, see Canonical
+	Confidence  int    // 0-100
+	Source      string // the feed or the organisation that published the indicator
+	Seen        time.Time
+	Expires     time.Time // zero: never
+	Description string
+	Labels      []string
+}
+
+// Expired reports whether the indicator is no longer valid at now.
+func (ind *Indicator) Expired(now time.Time) bool {
+	return !ind.Expires.IsZero() && !now.Before(ind.Expires)
+}
+
+// Canonical returns value in the form indicators of type t are indexed by: addresses unmapped, networks
+// masked, domains, emails, hashes and URL schemes and hosts lower-cased. A single address given as a
+// network (10.0.0.1/32) is an IP.
+func Canonical(t Type, value string) (Type, string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return t, "", fmt.Errorf("empty %s", t)
+	}
+	switch t {
+	case TypeIP, TypeCIDR:
+		if !strings.Contains(value, "/") {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return t, "", err
+			}
+			return TypeIP, addr.Unmap().String(), nil
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return t, "", err
+		}
+		if prefix.Addr().Is4In6() {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		prefix = prefix.Masked()
+		if prefix.IsSingleIP() {
+			return TypeIP, prefix.Addr().String(), nil
+		}
+		return TypeCIDR, prefix.String(), nil
+	case TypeDomain:
+		d := strings.TrimPrefix(strings.TrimSuffix(strings.ToLower(value), "."), "*.")
+		if strings.ContainsAny(d, "/@: ") || d == "" {
+			return t, "", fmt.Errorf("%q is not a domain", value)
+		}
+		return t, d, nil
+	case TypeURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return t, "", fmt.Errorf("%q is not an absolute URL", value)
+		}
+		u.Scheme, u.Host = strings.ToLower(u.Scheme), strings.ToLower(u.Host)
+		return t, u.String(), nil
+	case TypeHash:
+		h := strings.ToLower(value)
+		if !isHash(h) {
+			return t, "", fmt.Errorf("%q is not an MD5, SHA-1, SHA-256 or SHA-512 hash", value)
+		}
+		return t, h, nil
+	case TypeEmail:
+		e := strings.ToLower(value)
+		if at := strings.LastIndexByte(e, '@'); at <= 0 || at == len(e)-1 {
+			return t, "", fmt.Errorf("%q is not an email address", value)
+		}
+		return t, e, nil
+	}
+	return t, "", fmt.Errorf("cannot index indicators of type %q", t)
+}
+
+// Detect guesses the type of an observed value: an address or network, a URL, an email address, a hash,
+// or else a domain.
+func Detect(value string) Type {
+	switch {
+	case strings.Contains(value, "://"):
+		return TypeURL
+	case strings.Contains(value, "@"):
+		return TypeEmail
+	case isHash(strings.ToLower(value)):
+		return TypeHash
+	}
+	if _, err := netip.ParseAddr(value); err == nil {
+		return TypeIP
+	}
+	if _, err := netip.ParsePrefix(value); err == nil {
+		return TypeCIDR
+	}
+	return TypeDomain
+}
+
+func isHash(s string) bool {
+	switch len(s) {
+	case 32, 40, 64, 128:
+	default:
+		return false
+	}
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,224 @@
+package ioc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harishhary/blink/internal/dirwatch"
+	"github.com/harishhary/blink/pkg/events"
+	"github.com/harishhary/blink/pkg/rules/config"
+)
+
+const feedCSV = `type,value,confidence,source,seen,expires,description,labels
+ip,203.0.113.7,90,abuse-feed,,,C2 server,c2;botnet
+cidr,198.51.100.0/24,40,,,,,
+cidr,198.51.100.128/25,60,,,,,
+network,10.9.0.0/16,70,,2020-01-01,,stale,
+domain,Evil.Example.,80,,,,,phishing
+hash,D41D8CD98F00B204E9800998ECF8427E,,,,,,
+email,ceo@evil.example,20,,,,,
+ip,not-an-ip,50,,,,,
+`
+
+const feedSTIX = `{
+  "type": "bundle",
+  "id": "bundle--1",
+  "objects": [
+    {"type": "identity", "id": "identity--acme", "name": "ACME CERT"},
+    {"type": "indicator", "id": "indicator--1", "created_by_ref": "identity--acme", "confidence": 85,
+     "pattern": "[url:value = 'https://files.example/payload.exe'] OR [ipv6-addr:value = '2001:db8:bad::/48']",
+     "pattern_type": "stix", "valid_until": "2099-01-01T00:00:00Z", "indicator_types": ["malicious-activity"]},
+    {"type": "indicator", "id": "indicator--2",
+     "pattern": "[file:hashes.'SHA-256' = 'e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855']"},
+    {"type": "indicator", "id": "indicator--3", "pattern": "[ipv4-addr:value = '192.0.2.1' AND network-traffic:dst_port = 4444]"},
+    {"type": "indicator", "id": "indicator--4", "pattern": "[domain-name:value = 'old.example']", "valid_until": "2001-01-01T00:00:00Z"},
+    {"type": "indicator", "id": "indicator--5", "pattern": "title: x", "pattern_type": "sigma"}
+  ]
+}`
+
+const feedMISP = `{"response": [{"Event": {
+  "info": "Phishing wave", "Orgc": {"name": "CIRCL"},
+  "Tag": [{"name": "tlp:green"}, {"name": "confidence-level:\"usually-confident\""}],
+  "Attribute": [
+    {"type": "domain|ip", "value": "login.bank-secure.example|192.0.2.55", "to_ids": true, "timestamp": "1760000000"},
+    {"type": "ip-dst|port", "value": "192.0.2.66|443", "to_ids": true,
+     "Tag": [{"name": "confidence-level:\"rarely-confident\""}]},
+    {"type": "comment", "value": "not an observable", "to_ids": false},
+    {"type": "ip-dst", "value": "192.0.2.77", "to_ids": false}
+  ],
+  "Object": [{"Attribute": [{"type": "filename|sha1", "value": "invoice.pdf|da39a3ee5e6b4b0d3255bfef95601890afd80709", "to_ids": true}]}]
+}}]}`
+
+func loadTestFeeds(t *testing.T) []Indicator {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range map[string]string{"abuse.csv": feedCSV, "acme.json": feedSTIX, "misp.json": feedMISP, "notes.txt": "ignored"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	indicators, err := LoadDir(dir)
+	if err == nil {
+		t.Fatal("LoadDir reported no error for the invalid entries")
+	}
+	for _, want := range []string{"abuse.csv: row 9", "indicator--3: pattern"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadDir error %q does not mention %s", err, want)
+		}
+	}
+	return indicators
+}
+
+func TestLoadFormats(t *testing.T) {
+	indicators := loadTestFeeds(t)
+	byValue := make(map[string]Indicator)
+	for _, ind := range indicators {
+		byValue[ind.Value] = ind
+	}
+	for value, want := range map[string]Indicator{
+		"203.0.113.7":                              {Type: TypeIP, Confidence: 90, Source: "abuse-feed"},
+		"10.9.0.0/16":                              {Type: TypeCIDR, Confidence: 70, Source: "abuse"},
+		"evil.example":                             {Type: TypeDomain, Confidence: 80, Source: "abuse"},
+		"d41d8cd98f00b204e9800998ecf8427e":         {Type: TypeHash, Confidence: DefaultConfidence, Source: "abuse"},
+		"https://files.example/payload.exe":        {Type: TypeURL, Confidence: 85, Source: "ACME CERT"},
+		"2001:db8:bad::/48":                        {Type: TypeCIDR, Confidence: 85, Source: "ACME CERT"},
+		"login.bank-secure.example":                {Type: TypeDomain, Confidence: 75, Source: "CIRCL"},
+		"192.0.2.55":                               {Type: TypeIP, Confidence: 75, Source: "CIRCL"},
+		"192.0.2.66":                               {Type: TypeIP, Confidence: 25, Source: "CIRCL"},
+		"da39a3ee5e6b4b0d3255bfef95601890afd80709": {Type: TypeHash, Confidence: 75, Source: "CIRCL"},
+	} {
+		got, ok := byValue[value]
+		if !ok {
+			t.Errorf("%s not loaded", value)
+			continue
+		}
+		if got.Type != want.Type || got.Confidence != want.Confidence || got.Source != want.Source {
+			t.Errorf("%s = %s/%d/%q, want %s/%d/%q", value, got.Type, got.Confidence, got.Source, want.Type, want.Confidence, want.Source)
+		}
+	}
+	for _, skipped := range []string{"192.0.2.77", "192.0.2.1"} {
+		if _, ok := byValue[skipped]; ok {
+			t.Errorf("%s loaded, want it skipped", skipped)
+		}
+	}
+	if labels := byValue["192.0.2.55"].Labels; len(labels) != 1 || labels[0] != "tlp:green" {
+		t.Errorf("MISP labels = %v, want the non-confidence tags", labels)
+	}
+}
+
+func TestStoreLookup(t *testing.T) {
+	cfg := Config{DefaultTTL: 24 * time.Hour, MinConfidence: 30}
+	store := NewStore(cfg.prepare(loadTestFeeds(t)), time.Now())
+
+	for _, tc := range []struct {
+		t     Type
+		value string
+		want  string
+	}{
+		{TypeIP, "203.0.113.7", "203.0.113.7"},
+		{TypeIP, "::ffff:203.0.113.7", "203.0.113.7"},
+		{TypeIP, "198.51.100.200", "198.51.100.128/25"}, // the narrower network wins
+		{TypeIP, "198.51.100.5", "198.51.100.0/24"},
+		{TypeIP, "10.9.1.1", ""},   // seen in 2020, expired by default_ttl
+		{TypeIP, "192.0.2.66", ""}, // below min_confidence
+		{TypeAny, "2001:db8:bad:1::9", "2001:db8:bad::/48"},
+		{TypeDomain, "WWW.evil.example", "evil.example"},
+		{TypeDomain, "notevil.example", ""},
+		{TypeDomain, "old.example", ""}, // expired by valid_until
+		{TypeURL, "HTTPS://files.example/payload.exe", "https://files.example/payload.exe"},
+		{TypeURL, "http://cdn.evil.example/x.js", "evil.example"},
+		{TypeAny, "https://203.0.113.7:8443/gate", "203.0.113.7"},
+		{TypeAny, "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{TypeEmail, "ceo@evil.example", ""}, // below min_confidence
+		{TypeIP, "evil.example", ""},
+	} {
+		got, _ := store.Lookup(tc.t, tc.value)
+		if got.Value != tc.want {
+			t.Errorf("Lookup(%s, %s) = %q, want %q", tc.t, tc.value, got.Value, tc.want)
+		}
+	}
+}
+
+func TestTagAndRule(t *testing.T) {
+	cfg := Config{Fields: map[string]map[string]string{
+		AllLogTypes: {"src_endpoint.ip": "ip", "dst_endpoint.ip": "ip"},
+		"proxy":     {"request.url": "url", "dst_endpoint.ip": "any"},
+	}}
+	if err := cfg.compile(); err != nil {
+		t.Fatal(err)
+	}
+	if fields := cfg.FieldsFor("proxy"); len(fields) != 3 || fields[0] != (Field{"dst_endpoint.ip", TypeAny}) {
+		t.Fatalf("FieldsFor(proxy) = %v, want its own fields first and the shared ones it does not override", fields)
+	}
+	store := NewStore(loadTestFeeds(t), time.Now())
+	evt := events.Event{
+		"request": map[string]any{"url": "http://a.evil.example/login"},
+		events.NormalizedKey: map[string]any{
+			"src_endpoint": map[string]any{"ip": "10.0.0.1"},
+			"dst_endpoint": map[string]any{"ip": "198.51.100.9"},
+		},
+	}
+	hits := store.Tag(evt, cfg.FieldsFor("proxy"))
+	if len(hits) != 2 || hits[0].Indicator.Value != "198.51.100.0/24" || hits[1].Indicator.Value != "evil.example" {
+		t.Fatalf("hits = %+v, want the network by dst_endpoint.ip and the domain by request.url", hits)
+	}
+	attached := Attached(evt)
+	if len(attached) != 2 || attached[1].Indicator.Confidence != 80 || attached[1].Indicator.Labels[0] != "phishing" {
+		t.Errorf("attached = %+v, want the hits read back", attached)
+	}
+
+	for threshold, want := range map[string]bool{"70": true, "90": false} {
+		meta, err := config.New(config.RuleMetadata{ParamsField: map[string]string{"min_confidence": threshold}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := evaluate(t.Context(), meta, evt); got != want {
+			t.Errorf("ioc-match with min_confidence %s = %v, want %v", threshold, got, want)
+		}
+	}
+	if ok, _ := (hitMatcher{}).Match(t.Context(), evt); !ok {
+		t.Error("ioc-hit did not pass an event with hits")
+	}
+	if ok, _ := (hitMatcher{}).Match(t.Context(), events.Event{}); ok {
+		t.Error("ioc-hit passed an event without hits")
+	}
+}
+
+func TestTagDropsForgedHits(t *testing.T) {
+	cfg := Config{Fields: map[string]map[string]string{"proxy": {"request.url": "url"}}}
+	if err := cfg.compile(); err != nil {
+		t.Fatal(err)
+	}
+	store := NewStore(loadTestFeeds(t), time.Now())
+	feeds, err := dirwatch.New("ioc-watcher", t.TempDir(), dirwatch.Loader[Store]{
+		Load:     func(string) (*Store, error) { return store, nil },
+		Describe: func(*Store) string { return "test feeds" },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &Watcher{Watcher: feeds, cfg: cfg}
+	for _, tc := range []struct {
+		name    string
+		w       *Watcher
+		logType string
+	}{
+		{"no hit", w, "proxy"},
+		{"no fields for the log_type", w, "dns"},
+		{"no feeds", nil, "proxy"},
+	} {
+		evt := events.Event{
+			"request":     map[string]any{"url": "https://benign.example/"},
+			events.IOCKey: []any{map[string]any{"type": "domain", "indicator": "evil.example", "confidence": 100.0}},
+		}
+		if hits := tc.w.Tag(evt, tc.logType); len(hits) != 0 {
+			t.Errorf("%s: hits = %+v, want none", tc.name, hits)
+		}
+		if _, ok := evt[events.IOCKey]; ok {
+			t.Errorf("%s: the event kept the indicators it arrived with: %v", tc.name, evt)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package ioc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadDir reads every feed file in dir, in name order: *.csv files in the CSV format below and *.json
+// files holding a STIX 2.1 bundle or MISP events. Indicators without a source are attributed to their
+// file's name. It returns the indicators that loaded together with an error listing the files and
+// entries that did not.
+func LoadDir(dir string) ([]Indicator, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("ioc: read dir %s: %w", dir, err)
+	}
+	indicators := []Indicator{}
+	var errs []string
+	for _, e := range entries {
+		if e.IsDir() || !isFeed(e.Name()) {
+			continue
+		}
+		loaded, err := LoadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", e.Name(), err))
+		}
+		indicators = append(indicators, loaded...)
+	}
+	if len(errs) > 0 {
+		return indicators, fmt.Errorf("ioc: %s", strings.Join(errs, "\n  "))
+	}
+	return indicators, nil
+}
+
+// LoadFile reads one feed file, returning the indicators that parsed and an error for the rest.
+func LoadFile(path string) ([]Indicator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var indicators []Indicator
+	if strings.HasSuffix(path, ".csv") {
+		indicators, err = decodeCSV(bytes.NewReader(data))
+	} else {
+		indicators, err = decodeJSON(data)
+	}
+	feed := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for i := range indicators {
+		if indicators[i].Source == "" {
+			indicators[i].Source = feed
+		}
+	}
+	return indicators, err
+}
+
+// decodeJSON tells a STIX bundle ({"type": "bundle"}) from MISP events ({"Event": ...}, {"response":
+// [...]} or a list of either) and decodes it.
+func decodeJSON(data []byte) ([]Indicator, error) {
+	var probe struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &probe); err == nil && probe.Type == "bundle" {
+		return decodeSTIX(data)
+	}
+	return decodeMISP(data)
+}
+
+// decodeCSV reads a feed with a header row naming its columns; only type and value are required:
+//
+//	type,value,confidence,source,seen,expires,description,labels
+//	ip,203.0.113.7,90,abuse-feed,2026-10-01,2026-11-01,C2 server,c2;botnet
+//	domain,evil.example,70,,,,,phishing
+//
+// Times are RFC 3339 or dates; labels are separated by semicolons.
+func decodeCSV(r io.Reader) ([]Indicator, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	col := make(map[string]int)
+	for i, name := range rows[0] {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"type", "value"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("no %s column", required)
+		}
+	}
+	cell := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+	indicators := make([]Indicator, 0, len(rows)-1)
+	var errs []string
+	for n, row := range rows[1:] {
+		ind, err := csvIndicator(cell, row)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("row %d: %s", n+2, err))
+			continue
+		}
+		indicators = append(indicators, ind)
+	}
+	if len(errs) > 0 {
+		return indicators, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return indicators, nil
+}
+
+func csvIndicator(cell func([]string, string) string, row []string) (Indicator, error) {
+	t, err := ParseType(cell(row, "type"))
+	if err != nil {
+		return Indicator{}, err
+	}
+	if t == TypeAny {
+		t = Detect(cell(row, "value"))
+	}
+	ind := Indicator{Confidence: DefaultConfidence, Source: cell(row, "source"), Description: cell(row, "description")}
+	if ind.Type, ind.Value, err = Canonical(t, cell(row, "value")); err != nil {
+		return Indicator{}, err
+	}
+	if c := cell(row, "confidence"); c != "" {
+		if ind.Confidence, err = parseConfidence(c); err != nil {
+			return Indicator{}, err
+		}
+	}
+	if ind.Seen, err = parseTime(cell(row, "seen")); err != nil {
+		return Indicator{}, fmt.Errorf("seen: %w", err)
+	}
+	if ind.Expires, err = parseTime(cell(row, "expires")); err != nil {
+		return Indicator{}, fmt.Errorf("expires: %w", err)
+	}
+	for _, l := range strings.Split(cell(row, "labels"), ";") {
+		if l = strings.TrimSpace(l); l != "" {
+			ind.Labels = append(ind.Labels, l)
+		}
+	}
+	return ind, nil
+}
+
+func parseConfidence(s string) (int, error) {
+	c, err := strconv.Atoi(s)
+	if err != nil || c < 0 || c > 100 {
+		return 0, fmt.Errorf("confidence %q is not between 0 and 100", s)
+	}
+	return c, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
+
+func isFeed(name string) bool {
+	return !strings.HasPrefix(name, ".") && slices.Contains([]string{".csv", ".json"}, filepath.Ext(name))
+}
@@ -0,0 +1,168 @@
+package ioc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type mispEvent struct {
+	Info      string                `json:"info"`
+	Orgc      struct{ Name string } `json:"Orgc"`
+	Tags      []mispTag             `json:"Tag"`
+	Attribute []mispAttribute       `json:"Attribute"`
+	Object    []struct {
+		Attribute []mispAttribute `json:"Attribute"`
+	} `json:"Object"`
+}
+
+type mispAttribute struct {
+	Type      string    `json:"type"`
+	Value     string    `json:"value"`
+	ToIDs     *bool     `json:"to_ids"`
+	Deleted   bool      `json:"deleted"`
+	Comment   string    `json:"comment"`
+	Timestamp mispTime  `json:"timestamp"`
+	LastSeen  time.Time `json:"last_seen"`
+	Tags      []mispTag `json:"Tag"`
+}
+
+type mispTag struct {
+	Name string `json:"name"`
+}
+
+// mispTime is a Unix time, which MISP writes as a string.
+type mispTime struct{ time.Time }
+
+func (t *mispTime) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("timestamp %s: %w", data, err)
+	}
+	t.Time = time.Unix(sec, 0).UTC()
+	return nil
+}
+
+// mispConfidence maps the MISP confidence-level taxonomy to the 0-100 scale.
+var mispConfidence = map[string]int{
+	"completely-confident": 100,
+	"usually-confident":    75,
+	"fairly-confident":     50,
+	"rarely-confident":     25,
+	"unconfident":          0,
+}
+
+// decodeMISP reads MISP events as exported by the UI or returned by the REST API: {"Event": ...},
+// {"response": [{"Event": ...}]} or a list of {"Event": ...}. Attributes flagged for detection (to_ids)
+// whose type maps to an indexed observable become indicators, including those inside objects; the
+// event's creator organisation is their source. MISP has no confidence field, so the confidence-level
+// taxonomy is read from the attribute's tags, then the event's.
+func decodeMISP(data []byte) ([]Indicator, error) {
+	type wrapper struct {
+		Event *mispEvent `json:"Event"`
+	}
+	var wrappers []wrapper
+	var single struct {
+		wrapper
+		Response []wrapper `json:"response"`
+	}
+	if err := json.Unmarshal(data, &wrappers); err != nil {
+		if err := json.Unmarshal(data, &single); err != nil {
+			return nil, fmt.Errorf("neither a STIX bundle nor MISP events: %w", err)
+		}
+		wrappers = append(single.Response, single.wrapper)
+	}
+	var indicators []Indicator
+	var errs []string
+	found := false
+	for _, w := range wrappers {
+		if w.Event == nil {
+			continue
+		}
+		found = true
+		ev := w.Event
+		attrs := ev.Attribute
+		for _, o := range ev.Object {
+			attrs = append(attrs, o.Attribute...)
+		}
+		for _, a := range attrs {
+			if a.Deleted || (a.ToIDs != nil && !*a.ToIDs) {
+				continue
+			}
+			base := Indicator{
+				Source:      ev.Orgc.Name,
+				Seen:        firstSet(a.LastSeen, a.Timestamp.Time),
+				Description: a.Comment,
+			}
+			if base.Description == "" {
+				base.Description = ev.Info
+			}
+			base.Confidence, base.Labels = mispTags(ev.Tags, a.Tags)
+			for _, obs := range mispObservables(a.Type, a.Value) {
+				ind := base
+				var err error
+				if ind.Type, ind.Value, err = Canonical(obs.t, obs.value); err != nil {
+					errs = append(errs, fmt.Sprintf("%s attribute: %s", a.Type, err))
+					continue
+				}
+				indicators = append(indicators, ind)
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("neither a STIX bundle nor MISP events")
+	}
+	if len(errs) > 0 {
+		return indicators, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return indicators, nil
+}
+
+// mispTags returns the confidence set by the attribute's or else the event's confidence-level tag, and
+// the other tags as labels.
+func mispTags(event, attribute []mispTag) (int, []string) {
+	confidence, fromAttr := DefaultConfidence, false
+	var labels []string
+	for i, tags := range [][]mispTag{attribute, event} {
+		for _, tag := range tags {
+			level, ok := strings.CutPrefix(tag.Name, "confidence-level:")
+			if !ok {
+				labels = append(labels, tag.Name)
+				continue
+			}
+			if c, known := mispConfidence[strings.Trim(level, `"`)]; known && (i == 0 || !fromAttr) {
+				confidence, fromAttr = c, i == 0
+			}
+		}
+	}
+	return confidence, labels
+}
+
+// mispObservables maps a MISP attribute to the observables it names, e.g. ip-dst|port to the address
+// and domain|ip to both parts. Attribute types the store does not index name none.
+func mispObservables(attrType, value string) []observable {
+	first, second, _ := strings.Cut(value, "|")
+	switch attrType {
+	case "ip-src", "ip-dst", "ip-src|port", "ip-dst|port":
+		return []observable{{TypeIP, first}}
+	case "domain", "hostname", "hostname|port":
+		return []observable{{TypeDomain, first}}
+	case "domain|ip":
+		return []observable{{TypeDomain, first}, {TypeIP, second}}
+	case "url":
+		return []observable{{TypeURL, value}}
+	case "md5", "sha1", "sha256", "sha512":
+		return []observable{{TypeHash, value}}
+	case "filename|md5", "filename|sha1", "filename|sha256", "filename|sha512":
+		return []observable{{TypeHash, second}}
+	case "email", "email-src", "email-dst":
+		return []observable{{TypeEmail, value}}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package ioc
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// stixObject is the subset of a STIX 2.1 indicator and identity that the store uses.
+type stixObject struct {
+	Type           string    `json:"type"`
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Pattern        string    `json:"pattern"`
+	PatternType    string    `json:"pattern_type"`
+	Confidence     *int      `json:"confidence"`
+	Created        time.Time `json:"created"`
+	Modified       time.Time `json:"modified"`
+	ValidFrom      time.Time `json:"valid_from"`
+	ValidUntil     time.Time `json:"valid_until"`
+	CreatedByRef   string    `json:"created_by_ref"`
+	Labels         []string  `json:"labels"`
+	IndicatorTypes []string  `json:"indicator_types"`
+	Revoked        bool      `json:"revoked"`
+}
+
+// decodeSTIX reads the indicators of a STIX 2.1 bundle. Their patterns may compare the observables the
+// store indexes with = (or ISSUBSET for networks) and join comparisons with OR; each comparison becomes
+// an indicator. Patterns using other operators, e.g. AND or FOLLOWEDBY, describe behaviour rather than
+// an observable and are reported as errors. Indicators in other pattern languages (Sigma, YARA, Snort)
+// and revoked ones are skipped. The identity named by created_by_ref is the indicators' source.
+func decodeSTIX(data []byte) ([]Indicator, error) {
+	var bundle struct {
+		Objects []stixObject `json:"objects"`
+	}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, err
+	}
+	identities := make(map[string]string)
+	for _, o := range bundle.Objects {
+		if o.Type == "identity" {
+			identities[o.ID] = o.Name
+		}
+	}
+	var indicators []Indicator
+	var errs []string
+	for _, o := range bundle.Objects {
+		if o.Type != "indicator" || o.Revoked || (o.PatternType != "" && o.PatternType != "stix") {
+			continue
+		}
+		observables, err := parsePattern(o.Pattern)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", o.ID, err))
+			continue
+		}
+		base := Indicator{
+			Confidence:  DefaultConfidence,
+			Source:      identities[o.CreatedByRef],
+			Seen:        firstSet(o.Modified, o.ValidFrom, o.Created),
+			Expires:     o.ValidUntil,
+			Description: o.Description,
+			Labels:      append(append([]string(nil), o.Labels...), o.IndicatorTypes...),
+		}
+		if base.Description == "" {
+			base.Description = o.Name
+		}
+		if o.Confidence != nil {
+			base.Confidence = min(max(*o.Confidence, 0), 100)
+		}
+		for _, obs := range observables {
+			ind := base
+			if ind.Type, ind.Value, err = Canonical(obs.t, obs.value); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", o.ID, err))
+				continue
+			}
+			indicators = append(indicators, ind)
+		}
+	}
+	if len(errs) > 0 {
+		return indicators, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return indicators, nil
+}
+
+type observable struct {
+	t     Type
+	value string
+}
+
+var (
+	// comparisonRe matches one comparison such as file:hashes.'SHA-256' = '...'.
+	comparisonRe = regexp.MustCompile(`([a-z0-9-]+):([A-Za-z0-9_.'-]+)\s*(=|ISSUBSET)\s*'((?:[^'\\]|\\.)*)'`)
+	// connectiveRe matches what may separate comparisons: brackets and OR.
+	connectiveRe = regexp.MustCompile(`^(?:[\s\[\]()]|\bOR\b)*$`)
+)
+
+func parsePattern(pattern string) ([]observable, error) {
+	matches := comparisonRe.FindAllStringSubmatchIndex(pattern, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("pattern %q compares no observable", pattern)
+	}
+	var out []observable
+	prev := 0
+	for _, m := range matches {
+		if !connectiveRe.MatchString(pattern[prev:m[0]]) {
+			return nil, fmt.Errorf("pattern %q: only OR may join comparisons", pattern)
+		}
+		prev = m[1]
+		object, path, op := pattern[m[2]:m[3]], pattern[m[4]:m[5]], pattern[m[6]:m[7]]
+		value := strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(pattern[m[8]:m[9]])
+		t, ok := stixType(object, path)
+		if !ok {
+			return nil, fmt.Errorf("pattern %q: %s:%s is not an indexed observable", pattern, object, path)
+		}
+		if op == "ISSUBSET" && t != TypeIP {
+			return nil, fmt.Errorf("pattern %q: ISSUBSET applies to addresses only", pattern)
+		}
+		out = append(out, observable{t: t, value: value})
+	}
+	if !connectiveRe.MatchString(pattern[prev:]) {
+		return nil, fmt.Errorf("pattern %q: only OR may join comparisons", pattern)
+	}
+	return out, nil
+}
+
+func stixType(object, path string) (Type, bool) {
+	switch {
+	case (object == "ipv4-addr" || object == "ipv6-addr") && path == "value":
+		return TypeIP, true // a network when the value has a prefix length
+	case object == "domain-name" && path == "value":
+		return TypeDomain, true
+	case object == "url" && path == "value":
+		return TypeURL, true
+	case object == "email-addr" && path == "value",
+		object == "email-message" && (path == "from_ref.value" || path == "sender_ref.value"):
+		return TypeEmail, true
+	case object == "file" && strings.HasPrefix(path, "hashes."):
+		return TypeHash, true
+	}
+	return "", false
+}
+
+func firstSet(times ...time.Time) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,203 @@
+package ioc
+
+import (
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Store indexes indicators for lookup by observed values: hash sets for addresses, URLs, hashes and
+// email addresses, a radix tree per address family for networks, and a set of domains walked label by
+// label so an indicator for a domain matches its subdomains. A Store is immutable once built, so
+// lookups need no locking.
+type Store struct {
+	exact      map[Type]map[string]*Indicator
+	v4, v6     radix
+	n          int
+	generation uint64 // set by the Watcher that publishes the store
+	loadedAt   time.Time
+}
+
+// NewStore indexes indicators, dropping those already expired at now. When a feed repeats an indicator,
+// or two feeds share one, the more confident entry is kept, then the one that expires last.
+func NewStore(indicators []Indicator, now time.Time) *Store {
+	s := &Store{exact: make(map[Type]map[string]*Indicator), loadedAt: now}
+	for _, v := range indicators {
+		ind := &v
+		if ind.Expired(now) {
+			continue
+		}
+		set := s.exact[ind.Type]
+		if set == nil {
+			set = make(map[string]*Indicator)
+			s.exact[ind.Type] = set
+		}
+		prev := set[ind.Value]
+		if prev != nil && !better(ind, prev) {
+			continue
+		}
+		set[ind.Value] = ind
+		if prev == nil {
+			s.n++
+		}
+		if ind.Type == TypeCIDR {
+			prefix := netip.MustParsePrefix(ind.Value)
+			if prefix.Addr().Is4() {
+				s.v4.insert(prefix, ind)
+			} else {
+				s.v6.insert(prefix, ind)
+			}
+		}
+	}
+	return s
+}
+
+func better(a, b *Indicator) bool {
+	if a.Confidence != b.Confidence {
+		return a.Confidence > b.Confidence
+	}
+	if a.Expires.IsZero() || b.Expires.IsZero() {
+		return a.Expires.IsZero() && !b.Expires.IsZero()
+	}
+	return a.Expires.After(b.Expires)
+}
+
+// Len returns the number of indicators indexed.
+func (s *Store) Len() int { return s.n }
+
+// Generation counts the stores a Watcher has published, starting at 1; 0 for one built directly.
+func (s *Store) Generation() uint64 { return s.generation }
+
+// LoadedAt is when the store was built.
+func (s *Store) LoadedAt() time.Time { return s.loadedAt }
+
+// Lookup returns the most specific live indicator matching value read as type t, or as the type Detect
+// guesses for TypeAny. An address is matched exactly, then by the narrowest network holding it; a URL
+// exactly, then by its host.
+func (s *Store) Lookup(t Type, value string) (Indicator, bool) {
+	if ind := s.lookup(t, strings.TrimSpace(value), time.Now()); ind != nil {
+		return *ind, true
+	}
+	return Indicator{}, false
+}
+
+func (s *Store) lookup(t Type, value string, now time.Time) *Indicator {
+	if s == nil || value == "" {
+		return nil
+	}
+	if t == TypeAny {
+		t = Detect(value)
+	}
+	switch t {
+	case TypeIP:
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil
+		}
+		return s.lookupAddr(addr.Unmap(), now)
+	case TypeDomain:
+		return s.lookupDomain(value, now)
+	case TypeURL:
+		t, canonical, err := Canonical(TypeURL, value)
+		if err != nil {
+			return nil
+		}
+		if ind := s.live(t, canonical, now); ind != nil {
+			return ind
+		}
+		u, _ := url.Parse(canonical)
+		if addr, err := netip.ParseAddr(strings.Trim(u.Hostname(), "[]")); err == nil {
+			return s.lookupAddr(addr.Unmap(), now)
+		}
+		return s.lookupDomain(u.Hostname(), now)
+	}
+	t, canonical, err := Canonical(t, value)
+	if err != nil {
+		return nil
+	}
+	return s.live(t, canonical, now)
+}
+
+func (s *Store) live(t Type, value string, now time.Time) *Indicator {
+	if ind := s.exact[t][value]; ind != nil && !ind.Expired(now) {
+		return ind
+	}
+	return nil
+}
+
+func (s *Store) lookupAddr(addr netip.Addr, now time.Time) *Indicator {
+	if ind := s.live(TypeIP, addr.String(), now); ind != nil {
+		return ind
+	}
+	if addr.Is4() {
+		return s.v4.lookup(addr, now)
+	}
+	return s.v6.lookup(addr, now)
+}
+
+// lookupDomain tries the domain itself, then each parent: www.evil.example, evil.example, example.
+func (s *Store) lookupDomain(domain string, now time.Time) *Indicator {
+	domains := s.exact[TypeDomain]
+	if len(domains) == 0 {
+		return nil
+	}
+	d := strings.TrimSuffix(strings.ToLower(domain), ".")
+	for d != "" {
+		if ind := domains[d]; ind != nil && !ind.Expired(now) {
+			return ind
+		}
+		dot := strings.IndexByte(d, '.')
+		if dot < 0 {
+			break
+		}
+		d = d[dot+1:]
+	}
+	return nil
+}
+
+// radix is a binary radix tree of networks, one address bit per level, answering longest-prefix matches
+// in at most 32 or 128 steps whatever the number of networks.
+type radix struct {
+	root radixNode
+}
+
+type radixNode struct {
+	child [2]*radixNode
+	ind   *Indicator
+}
+
+func (r *radix) insert(prefix netip.Prefix, ind *Indicator) {
+	bytes := prefix.Addr().AsSlice()
+	n := &r.root
+	for i := range prefix.Bits() {
+		b := bit(bytes, i)
+		if n.child[b] == nil {
+			n.child[b] = &radixNode{}
+		}
+		n = n.child[b]
+	}
+	n.ind = ind
+}
+
+// lookup returns the indicator of the narrowest live network holding addr. An expired network falls back
+// to the wider ones around it.
+func (r *radix) lookup(addr netip.Addr, now time.Time) *Indicator {
+	bytes := addr.AsSlice()
+	var found *Indicator
+	n := &r.root
+	for i := 0; n != nil; i++ {
+		if n.ind != nil && !n.ind.Expired(now) {
+			found = n.ind
+		}
+		if i == len(bytes)*8 {
+			break
+		}
+		n = n.child[bit(bytes, i)]
+	}
+	return found
+}
+
+func bit(bytes []byte, i int) int {
+	return int(bytes[i/8]>>(7-i%8)) & 1
+}
@@ -0,0 +1,141 @@
+package ioc
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/harishhary/blink/pkg/events"
+)
+
+// Hit is an indicator found in an event, as attached under events.IOCKey.
+type Hit struct {
+	Field     string
+	Value     string // as it appears in the event
+	Indicator Indicator
+}
+
+func (h Hit) toMap() map[string]any {
+	labels := make([]any, len(h.Indicator.Labels))
+	for i, l := range h.Indicator.Labels {
+		labels[i] = l
+	}
+	m := map[string]any{
+		"field":       h.Field,
+		"value":       h.Value,
+		"type":        string(h.Indicator.Type),
+		"indicator":   h.Indicator.Value,
+		"confidence":  float64(h.Indicator.Confidence), // as the number would read back from JSON
+		"source":      h.Indicator.Source,
+		"description": h.Indicator.Description,
+		"labels":      labels,
+	}
+	if !h.Indicator.Expires.IsZero() {
+		m["expires"] = h.Indicator.Expires.UTC().Format(time.RFC3339)
+	}
+	return m
+}
+
+// Find matches the values of fields in evt, and in its normalized view, against the store and returns
+// the live indicators they hit. An indicator hit by several fields is returned once, for the first.
+func (s *Store) Find(evt events.Event, fields []Field) []Hit {
+	if s == nil || s.n == 0 {
+		return nil
+	}
+	now := time.Now()
+	var out []Hit
+	seen := make(map[*Indicator]bool)
+	for _, field := range fields {
+		v, ok := evt.Lookup(field.Path)
+		if !ok {
+			continue
+		}
+		values, isList := v.([]any)
+		if !isList {
+			values = []any{v}
+		}
+		for _, value := range values {
+			str, ok := value.(string)
+			if !ok || str == "" {
+				continue
+			}
+			ind := s.lookup(field.Type, str, now)
+			if ind == nil || seen[ind] {
+				continue
+			}
+			seen[ind] = true
+			out = append(out, Hit{Field: field.Path, Value: str, Indicator: *ind})
+		}
+	}
+	return out
+}
+
+// Tag attaches the indicators found in evt under events.IOCKey and returns them. Whatever evt carried
+// under that key before is dropped first, so an event that hits no indicator leaves without any.
+func (s *Store) Tag(evt events.Event, fields []Field) []Hit {
+	delete(evt, events.IOCKey)
+	hits := s.Find(evt, fields)
+	if len(hits) == 0 {
+		return nil
+	}
+	attached := make([]any, len(hits))
+	for i, h := range hits {
+		attached[i] = h.toMap()
+	}
+	evt[events.IOCKey] = attached
+	return hits
+}
+
+// Attached reads back the indicator hits attached to evt by the event matcher.
+func Attached(evt events.Event) []Hit {
+	list, _ := evt[events.IOCKey].([]any)
+	out := make([]Hit, 0, len(list))
+	for _, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		h := Hit{
+			Field: str(m["field"]),
+			Value: str(m["value"]),
+			Indicator: Indicator{
+				Type:        Type(str(m["type"])),
+				Value:       str(m["indicator"]),
+				Confidence:  number(m["confidence"]),
+				Source:      str(m["source"]),
+				Description: str(m["description"]),
+			},
+		}
+		h.Indicator.Expires, _ = time.Parse(time.RFC3339, str(m["expires"]))
+		if ls, ok := m["labels"].([]any); ok {
+			for _, l := range ls {
+				h.Indicator.Labels = append(h.Indicator.Labels, str(l))
+			}
+		}
+		out = append(out, h)
+	}
+	return out
+}
+
+func str(v any) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+func number(v any) int {
+	switch n := v.(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	case string:
+		i, _ := strconv.Atoi(n)
+		return i
+	}
+	return 0
+}
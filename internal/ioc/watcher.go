@@ -0,0 +1,63 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/harishhary/blink/internal/dirwatch"
+	"github.com/harishhary/blink/pkg/events"
+)
+
+// Watcher keeps the Store for a Config current, reloading the feeds when a file in its directory changes.
+type Watcher struct {
+	*dirwatch.Watcher[Store]
+	cfg Config
+}
+
+// NewWatcher loads the feeds described by cfg. Files and entries that do not load are logged and skipped
+// until fixed; NewWatcher only fails when the feed directory cannot be read.
+func NewWatcher(cfg Config) (*Watcher, error) {
+	if cfg.compiled == nil {
+		if err := cfg.compile(); err != nil {
+			return nil, err
+		}
+	}
+	w, err := dirwatch.New("ioc-watcher", cfg.Dir, dirwatch.Loader[Store]{
+		Match: isFeed,
+		Load: func(dir string) (*Store, error) {
+			indicators, err := LoadDir(dir)
+			if indicators == nil {
+				return nil, err
+			}
+			return NewStore(cfg.prepare(indicators), time.Now()), err
+		},
+		Publish:  func(store *Store, gen uint64) { store.generation = gen },
+		Describe: func(store *Store) string { return fmt.Sprintf("%d indicator(s)", store.Len()) },
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{Watcher: w, cfg: cfg}, nil
+}
+
+// FieldsFor returns the fields to match in events of logType.
+func (w *Watcher) FieldsFor(logType string) []Field { return w.cfg.FieldsFor(logType) }
+
+// Tag attaches the indicators hit by the fields configured for logType, as Store.Tag does. A nil
+// Watcher, for when no feeds are configured, only drops the indicators evt claims to carry.
+func (w *Watcher) Tag(evt events.Event, logType string) []Hit {
+	if w == nil {
+		delete(evt, events.IOCKey)
+		return nil
+	}
+	return w.Current().Tag(evt, w.FieldsFor(logType))
+}
+
+// Ready reports an error until the feeds have been loaded.
+func (w *Watcher) Ready(context.Context) error {
+	if w.Current() == nil {
+		return fmt.Errorf("ioc feeds not loaded")
+	}
+	return nil
+}
@@ -0,0 +1,354 @@
+// Package normalize maps vendor events onto a common schema such as OCSF or ECS, so one rule can cover
+// every log type that reports the same activity. Each log type has a YAML mapping whose steps build the
+// normalized view from the event, in order:
+//
+//	log_type: "okta:system"
+//	passthrough: false   # true starts the view from a copy of the event's own fields
+//	steps:
+//	  - copy:     { from: actor.alternateId, to: user.name }
+//	  - rename:   { from: client.ipAddress, to: src_endpoint.ip }  # also drops from from a passthrough view
+//	  - cast:     { field: severity_id, type: integer }
+//	  - map:      { from: outcome.result, to: status, values: { SUCCESS: Success, FAILURE: Failure }, default: Other }
+//	  - set:      { to: category_name, value: "Identity & Access Management" }
+//	  - template: { to: actor.display, value: "{actor.displayName} <{actor.alternateId}>" }
+//	  - extract:  { from: actor.alternateId, to: user.domain, pattern: '@(?P<value>.+)$' }
+//
+// from paths and template placeholders read the original event in the events.Path syntax, so they may
+// index arrays ("records[0].ip"); to and field are plain dotted paths into the view. A step whose source value is missing is skipped, so one mapping can
+// serve events that omit optional fields.
+package normalize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/harishhary/blink/internal/classifier"
+	"github.com/harishhary/blink/pkg/events"
+	"go.yaml.in/yaml/v4"
+)
+
+// Transfer moves a value from the event into the view, for copy and rename.
+type Transfer struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// Cast converts a view field to a type, using the classifier's conversion rules.
+type Cast struct {
+	Field string               `yaml:"field"`
+	Type  classifier.FieldType `yaml:"type"`
+}
+
+// ValueMap translates an event value through a lookup table, keyed by its text form.
+type ValueMap struct {
+	From    string         `yaml:"from"`
+	To      string         `yaml:"to"`
+	Values  map[string]any `yaml:"values"`
+	Default any            `yaml:"default"`
+}
+
+// Set writes a constant.
+type Set struct {
+	To    string `yaml:"to"`
+	Value any    `yaml:"value"`
+}
+
+// Template writes a string built from event fields named in braces.
+type Template struct {
+	To    string `yaml:"to"`
+	Value string `yaml:"value"`
+}
+
+// Extract writes the "value" group (or the whole match) of a pattern applied to an event field.
+type Extract struct {
+	From    string `yaml:"from"`
+	To      string `yaml:"to"`
+	Pattern string `yaml:"pattern"`
+}
+
+// Step is one mapping step; exactly one of its fields is set.
+type Step struct {
+	Copy     *Transfer `yaml:"copy"`
+	Rename   *Transfer `yaml:"rename"`
+	Cast     *Cast     `yaml:"cast"`
+	Map      *ValueMap `yaml:"map"`
+	Set      *Set      `yaml:"set"`
+	Template *Template `yaml:"template"`
+	Extract  *Extract  `yaml:"extract"`
+}
+
+// Mapping is the normalization for one log type, loaded from a YAML file.
+type Mapping struct {
+	LogType     string `yaml:"log_type"`
+	Passthrough bool   `yaml:"passthrough"`
+	Steps       []Step `yaml:"steps"`
+
+	file string
+	ops  []op
+}
+
+// op applies one compiled step. It reads the original event and writes the view.
+type op func(evt, view events.Event) error
+
+// File is the path the mapping was loaded from.
+func (m *Mapping) File() string { return m.file }
+
+// Load reads and compiles one mapping file.
+func Load(path string) (*Mapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Mapping
+	if err := yaml.Load(data, &m, yaml.WithKnownFields()); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	m.file = path
+	if err := m.compile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return &m, nil
+}
+
+func (m *Mapping) compile() error {
+	if m.LogType == "" {
+		return fmt.Errorf("mapping has no log_type")
+	}
+	m.ops = make([]op, len(m.Steps))
+	for i, s := range m.Steps {
+		o, err := s.compile(m.Passthrough)
+		if err != nil {
+			return fmt.Errorf("mapping %s: step %d: %w", m.LogType, i+1, err)
+		}
+		m.ops[i] = o
+	}
+	return nil
+}
+
+var placeholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+func (s Step) compile(passthrough bool) (op, error) {
+	set := 0
+	for _, p := range []bool{s.Copy != nil, s.Rename != nil, s.Cast != nil, s.Map != nil, s.Set != nil, s.Template != nil, s.Extract != nil} {
+		if p {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("a step must have exactly one of copy, rename, cast, map, set, template or extract")
+	}
+
+	switch {
+	case s.Copy != nil || s.Rename != nil:
+		t, rename := s.Copy, false
+		if t == nil {
+			t, rename = s.Rename, true
+		}
+		if t.From == "" || t.To == "" {
+			return nil, fmt.Errorf("copy and rename need from and to")
+		}
+		from, err := events.CompilePath(t.From)
+		if err != nil {
+			return nil, err
+		}
+		return func(evt, view events.Event) error {
+			v, ok := from.Get(evt)
+			if !ok {
+				return nil
+			}
+			if rename && passthrough {
+				del(view, t.From)
+			}
+			return put(view, t.To, v)
+		}, nil
+
+	case s.Cast != nil:
+		c := s.Cast
+		if c.Field == "" || !c.Type.Valid() {
+			return nil, fmt.Errorf("cast needs a field and a valid type")
+		}
+		field, err := events.CompilePath(c.Field)
+		if err != nil {
+			return nil, err
+		}
+		return func(_, view events.Event) error {
+			v, ok := field.Get(view)
+			if !ok {
+				return nil
+			}
+			n, err := c.Type.Convert(v)
+			if err != nil {
+				del(view, c.Field) // a value of the wrong type would mislead rules more than a missing one
+				return fmt.Errorf("cast %s: %w", c.Field, err)
+			}
+			return put(view, c.Field, n)
+		}, nil
+
+	case s.Map != nil:
+		vm := s.Map
+		if vm.From == "" || vm.To == "" || len(vm.Values) == 0 {
+			return nil, fmt.Errorf("map needs from, to and values")
+		}
+		from, err := events.CompilePath(vm.From)
+		if err != nil {
+			return nil, err
+		}
+		return func(evt, view events.Event) error {
+			v, ok := from.Get(evt)
+			if !ok {
+				return nil
+			}
+			if mapped, ok := vm.Values[fmt.Sprint(v)]; ok {
+				return put(view, vm.To, mapped)
+			}
+			if vm.Default != nil {
+				return put(view, vm.To, vm.Default)
+			}
+			return nil
+		}, nil
+
+	case s.Set != nil:
+		if s.Set.To == "" || s.Set.Value == nil {
+			return nil, fmt.Errorf("set needs to and value")
+		}
+		to, value := s.Set.To, s.Set.Value
+		return func(_, view events.Event) error { return put(view, to, value) }, nil
+
+	case s.Template != nil:
+		t := s.Template
+		if t.To == "" || !placeholder.MatchString(t.Value) {
+			return nil, fmt.Errorf("template needs to and a value with at least one {field}")
+		}
+		fields := make(map[string]events.Path) // by placeholder, braces included
+		for _, m := range placeholder.FindAllStringSubmatch(t.Value, -1) {
+			p, err := events.CompilePath(m[1])
+			if err != nil {
+				return nil, err
+			}
+			fields[m[0]] = p
+		}
+		return func(evt, view events.Event) error {
+			missing := false
+			out := placeholder.ReplaceAllStringFunc(t.Value, func(p string) string {
+				v, ok := fields[p].Get(evt)
+				if !ok {
+					missing = true
+					return ""
+				}
+				return fmt.Sprint(v)
+			})
+			if missing {
+				return nil
+			}
+			return put(view, t.To, out)
+		}, nil
+
+	case s.Extract != nil:
+		x := s.Extract
+		if x.From == "" || x.To == "" || x.Pattern == "" {
+			return nil, fmt.Errorf("extract needs from, to and pattern")
+		}
+		from, err := events.CompilePath(x.From)
+		if err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(x.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		group := re.SubexpIndex("value")
+		return func(evt, view events.Event) error {
+			v, ok := from.Get(evt)
+			if !ok {
+				return nil
+			}
+			match := re.FindStringSubmatch(fmt.Sprint(v))
+			switch {
+			case match == nil:
+				return nil
+			case group >= 0:
+				return put(view, x.To, match[group])
+			}
+			return put(view, x.To, match[0])
+		}, nil
+	}
+	return nil, nil
+}
+
+// Apply builds the normalized view of evt. The view always carries the event's log_type. Steps that
+// fail are reported and leave their target unset; the rest of the view is still returned.
+func (m *Mapping) Apply(evt events.Event) (events.Event, []error) {
+	view := events.Event{}
+	if m.Passthrough {
+		for k, v := range evt {
+			if k != events.NormalizedKey && k != "_ingest" {
+				view[k] = deepCopy(v)
+			}
+		}
+	}
+	var errs []error
+	for _, o := range m.ops {
+		if err := o(evt, view); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	view["log_type"] = evt["log_type"]
+	return view, errs
+}
+
+// put writes v at path, creating intermediate objects. It fails if a prefix of path holds a scalar.
+func put(view events.Event, path string, v any) error {
+	keys := strings.Split(path, ".")
+	cur := map[string]any(view)
+	for _, k := range keys[:len(keys)-1] {
+		switch next := cur[k].(type) {
+		case map[string]any:
+			cur = next
+		case events.Event:
+			cur = next
+		case nil:
+			m := map[string]any{}
+			cur[k] = m
+			cur = m
+		default:
+			return fmt.Errorf("cannot set %s: %s is not an object", path, k)
+		}
+	}
+	cur[keys[len(keys)-1]] = v
+	return nil
+}
+
+func del(view events.Event, path string) {
+	keys := strings.Split(path, ".")
+	cur := map[string]any(view)
+	for _, k := range keys[:len(keys)-1] {
+		next, ok := cur[k].(map[string]any)
+		if !ok {
+			return
+		}
+		cur = next
+	}
+	delete(cur, keys[len(keys)-1])
+}
+
+// deepCopy copies the JSON-shaped containers in v so passthrough views never alias the original event.
+func deepCopy(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(x))
+		for k, e := range x {
+			m[k] = deepCopy(e)
+		}
+		return m
+	case []any:
+		a := make([]any, len(x))
+		for i, e := range x {
+			a[i] = deepCopy(e)
+		}
+		return a
+	}
+	return v
+}
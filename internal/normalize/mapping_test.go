@@ -0,0 +1,125 @@
+package normalize
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/harishhary/blink/pkg/events"
+)
+
+const oktaMapping = `
+log_type: "okta:system"
+steps:
+  - copy:     { from: actor.alternateId, to: user.name }
+  - rename:   { from: client.ipAddress, to: src_endpoint.ip }
+  - map:      { from: outcome.result, to: status, values: { SUCCESS: Success, FAILURE: Failure }, default: Other }
+  - set:      { to: category_uid, value: 3 }
+  - copy:     { from: severity, to: severity_id }
+  - cast:     { field: severity_id, type: integer }
+  - template: { to: actor.display, value: "{actor.displayName} <{actor.alternateId}>" }
+  - extract:  { from: actor.alternateId, to: user.domain, pattern: '@(?P<value>.+)$' }
+  - copy:     { from: not.there, to: ignored }
+`
+
+func loadMapping(t *testing.T, body string) *Mapping {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mapping.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestApply(t *testing.T) {
+	m := loadMapping(t, oktaMapping)
+	evt := events.Event{
+		"log_type": "okta:system",
+		"actor":    map[string]any{"alternateId": "alice@example.com", "displayName": "Alice"},
+		"client":   map[string]any{"ipAddress": "10.0.0.1"},
+		"outcome":  map[string]any{"result": "FAILURE"},
+		"severity": "4",
+	}
+	view, errs := m.Apply(evt)
+	if len(errs) != 0 {
+		t.Fatalf("Apply errors: %v", errs)
+	}
+	want := events.Event{
+		"log_type":     "okta:system",
+		"user":         map[string]any{"name": "alice@example.com", "domain": "example.com"},
+		"src_endpoint": map[string]any{"ip": "10.0.0.1"},
+		"status":       "Failure",
+		"category_uid": 3,
+		"severity_id":  int64(4),
+		"actor":        map[string]any{"display": "Alice <alice@example.com>"},
+	}
+	if !reflect.DeepEqual(view, want) {
+		t.Errorf("view:\n got  %v\n want %v", view, want)
+	}
+	if _, ok := evt["user"]; ok {
+		t.Error("Apply modified the original event")
+	}
+
+	// Rule keys resolve against the original event first, then the view.
+	evt[events.NormalizedKey] = map[string]any(view)
+	if v, ok := evt.Lookup("user.name"); !ok || v != "alice@example.com" {
+		t.Errorf("Lookup(user.name) = %v, %v", v, ok)
+	}
+	if v, ok := evt.Lookup("severity"); !ok || v != "4" {
+		t.Errorf("Lookup(severity) = %v, %v; want the original value", v, ok)
+	}
+	if got := evt.GetMergedKeys([]string{"src_endpoint.ip"}); got["src_endpoint.ip"] != "10.0.0.1" {
+		t.Errorf("GetMergedKeys = %v", got)
+	}
+}
+
+func TestPassthroughRenameAndFailedCast(t *testing.T) {
+	m := loadMapping(t, `
+log_type: "app"
+passthrough: true
+steps:
+  - rename: { from: src, to: source.ip }
+  - cast:   { field: port, type: integer }
+`)
+	view, errs := m.Apply(events.Event{"log_type": "app", "src": "10.0.0.1", "port": "https", "_ingest": map[string]any{}})
+	if len(errs) != 1 {
+		t.Fatalf("errs = %v, want the failed cast", errs)
+	}
+	want := events.Event{"log_type": "app", "source": map[string]any{"ip": "10.0.0.1"}}
+	if !reflect.DeepEqual(view, want) {
+		t.Errorf("view:\n got  %v\n want %v", view, want)
+	}
+}
+
+func TestStepNeedsOneOperation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.yaml")
+	body := "log_type: x\nsteps:\n  - copy: { from: a, to: b }\n    set: { to: c, value: 1 }\n"
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatal("step with two operations was accepted")
+	}
+}
+
+func TestBadPathFailsLoad(t *testing.T) {
+	for name, step := range map[string]string{
+		"copy":     "copy: { from: 'records[x].ip', to: ip }",
+		"template": "template: { to: who, value: '{actor..name}' }",
+		"cast":     "cast: { field: 'port.', type: integer }",
+	} {
+		path := filepath.Join(t.TempDir(), name+".yaml")
+		body := "log_type: x\nsteps:\n  - " + step + "\n"
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("%s step with a malformed path was accepted", name)
+		}
+	}
+}
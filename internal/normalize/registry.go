@@ -0,0 +1,65 @@
+package normalize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Registry is an immutable set of mappings keyed by log type.
+type Registry struct {
+	byLogType  map[string]*Mapping
+	loadedAt   time.Time
+	generation uint64
+}
+
+// NewRegistry loads every *.yaml / *.yml mapping in dir. It returns the mappings that loaded together
+// with an error listing the files that did not.
+func NewRegistry(dir string) (*Registry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("normalize: read dir %s: %w", dir, err)
+	}
+	reg := &Registry{byLogType: make(map[string]*Mapping), loadedAt: time.Now()}
+
+	var errs []string
+	for _, e := range entries {
+		if e.IsDir() || !isYAML(e.Name()) {
+			continue
+		}
+		m, err := Load(filepath.Join(dir, e.Name()))
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if prev, ok := reg.byLogType[m.LogType]; ok {
+			errs = append(errs, fmt.Sprintf("%s: log_type %s is already mapped by %s", e.Name(), m.LogType, filepath.Base(prev.file)))
+			continue
+		}
+		reg.byLogType[m.LogType] = m
+	}
+
+	if len(errs) > 0 {
+		return reg, fmt.Errorf("normalize: %d file(s) failed to load:\n  %s", len(errs), strings.Join(errs, "\n  "))
+	}
+	return reg, nil
+}
+
+func (r *Registry) Len() int { return len(r.byLogType) }
+
+// Get returns the mapping for a log type.
+func (r *Registry) Get(logType string) (*Mapping, bool) {
+	m, ok := r.byLogType[logType]
+	return m, ok
+}
+
+func (r *Registry) LoadedAt() time.Time { return r.loadedAt }
+
+// Generation counts the registries a Watcher has published, starting at 1; 0 for one built directly.
+func (r *Registry) Generation() uint64 { return r.generation }
+
+func isYAML(name string) bool {
+	return strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")
+}
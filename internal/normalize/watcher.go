@@ -0,0 +1,36 @@
+package normalize
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/harishhary/blink/internal/dirwatch"
+)
+
+// Watcher keeps the mapping Registry for a directory current.
+type Watcher struct {
+	*dirwatch.Watcher[Registry]
+}
+
+// NewWatcher loads dir. Mapping files that do not load are logged and skipped until fixed; their log
+// types pass through without a normalized view meanwhile.
+func NewWatcher(dir string) (*Watcher, error) {
+	w, err := dirwatch.New("mapping-watcher", dir, dirwatch.Loader[Registry]{
+		Match:    isYAML,
+		Load:     NewRegistry,
+		Publish:  func(reg *Registry, gen uint64) { reg.generation = gen },
+		Describe: func(reg *Registry) string { return fmt.Sprintf("%d mapping(s)", reg.Len()) },
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{w}, nil
+}
+
+// Ready reports an error until a registry has been loaded.
+func (w *Watcher) Ready(context.Context) error {
+	if w.Current() == nil {
+		return fmt.Errorf("mappings in %s not loaded", w.Dir())
+	}
+	return nil
+}
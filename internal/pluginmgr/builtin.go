@@ -0,0 +1,181 @@
+package pluginmgr
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/harishhary/blink/internal/pools"
+)
+
+// builtinPrefix marks the Path of an in-process plugin in PluginStatus and lifecycle events.
+const builtinPrefix = "builtin:"
+
+// Factory builds one in-process worker. It is called once per worker, up front for minProcs and
+// again whenever the pool grows towards maxProcs, so workers never share state unless the factory
+// hands out the same value.
+type Factory[T ISyncable] func() (T, error)
+
+// builtin is one in-process plugin registered with Register. Each Register call starts a new
+// generation; workers of a replaced generation are closed once the pool has drained them.
+type builtin[T ISyncable] struct {
+	id       string
+	name     string
+	checksum string
+	factory  Factory[T]
+	limits   func() (minProcs, maxProcs int)
+	gen      uint64
+	workers  atomic.Int64 // live workers of this generation
+}
+
+// Register serves in-process Go implementations of T from the same pool as the plugin binaries,
+// e.g. built-in plugins of a service that embeds Blink, or plugins under test. The first call for a
+// name sends a RegisterMessage; later calls send an UpdateMessage, so the pool drains (or canaries)
+// the previous generation exactly as it would for a changed binary. Workers implementing io.Closer
+// are closed when the pool retires them.
+//
+// In-process plugins are not supervised: there is no ping loop, restart or quarantine, and
+// reconciling the plugin directory never touches them. Register may be called before or after Start.
+func (m *PluginManager[T]) Register(factory Factory[T], minProcs, maxProcs int) error {
+	return m.RegisterWithLimits(factory, func() (int, int) { return minProcs, maxProcs })
+}
+
+// RegisterWithLimits is Register with worker bounds that can change: limits is called on every
+// autoscale tick, e.g. to read them from a config that reloads, so it must be cheap.
+func (m *PluginManager[T]) RegisterWithLimits(factory Factory[T], limits func() (minProcs, maxProcs int)) error {
+	minProcs, _ := pools.ClampLimits(limits())
+	items := make([]T, 0, minProcs)
+	for range minProcs {
+		item, err := factory()
+		if err != nil {
+			closeWorkers(items)
+			return fmt.Errorf("build in-process %s: %w", m.adapter.PluginKey(), err)
+		}
+		items = append(items, item)
+	}
+	b := &builtin[T]{
+		id:       pluginID(items[0]),
+		name:     items[0].Name(),
+		checksum: items[0].Checksum(),
+		factory:  factory,
+		limits:   limits,
+		gen:      m.gens.Add(1),
+	}
+	if b.id == "" {
+		closeWorkers(items)
+		return fmt.Errorf("in-process %s %q has no ID", m.adapter.PluginKey(), b.name)
+	}
+	b.workers.Store(int64(len(items)))
+	stops := make([]func(), len(items))
+	for i, item := range items {
+		stops[i] = b.stop(item)
+	}
+
+	m.mu.Lock()
+	prev := m.builtins[b.name]
+	m.builtins[b.name] = b
+	m.mu.Unlock()
+
+	scaler := &builtinScaler[T]{m: m, b: b}
+	if prev == nil {
+		m.notify(NewRegisterMessage[T](items, stops, scaler))
+		m.emit(b.event(EventStarted, ""))
+		m.log.Info("%s registered in-process: %s [%s] (%d worker(s))", m.adapter.PluginKey(), b.name, b.id, len(items))
+		return nil
+	}
+	// The pool stops the previous generation's workers through their Stop funcs once it drains,
+	// so unlike a binary update there are no subprocesses left for OnDrained to kill.
+	m.notify(NewUpdateMessage[T](items, stops, scaler, nil))
+	m.metrics.Updates.Inc()
+	m.emit(b.event(EventUpdated, "in-process"))
+	m.log.Info("%s updated in-process: %s [%s] (%d worker(s))", m.adapter.PluginKey(), b.name, b.id, len(items))
+	return nil
+}
+
+// Unregister removes the named in-process plugin from the pool, tombstoning its ID as a deleted
+// binary would, and reports whether it was registered.
+func (m *PluginManager[T]) Unregister(name string) bool {
+	m.mu.Lock()
+	b, ok := m.builtins[name]
+	delete(m.builtins, name)
+	m.mu.Unlock()
+	if !ok {
+		return false
+	}
+	m.notify(NewRemoveMessage[T](b.id))
+	m.emit(b.event(EventRemoved, "unregistered"))
+	m.log.Info("%s unregistered in-process: %s [%s]", m.adapter.PluginKey(), b.name, b.id)
+	return true
+}
+
+// builtinScaler is the pools.Scaler for one generation of an in-process plugin.
+type builtinScaler[T ISyncable] struct {
+	m *PluginManager[T]
+	b *builtin[T]
+}
+
+func (s *builtinScaler[T]) Limits() (int, int) {
+	return pools.ClampLimits(s.b.limits())
+}
+
+// Grow builds one more worker. Like workerScaler.Grow it refuses once the generation has been replaced.
+func (s *builtinScaler[T]) Grow() (pools.Worker[T], error) {
+	s.m.mu.RLock()
+	current := s.m.builtins[s.b.name]
+	s.m.mu.RUnlock()
+	if current != s.b {
+		return pools.Worker[T]{}, fmt.Errorf("in-process %s generation %d is no longer active", s.b.name, s.b.gen)
+	}
+	item, err := s.b.factory()
+	if err != nil {
+		return pools.Worker[T]{}, err
+	}
+	s.b.workers.Add(1)
+	return pools.Worker[T]{Plugin: item, Stop: s.b.stop(item)}, nil
+}
+
+// stop returns the Stop func for one worker.
+func (b *builtin[T]) stop(item T) func() {
+	var once atomic.Bool
+	return func() {
+		if once.Swap(true) {
+			return
+		}
+		b.workers.Add(-1)
+		closeWorkers([]T{item})
+	}
+}
+
+func (b *builtin[T]) event(kind EventKind, reason string) Event {
+	return Event{Kind: kind, ID: b.id, Name: b.name, Path: builtinPrefix + b.name, Hash: b.checksum, Reason: reason}
+}
+
+func (b *builtin[T]) status(pluginKey string) PluginStatus {
+	n := int(b.workers.Load())
+	return PluginStatus{
+		Type:    pluginKey,
+		ID:      b.id,
+		Name:    b.name,
+		Path:    builtinPrefix + b.name,
+		Hash:    b.checksum,
+		Workers: n,
+		Healthy: n,
+	}
+}
+
+// pluginID returns the ID the pools key item by. Every plugin type has an Id method, but ISyncable
+// does not require one.
+func pluginID[T ISyncable](item T) string {
+	if v, ok := any(item).(interface{ Id() string }); ok {
+		return v.Id()
+	}
+	return ""
+}
+
+func closeWorkers[T ISyncable](items []T) {
+	for _, item := range items {
+		if c, ok := any(item).(io.Closer); ok {
+			_ = c.Close()
+		}
+	}
+}
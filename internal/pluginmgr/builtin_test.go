@@ -0,0 +1,137 @@
+package pluginmgr
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/messaging"
+	"github.com/harishhary/blink/internal/pools"
+)
+
+var builtinTestMetrics = NewPluginManagerMetrics("_test_builtin")
+
+type inProcessPlugin struct {
+	version string
+	closed  *atomic.Int64
+}
+
+func (p *inProcessPlugin) Id() string          { return "in-process-id" }
+func (p *inProcessPlugin) Name() string        { return "in-process" }
+func (p *inProcessPlugin) Description() string { return "" }
+func (p *inProcessPlugin) Enabled() bool       { return true }
+func (p *inProcessPlugin) Checksum() string    { return p.version }
+func (p *inProcessPlugin) Close() error        { p.closed.Add(1); return nil }
+
+type inProcessAdapter struct{ stubAdapter }
+
+func (inProcessAdapter) Handshake(context.Context, interface{}, int, string, string, ResolvedConfig) (*inProcessPlugin, PluginLifecycle, string, string, error) {
+	panic("in-process plugins never reach the handshake")
+}
+
+func TestRegisterInProcess(t *testing.T) {
+	pp := pools.NewProcessPool[*inProcessPlugin](pools.NewRoutingTable().Config(), nil, nil, time.Second)
+	sync := func(msg messaging.Message) {
+		switch m := msg.(type) {
+		case RegisterMessage[*inProcessPlugin]:
+			pp.Register(pools.PoolKey{PluginID: m.Items[0].Id(), Version: m.Items[0].Checksum()}, m.Workers(), m.Scaler, nil, nil)
+		case UpdateMessage[*inProcessPlugin]:
+			pp.Register(pools.PoolKey{PluginID: m.Items[0].Id(), Version: m.Items[0].Checksum()}, m.Workers(), m.Scaler, m.OnDrained, m.OnRolledBack)
+		case RemoveMessage[*inProcessPlugin]:
+			pp.Remove(m.ItemID)
+		}
+	}
+	m := NewPluginManager[*inProcessPlugin](logger.New("pluginmgr-test", "dev"), sync, "", inProcessAdapter{}, builtinTestMetrics)
+	sink := &recordingSink{}
+	m.SetEventSink(sink)
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var v1Closed, v2Closed atomic.Int64
+	factory := func(version string, closed *atomic.Int64) Factory[*inProcessPlugin] {
+		return func() (*inProcessPlugin, error) { return &inProcessPlugin{version: version, closed: closed}, nil }
+	}
+	if err := m.Register(factory("v1", &v1Closed), 2, 4); err != nil {
+		t.Fatal(err)
+	}
+	if st := m.Plugins(); len(st) != 1 || st[0].Path != "builtin:in-process" || st[0].Workers != 2 {
+		t.Fatalf("status = %+v, want one builtin entry with 2 workers", st)
+	}
+
+	version := func() string {
+		var v string
+		if err := pp.Call(context.Background(), "in-process-id", "", func(_ context.Context, p *inProcessPlugin) error {
+			v = p.version
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	if v := version(); v != "v1" {
+		t.Fatalf("served version %q, want v1", v)
+	}
+
+	// Re-registering replaces the generation; the pool closes the old workers once drained.
+	if err := m.Register(factory("v2", &v2Closed), 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if v := version(); v != "v2" {
+		t.Fatalf("served version %q after update, want v2", v)
+	}
+	waitFor(t, func() bool { return v1Closed.Load() == 2 })
+
+	if !m.Unregister("in-process") || m.Unregister("in-process") {
+		t.Fatal("Unregister did not report the registration exactly once")
+	}
+	waitFor(t, func() bool { return v2Closed.Load() == 1 })
+	if pp.Registered("in-process-id") || len(m.Plugins()) != 0 {
+		t.Fatalf("plugin still registered after Unregister: %+v", m.Plugins())
+	}
+	want := []EventKind{EventStarted, EventUpdated, EventRemoved}
+	if got := sink.kinds(); !slices.Equal(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+}
+
+func TestRegisterWithLimits(t *testing.T) {
+	var scaler pools.Scaler[*inProcessPlugin]
+	notify := func(msg messaging.Message) {
+		if m, ok := msg.(RegisterMessage[*inProcessPlugin]); ok {
+			scaler = m.Scaler
+		}
+	}
+	m := NewPluginManager[*inProcessPlugin](logger.New("pluginmgr-test", "dev"), notify, "", inProcessAdapter{}, builtinTestMetrics)
+	var closed atomic.Int64
+	var maxProcs atomic.Int64
+	maxProcs.Store(2)
+	factory := func() (*inProcessPlugin, error) { return &inProcessPlugin{version: "v1", closed: &closed}, nil }
+	if err := m.RegisterWithLimits(factory, func() (int, int) { return 1, int(maxProcs.Load()) }); err != nil {
+		t.Fatal(err)
+	}
+	if scaler == nil {
+		t.Fatal("no RegisterMessage sent")
+	}
+	if lo, hi := scaler.Limits(); lo != 1 || hi != 2 {
+		t.Fatalf("limits = %d, %d, want 1, 2", lo, hi)
+	}
+	maxProcs.Store(4)
+	if lo, hi := scaler.Limits(); lo != 1 || hi != 4 {
+		t.Fatalf("limits after the bound changed = %d, %d, want 1, 4", lo, hi)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 5s")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,204 @@
+package pluginmgr
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/harishhary/blink/internal/pools"
+	"github.com/harishhary/blink/internal/secrets"
+	"go.yaml.in/yaml/v4"
+)
+
+// defaultSecretRotation is how long resolved secrets are reused before a reconcile resolves them again.
+const defaultSecretRotation = 5 * time.Minute
+
+// resolvedEntry caches the resolution of one binary's sidecar config.
+type resolvedEntry struct {
+	declared   string // PluginConfig.Checksum() it was resolved from
+	cfg        ResolvedConfig
+	resolvedAt time.Time
+}
+
+// SetSecretRotation sets how long a binary's resolved secrets are reused (5m if ≤ 0). Reconciles resolve
+// secrets again only when the sidecar changed or this interval passed; a restart always does. Call it
+// before Start.
+func (m *PluginManager[T]) SetSecretRotation(interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultSecretRotation
+	}
+	m.secretRotation = interval
+}
+
+// SetRouting makes the manager publish the queue policy each binary's sidecar declares to table, over
+// the stage's defaults, so the pool that reads table queues and sheds calls to that plugin accordingly.
+// Call it before Start.
+func (m *PluginManager[T]) SetRouting(table *pools.RoutingTable, defaults pools.QueuePolicy) {
+	m.routing = table
+	m.queueDefaults = defaults
+}
+
+// route publishes the queue policy declared for the binary at path under the ID of its running
+// plugin. It does nothing without a routing table or while the binary is not running.
+func (m *PluginManager[T]) route(path string, declared PluginConfig) {
+	if m.routing == nil {
+		return
+	}
+	m.mu.RLock()
+	handles := m.plugin_handles[path]
+	m.mu.RUnlock()
+	if len(handles) == 0 {
+		return
+	}
+	queue, err := declared.Queue(m.queueDefaults)
+	if err != nil {
+		m.log.ErrorF("config %s: %v", path, err)
+		return
+	}
+	m.routing.Set(handles[0].ID, pools.PluginRouting{Queue: &queue})
+}
+
+// resolveConfig loads the binary's sidecar config from the adapter and resolves its secret references.
+// The last resolution is reused while the sidecar is unchanged and its secrets are younger than the
+// rotation interval, so a reconcile does not call every secret provider for every binary; force skips
+// the cache. The declared config is returned as well.
+func (m *PluginManager[T]) resolveConfig(path string, force bool) (PluginConfig, ResolvedConfig, error) {
+	declared, err := m.adapter.Config(path)
+	if err != nil {
+		return PluginConfig{}, ResolvedConfig{}, err
+	}
+	sum := declared.Checksum()
+	m.mu.RLock()
+	cached, ok := m.resolved[path]
+	m.mu.RUnlock()
+	fresh := len(declared.Secrets) == 0 || time.Since(cached.resolvedAt) < m.secretRotation
+	if ok && !force && cached.declared == sum && fresh {
+		return declared, cached.cfg, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cfg, err := declared.Resolve(ctx)
+	if err != nil {
+		return PluginConfig{}, ResolvedConfig{}, err
+	}
+	m.mu.Lock()
+	m.resolved[path] = resolvedEntry{declared: sum, cfg: cfg, resolvedAt: time.Now()}
+	m.mu.Unlock()
+	return declared, cfg, nil
+}
+
+// PluginConfig is the per-plugin configuration declared in a sidecar: free-form params plus secret
+// references, and how calls to the plugin wait for a free worker.
+type PluginConfig struct {
+	Params  map[string]string      `yaml:"params"`
+	Secrets map[string]secrets.Ref `yaml:"secrets"`
+
+	// Queueing and load shedding, as for rules; an unset field keeps the stage's default.
+	MaxQueue       int    `yaml:"max_queue"`
+	AcquireTimeout string `yaml:"acquire_timeout"`
+	ShedPolicy     string `yaml:"shed_policy"`
+}
+
+// Queue returns defaults overridden by the queueing fields c sets.
+func (c PluginConfig) Queue(defaults pools.QueuePolicy) (pools.QueuePolicy, error) {
+	queue := defaults
+	if c.MaxQueue < 0 {
+		return queue, fmt.Errorf("max_queue: must not be negative")
+	}
+	if c.MaxQueue > 0 {
+		queue.MaxQueue = c.MaxQueue
+	}
+	if c.AcquireTimeout != "" {
+		d, err := time.ParseDuration(c.AcquireTimeout)
+		if err != nil {
+			return queue, fmt.Errorf("acquire_timeout: %w", err)
+		}
+		queue.AcquireTimeout = d
+	}
+	if c.ShedPolicy != "" {
+		if err := queue.Shed.UnmarshalText([]byte(c.ShedPolicy)); err != nil {
+			return queue, fmt.Errorf("shed_policy: %w", err)
+		}
+	}
+	return queue, nil
+}
+
+// ResolvedConfig is a PluginConfig with every secret reference replaced by its value.
+// It is what the host sends in the Init RPC.
+type ResolvedConfig struct {
+	Params  map[string]string
+	Secrets map[string]string `obfuscate:"true"`
+}
+
+// Resolve looks up every secret reference in c.
+func (c PluginConfig) Resolve(ctx context.Context) (ResolvedConfig, error) {
+	vals, err := secrets.ResolveAll(ctx, c.Secrets)
+	if err != nil {
+		return ResolvedConfig{}, err
+	}
+	return ResolvedConfig{Params: c.Params, Secrets: vals}, nil
+}
+
+// Checksum is a stable digest over params and secret references, not their values: it changes when the
+// sidecar does, so the manager knows to resolve the secrets again.
+func (c PluginConfig) Checksum() string {
+	refs := make(map[string]string, len(c.Secrets))
+	for k, ref := range c.Secrets {
+		refs[k] = ref.String()
+	}
+	return digest(c.Params, refs)
+}
+
+// Checksum is a stable digest over params and resolved secret values. A rotated secret changes the checksum
+// so the manager re-initialises the plugin, but the digest itself reveals nothing about the values.
+func (c ResolvedConfig) Checksum() string {
+	return digest(c.Params, c.Secrets)
+}
+
+func digest(params, secrets map[string]string) string {
+	if len(params) == 0 && len(secrets) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	write := func(prefix string, m map[string]string) {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(h, "%s\x00%s\x00%s\x00", prefix, k, m[k])
+		}
+	}
+	write("p", params)
+	write("s", secrets)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// LoadSidecarConfig reads the optional <binPath>.yaml (or .yml) sidecar that carries params and secrets for plugin
+// types whose metadata comes from GetMetadata rather than a YAML registry. A missing sidecar yields an empty config.
+func LoadSidecarConfig(binPath string) (PluginConfig, error) {
+	for _, ext := range []string{".yaml", ".yml"} {
+		data, err := os.ReadFile(binPath + ext)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return PluginConfig{}, fmt.Errorf("sidecar: read %s%s: %w", binPath, ext, err)
+		}
+		var cfg PluginConfig
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return PluginConfig{}, fmt.Errorf("sidecar: parse %s%s: %w", binPath, ext, err)
+		}
+		if _, err := cfg.Queue(pools.QueuePolicy{}); err != nil {
+			return PluginConfig{}, fmt.Errorf("sidecar: validate %s%s: %w", binPath, ext, err)
+		}
+		return cfg, nil
+	}
+	return PluginConfig{}, nil
+}
@@ -0,0 +1,124 @@
+package pluginmgr
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/harishhary/blink/internal/messaging"
+	"github.com/harishhary/blink/internal/pools"
+	"github.com/harishhary/blink/internal/secrets"
+)
+
+func TestSidecarQueue(t *testing.T) {
+	defaults := pools.QueuePolicy{MaxQueue: 8, Shed: pools.ShedDeadLetter}
+	got, err := PluginConfig{AcquireTimeout: "250ms"}.Queue(defaults)
+	if err != nil || got != (pools.QueuePolicy{MaxQueue: 8, AcquireTimeout: 250 * time.Millisecond, Shed: pools.ShedDeadLetter}) {
+		t.Errorf("Queue = %+v, %v; want the timeout over the defaults", got, err)
+	}
+	got, err = PluginConfig{MaxQueue: 2, ShedPolicy: "pass-through"}.Queue(defaults)
+	if err != nil || got != (pools.QueuePolicy{MaxQueue: 2, Shed: pools.ShedPassThrough}) {
+		t.Errorf("Queue = %+v, %v; want max_queue and shed_policy overridden", got, err)
+	}
+	for _, bad := range []PluginConfig{{MaxQueue: -1}, {AcquireTimeout: "soon"}, {ShedPolicy: "drop"}} {
+		if _, err := bad.Queue(defaults); err == nil {
+			t.Errorf("Queue(%+v) accepted", bad)
+		}
+	}
+}
+
+func TestResolveConfigCache(t *testing.T) {
+	var calls atomic.Int64
+	secrets.Register("pluginmgr-test-counting", secrets.ProviderFunc(func(_ context.Context, key string) (string, error) {
+		calls.Add(1)
+		return "value-of-" + key, nil
+	}))
+	adapter := &helperAdapter{cfg: PluginConfig{
+		Params:  map[string]string{"threshold": "1"},
+		Secrets: map[string]secrets.Ref{"token": {Provider: "pluginmgr-test-counting", Key: "k"}},
+	}}
+	m, _ := newHelperManager[stubPlugin](t, t.TempDir(), adapter)
+	path := filepath.Join(m.dir, "helper")
+
+	resolve := func(force bool) ResolvedConfig {
+		t.Helper()
+		_, cfg, err := m.resolveConfig(path, force)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+	for range 3 {
+		resolve(false)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("provider called %d times by unchanged reconciles, want once", n)
+	}
+
+	adapter.setConfig(PluginConfig{
+		Params:  map[string]string{"threshold": "2"},
+		Secrets: map[string]secrets.Ref{"token": {Provider: "pluginmgr-test-counting", Key: "k"}},
+	})
+	if cfg := resolve(false); cfg.Params["threshold"] != "2" || calls.Load() != 2 {
+		t.Fatalf("after a sidecar change: params %v, %d provider calls; want the new params resolved", cfg.Params, calls.Load())
+	}
+	if resolve(true); calls.Load() != 3 {
+		t.Fatalf("a forced resolve (restart) made %d provider calls in all, want 3", calls.Load())
+	}
+
+	m.SetSecretRotation(time.Nanosecond)
+	resolve(false)
+	if n := calls.Load(); n != 4 {
+		t.Fatalf("provider called %d times once the rotation interval passed, want 4", n)
+	}
+}
+
+func TestParamsChangeReinitialises(t *testing.T) {
+	dir := t.TempDir()
+	installHelper(t, dir, "helper", "1")
+	adapter := &helperAdapter{cfg: PluginConfig{Params: map[string]string{"threshold": "1"}}}
+	m, messages := newHelperManager[stubPlugin](t, dir, adapter)
+
+	if err := m.reconcile("test"); err != nil {
+		t.Fatal(err)
+	}
+	before := m.Plugins()
+	if len(before) != 1 || before[0].Workers != 1 || adapter.lastInit().Params["threshold"] != "1" {
+		t.Fatalf("status %+v, last init %+v; want one running plugin initialised with threshold 1", before, adapter.lastInit())
+	}
+
+	adapter.setConfig(PluginConfig{Params: map[string]string{"threshold": "2"}})
+	if err := m.reconcile("test"); err != nil {
+		t.Fatal(err)
+	}
+	after := m.Plugins()
+	if len(after) != 1 || after[0].ConfigHash == before[0].ConfigHash || after[0].Hash != before[0].Hash {
+		t.Fatalf("status %+v after a params change, want the same binary under a new config hash", after)
+	}
+	if got := adapter.lastInit().Params["threshold"]; got != "2" {
+		t.Fatalf("new workers initialised with threshold %q, want 2", got)
+	}
+	var kinds []string
+	for _, msg := range messages() {
+		switch msg.(type) {
+		case RegisterMessage[stubPlugin]:
+			kinds = append(kinds, "register")
+		case UpdateMessage[stubPlugin]:
+			kinds = append(kinds, "update")
+		case messaging.Message:
+			kinds = append(kinds, "other")
+		}
+	}
+	if len(kinds) != 2 || kinds[0] != "register" || kinds[1] != "update" {
+		t.Fatalf("messages %v, want the plugin registered then rolled over by an update", kinds)
+	}
+
+	if err := m.reconcile("test"); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(messages()); n != 2 {
+		t.Fatalf("%d messages after an unchanged reconcile, want no further rollover", n)
+	}
+}
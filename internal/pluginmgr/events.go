@@ -0,0 +1,101 @@
+package pluginmgr
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/harishhary/blink/internal/broker"
+	"github.com/harishhary/blink/internal/logger"
+)
+
+// EventKind names a plugin lifecycle transition.
+type EventKind string
+
+const (
+	EventStarted     EventKind = "started"
+	EventUpdated     EventKind = "updated"
+	EventStopped     EventKind = "stopped" // disabled by config, or taken down for a restart
+	EventCrashed     EventKind = "crashed" // failed a health check or failed to start
+	EventQuarantined EventKind = "quarantined"
+	EventReleased    EventKind = "released" // quarantine lifted by a new revision or an operator
+	EventRemoved     EventKind = "removed"
+	EventRolledBack  EventKind = "rolled_back" // a staged canary or shadow update discarded by an operator
+)
+
+// Event is one plugin lifecycle transition, as published on the plugin status topic.
+type Event struct {
+	Time       time.Time `json:"time"`
+	Kind       EventKind `json:"event"`
+	Host       string    `json:"host"`
+	Type       string    `json:"type"`
+	ID         string    `json:"id,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	Path       string    `json:"path"`
+	Hash       string    `json:"hash,omitempty"`
+	ConfigHash string    `json:"config_hash,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	Failures   int       `json:"failures,omitempty"` // failures inside the crash-loop window
+}
+
+// EventSink receives every Event a manager emits. Publish is called with the manager's state already
+// updated and must not block.
+type EventSink interface {
+	Publish(e Event)
+}
+
+var hostname, _ = os.Hostname()
+
+var droppedEvents = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "blink", Subsystem: "plugin_events", Name: "dropped_total",
+	Help: "Plugin status events dropped because the publish buffer was full.",
+})
+
+// BrokerSink publishes Events as JSON to a broker topic, keyed by plugin type and path so that one
+// plugin's events stay in order. Events are buffered and written by Run; when the buffer is full
+// they are dropped and counted rather than holding up the manager.
+type BrokerSink struct {
+	w   broker.Writer
+	log *logger.Logger
+	ch  chan Event
+}
+
+// Creates a BrokerSink writing to w. Call Run to start publishing.
+func NewBrokerSink(w broker.Writer, log *logger.Logger) *BrokerSink {
+	return &BrokerSink{w: w, log: log, ch: make(chan Event, 256)}
+}
+
+func (s *BrokerSink) Publish(e Event) {
+	select {
+	case s.ch <- e:
+	default:
+		droppedEvents.Inc()
+	}
+}
+
+// Run writes buffered events until ctx is cancelled, then closes the writer.
+func (s *BrokerSink) Run(ctx context.Context) {
+	defer s.w.Close()
+	for {
+		select {
+		case e := <-s.ch:
+			value, err := json.Marshal(e)
+			if err != nil {
+				s.log.ErrorF("plugin event: %v", err)
+				continue
+			}
+			wctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			err = s.w.WriteMessages(wctx, broker.Message{Key: []byte(e.Type + ":" + e.Path), Value: value})
+			cancel()
+			if err != nil {
+				s.log.ErrorF("publish plugin %s event for %s: %v", e.Kind, e.Path, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
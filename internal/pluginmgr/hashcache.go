@@ -0,0 +1,67 @@
+package pluginmgr
+
+import (
+	"os"
+	"sync"
+
+	"github.com/harishhary/blink/internal/helpers"
+)
+
+// hashConcurrency bounds how many binaries a reconcile hashes at once.
+const hashConcurrency = 4
+
+// fileStamp is the metadata a binary is re-hashed on. Replacing a file (including swapping an
+// artifact symlink) changes the inode; rewriting it in place changes size, mtime or ctime.
+type fileStamp struct {
+	dev, ino     uint64
+	size         int64
+	mtime, ctime int64 // nanoseconds
+}
+
+type cachedHash struct {
+	stamp fileStamp
+	sum   string
+}
+
+// hashCache remembers binary checksums keyed by path, valid while the file's stamp is unchanged,
+// so a reconcile only reads the binaries that actually changed.
+type hashCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedHash
+}
+
+func newHashCache() *hashCache {
+	return &hashCache{entries: make(map[string]cachedHash)}
+}
+
+// sum returns path's SHA-256, from the cache when info's stamp matches the one it was hashed at.
+// It also reports whether the file had to be read.
+func (c *hashCache) sum(path string, info os.FileInfo) (string, bool, error) {
+	st := stampOf(info)
+	c.mu.Lock()
+	e, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && e.stamp == st {
+		return e.sum, false, nil
+	}
+
+	h, err := helpers.BinaryChecksum(path)
+	if err != nil {
+		return "", true, err
+	}
+	c.mu.Lock()
+	c.entries[path] = cachedHash{stamp: st, sum: h}
+	c.mu.Unlock()
+	return h, true, nil
+}
+
+// retain drops the entries of binaries that are no longer in the directory.
+func (c *hashCache) retain(seen map[string]struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for path := range c.entries {
+		if _, ok := seen[path]; !ok {
+			delete(c.entries, path)
+		}
+	}
+}
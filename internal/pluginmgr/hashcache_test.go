@@ -0,0 +1,64 @@
+package pluginmgr
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestHashCacheRehashesOnStatChange(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rule")
+	write := func(name, content string, mtime time.Time) {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sum := func(c *hashCache) (string, bool) {
+		t.Helper()
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, hashed, err := c.sum(path, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h, hashed
+	}
+
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	write("rule", "v1", mtime)
+	c := newHashCache()
+	v1, hashed := sum(c)
+	if !hashed {
+		t.Fatal("first sum came from the cache")
+	}
+	if h, hashed := sum(c); hashed || h != v1 {
+		t.Fatalf("unchanged file: hashed=%v sum=%s, want cached %s", hashed, h, v1)
+	}
+
+	// Same size and mtime, new inode: an atomic replace must still be noticed. Only the platforms with a
+	// stamp_<GOOS>.go see inodes; elsewhere size and mtime are all the cache has.
+	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
+		write(".rule.tmp", "v2", mtime)
+		if err := os.Rename(filepath.Join(dir, ".rule.tmp"), path); err != nil {
+			t.Fatal(err)
+		}
+		if h, hashed := sum(c); !hashed || h == v1 {
+			t.Fatalf("replaced file: hashed=%v sum=%s, want a new hash", hashed, h)
+		}
+	}
+
+	c.retain(map[string]struct{}{})
+	if _, hashed := sum(c); !hashed {
+		t.Fatal("retain kept an entry for a binary that was not seen")
+	}
+}
@@ -0,0 +1,197 @@
+package pluginmgr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/messaging"
+)
+
+// helperEnv makes the test binary serve the helper plugin instead of running tests. Its value lists the
+// protocol versions to serve, e.g. "1,2".
+const helperEnv = "BLINK_PLUGINMGR_HELPER"
+
+func TestMain(m *testing.M) {
+	if versions := os.Getenv(helperEnv); versions != "" {
+		serveHelper(versions)
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func serveHelper(versions string) {
+	sets := make(map[int]plugin.PluginSet)
+	for _, v := range strings.Split(versions, ",") {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s=%s: %v\n", helperEnv, versions, err)
+			os.Exit(2)
+		}
+		sets[n] = plugin.PluginSet{"helper": helperPlugin{}}
+		if n == 2 {
+			sets[n] = plugin.PluginSet{"helper": helperV2Plugin{}}
+		}
+	}
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig:  plugin.HandshakeConfig{MagicCookieKey: "BLINK_PLUGIN", MagicCookieValue: "helper"},
+		VersionedPlugins: sets,
+		GRPCServer:       plugin.DefaultGRPCServer,
+	})
+}
+
+// helperPlugin serves nothing of its own: the host talks to the health service go-plugin registers.
+type helperPlugin struct{ plugin.NetRPCUnsupportedPlugin }
+
+func (helperPlugin) GRPCServer(*plugin.GRPCBroker, *grpc.Server) error { return nil }
+func (helperPlugin) GRPCClient(_ context.Context, _ *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return c, nil
+}
+
+// helperV2Plugin is protocol v2 of the helper. Its client stub has a type of its own, so a handshake
+// that took it for v1 would fail.
+type helperV2Plugin struct{ helperPlugin }
+
+type helperV2Client struct{ conn *grpc.ClientConn }
+
+func (helperV2Plugin) GRPCClient(_ context.Context, _ *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return helperV2Client{conn: c}, nil
+}
+
+// helperAdapter runs helper plugins with the sidecar config in cfg and records what each handshake sent.
+type helperAdapter struct {
+	stubAdapter
+	v2 bool // the host speaks protocol v2 as well as v1
+
+	mu         sync.Mutex
+	cfg        PluginConfig
+	inits      []ResolvedConfig
+	handshakes map[int]int // by protocol version
+}
+
+func (a *helperAdapter) PluginKey() string  { return "helper" }
+func (a *helperAdapter) MagicValue() string { return "helper" }
+func (a *helperAdapter) Protocols() map[int]plugin.Plugin {
+	if a.v2 {
+		return map[int]plugin.Plugin{1: helperPlugin{}, 2: helperV2Plugin{}}
+	}
+	return map[int]plugin.Plugin{1: helperPlugin{}}
+}
+
+func (a *helperAdapter) Handshake(_ context.Context, raw interface{}, protocol int, binPath string, _ string, cfg ResolvedConfig) (stubPlugin, PluginLifecycle, string, string, error) {
+	var conn *grpc.ClientConn
+	switch protocol {
+	case 1:
+		conn, _ = raw.(*grpc.ClientConn)
+	case 2:
+		if c, ok := raw.(helperV2Client); ok {
+			conn = c.conn
+		}
+	default:
+		return stubPlugin{}, nil, "", "", fmt.Errorf("unsupported plugin protocol v%d", protocol)
+	}
+	if conn == nil {
+		return stubPlugin{}, nil, "", "", fmt.Errorf("dispense: unexpected type %T for protocol v%d", raw, protocol)
+	}
+	a.mu.Lock()
+	a.inits = append(a.inits, cfg)
+	if a.handshakes == nil {
+		a.handshakes = make(map[int]int)
+	}
+	a.handshakes[protocol]++
+	a.mu.Unlock()
+	name := filepath.Base(binPath)
+	return stubPlugin{}, helperLifecycle{grpc_health_v1.NewHealthClient(conn)}, name + "-id", name, nil
+}
+
+func (a *helperAdapter) Config(string) (PluginConfig, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.cfg, nil
+}
+
+func (a *helperAdapter) setConfig(cfg PluginConfig) {
+	a.mu.Lock()
+	a.cfg = cfg
+	a.mu.Unlock()
+}
+
+func (a *helperAdapter) lastInit() ResolvedConfig {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.inits) == 0 {
+		return ResolvedConfig{}
+	}
+	return a.inits[len(a.inits)-1]
+}
+
+type helperLifecycle struct{ health grpc_health_v1.HealthClient }
+
+func (l helperLifecycle) Ping(ctx context.Context) error {
+	_, err := l.health.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: plugin.GRPCServiceName})
+	return err
+}
+
+func (helperLifecycle) Shutdown(context.Context) error { return nil }
+
+var helperTestMetrics = NewPluginManagerMetrics("_test_helper")
+
+// installHelper writes an executable named name into dir that starts the test binary as a helper
+// plugin serving versions.
+func installHelper(t *testing.T, dir, name, versions string) string {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	script := fmt.Sprintf("#!/bin/sh\n%s=%s exec %q \"$@\"\n", helperEnv, versions, exe)
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newHelperManager returns a manager over dir whose Register, Update and Remove messages are recorded.
+// Replaced workers are released at once, as a pool that drained them would. Every plugin left running
+// is killed when the test ends.
+func newHelperManager[T ISyncable](t *testing.T, dir string, adapter PluginAdapter[T]) (*PluginManager[T], func() []messaging.Message) {
+	t.Helper()
+	var mu sync.Mutex
+	var msgs []messaging.Message
+	notify := func(msg messaging.Message) {
+		if u, ok := msg.(UpdateMessage[T]); ok && u.OnDrained != nil {
+			u.OnDrained()
+		}
+		mu.Lock()
+		msgs = append(msgs, msg)
+		mu.Unlock()
+	}
+	m := NewPluginManager[T](logger.New("pluginmgr-test", "dev"), notify, dir, adapter, helperTestMetrics)
+	t.Cleanup(func() {
+		m.mu.RLock()
+		var handles []*PluginHandle
+		for _, hs := range m.plugin_handles {
+			handles = append(handles, hs...)
+		}
+		m.mu.RUnlock()
+		for _, h := range handles {
+			m.kill(h)
+		}
+	})
+	return m, func() []messaging.Message {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]messaging.Message(nil), msgs...)
+	}
+}
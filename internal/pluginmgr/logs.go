@@ -0,0 +1,174 @@
+package pluginmgr
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/harishhary/blink/internal/logger"
+)
+
+// Each plugin binary may log pluginLogBurst lines per pluginLogWindow across all of its workers;
+// the rest are dropped and reported as a count when the window rolls over.
+const (
+	pluginLogBurst  = 200
+	pluginLogWindow = time.Second
+)
+
+// clientLoggerName is the hclog name go-plugin's own messages carry; lines read from a plugin's
+// stderr are logged under clientLoggerName + "." + <binary name>.
+const clientLoggerName = "plugin"
+
+// logLimiter is a fixed-window line budget shared by the workers of one binary.
+type logLimiter struct {
+	mu      sync.Mutex
+	start   time.Time
+	used    int
+	dropped int
+}
+
+// allow reports whether a line may be logged now, and how many lines the previous window dropped
+// if this is the first line of a new one.
+func (l *logLimiter) allow(now time.Time) (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	dropped := 0
+	if now.Sub(l.start) >= pluginLogWindow {
+		l.start, l.used, dropped, l.dropped = now, 0, l.dropped, 0
+	}
+	if l.used >= pluginLogBurst {
+		l.dropped++
+		return false, 0
+	}
+	l.used++
+	return true, dropped
+}
+
+// pluginLog turns one worker's output into service log lines tagged with the plugin's identity.
+// It is the hclog sink behind go-plugin's client logger, which carries the worker's stderr
+// (including pluginlog's JSON records), and the writer behind the forwarded stdout and stderr.
+type pluginLog struct {
+	log     *logger.Logger
+	metrics *PluginManagerMetrics
+	key     string
+	limiter *logLimiter
+
+	mu   sync.RWMutex
+	tags string
+}
+
+func newPluginLog(log *logger.Logger, metrics *PluginManagerMetrics, key, path string, worker int, limiter *logLimiter) *pluginLog {
+	pl := &pluginLog{log: log, metrics: metrics, key: key, limiter: limiter}
+	pl.identify("", filepath.Base(path), "", worker)
+	return pl
+}
+
+// identify sets the tags once the handshake has returned the plugin's ID, name and version.
+// Lines logged while Init runs carry the binary name instead.
+func (pl *pluginLog) identify(id, name, version string, worker int) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "plugin=%s name=%q", pl.key, name)
+	if id != "" {
+		fmt.Fprintf(&b, " id=%s", id)
+	}
+	if version != "" {
+		fmt.Fprintf(&b, " version=%s", version)
+	}
+	fmt.Fprintf(&b, " worker=%d", worker)
+	pl.mu.Lock()
+	pl.tags = b.String()
+	pl.mu.Unlock()
+}
+
+// clientLogger returns the hclog.Logger for plugin.ClientConfig.Logger. Its own output is discarded:
+// every record reaches the service log through Accept.
+func (pl *pluginLog) clientLogger() hclog.Logger {
+	l := hclog.NewInterceptLogger(&hclog.LoggerOptions{Name: clientLoggerName, Level: hclog.Trace, Output: io.Discard})
+	l.RegisterSink(pl)
+	return l
+}
+
+// Accept implements hclog.SinkAdapter.
+func (pl *pluginLog) Accept(name string, level hclog.Level, msg string, args ...any) {
+	if name == clientLoggerName && level < hclog.Warn {
+		return // go-plugin's own start/exit chatter; the manager logs lifecycle itself
+	}
+	pl.write(level, msg, args)
+}
+
+// stream returns a line writer for the worker's forwarded stdout or stderr.
+func (pl *pluginLog) stream(name string, level hclog.Level) io.Writer {
+	return &lineWriter{emit: func(line string) { pl.write(level, line, []any{"stream", name}) }}
+}
+
+func (pl *pluginLog) write(level hclog.Level, msg string, args []any) {
+	ok, dropped := pl.limiter.allow(time.Now())
+	pl.mu.RLock()
+	tags := pl.tags
+	pl.mu.RUnlock()
+	if dropped > 0 {
+		pl.metrics.LogLinesDropped.Add(float64(dropped))
+		pl.log.ErrorF("[%s] %d log line(s) dropped by the rate limit", tags, dropped)
+	}
+	if !ok {
+		return
+	}
+
+	// go-plugin flattens JSON records from a map, so fields arrive in random order, plus a
+	// timestamp the service log already has.
+	fields := make([]string, 0, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		if k := fmt.Sprint(args[i]); k != "timestamp" {
+			fields = append(fields, fmt.Sprintf("%s=%v", k, args[i+1]))
+		}
+	}
+	slices.Sort(fields)
+	var b strings.Builder
+	b.WriteString(msg)
+	for _, f := range fields {
+		b.WriteString(" " + f)
+	}
+	switch {
+	case level >= hclog.Warn:
+		pl.log.ErrorF("[%s] %s: %s", tags, strings.ToLower(level.String()), b.String())
+	case level >= hclog.Info:
+		pl.log.Info("[%s] %s", tags, b.String())
+	default:
+		pl.log.Debug("[%s] %s", tags, b.String())
+	}
+}
+
+// lineWriter splits writes into lines. Lines longer than 64 KiB are emitted in pieces.
+type lineWriter struct {
+	mu   sync.Mutex
+	buf  []byte
+	emit func(line string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if line := strings.TrimRight(string(w.buf[:i]), "\r"); line != "" {
+			w.emit(line)
+		}
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) >= 64<<10 {
+		w.emit(string(w.buf))
+		w.buf = nil
+	}
+	return len(p), nil
+}
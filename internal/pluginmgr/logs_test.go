@@ -0,0 +1,37 @@
+package pluginmgr
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLineWriterSplitsWrites(t *testing.T) {
+	var lines []string
+	w := &lineWriter{emit: func(line string) { lines = append(lines, line) }}
+	for _, chunk := range []string{"first li", "ne\r\nsecond\n\nthi", "rd\n", "partial"} {
+		fmt.Fprint(w, chunk)
+	}
+	if want := []string{"first line", "second", "third"}; !slices.Equal(lines, want) {
+		t.Fatalf("lines = %q, want %q", lines, want)
+	}
+}
+
+func TestLogLimiterReportsDrops(t *testing.T) {
+	var l logLimiter
+	now := time.Now()
+	for i := range pluginLogBurst {
+		if ok, _ := l.allow(now); !ok {
+			t.Fatalf("line %d refused inside the budget", i)
+		}
+	}
+	for range 3 {
+		if ok, _ := l.allow(now); ok {
+			t.Fatal("line allowed past the budget")
+		}
+	}
+	if ok, dropped := l.allow(now.Add(pluginLogWindow)); !ok || dropped != 3 {
+		t.Fatalf("next window: ok=%v dropped=%d, want true 3", ok, dropped)
+	}
+}
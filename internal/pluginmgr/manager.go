@@ -6,21 +6,54 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/hashicorp/go-hclog"
 	plugin "github.com/hashicorp/go-plugin"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 
 	"github.com/harishhary/blink/internal/errors"
-	"github.com/harishhary/blink/internal/helpers"
 	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/pools"
+	"github.com/harishhary/blink/pkg/plugindebug"
 )
 
-// Plugin is implemented by every plugin Manager - it can be started.
+// Plugin is implemented by every plugin Manager - it can be started, inspected and told to rescan its directory.
 type Plugin interface {
 	Start(ctx context.Context) error
+	// Plugins returns a point-in-time view of the plugin binaries the manager knows about.
+	Plugins() []PluginStatus
+	// Reconcile rescans the plugin directory immediately instead of waiting for the next event or poll.
+	Reconcile() error
+	// SetSource makes the manager fill its directory from src every interval. Call it before Start.
+	SetSource(src Source, interval time.Duration)
+	// Ready reports an error until the initial reconcile has completed.
+	Ready(ctx context.Context) error
+	// SetEventSink makes the manager publish every lifecycle transition to sink. Call it before Start.
+	SetEventSink(sink EventSink)
+	// SetCrashLoop sets how many failures within window quarantine a binary. Call it before Start.
+	SetCrashLoop(maxFailures int, window time.Duration)
+	// SetSecretRotation sets how long resolved plugin secrets are reused. Call it before Start.
+	SetSecretRotation(interval time.Duration)
+	// ClearQuarantine lifts the quarantine on the named binary and reports whether it was quarantined.
+	ClearQuarantine(name string) bool
+	// SetReattachFile makes the manager adopt plugins started outside the host, e.g. under a debugger,
+	// in place of their binaries. Call it before Start.
+	SetReattachFile(path string)
+}
+
+// Source materialises the desired set of plugin binaries into a manager's directory,
+// e.g. from a remote artifact store.
+type Source interface {
+	// Sync brings dir in line with the desired plugins of type pluginKey and reports whether it changed anything.
+	Sync(ctx context.Context, pluginKey, dir string) (bool, error)
 }
 
 // ISyncable is the type constraint for all plugin types managed by a Manager.
@@ -42,9 +75,14 @@ type PluginHandle struct {
 	Client    *plugin.Client
 	Lifecycle PluginLifecycle
 	BinPath   string
-	ID        string // stable plugin identifier (e.g. UUID); used for bus messages and pool ops
-	Name      string // human-readable display name; used for logging
-	Hash      string // SHA-256 of the binary at launch time
+	ID        string      // stable plugin identifier (e.g. UUID); used for bus messages and pool ops
+	Name      string      // human-readable display name; used for logging
+	Hash      string      // SHA-256 of the binary at launch time
+	CfgHash   string      // ResolvedConfig.Checksum() of the config sent in Init
+	Protocol  int         // plugin protocol version negotiated with the binary
+	Adopted   bool        // attached through the reattach file; its process is never shut down, killed or restarted
+	gen       uint64      // worker generation: handles spawned together by start/update, plus those grown into it
+	healthy   atomic.Bool // result of the last ping
 	killOnce  sync.Once
 	stopped   chan struct{}
 }
@@ -54,25 +92,33 @@ type PluginHandle struct {
 type PluginAdapter[T ISyncable] interface {
 	// This is the go-plugin dispense key, e.g. "rule", "enrichment".
 	PluginKey() string
-	// This is the HandshakeConfig cookie value, e.g. "rule_v1".
+	// This is the HandshakeConfig cookie value, e.g. "rule". It identifies the plugin type, not a protocol
+	// version, so it must stay the same across versions.
 	MagicValue() string
-	// GRPCPlugin returns the go-plugin.Plugin that constructs the gRPC client stub.
-	GRPCPlugin() plugin.Plugin
-	// Handshake type-asserts the dispensed raw interface, calls Init (and optionally GetMetadata),
-	// and returns the wrapped public T, a PluginLifecycle, the plugin stable ID, the display name, and any error.
-	Handshake(ctx context.Context, raw interface{}, binPath string, hash string) (T, PluginLifecycle, string, string, error)
+	// Protocols returns, per plugin protocol version the host speaks, the go-plugin.Plugin that constructs
+	// that version's gRPC client stub. Each binary negotiates the highest version both sides support, so
+	// v1 and v2 binaries can run side by side. Dropping a version makes binaries that only speak it fail
+	// to start.
+	Protocols() map[int]plugin.Plugin
+	// Handshake type-asserts the dispensed raw interface for the negotiated protocol version, calls Init
+	// with cfg (and optionally GetMetadata), and returns the wrapped public T, a PluginLifecycle, the plugin
+	// stable ID, the display name, and any error.
+	Handshake(ctx context.Context, raw interface{}, protocol int, binPath string, hash string, cfg ResolvedConfig) (T, PluginLifecycle, string, string, error)
+	// Config returns the params and secret references declared for the binary's sidecar.
+	Config(binPath string) (PluginConfig, error)
 	// IsEnabled reports whether a running handle should continue running.
 	IsEnabled(handle *PluginHandle) bool
-	// Returns how many subprocess instances to spawn for this binary.
-	// Return 1 (or ≤ 0) for the default single-worker behaviour.
-	Workers(binPath string) int
+	// Returns the worker bounds for this binary: minProcs are spawned up front and the pool
+	// grows towards maxProcs under load. Called on every autoscale tick, so it must be cheap.
+	// Return 1, 1 (or ≤ 0) for the default single-worker behaviour.
+	Workers(binPath string) (minProcs, maxProcs int)
 }
 
 // startFailure tracks consecutive start failures for a binary path.
 type startFailure struct {
 	count     int
 	nextRetry time.Time
-	hash      string // hash at time of last failure; reset backoff if binary changes
+	hash      string // binary + config hash at time of last failure; reset backoff if either changes
 }
 
 // PluginManager[T] is the generic plugin subprocess manager.
@@ -86,7 +132,26 @@ type PluginManager[T ISyncable] struct {
 	mu             sync.RWMutex
 	plugin_handles map[string][]*PluginHandle
 	failures       map[string]*startFailure
-	restarting     map[string]struct{} // paths mid-restart; reconcile skips these to prevent double-start
+	restarting     map[string]struct{}    // paths mid-restart; reconcile skips these to prevent double-start
+	crashes        map[string][]time.Time // recent crashes and failed starts per path, inside crashWindow
+	quarantined    map[string]*quarantine
+	crashMax       int
+	crashWindow    time.Duration
+	events         EventSink              // optional; receives every lifecycle transition
+	logLimits      map[string]*logLimiter // per-path log line budget shared by a binary's workers
+	hashes         *hashCache
+	resolved       map[string]resolvedEntry // last resolved sidecar config per path; see resolveConfig
+	rolledBack     map[string]string        // revision per path whose canary an operator rolled back; see rollback
+	secretRotation time.Duration
+	routing        *pools.RoutingTable // optional; see SetRouting
+	queueDefaults  pools.QueuePolicy
+	builtins       map[string]*builtin[T]          // in-process plugins by name; see Register
+	reattachFile   string                          // optional; see SetReattachFile
+	adopted        map[string]plugindebug.Reattach // plugins attached to instead of spawned, by path
+	gens           atomic.Uint64
+	source         Source // optional; fills dir before each scheduled reconcile
+	sourceEvery    time.Duration
+	started        atomic.Bool // initial reconcile done
 }
 
 func NewPluginManager[T ISyncable](
@@ -105,14 +170,50 @@ func NewPluginManager[T ISyncable](
 		plugin_handles: make(map[string][]*PluginHandle),
 		failures:       make(map[string]*startFailure),
 		restarting:     make(map[string]struct{}),
+		crashes:        make(map[string][]time.Time),
+		quarantined:    make(map[string]*quarantine),
+		logLimits:      make(map[string]*logLimiter),
+		hashes:         newHashCache(),
+		resolved:       make(map[string]resolvedEntry),
+		rolledBack:     make(map[string]string),
+		secretRotation: defaultSecretRotation,
+		builtins:       make(map[string]*builtin[T]),
+		crashMax:       defaultCrashLoopFailures,
+		crashWindow:    defaultCrashLoopWindow,
+	}
+}
+
+// SetSource makes the manager sync its directory from src, first before the initial reconcile and then
+// every interval (30s if ≤ 0). Must be called before Start.
+func (m *PluginManager[T]) SetSource(src Source, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
 	}
+	m.source = src
+	m.sourceEvery = interval
+}
+
+// SetEventSink makes the manager publish every lifecycle transition to sink. Must be called before Start.
+func (m *PluginManager[T]) SetEventSink(sink EventSink) {
+	m.events = sink
 }
 
 // Performs an initial reconcile then watches the plugin directory for changes.
+// A manager with no directory only serves the in-process plugins given to Register.
 func (m *PluginManager[T]) Start(ctx context.Context) error {
+	if m.dir == "" {
+		m.started.Store(true)
+		return nil
+	}
+	if m.source != nil {
+		// A failed first sync is not fatal: the manager starts with whatever the directory already holds.
+		m.syncSource(ctx)
+		go m.sourceLoop(ctx)
+	}
 	if err := m.reconcile("initial"); err != nil {
 		return err
 	}
+	m.started.Store(true)
 
 	w, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -168,30 +269,80 @@ func (m *PluginManager[T]) Start(ctx context.Context) error {
 	return nil
 }
 
+// Reconcile rescans the plugin directory now. Used by the admin API to force a reload.
+func (m *PluginManager[T]) Reconcile() error {
+	return m.reconcile("admin")
+}
+
+// Ready reports an error until the initial reconcile has spawned every plugin it could.
+func (m *PluginManager[T]) Ready(context.Context) error {
+	if !m.started.Load() {
+		return fmt.Errorf("initial %s reconcile of %s has not completed", m.adapter.PluginKey(), m.dir)
+	}
+	return nil
+}
+
+// syncSource runs one Source sync and reports whether it changed the directory.
+func (m *PluginManager[T]) syncSource(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+	changed, err := m.source.Sync(ctx, m.adapter.PluginKey(), m.dir)
+	if err != nil {
+		m.log.ErrorF("%s artifact sync: %v", m.adapter.PluginKey(), err)
+	}
+	return changed
+}
+
+// sourceLoop re-syncs from the Source and reconciles straight away when the directory changed,
+// rather than waiting for fsnotify (which does not report sidecar-only changes) or the poll.
+func (m *PluginManager[T]) sourceLoop(ctx context.Context) {
+	t := time.NewTicker(m.sourceEvery)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if m.syncSource(ctx) {
+				if err := m.reconcile("artifacts"); err != nil {
+					m.log.ErrorF("reconcile error: %v", err)
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 func (m *PluginManager[T]) reconcile(reason string) error {
+	if m.dir == "" {
+		return nil
+	}
 	m.log.Info("reconciling %s plugins (%s)...", m.adapter.PluginKey(), reason)
+	defer func(start time.Time) { m.metrics.ReconcileDuration.Observe(time.Since(start).Seconds()) }(time.Now())
 
-	entries, err := os.ReadDir(m.dir)
+	binaries, err := m.scan()
 	if err != nil {
 		return err
 	}
+	binaries = m.adopt(binaries)
 
-	seen := make(map[string]struct{})
-	for _, e := range entries {
-		if e.IsDir() {
+	seen := make(map[string]struct{}, len(binaries))
+	for _, b := range binaries {
+		seen[b.path] = struct{}{}
+	}
+	m.hashes.retain(seen)
+
+	for _, b := range binaries {
+		path, h := b.path, b.hash
+		if b.err != nil {
+			m.log.ErrorF("hash %s: %v", path, b.err)
 			continue
 		}
-		path := filepath.Join(m.dir, e.Name())
-		info, err := e.Info()
-		if err != nil || info.Mode()&0111 == 0 {
-			continue // skip non-executables
-		}
-		h, err := helpers.BinaryChecksum(path)
+
+		declared, cfg, err := m.resolveConfig(path, false)
 		if err != nil {
-			m.log.ErrorF("hash %s: %v", path, err)
+			m.log.ErrorF("config %s: %v", path, err)
 			continue
 		}
-		seen[path] = struct{}{}
 
 		m.mu.RLock()
 		handles, exists := m.plugin_handles[path]
@@ -202,21 +353,23 @@ func (m *PluginManager[T]) reconcile(reason string) error {
 			continue // pingLoop is already handling the restart
 		}
 
-		if exists {
-			if handles[0].Hash == h {
-				continue // binary unchanged
+		if !exists {
+			if err := m.startWithBackoff(path, h, cfg); err != nil {
+				m.log.ErrorF("start %s %s: %v", m.adapter.PluginKey(), path, err)
 			}
-			if err := m.update(path, handles, h); err != nil {
+		} else if handles[0].Hash != h || handles[0].CfgHash != cfg.Checksum() {
+			if m.isRolledBack(path, revision(path, h, cfg.Checksum())) {
+				continue // the operator rolled this revision back; wait for the next one
+			}
+			if err := m.update(path, handles, h, cfg); err != nil {
 				m.log.ErrorF("update %s %s: %v", m.adapter.PluginKey(), path, err)
 			}
-			continue
-		}
-
-		if err := m.startWithBackoff(path, h); err != nil {
-			m.log.ErrorF("start %s %s: %v", m.adapter.PluginKey(), path, err)
 		}
+		m.route(path, declared) // queueing changes apply without restarting the plugin
 	}
 
+	m.forget(seen)
+
 	// Collect plugins that need to be stopped or removed, then act outside the lock
 	// so that kill() (gRPC Shutdown, up to 3s) does not block readers.
 	type pendingAction struct {
@@ -240,12 +393,64 @@ func (m *PluginManager[T]) reconcile(reason string) error {
 		if p.perm {
 			m.remove(p.key, p.handles)
 		} else {
-			m.stop(p.key, p.handles)
+			m.stop(p.key, p.handles, "disabled")
 		}
 	}
 	return nil
 }
 
+// binary is one executable found by scan.
+type binary struct {
+	path string
+	hash string
+	err  error
+}
+
+// scan lists the executables in the plugin directory and checksums them. Checksums come from the
+// hash cache unless a file's stat changed; the rest are computed hashConcurrency at a time.
+func (m *PluginManager[T]) scan() ([]binary, error) {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var binaries []binary
+	var infos []os.FileInfo
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue // hidden entries include in-progress artifact links
+		}
+		path := filepath.Join(m.dir, e.Name())
+		info, err := os.Stat(path) // follows artifact symlinks
+		if err != nil || info.Mode()&0111 == 0 {
+			continue // skip non-executables
+		}
+		binaries = append(binaries, binary{path: path})
+		infos = append(infos, info)
+	}
+
+	var g errgroup.Group
+	g.SetLimit(hashConcurrency)
+	for i := range binaries {
+		g.Go(func() error {
+			b := &binaries[i]
+			var hashed bool
+			b.hash, hashed, b.err = m.hashes.sum(b.path, infos[i])
+			switch {
+			case b.err != nil:
+				m.metrics.FilesScanned.WithLabelValues("error").Inc()
+			case hashed:
+				m.metrics.FilesScanned.WithLabelValues("hashed").Inc()
+			default:
+				m.metrics.FilesScanned.WithLabelValues("cached").Inc()
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return binaries, nil
+}
+
 // This is a gRPC service config that retries UNAVAILABLE responses with exponential backoff. This absorbs the startup race where the subprocess hasn't yet
 // bound its port when the first RPC arrives. maxAttempts=3 means 1 attempt + 2 retries.
 const pluginRetryPolicy = `{
@@ -264,26 +469,43 @@ const pluginRetryPolicy = `{
 // spawn ONE subprocess, runs the PluginAdapter handshake, and returns the
 // wrapped handle. It does NOT store the handle in plugin_handles or start pingLoop -
 // spawnN handles that after all worker instances are ready.
-func (m *PluginManager[T]) spawn(path, hash string) (T, *PluginHandle, error) {
+// worker is the index of the subprocess within its generation; it only tags log lines.
+func (m *PluginManager[T]) spawn(path, hash string, cfg ResolvedConfig, worker int) (T, *PluginHandle, error) {
 	startedAt := time.Now()
+	plog := newPluginLog(m.log, m.metrics, m.adapter.PluginKey(), path, worker, m.logLimiter(path))
 
-	cfg := &plugin.ClientConfig{
+	versions := make(map[int]plugin.PluginSet)
+	for v, p := range m.adapter.Protocols() {
+		versions[v] = plugin.PluginSet{m.adapter.PluginKey(): p}
+	}
+
+	clientCfg := &plugin.ClientConfig{
 		HandshakeConfig: plugin.HandshakeConfig{
-			ProtocolVersion:  1,
 			MagicCookieKey:   "BLINK_PLUGIN",
 			MagicCookieValue: m.adapter.MagicValue(),
 		},
-		Cmd:              exec.Command(path),
 		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
-		Plugins: map[string]plugin.Plugin{
-			m.adapter.PluginKey(): m.adapter.GRPCPlugin(),
-		},
+		VersionedPlugins: versions,
 		GRPCDialOptions: []grpc.DialOption{
 			grpc.WithDefaultServiceConfig(pluginRetryPolicy),
 		},
+		Logger:     plog.clientLogger(),
+		SyncStdout: plog.stream("stdout", hclog.Info),
+		SyncStderr: plog.stream("stderr", hclog.Warn),
 	}
 
-	cl := plugin.NewClient(cfg)
+	reattach, err := m.reattachConfig(path)
+	if err != nil {
+		var zero T
+		return zero, nil, fmt.Errorf("reattach: %w", err)
+	}
+	if reattach != nil {
+		clientCfg.Reattach = reattach
+	} else {
+		clientCfg.Cmd = exec.Command(path)
+	}
+
+	cl := plugin.NewClient(clientCfg)
 	rpcClient, err := cl.Client()
 	if err != nil {
 		cl.Kill()
@@ -298,27 +520,51 @@ func (m *PluginManager[T]) spawn(path, hash string) (T, *PluginHandle, error) {
 		return zero, nil, fmt.Errorf("dispense: %w", err)
 	}
 
-	wrapped, lifecycle, id, name, err := m.adapter.Handshake(context.Background(), raw, path, hash)
+	wrapped, lifecycle, id, name, err := m.adapter.Handshake(context.Background(), raw, cl.NegotiatedVersion(), path, hash, cfg)
 	if err != nil {
 		cl.Kill()
 		var zero T
 		return zero, nil, err
 	}
 
-	handle := &PluginHandle{Client: cl, Lifecycle: lifecycle, BinPath: path, ID: id, Name: name, Hash: hash, stopped: make(chan struct{})}
+	handle := &PluginHandle{Client: cl, Lifecycle: lifecycle, BinPath: path, ID: id, Name: name, Hash: hash, CfgHash: cfg.Checksum(), Protocol: cl.NegotiatedVersion(), Adopted: reattach != nil, stopped: make(chan struct{})}
+	handle.healthy.Store(true)
+	version := ""
+	if v, ok := any(wrapped).(interface{ Version() string }); ok {
+		version = v.Version()
+	}
+	plog.identify(id, name, version, worker)
 
 	m.metrics.StartLatency.Observe(time.Since(startedAt).Seconds())
 	m.metrics.ActiveSubprocesses.WithLabelValues(m.adapter.PluginKey()).Inc()
+	m.metrics.Protocols.WithLabelValues(m.adapter.PluginKey(), strconv.Itoa(handle.Protocol)).Inc()
 	m.metrics.Starts.Inc()
-	m.log.Info("%s started: %s [%s] (%s)", m.adapter.PluginKey(), name, id, path)
+	if handle.Adopted {
+		m.log.Info("%s adopted: %s [%s] (%s, pid %d, protocol v%d)", m.adapter.PluginKey(), name, id, path, reattach.Pid, handle.Protocol)
+	} else {
+		m.log.Info("%s started: %s [%s] (%s, protocol v%d)", m.adapter.PluginKey(), name, id, path, handle.Protocol)
+	}
 
 	return wrapped, handle, nil
 }
 
-// spawnN spawns n worker subprocess instances for the same binary, stores the full
-// slice in plugin_handles, and starts a pingLoop for each. If any spawn fails, all
+// logLimiter returns the log line budget shared by every worker of path.
+func (m *PluginManager[T]) logLimiter(path string) *logLimiter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l := m.logLimits[path]
+	if l == nil {
+		l = &logLimiter{}
+		m.logLimits[path] = l
+	}
+	return l
+}
+
+// spawnN spawns n worker subprocess instances for the same binary as a new generation, stores the
+// full slice in plugin_handles, and starts a pingLoop for each. It returns the slice it replaced,
+// which includes any workers the pool grew into the previous generation. If any spawn fails, all
 // already-started subprocesses are killed and an error is returned.
-func (m *PluginManager[T]) spawnN(path, hash string, n int) ([]T, []*PluginHandle, error) {
+func (m *PluginManager[T]) spawnN(path, hash string, cfg ResolvedConfig, n int) ([]T, []*PluginHandle, []*PluginHandle, error) {
 	if n <= 0 {
 		n = 1
 	}
@@ -326,34 +572,104 @@ func (m *PluginManager[T]) spawnN(path, hash string, n int) ([]T, []*PluginHandl
 	handles := make([]*PluginHandle, 0, n)
 
 	for i := 0; i < n; i++ {
-		w, h, err := m.spawn(path, hash)
+		w, h, err := m.spawn(path, hash, cfg, i)
 		if err != nil {
 			for _, h := range handles {
 				m.kill(h)
 			}
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		wrapped = append(wrapped, w)
 		handles = append(handles, h)
 	}
 
+	gen := m.gens.Add(1)
+	for _, h := range handles {
+		h.gen = gen
+	}
+
 	m.mu.Lock()
+	replaced := m.plugin_handles[path]
 	m.plugin_handles[path] = handles
 	m.mu.Unlock()
 
 	for _, h := range handles {
 		go m.pingLoop(h)
 	}
-	return wrapped, handles, nil
+	return wrapped, handles, replaced, nil
+}
+
+// workers builds the stop funcs and Scaler the pool needs to resize the generation handles belong to.
+func (m *PluginManager[T]) workers(path, hash string, cfg ResolvedConfig, handles []*PluginHandle) ([]func(), pools.Scaler[T]) {
+	stops := make([]func(), len(handles))
+	for i, h := range handles {
+		stops[i] = func() { m.retire(path, h) }
+	}
+	return stops, &workerScaler[T]{m: m, path: path, hash: hash, cfg: cfg, gen: handles[0].gen}
+}
+
+// workerScaler is the pools.Scaler for one worker generation of a binary.
+type workerScaler[T ISyncable] struct {
+	m    *PluginManager[T]
+	path string
+	hash string
+	cfg  ResolvedConfig
+	gen  uint64
+}
+
+func (s *workerScaler[T]) Limits() (int, int) {
+	return s.m.limits(s.path)
+}
+
+// Grow spawns one more worker into the generation. It fails once the generation has been replaced
+// or stopped, so a draining pool never gains workers behind the manager's back.
+func (s *workerScaler[T]) Grow() (pools.Worker[T], error) {
+	m := s.m
+	m.mu.RLock()
+	worker := len(m.plugin_handles[s.path])
+	m.mu.RUnlock()
+	w, h, err := m.spawn(s.path, s.hash, s.cfg, worker)
+	if err != nil {
+		return pools.Worker[T]{}, err
+	}
+	h.gen = s.gen
+
+	m.mu.Lock()
+	current := m.plugin_handles[s.path]
+	if len(current) == 0 || current[0].gen != s.gen {
+		m.mu.Unlock()
+		m.kill(h)
+		return pools.Worker[T]{}, fmt.Errorf("%s generation %d is no longer active", s.path, s.gen)
+	}
+	m.plugin_handles[s.path] = append(slices.Clip(current), h)
+	m.mu.Unlock()
+
+	go m.pingLoop(h)
+	return pools.Worker[T]{Plugin: w, Stop: func() { m.retire(s.path, h) }}, nil
+}
+
+// retire shuts down one worker the pool scaled in (or stopped after a drain) and drops it from plugin_handles.
+func (m *PluginManager[T]) retire(path string, h *PluginHandle) {
+	m.mu.Lock()
+	current := m.plugin_handles[path]
+	if i := slices.Index(current, h); i >= 0 && len(current) > 1 {
+		m.plugin_handles[path] = slices.Delete(slices.Clone(current), i, i+1)
+	}
+	m.mu.Unlock()
+	m.kill(h)
 }
 
-// wraps start() with exponential backoff on consecutive failures.
-func (m *PluginManager[T]) startWithBackoff(path, hash string) error {
+// wraps start() with exponential backoff on consecutive failures, and leaves quarantined binaries alone.
+func (m *PluginManager[T]) startWithBackoff(path, hash string, cfg ResolvedConfig) error {
+	rev := revision(path, hash, cfg.Checksum())
+	if m.isQuarantined(path, rev) {
+		return nil
+	}
 	m.mu.Lock()
 	f := m.failures[path]
 	if f != nil {
-		if f.hash != hash {
-			// Binary changed — reset backoff immediately.
+		if f.hash != rev {
+			// Binary or config changed — reset backoff immediately.
 			delete(m.failures, path)
 			f = nil
 		} else if time.Now().Before(f.nextRetry) {
@@ -364,22 +680,35 @@ func (m *PluginManager[T]) startWithBackoff(path, hash string) error {
 	}
 	m.mu.Unlock()
 
-	err := m.start(path, hash)
+	err := m.start(path, hash, cfg)
 	if err != nil {
+		reason := "start: " + err.Error()
 		m.mu.Lock()
-		f = m.failures[path]
-		if f == nil {
-			f = &startFailure{hash: hash}
-			m.failures[path] = f
-		}
-		f.count++
-		backoff := time.Duration(10<<min(f.count-1, 5)) * time.Second // 10s→320s, cap 5min
-		if backoff > 5*time.Minute {
-			backoff = 5 * time.Minute
+		failures, quarantined := m.recordFailure(path, rev, reason)
+		var count int
+		var backoff time.Duration
+		if !quarantined {
+			f = m.failures[path]
+			if f == nil {
+				f = &startFailure{hash: rev}
+				m.failures[path] = f
+			}
+			f.count++
+			backoff = time.Duration(10<<min(f.count-1, 5)) * time.Second // 10s→320s, cap 5min
+			if backoff > 5*time.Minute {
+				backoff = 5 * time.Minute
+			}
+			f.nextRetry = time.Now().Add(backoff)
+			count = f.count
 		}
-		f.nextRetry = time.Now().Add(backoff)
 		m.mu.Unlock()
-		m.log.ErrorF("%s %s start failed (attempt %d), next retry in %v", m.adapter.PluginKey(), path, f.count, backoff)
+
+		m.emit(Event{Kind: EventCrashed, Path: path, Hash: hash, ConfigHash: cfg.Checksum(), Reason: reason, Failures: failures})
+		if quarantined {
+			m.quarantine(path, hash, cfg.Checksum(), reason, failures)
+			return err
+		}
+		m.log.ErrorF("%s %s start failed (attempt %d), next retry in %v", m.adapter.PluginKey(), path, count, backoff)
 		return err
 	}
 
@@ -391,78 +720,149 @@ func (m *PluginManager[T]) startWithBackoff(path, hash string) error {
 }
 
 // spawns n worker subprocesses and notifies the pool to register them.
-func (m *PluginManager[T]) start(path, hash string) error {
-	n := m.adapter.Workers(path)
-	wrapped, handles, err := m.spawnN(path, hash, n)
+func (m *PluginManager[T]) start(path, hash string, cfg ResolvedConfig) error {
+	n, _ := m.limits(path)
+	wrapped, handles, _, err := m.spawnN(path, hash, cfg, n)
 	if err != nil {
 		return err
 	}
-	m.notify(NewRegisterMessage[T](wrapped, len(handles)))
+	stops, scaler := m.workers(path, hash, cfg, handles)
+	m.notify(NewRegisterMessage[T](wrapped, stops, scaler))
+	m.emit(handleEvent(EventStarted, handles[0], ""))
 	return nil
 }
 
 // spawns new worker subprocesses and notifies the pool with an onDrained callback.
 // The old subprocesses are only killed after all in-flight calls on the old VersionedPool
 // complete - ensuring no call ever hits a dead gRPC connection.
-func (m *PluginManager[T]) update(path string, oldHandles []*PluginHandle, newHash string) error {
-	n := m.adapter.Workers(path)
-	wrapped, newHandles, err := m.spawnN(path, newHash, n)
+// A config-only change takes the same path: new workers are Init'ed with the new config before the old ones drain.
+func (m *PluginManager[T]) update(path string, oldHandles []*PluginHandle, newHash string, cfg ResolvedConfig) error {
+	n, _ := m.limits(path)
+	wrapped, newHandles, replaced, err := m.spawnN(path, newHash, cfg, n)
 	if err != nil {
 		return err
 	}
-	m.notify(NewUpdateMessage[T](wrapped, len(newHandles), func() {
-		for _, h := range oldHandles {
+	if replaced == nil {
+		replaced = oldHandles
+	}
+	stops, scaler := m.workers(path, newHash, cfg, newHandles)
+	msg := NewUpdateMessage[T](wrapped, stops, scaler, func() {
+		for _, h := range replaced {
 			m.kill(h)
 		}
-	}))
+	})
+	msg.OnRolledBack = func() { m.rollback(path, replaced, newHandles) }
+	m.notify(msg)
+	reason := "binary"
+	if oldHandles[0].Hash == newHash {
+		reason = "config"
+		m.metrics.ConfigReloads.Inc()
+	}
 	m.metrics.Updates.Inc()
-	m.log.Info("%s updated: %s (%d worker(s))", m.adapter.PluginKey(), path, len(newHandles))
+	m.emit(handleEvent(EventUpdated, newHandles[0], reason))
+	m.log.Info("%s updated (%s): %s (%d worker(s))", m.adapter.PluginKey(), reason, path, len(newHandles))
 	return nil
 }
 
+// rollback is called when the pool discards the canary or shadow generation update staged for path.
+// It hands plugin_handles back to the generation that still serves, whose health checks resume, and
+// remembers the discarded revision so reconcile does not stage it again; the pool stops its workers.
+func (m *PluginManager[T]) rollback(path string, restored, discarded []*PluginHandle) {
+	live := slices.DeleteFunc(slices.Clone(restored), func(h *PluginHandle) bool {
+		select {
+		case <-h.stopped:
+			return true
+		default:
+			return false
+		}
+	})
+	m.mu.Lock()
+	current := m.plugin_handles[path]
+	if len(live) > 0 && len(current) > 0 && current[0].gen == discarded[0].gen {
+		m.plugin_handles[path] = live
+	}
+	m.rolledBack[path] = revision(path, discarded[0].Hash, discarded[0].CfgHash)
+	m.mu.Unlock()
+	m.emit(handleEvent(EventRolledBack, discarded[0], "operator"))
+	m.log.Info("%s rolled back: %s [%s]", m.adapter.PluginKey(), path, discarded[0].ID)
+}
+
+// isRolledBack reports whether rev of path is the one an operator rolled back. Any other revision
+// clears the mark.
+func (m *PluginManager[T]) isRolledBack(path, rev string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rolledBack[path] == rev {
+		return true
+	}
+	delete(m.rolledBack, path)
+	return false
+}
+
 // kill gracefully shuts down the subprocess exactly once (safe for concurrent calls).
 // It does NOT touch plugin_handles - callers that own the map entry call evict instead.
 func (m *PluginManager[T]) kill(handle *PluginHandle) {
 	handle.killOnce.Do(func() {
 		close(handle.stopped)
 		defer func() { recover() }() //nolint:errcheck - best-effort shutdown
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-		_ = handle.Lifecycle.Shutdown(ctx)
-		cancel()
-		handle.Client.Kill()
+		if handle.Adopted {
+			// The process belongs to whoever started it; only drop the connection.
+			if c, err := handle.Client.Client(); err == nil {
+				if gc, ok := c.(*plugin.GRPCClient); ok {
+					_ = gc.Conn.Close()
+				}
+			}
+		} else {
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			_ = handle.Lifecycle.Shutdown(ctx)
+			cancel()
+			handle.Client.Kill()
+		}
 		m.metrics.ActiveSubprocesses.WithLabelValues(m.adapter.PluginKey()).Dec()
+		m.metrics.Protocols.WithLabelValues(m.adapter.PluginKey(), strconv.Itoa(handle.Protocol)).Dec()
 	})
 }
 
 // kills all handles in the group and removes the group from plugin_handles.
 // It acquires the write lock only for the map delete, so kill() (gRPC Shutdown)
 // runs outside the lock. Guards against a concurrent handle replacement at the same key
-// by checking that the stored slice still begins with the same pointer.
+// by checking that the stored slice still belongs to the same worker generation.
 func (m *PluginManager[T]) evict(key string, handles []*PluginHandle) {
 	for _, h := range handles {
 		m.kill(h)
 	}
 	m.mu.Lock()
 	current := m.plugin_handles[key]
-	if len(current) > 0 && len(handles) > 0 && current[0] == handles[0] {
+	if len(current) > 0 && len(handles) > 0 && current[0].gen == handles[0].gen {
 		delete(m.plugin_handles, key)
+	} else {
+		current = nil
 	}
 	m.mu.Unlock()
+	// Workers the pool grew after the caller read handles.
+	for _, h := range current {
+		m.kill(h)
+	}
 }
 
-// evicts the subprocesses transiently (crash restart, config disable) and
+// evicts the subprocesses transiently (crash restart, config disable, quarantine) and
 // sends UnregisterMessage - pool removes the active entry but does NOT tombstone.
-func (m *PluginManager[T]) stop(key string, handles []*PluginHandle) {
+func (m *PluginManager[T]) stop(key string, handles []*PluginHandle, reason string) {
 	m.evict(key, handles)
 	m.notify(NewUnregisterMessage[T](handles[0].ID))
-	m.log.Info("%s stopped: %s [%s]", m.adapter.PluginKey(), handles[0].Name, handles[0].ID)
+	m.emit(handleEvent(EventStopped, handles[0], reason))
+	m.log.Info("%s stopped (%s): %s [%s]", m.adapter.PluginKey(), reason, handles[0].Name, handles[0].ID)
 }
 
 // evicts the subprocesses permanently (binary deleted from disk) and
 // sends RemoveMessage - pool removes the active entry AND tombstones the plugin ID.
 func (m *PluginManager[T]) remove(key string, handles []*PluginHandle) {
 	m.evict(key, handles)
+	if m.routing != nil {
+		m.routing.Delete(handles[0].ID)
+	}
 	m.notify(NewRemoveMessage[T](handles[0].ID))
+	m.emit(handleEvent(EventRemoved, handles[0], "binary deleted"))
 	m.log.Info("%s removed: %s [%s]", m.adapter.PluginKey(), handles[0].Name, handles[0].ID)
 }
 
@@ -477,9 +877,13 @@ func (m *PluginManager[T]) restart(key string, handles []*PluginHandle) error {
 	m.restarting[path] = struct{}{}
 	m.mu.Unlock()
 
-	m.stop(key, handles)
+	m.stop(key, handles, "restart")
 
-	err := m.startWithBackoff(path, hash)
+	// Re-resolve rather than reuse the old config so a restart also picks up rotated secrets.
+	_, cfg, err := m.resolveConfig(path, true)
+	if err == nil {
+		err = m.startWithBackoff(path, hash, cfg)
+	}
 
 	m.mu.Lock()
 	delete(m.restarting, path)
@@ -497,8 +901,9 @@ func (m *PluginManager[T]) pingLoop(handle *PluginHandle) {
 			return // intentionally stopped - do not restart
 		case <-t.C:
 			// During a graceful update, spawnN stores the new handles in the map
-			// before notify() is called. If this handle is no longer in the active
-			// slice, it was replaced - exit without restarting.
+			// before notify() is called. A handle no longer in the active slice was
+			// replaced: leave it alone until it is killed once drained, or until a
+			// rolled-back canary puts its generation back.
 			m.mu.RLock()
 			current := m.plugin_handles[handle.BinPath]
 			m.mu.RUnlock()
@@ -510,19 +915,40 @@ func (m *PluginManager[T]) pingLoop(handle *PluginHandle) {
 				}
 			}
 			if !active {
-				return
+				continue
 			}
 
 			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 			err := handle.Lifecycle.Ping(ctx)
 			cancel()
+			wasHealthy := handle.healthy.Swap(err == nil)
+			if err != nil && handle.Adopted {
+				// Most likely paused at a breakpoint: report it unhealthy but leave it in the pool.
+				if wasHealthy {
+					m.log.Info("%s adopted plugin %s is not responding: %v", m.adapter.PluginKey(), handle.Name, err)
+				}
+				continue
+			}
 			if err != nil {
 				m.metrics.Crashes.Inc()
-				m.log.ErrorF("%s crash/health fail %s: %v - restarting", m.adapter.PluginKey(), handle.Name, err)
+				reason := "health check: " + err.Error()
+				m.mu.Lock()
+				failures, quarantined := m.recordFailure(handle.BinPath, revision(handle.BinPath, handle.Hash, handle.CfgHash), reason)
 				// Fetch the full current group so restart kills all workers, not just this one.
-				m.mu.RLock()
 				group := m.plugin_handles[handle.BinPath]
-				m.mu.RUnlock()
+				m.mu.Unlock()
+
+				crash := handleEvent(EventCrashed, handle, reason)
+				crash.Failures = failures
+				m.emit(crash)
+				if quarantined {
+					if len(group) > 0 {
+						m.stop(handle.BinPath, group, "quarantined")
+					}
+					m.quarantine(handle.BinPath, handle.Hash, handle.CfgHash, reason, failures)
+					return
+				}
+				m.log.ErrorF("%s crash/health fail %s: %v - restarting", m.adapter.PluginKey(), handle.Name, err)
 				if restartErr := m.restart(handle.BinPath, group); restartErr != nil {
 					m.log.Error(errors.NewF("restart failed for %s: %v", handle.BinPath, restartErr))
 				}
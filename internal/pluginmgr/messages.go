@@ -1,17 +1,22 @@
 package pluginmgr
 
-import "github.com/harishhary/blink/internal/messaging"
+import (
+	"github.com/harishhary/blink/internal/messaging"
+	"github.com/harishhary/blink/internal/pools"
+)
 
 // Notify is the callback a PluginManager calls when a plugin starts, updates, or stops.
 // Implementations are typically pool.Sync methods that register/deregister plugin handles.
 type Notify = func(messaging.Message)
 
 // Delivered when a new plugin subprocess is ready.
-// Items holds all N worker instances for the binary; MaxProcs is the pool capacity hint.
+// Items holds the initial (min_procs) worker instances for the binary and Stops the matching shutdown funcs.
+// Scaler lets the pool add workers up to max_procs; pass Workers() and Scaler to ProcessPool.Register.
 type RegisterMessage[T ISyncable] struct {
 	messaging.IsMessage
-	Items    []T
-	MaxProcs int
+	Items  []T
+	Stops  []func()
+	Scaler pools.Scaler[T]
 }
 
 // Delivered when a plugin subprocess is stopped transiently aka a crash being restarted, or a plugin disabled via config. The plugin may come back.
@@ -29,17 +34,20 @@ type RemoveMessage[T ISyncable] struct {
 }
 
 // Delivered when a plugin binary changes in-place.
-// Items holds all N worker instances for the new binary version.
+// Items, Stops and Scaler describe the new workers as in RegisterMessage.
 // OnDrained is called by ProcessPool.drain once all in-flight calls on the old VersionedPool complete - the PluginManager uses it to kill the old subprocesses only after the pool has finished draining.
+// OnRolledBack is called by ProcessPool.Rollback if the new workers were staged as a canary and discarded - the PluginManager uses it to supervise the old subprocesses again.
 type UpdateMessage[T ISyncable] struct {
 	messaging.IsMessage
-	Items     []T
-	MaxProcs  int
-	OnDrained func()
+	Items        []T
+	Stops        []func()
+	Scaler       pools.Scaler[T]
+	OnDrained    func()
+	OnRolledBack func()
 }
 
-func NewRegisterMessage[T ISyncable](items []T, maxProcs int) RegisterMessage[T] {
-	return RegisterMessage[T]{Items: items, MaxProcs: maxProcs}
+func NewRegisterMessage[T ISyncable](items []T, stops []func(), scaler pools.Scaler[T]) RegisterMessage[T] {
+	return RegisterMessage[T]{Items: items, Stops: stops, Scaler: scaler}
 }
 
 func NewUnregisterMessage[T ISyncable](itemID string) UnregisterMessage[T] {
@@ -50,6 +58,23 @@ func NewRemoveMessage[T ISyncable](itemID string) RemoveMessage[T] {
 	return RemoveMessage[T]{ItemID: itemID}
 }
 
-func NewUpdateMessage[T ISyncable](items []T, maxProcs int, onDrained func()) UpdateMessage[T] {
-	return UpdateMessage[T]{Items: items, MaxProcs: maxProcs, OnDrained: onDrained}
+func NewUpdateMessage[T ISyncable](items []T, stops []func(), scaler pools.Scaler[T], onDrained func()) UpdateMessage[T] {
+	return UpdateMessage[T]{Items: items, Stops: stops, Scaler: scaler, OnDrained: onDrained}
+}
+
+// Pairs each item with its stop func for ProcessPool.Register.
+func (m RegisterMessage[T]) Workers() []pools.Worker[T] { return zipWorkers(m.Items, m.Stops) }
+
+// Pairs each item with its stop func for ProcessPool.Register.
+func (m UpdateMessage[T]) Workers() []pools.Worker[T] { return zipWorkers(m.Items, m.Stops) }
+
+func zipWorkers[T any](items []T, stops []func()) []pools.Worker[T] {
+	workers := make([]pools.Worker[T], len(items))
+	for i, item := range items {
+		workers[i].Plugin = item
+		if i < len(stops) {
+			workers[i].Stop = stops[i]
+		}
+	}
+	return workers
 }
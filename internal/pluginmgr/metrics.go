@@ -11,8 +11,15 @@ type PluginManagerMetrics struct {
 	Crashes            prometheus.Counter
 	Restarts           prometheus.Counter
 	Updates            prometheus.Counter
+	ConfigReloads      prometheus.Counter
 	StartLatency       prometheus.Histogram
 	ActiveSubprocesses *prometheus.GaugeVec
+	Events             *prometheus.CounterVec
+	Quarantined        *prometheus.GaugeVec
+	LogLinesDropped    prometheus.Counter
+	Protocols          *prometheus.GaugeVec
+	ReconcileDuration  prometheus.Histogram
+	FilesScanned       *prometheus.CounterVec
 }
 
 // Registers and returns a metric set for the given subsystem.
@@ -34,6 +41,10 @@ func NewPluginManagerMetrics(subsystem string) *PluginManagerMetrics {
 			Namespace: "blink", Subsystem: "plugin_manager" + subsystem, Name: "plugin_updates_total",
 			Help: "Total plugin subprocess hot-updates (binary replacement).",
 		}),
+		ConfigReloads: promauto.NewCounter(prometheus.CounterOpts{
+			Namespace: "blink", Subsystem: "plugin_manager" + subsystem, Name: "plugin_config_reloads_total",
+			Help: "Total plugin re-inits triggered by a params/secrets change with an unchanged binary.",
+		}),
 		StartLatency: promauto.NewHistogram(prometheus.HistogramOpts{
 			Namespace: "blink", Subsystem: "plugin_manager" + subsystem, Name: "plugin_start_latency_seconds",
 			Help:    "Time from plugin launch start to first bus publish.",
@@ -43,5 +54,30 @@ func NewPluginManagerMetrics(subsystem string) *PluginManagerMetrics {
 			Namespace: "blink", Subsystem: "plugin_manager" + subsystem, Name: "plugin_active_subprocesses",
 			Help: "Number of currently active plugin subprocesses.",
 		}, []string{"type"}),
+		Events: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "blink", Subsystem: "plugin_manager" + subsystem, Name: "plugin_events_total",
+			Help: "Plugin lifecycle transitions by event (started, updated, stopped, crashed, quarantined, released, removed).",
+		}, []string{"type", "event"}),
+		Quarantined: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "blink", Subsystem: "plugin_manager" + subsystem, Name: "plugin_quarantined",
+			Help: "Number of plugin binaries quarantined after a crash loop.",
+		}, []string{"type"}),
+		LogLinesDropped: promauto.NewCounter(prometheus.CounterOpts{
+			Namespace: "blink", Subsystem: "plugin_manager" + subsystem, Name: "plugin_log_lines_dropped_total",
+			Help: "Plugin log lines dropped by the per-plugin rate limit.",
+		}),
+		Protocols: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "blink", Subsystem: "plugin_manager" + subsystem, Name: "plugin_protocol_subprocesses",
+			Help: "Active plugin subprocesses by negotiated protocol version, to plan version deprecations.",
+		}, []string{"type", "version"}),
+		ReconcileDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "blink", Subsystem: "plugin_manager" + subsystem, Name: "reconcile_duration_seconds",
+			Help:    "Time taken by one reconcile of the plugin directory.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		FilesScanned: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "blink", Subsystem: "plugin_manager" + subsystem, Name: "reconcile_files_scanned_total",
+			Help: "Plugin binaries examined by reconcile, by how their checksum was obtained (cached, hashed, error).",
+		}, []string{"result"}),
 	}
 }
@@ -0,0 +1,57 @@
+package pluginmgr
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNegotiateProtocolVersions(t *testing.T) {
+	dir := t.TempDir()
+	installHelper(t, dir, "old", "1")
+	installHelper(t, dir, "new", "1,2")
+	adapter := &helperAdapter{v2: true}
+	m, _ := newHelperManager[stubPlugin](t, dir, adapter)
+	gauge := func(version string) float64 {
+		return testutil.ToFloat64(helperTestMetrics.Protocols.WithLabelValues("helper", version))
+	}
+	v1, v2 := gauge("1"), gauge("2")
+
+	if err := m.reconcile("test"); err != nil {
+		t.Fatal(err)
+	}
+	protocols := make(map[string]int)
+	for _, st := range m.Plugins() {
+		protocols[st.Name] = st.Protocol
+	}
+	if protocols["old"] != 1 || protocols["new"] != 2 {
+		t.Fatalf("negotiated %v, want old on v1 and new on v2 side by side", protocols)
+	}
+	if adapter.handshakes[1] != 1 || adapter.handshakes[2] != 1 {
+		t.Fatalf("handshakes by version %v, want one through each", adapter.handshakes)
+	}
+	if d1, d2 := gauge("1")-v1, gauge("2")-v2; d1 != 1 || d2 != 1 {
+		t.Fatalf("subprocesses by protocol rose by v1=%v v2=%v, want 1 each", d1, d2)
+	}
+
+	// A host that drops v2 still serves both binaries over v1.
+	m.mu.RLock()
+	handles := maps.Clone(m.plugin_handles)
+	m.mu.RUnlock()
+	for path, hs := range handles {
+		m.remove(path, hs)
+	}
+	if d1, d2 := gauge("1")-v1, gauge("2")-v2; d1 != 0 || d2 != 0 {
+		t.Fatalf("subprocesses by protocol after removal rose by v1=%v v2=%v, want 0", d1, d2)
+	}
+	v1Only, _ := newHelperManager[stubPlugin](t, dir, &helperAdapter{})
+	if err := v1Only.reconcile("test"); err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range v1Only.Plugins() {
+		if st.Protocol != 1 {
+			t.Errorf("%s negotiated v%d with a v1-only host, want v1", st.Name, st.Protocol)
+		}
+	}
+}
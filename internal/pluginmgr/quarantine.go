@@ -0,0 +1,184 @@
+package pluginmgr
+
+import (
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/harishhary/blink/internal/helpers"
+)
+
+// A binary that crashes or fails to start defaultCrashLoopFailures times within defaultCrashLoopWindow is quarantined.
+const (
+	defaultCrashLoopFailures = 5
+	defaultCrashLoopWindow   = 10 * time.Minute
+)
+
+// quarantine records a binary taken out of rotation after a crash loop. It is not started again
+// until its revision changes or an operator clears it.
+type quarantine struct {
+	revision string
+	since    time.Time
+	failures int
+	reason   string
+}
+
+// SetCrashLoop sets how many failures (crashes plus failed starts) within window quarantine a binary.
+// Values ≤ 0 keep the defaults of 5 failures in 10 minutes. Must be called before Start.
+func (m *PluginManager[T]) SetCrashLoop(maxFailures int, window time.Duration) {
+	if maxFailures > 0 {
+		m.crashMax = maxFailures
+	}
+	if window > 0 {
+		m.crashWindow = window
+	}
+}
+
+// revision identifies what a binary runs as: its hash, the resolved config sent in Init and the raw
+// sidecar, which also carries metadata that never reaches the config. Backoff and quarantine both
+// reset when it changes.
+func revision(path, hash, cfgHash string) string {
+	return hash + ":" + cfgHash + ":" + sidecarChecksum(path)
+}
+
+func sidecarChecksum(binPath string) string {
+	for _, ext := range []string{".yaml", ".yml"} {
+		if h, err := helpers.BinaryChecksum(binPath + ext); err == nil {
+			return h
+		}
+	}
+	return ""
+}
+
+// recordFailure notes a crash or failed start of path and quarantines it once the failures inside the
+// crash-loop window reach the limit. It returns the failures in the window and whether path was quarantined.
+// Callers must hold m.mu.
+func (m *PluginManager[T]) recordFailure(path, rev, reason string) (int, bool) {
+	now := time.Now()
+	recent := slices.DeleteFunc(m.crashes[path], func(t time.Time) bool { return now.Sub(t) > m.crashWindow })
+	recent = append(recent, now)
+	if len(recent) < m.crashMax {
+		m.crashes[path] = recent
+		return len(recent), false
+	}
+	delete(m.crashes, path)
+	delete(m.failures, path) // quarantine supersedes the start backoff
+	m.quarantined[path] = &quarantine{revision: rev, since: now, failures: len(recent), reason: reason}
+	m.metrics.Quarantined.WithLabelValues(m.adapter.PluginKey()).Set(float64(len(m.quarantined)))
+	return len(recent), true
+}
+
+// quarantine logs and publishes a quarantine that recordFailure has just placed on path.
+func (m *PluginManager[T]) quarantine(path, hash, cfgHash, reason string, failures int) {
+	m.log.ErrorF("%s %s quarantined after %d failures in %v (last: %s); replace the binary or clear it to retry",
+		m.adapter.PluginKey(), path, failures, m.crashWindow, reason)
+	m.emit(Event{Kind: EventQuarantined, Path: path, Hash: hash, ConfigHash: cfgHash, Reason: reason, Failures: failures})
+}
+
+// isQuarantined reports whether path is quarantined at rev. A quarantine placed on an older revision
+// is lifted, since the binary or its config has changed since it crash-looped.
+func (m *PluginManager[T]) isQuarantined(path, rev string) bool {
+	m.mu.Lock()
+	q := m.quarantined[path]
+	if q != nil && q.revision != rev {
+		m.release(path)
+	}
+	m.mu.Unlock()
+
+	if q == nil {
+		return false
+	}
+	if q.revision == rev {
+		return true
+	}
+	m.log.Info("%s %s released from quarantine: binary or config changed", m.adapter.PluginKey(), path)
+	m.emit(Event{Kind: EventReleased, Path: path, Reason: "binary or config changed"})
+	return false
+}
+
+// release drops every failure record for path. Callers must hold m.mu.
+func (m *PluginManager[T]) release(path string) {
+	delete(m.quarantined, path)
+	delete(m.crashes, path)
+	delete(m.failures, path)
+	m.metrics.Quarantined.WithLabelValues(m.adapter.PluginKey()).Set(float64(len(m.quarantined)))
+}
+
+// ClearQuarantine lifts the quarantine on the binary with the given file name (or path) so the next
+// reconcile starts it again. It reports whether such a quarantine existed.
+func (m *PluginManager[T]) ClearQuarantine(name string) bool {
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.dir, filepath.Base(name))
+	}
+	m.mu.Lock()
+	_, ok := m.quarantined[path]
+	if ok {
+		m.release(path)
+	}
+	m.mu.Unlock()
+
+	if ok {
+		m.log.Info("%s %s released from quarantine by operator", m.adapter.PluginKey(), path)
+		m.emit(Event{Kind: EventReleased, Path: path, Reason: "operator"})
+	}
+	return ok
+}
+
+// forget drops the failure records, resolved configs and log budgets of binaries that are no longer in the directory.
+func (m *PluginManager[T]) forget(seen map[string]struct{}) {
+	var removed []string
+	m.mu.Lock()
+	for path := range m.failures {
+		if _, ok := seen[path]; !ok {
+			delete(m.failures, path)
+		}
+	}
+	for path := range m.crashes {
+		if _, ok := seen[path]; !ok {
+			delete(m.crashes, path)
+		}
+	}
+	for path := range m.resolved {
+		if _, ok := seen[path]; !ok {
+			delete(m.resolved, path)
+		}
+	}
+	for path := range m.rolledBack {
+		if _, ok := seen[path]; !ok {
+			delete(m.rolledBack, path)
+		}
+	}
+	for path := range m.logLimits {
+		if _, ok := seen[path]; !ok {
+			delete(m.logLimits, path)
+		}
+	}
+	for path := range m.quarantined {
+		if _, ok := seen[path]; !ok {
+			removed = append(removed, path)
+			m.release(path)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, path := range removed {
+		m.emit(Event{Kind: EventRemoved, Path: path, Reason: "quarantined binary deleted"})
+	}
+}
+
+// emit records a lifecycle transition in metrics and hands it to the event sink, if any.
+func (m *PluginManager[T]) emit(e Event) {
+	e.Time = time.Now()
+	e.Host = hostname
+	e.Type = m.adapter.PluginKey()
+	m.metrics.Events.WithLabelValues(e.Type, string(e.Kind)).Inc()
+	if m.events != nil {
+		m.events.Publish(e)
+	}
+}
+
+// handleEvent fills an Event's identity from a running handle.
+func handleEvent(kind EventKind, h *PluginHandle, reason string) Event {
+	return Event{Kind: kind, ID: h.ID, Name: h.Name, Path: h.BinPath, Hash: h.Hash, ConfigHash: h.CfgHash, Reason: reason}
+}
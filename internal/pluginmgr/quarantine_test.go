@@ -0,0 +1,116 @@
+package pluginmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	plugin "github.com/hashicorp/go-plugin"
+
+	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/messaging"
+)
+
+type stubPlugin struct{}
+
+func (stubPlugin) Name() string        { return "stub" }
+func (stubPlugin) Description() string { return "" }
+func (stubPlugin) Enabled() bool       { return true }
+func (stubPlugin) Checksum() string    { return "" }
+
+type stubAdapter struct{}
+
+func (stubAdapter) PluginKey() string                { return "stub" }
+func (stubAdapter) MagicValue() string               { return "stub" }
+func (stubAdapter) Protocols() map[int]plugin.Plugin { return nil }
+func (stubAdapter) Handshake(context.Context, interface{}, int, string, string, ResolvedConfig) (stubPlugin, PluginLifecycle, string, string, error) {
+	panic("a crashing binary never reaches the handshake")
+}
+func (stubAdapter) Config(string) (PluginConfig, error) { return PluginConfig{}, nil }
+func (stubAdapter) IsEnabled(*PluginHandle) bool        { return true }
+func (stubAdapter) Workers(string) (int, int)           { return 1, 1 }
+
+type recordingSink struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (s *recordingSink) Publish(e Event) {
+	s.mu.Lock()
+	s.events = append(s.events, e)
+	s.mu.Unlock()
+}
+
+func (s *recordingSink) kinds() []EventKind {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]EventKind, len(s.events))
+	for i, e := range s.events {
+		out[i] = e.Kind
+	}
+	return out
+}
+
+func TestCrashLoopQuarantine(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "broken")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	m := NewPluginManager[stubPlugin](logger.New("pluginmgr-test", "dev"), func(messaging.Message) {}, dir, stubAdapter{}, NewPluginManagerMetrics("_test"))
+	m.SetCrashLoop(3, time.Minute)
+	sink := &recordingSink{}
+	m.SetEventSink(sink)
+
+	attempt := func() {
+		t.Helper()
+		m.mu.Lock()
+		if f := m.failures[path]; f != nil {
+			f.nextRetry = time.Time{} // skip the backoff
+		}
+		m.mu.Unlock()
+		_ = m.startWithBackoff(path, "h1", ResolvedConfig{})
+	}
+	for range 3 {
+		attempt()
+	}
+
+	st := m.Plugins()
+	if len(st) != 1 || !st[0].Quarantined || st[0].StartFailures != 3 {
+		t.Fatalf("status after 3 failures = %+v, want one quarantined entry", st)
+	}
+	want := []EventKind{EventCrashed, EventCrashed, EventCrashed, EventQuarantined}
+	if got := sink.kinds(); !slices.Equal(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+
+	// Quarantined at the same revision: no further start attempts.
+	attempt()
+	if got := len(sink.kinds()); got != len(want) {
+		t.Fatalf("quarantined plugin was started again: %v", sink.kinds())
+	}
+
+	// A new binary lifts the quarantine and counts from zero.
+	_ = m.startWithBackoff(path, "h2", ResolvedConfig{})
+	want = append(want, EventReleased, EventCrashed)
+	if got := sink.kinds(); !slices.Equal(got, want) {
+		t.Fatalf("events after new revision = %v, want %v", got, want)
+	}
+	if st := m.Plugins(); len(st) != 1 || st[0].Quarantined || st[0].StartFailures != 1 {
+		t.Fatalf("status after new revision = %+v, want one backoff entry", st)
+	}
+
+	if m.ClearQuarantine("broken") {
+		t.Fatal("ClearQuarantine reported a quarantine that does not exist")
+	}
+	m.mu.Lock()
+	m.quarantined[path] = &quarantine{revision: "x"}
+	m.mu.Unlock()
+	if !m.ClearQuarantine("broken") || len(m.Plugins()) != 0 {
+		t.Fatalf("ClearQuarantine did not drop the plugin's failure records: %+v", m.Plugins())
+	}
+}
@@ -0,0 +1,89 @@
+package pluginmgr
+
+import (
+	"fmt"
+	"path/filepath"
+
+	plugin "github.com/hashicorp/go-plugin"
+
+	"github.com/harishhary/blink/internal/pools"
+	"github.com/harishhary/blink/pkg/plugindebug"
+)
+
+// SetReattachFile makes the manager adopt the plugins listed in a plugindebug reattach file instead of
+// spawning the binaries of the same name. The file is re-read on every reconcile, so entries added or
+// removed by a debugged plugin apply within one poll interval. Must be called before Start.
+func (m *PluginManager[T]) SetReattachFile(path string) {
+	m.reattachFile = path
+}
+
+// adopt merges the reattach file into the scanned binaries. An adopted plugin's hash names the process it
+// runs in, so restarting it under the debugger, or removing its entry, replaces the workers like a new binary.
+// If the file cannot be read, e.g. mid-write, the plugins adopted from the last good read stay adopted.
+func (m *PluginManager[T]) adopt(binaries []binary) []binary {
+	if m.reattachFile == "" {
+		return binaries
+	}
+	f, err := plugindebug.ReadFile(m.reattachFile)
+	if err != nil {
+		m.log.ErrorF("reattach file: %v", err)
+		m.mu.RLock()
+		adopted := m.adopted
+		m.mu.RUnlock()
+		for path, r := range adopted {
+			binaries = withAdopted(binaries, path, r)
+		}
+		return binaries
+	}
+	adopted := make(map[string]plugindebug.Reattach, len(f))
+	for name, r := range f {
+		path := filepath.Join(m.dir, filepath.Base(name))
+		adopted[path] = r
+		binaries = withAdopted(binaries, path, r)
+	}
+	m.mu.Lock()
+	m.adopted = adopted
+	m.mu.Unlock()
+	return binaries
+}
+
+// withAdopted puts the plugin adopted at path in place of the binary scanned there, or appends it.
+func withAdopted(binaries []binary, path string, r plugindebug.Reattach) []binary {
+	b := binary{path: path, hash: fmt.Sprintf("reattach:%d:%s", r.Pid, r.Addr)}
+	if i := indexBinary(binaries, path); i >= 0 {
+		binaries[i] = b
+		return binaries
+	}
+	return append(binaries, b)
+}
+
+func indexBinary(binaries []binary, path string) int {
+	for i, b := range binaries {
+		if b.path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// reattachConfig returns the client config for an adopted plugin at path, or nil if path is spawned.
+func (m *PluginManager[T]) reattachConfig(path string) (*plugin.ReattachConfig, error) {
+	m.mu.RLock()
+	r, ok := m.adopted[path]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+	return r.Config()
+}
+
+// limits returns the clamped worker bounds for path. An adopted plugin is a single process.
+func (m *PluginManager[T]) limits(path string) (int, int) {
+	m.mu.RLock()
+	_, adopted := m.adopted[path]
+	m.mu.RUnlock()
+	if adopted {
+		return 1, 1
+	}
+	return pools.ClampLimits(m.adapter.Workers(path))
+}
@@ -0,0 +1,70 @@
+package pluginmgr
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/messaging"
+	"github.com/harishhary/blink/pkg/plugindebug"
+)
+
+func TestAdoptReattachFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(t.TempDir(), "reattach.json")
+	m := NewPluginManager[stubPlugin](logger.New("pluginmgr-test", "dev"), func(messaging.Message) {}, dir, stubAdapter{}, builtinTestMetrics)
+	m.SetReattachFile(file)
+
+	spawned := []binary{{path: filepath.Join(dir, "rule"), hash: "h1"}, {path: filepath.Join(dir, "other"), hash: "h2"}}
+	if got := m.adopt(append([]binary(nil), spawned...)); len(got) != 2 || got[0].hash != "h1" {
+		t.Fatalf("missing reattach file changed the scan: %+v", got)
+	}
+
+	write := func(f plugindebug.File) {
+		t.Helper()
+		b, err := json.Marshal(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(plugindebug.File{
+		"rule":  {Protocol: "grpc", ProtocolVersion: 1, Pid: 42, Network: "unix", Addr: "/tmp/plugin1"},
+		"extra": {Protocol: "grpc", ProtocolVersion: 1, Pid: 43, Network: "tcp", Addr: "127.0.0.1:1234"},
+	})
+	got := m.adopt(append([]binary(nil), spawned...))
+	if len(got) != 3 || got[0].hash != "reattach:42:/tmp/plugin1" || got[1].hash != "h2" || got[2].path != filepath.Join(dir, "extra") {
+		t.Fatalf("adopted scan = %+v", got)
+	}
+	if n, max := m.limits(filepath.Join(dir, "rule")); n != 1 || max != 1 {
+		t.Fatalf("adopted limits = %d, %d, want 1, 1", n, max)
+	}
+	rc, err := m.reattachConfig(filepath.Join(dir, "extra"))
+	if err != nil || rc == nil || !rc.Test || rc.Pid != 43 || rc.Addr.String() != "127.0.0.1:1234" {
+		t.Fatalf("reattach config = %+v, %v", rc, err)
+	}
+	if rc, _ := m.reattachConfig(filepath.Join(dir, "other")); rc != nil {
+		t.Fatal("a binary without an entry is adopted")
+	}
+
+	// A corrupt file, e.g. caught mid-write, keeps the plugins adopted from the last good read.
+	if err := os.WriteFile(file, []byte(`{"rule": {`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.adopt(append([]binary(nil), spawned...)); len(got) != 3 || got[0].hash != "reattach:42:/tmp/plugin1" {
+		t.Fatalf("scan with a corrupt reattach file = %+v, want the previous adoptions kept", got)
+	}
+	if rc, _ := m.reattachConfig(filepath.Join(dir, "extra")); rc == nil || rc.Pid != 43 {
+		t.Fatalf("reattach config after a corrupt read = %+v, want the previous entry", rc)
+	}
+
+	// Dropping the entry hands the path back to its binary.
+	write(plugindebug.File{})
+	if got := m.adopt(append([]binary(nil), spawned...)); len(got) != 2 || got[0].hash != "h1" {
+		t.Fatalf("scan after the entry was removed = %+v", got)
+	}
+}
@@ -0,0 +1,78 @@
+package pluginmgr
+
+import (
+	"testing"
+
+	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/messaging"
+)
+
+// TestRollbackRestoresHandles stages a params change the way a canary pool would, leaving the old
+// workers running, then rolls it back and checks the manager supervises the old generation again and
+// does not stage the rolled-back revision a second time.
+func TestRollbackRestoresHandles(t *testing.T) {
+	dir := t.TempDir()
+	path := installHelper(t, dir, "helper", "1")
+	adapter := &helperAdapter{cfg: PluginConfig{Params: map[string]string{"threshold": "1"}}}
+	var updates []UpdateMessage[stubPlugin]
+	notify := func(msg messaging.Message) {
+		if u, ok := msg.(UpdateMessage[stubPlugin]); ok {
+			updates = append(updates, u)
+		}
+	}
+	m := NewPluginManager[stubPlugin](logger.New("pluginmgr-test", "dev"), notify, dir, adapter, helperTestMetrics)
+	t.Cleanup(func() {
+		m.mu.RLock()
+		var handles []*PluginHandle
+		for _, hs := range m.plugin_handles {
+			handles = append(handles, hs...)
+		}
+		m.mu.RUnlock()
+		for _, u := range updates {
+			for _, stop := range u.Stops {
+				stop()
+			}
+		}
+		for _, h := range handles {
+			m.kill(h)
+		}
+	})
+
+	if err := m.reconcile("test"); err != nil {
+		t.Fatal(err)
+	}
+	m.mu.RLock()
+	original := m.plugin_handles[path]
+	m.mu.RUnlock()
+
+	adapter.setConfig(PluginConfig{Params: map[string]string{"threshold": "2"}})
+	if err := m.reconcile("test"); err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 1 || updates[0].OnRolledBack == nil {
+		t.Fatalf("%d update(s) after a params change, want one that can be rolled back", len(updates))
+	}
+	updates[0].OnRolledBack()
+
+	m.mu.RLock()
+	current := m.plugin_handles[path]
+	m.mu.RUnlock()
+	if len(current) != len(original) || current[0] != original[0] {
+		t.Fatalf("handles after rollback = %v, want the original generation %v", current, original)
+	}
+
+	if err := m.reconcile("test"); err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 1 {
+		t.Fatalf("%d updates after reconciling the rolled-back revision, want it left alone", len(updates))
+	}
+
+	adapter.setConfig(PluginConfig{Params: map[string]string{"threshold": "3"}})
+	if err := m.reconcile("test"); err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 2 {
+		t.Fatalf("%d updates after a further params change, want it staged", len(updates))
+	}
+}
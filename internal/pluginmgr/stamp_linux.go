@@ -0,0 +1,15 @@
+package pluginmgr
+
+import (
+	"os"
+	"syscall"
+)
+
+func stampOf(info os.FileInfo) fileStamp {
+	st := fileStamp{size: info.Size(), mtime: info.ModTime().UnixNano()}
+	if sys, ok := info.Sys().(*syscall.Stat_t); ok {
+		st.dev, st.ino = uint64(sys.Dev), sys.Ino
+		st.ctime = sys.Ctim.Nano()
+	}
+	return st
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package pluginmgr
+
+import "os"
+
+// Without an inode or ctime, size and mtime have to do.
+func stampOf(info os.FileInfo) fileStamp {
+	return fileStamp{size: info.Size(), mtime: info.ModTime().UnixNano()}
+}
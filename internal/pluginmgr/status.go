@@ -0,0 +1,86 @@
+package pluginmgr
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// PluginStatus is a point-in-time view of one plugin binary, as reported by Plugins.
+type PluginStatus struct {
+	Type          string    `json:"type"`
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Path          string    `json:"path"`
+	Hash          string    `json:"hash"`
+	ConfigHash    string    `json:"config_hash"`
+	Protocol      int       `json:"protocol,omitempty"` // negotiated plugin protocol version
+	Adopted       bool      `json:"adopted,omitempty"`  // attached to a plugin started outside the host
+	Workers       int       `json:"workers"`
+	Healthy       int       `json:"healthy"` // workers whose last ping succeeded
+	Restarting    bool      `json:"restarting,omitempty"`
+	StartFailures int       `json:"start_failures,omitempty"`
+	NextRetry     time.Time `json:"next_retry,omitzero"`
+	// Quarantined is set once the binary crash-looped; it is not started again until it changes or is cleared.
+	Quarantined      bool      `json:"quarantined,omitempty"`
+	QuarantinedAt    time.Time `json:"quarantined_at,omitzero"`
+	QuarantineReason string    `json:"quarantine_reason,omitempty"`
+}
+
+// Plugins returns every running binary plus those waiting out a start backoff or quarantined, and every
+// in-process plugin under a "builtin:" path, sorted by path.
+func (m *PluginManager[T]) Plugins() []PluginStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]PluginStatus, 0, len(m.plugin_handles)+len(m.failures)+len(m.quarantined)+len(m.builtins))
+	for path, handles := range m.plugin_handles {
+		h := handles[0]
+		st := PluginStatus{
+			Type:       m.adapter.PluginKey(),
+			ID:         h.ID,
+			Name:       h.Name,
+			Path:       path,
+			Hash:       h.Hash,
+			ConfigHash: h.CfgHash,
+			Protocol:   h.Protocol,
+			Adopted:    h.Adopted,
+			Workers:    len(handles),
+		}
+		for _, h := range handles {
+			if h.healthy.Load() {
+				st.Healthy++
+			}
+		}
+		_, st.Restarting = m.restarting[path]
+		out = append(out, st)
+	}
+	for path, f := range m.failures {
+		if _, running := m.plugin_handles[path]; running {
+			continue
+		}
+		_, restarting := m.restarting[path]
+		out = append(out, PluginStatus{
+			Type:          m.adapter.PluginKey(),
+			Path:          path,
+			Restarting:    restarting,
+			StartFailures: f.count,
+			NextRetry:     f.nextRetry,
+		})
+	}
+	for path, q := range m.quarantined {
+		out = append(out, PluginStatus{
+			Type:             m.adapter.PluginKey(),
+			Path:             path,
+			StartFailures:    q.failures,
+			Quarantined:      true,
+			QuarantinedAt:    q.since,
+			QuarantineReason: q.reason,
+		})
+	}
+	for _, b := range m.builtins {
+		out = append(out, b.status(m.adapter.PluginKey()))
+	}
+	slices.SortFunc(out, func(a, b PluginStatus) int { return cmp.Compare(a.Path, b.Path) })
+	return out
+}
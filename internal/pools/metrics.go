@@ -12,6 +12,10 @@ type PoolMetrics struct {
 	drainDuration *prometheus.HistogramVec
 	killSwitches  *prometheus.CounterVec
 	shadowDiffs   *prometheus.CounterVec
+	queueDepth    *prometheus.GaugeVec
+	scaleEvents   *prometheus.CounterVec
+	acquireWait   *prometheus.HistogramVec
+	shed          *prometheus.CounterVec
 }
 
 // Registers and returns Prometheus metrics namespaced under
@@ -38,5 +42,22 @@ func NewPoolMetrics(subsystem string) *PoolMetrics {
 			Namespace: "blink", Subsystem: "pool_" + subsystem,
 			Name: "shadow_diff_total", Help: "Shadow evaluation errors or divergences.",
 		}, []string{"plugin_id"}),
+		queueDepth: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "blink", Subsystem: "pool_" + subsystem,
+			Name: "queue_depth", Help: "Callers blocked waiting for a free worker per pool.",
+		}, []string{"plugin_id", "version"}),
+		scaleEvents: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "blink", Subsystem: "pool_" + subsystem,
+			Name: "scale_events_total", Help: "Workers added or retired by the autoscaler.",
+		}, []string{"plugin_id", "direction"}),
+		acquireWait: promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "blink", Subsystem: "pool_" + subsystem,
+			Name: "acquire_wait_seconds", Help: "Time callers waited for a free worker.",
+			Buckets: []float64{0, .0005, .001, .005, .01, .05, .1, .5, 1, 5},
+		}, []string{"plugin_id", "version"}),
+		shed: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "blink", Subsystem: "pool_" + subsystem,
+			Name: "shed_total", Help: "Calls rejected because the wait queue was full or the acquire timeout fired.",
+		}, []string{"plugin_id", "reason"}),
 	}
 }
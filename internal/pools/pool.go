@@ -1,11 +1,15 @@
 package pools
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"hash/fnv"
 	"log"
+	"maps"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -19,6 +23,9 @@ var ErrPluginNotFound = errors.New("plugin not found")
 // Returned by Call when the plugin was explicitly deregistered (binary was deleted).
 var ErrPluginRemoved = errors.New("plugin removed")
 
+// Returned by Acquire once the pool has started draining.
+var ErrPoolDraining = errors.New("pool draining")
+
 // RolloutMode controls how traffic is split between old and new plugin versions.
 type RolloutMode int
 
@@ -76,52 +83,129 @@ func (k PoolKey) String() string {
 	return k.PluginID + "@" + k.Version
 }
 
-// VersionedPool manages a fixed-size pool of plugin subprocess handles of type T.
+// VersionedPool manages an elastic pool of plugin subprocess handles of type T.
 // Acquire/Release use a channel-based semaphore; handles are stateful gRPC connections
-// and must not be discarded by the GC (no sync.Pool).
+// and must not be discarded by the GC (no sync.Pool). The channel is allocated at
+// MaxWorkers so the worker count can change without replacing it.
 type VersionedPool[T any] struct {
-	key      PoolKey
-	slots    chan T
-	inflight atomic.Int64
-	draining atomic.Bool
+	key       PoolKey
+	slots     chan *Worker[T]
+	scaler    Scaler[T]
+	metrics   *PoolMetrics
+	size      atomic.Int64 // workers owned by the pool, idle or busy
+	inflight  atomic.Int64
+	users     atomic.Int64 // callers inside Acquire or holding a worker; drain waits for this to reach zero
+	waiting   atomic.Int64 // callers blocked in Acquire
+	acquires  atomic.Int64
+	waitNanos atomic.Int64 // total time callers spent blocked in Acquire
+	draining  atomic.Bool
+	closed    atomic.Bool // set once drain has finished; released workers are stopped instead of re-queued
 }
 
-func newVersionedPool[T any](key PoolKey, plugins []T, maxProcs int) *VersionedPool[T] {
-	size := maxProcs
-	if size < len(plugins) {
-		size = len(plugins)
-	}
+func newVersionedPool[T any](key PoolKey, workers []Worker[T], scaler Scaler[T], metrics *PoolMetrics) *VersionedPool[T] {
 	p := &VersionedPool[T]{
-		key:   key,
-		slots: make(chan T, size),
-	}
-	for _, plugin := range plugins {
-		p.slots <- plugin
+		key:     key,
+		slots:   make(chan *Worker[T], MaxWorkers),
+		scaler:  scaler,
+		metrics: metrics,
+	}
+	for i := range workers {
+		if i == MaxWorkers {
+			log.Printf("processpool: %s registered with %d workers, stopping those above %d", key, len(workers), MaxWorkers)
+			for _, w := range workers[i:] {
+				w.stop()
+			}
+			break
+		}
+		p.add(&workers[i])
 	}
 	return p
 }
 
-// Returns a plugin handle for exclusive use. Blocks until one is available or ctx is cancelled.
-// Returns an error if the pool is draining.
-func (p *VersionedPool[T]) Acquire(ctx context.Context) (T, error) {
+// Returns a worker for exclusive use. Blocks until one is available, ctx is cancelled, or
+// policy.AcquireTimeout elapses. Returns ErrPoolDraining if the pool is draining, and a *ShedError
+// when the wait queue is already policy.MaxQueue long or the timeout fires.
+func (p *VersionedPool[T]) Acquire(ctx context.Context, policy QueuePolicy) (*Worker[T], error) {
+	// Counted before the draining check: either drain sees this caller, or this caller sees draining.
+	p.users.Add(1)
 	if p.draining.Load() {
-		var zero T
-		return zero, fmt.Errorf("pool %s is draining", p.key)
+		p.users.Add(-1)
+		return nil, fmt.Errorf("%w: %s", ErrPoolDraining, p.key)
+	}
+	w, err := p.acquire(ctx, policy)
+	if err != nil {
+		p.users.Add(-1)
 	}
+	return w, err
+}
+
+func (p *VersionedPool[T]) acquire(ctx context.Context, policy QueuePolicy) (*Worker[T], error) {
 	select {
-	case plugin := <-p.slots:
-		p.inflight.Add(1)
-		return plugin, nil
+	case w := <-p.slots:
+		p.acquired(0)
+		return w, nil
+	default:
+	}
+
+	// Saturated: queue, unless the queue is already full.
+	if waiting := p.waiting.Add(1); policy.MaxQueue > 0 && waiting > int64(policy.MaxQueue) {
+		p.waiting.Add(-1)
+		return nil, p.shed(ShedQueueFull, policy)
+	}
+	if p.metrics != nil {
+		p.metrics.queueDepth.WithLabelValues(p.key.PluginID, p.key.Version).Inc()
+	}
+	start := time.Now()
+	defer func() {
+		// Recorded whether or not a worker was obtained so the autoscaler sees timeouts as pressure too.
+		p.waiting.Add(-1)
+		p.waitNanos.Add(int64(time.Since(start)))
+		if p.metrics != nil {
+			p.metrics.queueDepth.WithLabelValues(p.key.PluginID, p.key.Version).Dec()
+		}
+	}()
+
+	var timeout <-chan time.Time
+	if policy.AcquireTimeout > 0 {
+		t := time.NewTimer(policy.AcquireTimeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+	select {
+	case w := <-p.slots:
+		p.acquired(time.Since(start))
+		return w, nil
+	case <-timeout:
+		return nil, p.shed(ShedTimeout, policy)
 	case <-ctx.Done():
-		var zero T
-		return zero, ctx.Err()
+		return nil, ctx.Err()
+	}
+}
+
+func (p *VersionedPool[T]) acquired(waited time.Duration) {
+	p.inflight.Add(1)
+	p.acquires.Add(1)
+	if p.metrics != nil {
+		p.metrics.acquireWait.WithLabelValues(p.key.PluginID, p.key.Version).Observe(waited.Seconds())
+	}
+}
+
+func (p *VersionedPool[T]) shed(reason string, policy QueuePolicy) *ShedError {
+	if p.metrics != nil {
+		p.metrics.shed.WithLabelValues(p.key.PluginID, reason).Inc()
 	}
+	return newShedError(p.key.PluginID, reason, policy.Shed)
 }
 
-// Release returns the handle to the pool after use.
-func (p *VersionedPool[T]) Release(plugin T) {
+// Release returns the worker to the pool after use.
+func (p *VersionedPool[T]) Release(w *Worker[T]) {
 	p.inflight.Add(-1)
-	p.slots <- plugin
+	p.slots <- w
+	p.users.Add(-1)
+	if p.closed.Load() {
+		// Released after drain gave up waiting; stop it rather than leave it queued in a dead pool.
+		p.stopIdle()
+	}
 }
 
 // Inflight returns the number of calls currently executing in this pool.
@@ -129,45 +213,130 @@ func (p *VersionedPool[T]) Inflight() int64 {
 	return p.inflight.Load()
 }
 
-// Size returns the total capacity of this pool.
+// Size returns the number of workers currently owned by this pool.
 func (p *VersionedPool[T]) Size() int {
-	return cap(p.slots)
+	return int(p.size.Load())
+}
+
+// Waiting returns the number of callers currently blocked in Acquire.
+func (p *VersionedPool[T]) Waiting() int64 {
+	return p.waiting.Load()
+}
+
+func (p *VersionedPool[T]) add(w *Worker[T]) {
+	p.size.Add(1)
+	p.slots <- w
+	if p.closed.Load() {
+		p.stopIdle()
+	}
+}
+
+// retire takes one idle worker out of rotation and stops it. Busy workers are never touched,
+// so scale-down cannot interrupt an in-flight call. Returns false when no worker is idle.
+func (p *VersionedPool[T]) retire() bool {
+	select {
+	case w := <-p.slots:
+		p.size.Add(-1)
+		go w.stop()
+		return true
+	default:
+		return false
+	}
+}
+
+// stopIdle stops every worker currently idle in the pool.
+func (p *VersionedPool[T]) stopIdle() {
+	for p.retire() {
+	}
 }
 
 // holds a pre-warmed pool that is waiting to be promoted to active via Promote().
 // Used for canary and shadow rollouts where traffic must stay on the old version until the operator explicitly graduates the new version.
 type pendingPromotion struct {
-	key       PoolKey
-	onDrained func()
+	key          PoolKey
+	onDrained    func()
+	onRolledBack func()
+}
+
+// routingState is one immutable snapshot of which pools exist and which of them serve traffic.
+// It is never modified once published; writers clone it, edit the clone and swap the pointer.
+type routingState[T any] struct {
+	pools   map[PoolKey]*VersionedPool[T]
+	active  map[string]PoolKey
+	pending map[string]pendingPromotion
+	removed map[string]struct{}
+	killed  map[string]struct{} // kill switches set by an operator, on top of RoutingConfig
+}
+
+func (s *routingState[T]) clone() *routingState[T] {
+	return &routingState[T]{
+		pools:   maps.Clone(s.pools),
+		active:  maps.Clone(s.active),
+		pending: maps.Clone(s.pending),
+		removed: maps.Clone(s.removed),
+		killed:  maps.Clone(s.killed),
+	}
 }
 
 // Manages VersionedPools keyed by (PluginID, Version).
+//
+// Calls read the routing state through an atomic pointer and take no locks. Register, Promote,
+// Unregister, Remove and drain are serialised on mu and publish a new copy-on-write snapshot.
 type ProcessPool[T any] struct {
-	pools        map[PoolKey]*VersionedPool[T]
-	active       map[string]PoolKey
-	pending      map[string]pendingPromotion
-	removed      map[string]struct{}
+	state        atomic.Pointer[routingState[T]]
+	mu           sync.Mutex // serialises writers of state
 	routing      RoutingConfig
+	queue        QueueConfig
 	drainTimeout time.Duration // drainTimeout ≤ 0 uses 60s.
 	metrics      *PoolMetrics
 }
 
 const defaultDrainTimeout = 60 * time.Second
 
-// Creates a ProcessPool driven by the given RoutingConfig callback.
-func NewProcessPool[T any](routing RoutingConfig, metrics *PoolMetrics, drainTimeout time.Duration) *ProcessPool[T] {
+// Creates a ProcessPool driven by the given RoutingConfig and QueueConfig callbacks. A nil queue
+// leaves every plugin with unbounded waiting and fail-fast shedding.
+func NewProcessPool[T any](routing RoutingConfig, queue QueueConfig, metrics *PoolMetrics, drainTimeout time.Duration) *ProcessPool[T] {
+	if queue == nil {
+		queue = func(string) QueuePolicy { return QueuePolicy{} }
+	}
 	if drainTimeout <= 0 {
 		drainTimeout = defaultDrainTimeout
 	}
-	return &ProcessPool[T]{
-		pools:        make(map[PoolKey]*VersionedPool[T]),
-		active:       make(map[string]PoolKey),
-		pending:      make(map[string]pendingPromotion),
-		removed:      make(map[string]struct{}),
+	pp := &ProcessPool[T]{
 		routing:      routing,
+		queue:        queue,
 		drainTimeout: drainTimeout,
 		metrics:      metrics,
 	}
+	pp.state.Store(&routingState[T]{
+		pools:   make(map[PoolKey]*VersionedPool[T]),
+		active:  make(map[string]PoolKey),
+		pending: make(map[string]pendingPromotion),
+		removed: make(map[string]struct{}),
+		killed:  make(map[string]struct{}),
+	})
+	return pp
+}
+
+// drainRequest is a pool taken out of routing by a state update, drained once the new state is published.
+type drainRequest[T any] struct {
+	key       PoolKey
+	pool      *VersionedPool[T]
+	onDrained func()
+}
+
+// update applies fn to a copy of the current state and publishes the copy. Pools fn returns
+// are drained asynchronously after the swap, so no caller can pick them up from the new state.
+func (pp *ProcessPool[T]) update(fn func(s *routingState[T]) []drainRequest[T]) {
+	pp.mu.Lock()
+	next := pp.state.Load().clone()
+	drains := fn(next)
+	pp.state.Store(next)
+	pp.mu.Unlock()
+
+	for _, d := range drains {
+		go pp.drain(d.key, d.pool, d.onDrained)
+	}
 }
 
 // Register adds a pre-warmed pool for the given key.
@@ -175,112 +344,231 @@ func NewProcessPool[T any](routing RoutingConfig, metrics *PoolMetrics, drainTim
 // Blue-green (default): the new pool is promoted to active immediately and the old pool
 // is drained asynchronously. onDrained is called once the drain completes
 //
-// Canary / Shadow: the new pool is added to pp.pools but active is NOT flipped. The old
+// Canary / Shadow: the new pool is added to the pools but active is NOT flipped. The old
 // pool keeps serving production traffic; the new pool serves only the canary/shadow
 // percentage as found by callCanary/callShadow. Call Promote(pluginID) to graduate the
 // new pool to production and drain the old one.
-func (pp *ProcessPool[T]) Register(key PoolKey, plugins []T, maxProcs int, onDrained func()) {
-	pool := newVersionedPool(key, plugins, maxProcs)
-	pp.pools[key] = pool
-	if pp.metrics != nil {
-		pp.metrics.poolSize.WithLabelValues(key.PluginID, key.Version).Set(float64(pool.Size()))
-	}
+//
+// When scaler is non-nil the pool resizes itself between the scaler's limits; see autoscale.
+// onRolledBack is called if a staged canary/shadow pool is discarded by Rollback instead.
+func (pp *ProcessPool[T]) Register(key PoolKey, workers []Worker[T], scaler Scaler[T], onDrained, onRolledBack func()) {
+	pool := newVersionedPool(key, workers, scaler, pp.metrics)
+	_, mode, _ := pp.routing(key.PluginID)
 
-	// Clear tombstone: plugin has come back (re-deployed after deletion).
-	delete(pp.removed, key.PluginID)
+	pp.update(func(s *routingState[T]) []drainRequest[T] {
+		prev, replaced := s.pools[key]
+		s.pools[key] = pool
 
-	_, mode, _ := pp.routing(key.PluginID)
+		// Clear tombstone: plugin has come back (re-deployed after deletion).
+		delete(s.removed, key.PluginID)
 
-	if mode == RolloutModeCanary || mode == RolloutModeShadow {
-		// Stage the new pool without promoting - preserve active as production.
-		// First registration for this pluginID still needs an active entry.
-		if _, hasActive := pp.active[key.PluginID]; !hasActive {
-			pp.active[key.PluginID] = key
-		} else {
+		// Same version re-registered (e.g. only its params/secrets changed): the key and its routing
+		// stay as they are, only the workers behind it are swapped. Drain the replaced pool object.
+		if replaced {
+			return []drainRequest[T]{{key, prev, onDrained}}
+		}
+
+		if mode == RolloutModeCanary || mode == RolloutModeShadow {
+			// Stage the new pool without promoting - preserve active as production.
+			// First registration for this pluginID still needs an active entry.
+			if _, hasActive := s.active[key.PluginID]; !hasActive {
+				s.active[key.PluginID] = key
+				return nil
+			}
 			// Drain the previous pending pool before replacing it so its subprocess
 			// is killed and its onDrained callback fires. Without this, rapid deploys
-			// in canary mode would orphan intermediate pools in pp.pools indefinitely.
-			if prev, ok := pp.pending[key.PluginID]; ok {
-				if prevPool, ok := pp.pools[prev.key]; ok {
-					go pp.drain(prev.key, prevPool, prev.onDrained)
+			// in canary mode would orphan intermediate pools indefinitely.
+			var drains []drainRequest[T]
+			if prev, ok := s.pending[key.PluginID]; ok {
+				if prevPool, ok := s.pools[prev.key]; ok {
+					drains = append(drains, drainRequest[T]{prev.key, prevPool, prev.onDrained})
 				}
 			}
-			pp.pending[key.PluginID] = pendingPromotion{key: key, onDrained: onDrained}
+			s.pending[key.PluginID] = pendingPromotion{key: key, onDrained: onDrained, onRolledBack: onRolledBack}
+			return drains
 		}
-		return
-	}
-
-	// Blue-green: promote immediately and drain old.
-	oldKey, hasOld := pp.active[key.PluginID]
-	pp.active[key.PluginID] = key
 
-	if hasOld && oldKey != key {
-		if oldPool, ok := pp.pools[oldKey]; ok {
-			go pp.drain(oldKey, oldPool, onDrained)
+		// Blue-green: promote immediately and drain old.
+		oldKey, hasOld := s.active[key.PluginID]
+		s.active[key.PluginID] = key
+		if hasOld && oldKey != key {
+			if oldPool, ok := s.pools[oldKey]; ok {
+				return []drainRequest[T]{{oldKey, oldPool, onDrained}}
+			}
 		}
+		return nil
+	})
+
+	if pp.metrics != nil {
+		pp.metrics.poolSize.WithLabelValues(key.PluginID, key.Version).Set(float64(pool.Size()))
+	}
+	if scaler != nil {
+		go pp.autoscale(pool)
 	}
 }
 
 // Promote graduates the pending canary/shadow pool for pluginID to active production,
 // draining the old pool asynchronously. If no pending pool exists, this is a no-op.
 // Typically called by an operator API or a health-check once canary metrics are green.
-func (pp *ProcessPool[T]) Promote(pluginID string) {
-	p, ok := pp.pending[pluginID]
-	if !ok {
-		return
+// Reports whether a pending pool was promoted.
+func (pp *ProcessPool[T]) Promote(pluginID string) bool {
+	var done func()
+	promoted := false
+	pp.update(func(s *routingState[T]) []drainRequest[T] {
+		p, ok := s.pending[pluginID]
+		if !ok {
+			return nil
+		}
+		delete(s.pending, pluginID)
+		promoted = true
+
+		oldKey, hasOld := s.active[pluginID]
+		s.active[pluginID] = p.key
+		if hasOld && oldKey != p.key {
+			if oldPool, ok := s.pools[oldKey]; ok {
+				return []drainRequest[T]{{oldKey, oldPool, p.onDrained}}
+			}
+		} else {
+			done = p.onDrained
+		}
+		return nil
+	})
+	if done != nil {
+		done()
 	}
-	delete(pp.pending, pluginID)
-
-	oldKey, hasOld := pp.active[pluginID]
-	pp.active[pluginID] = p.key
+	return promoted
+}
 
-	if hasOld && oldKey != p.key {
-		if oldPool, ok := pp.pools[oldKey]; ok {
-			go pp.drain(oldKey, oldPool, p.onDrained)
+// Rollback discards the pending canary/shadow pool for pluginID and drains it; production stays
+// on the active pool. Its onDrained callback is not run, since that would stop the old version's
+// subprocesses, which keep serving; its onRolledBack callback is, so the plugin manager goes back to
+// supervising them. Reports whether a pending pool was discarded.
+func (pp *ProcessPool[T]) Rollback(pluginID string) bool {
+	var discarded *pendingPromotion
+	pp.update(func(s *routingState[T]) []drainRequest[T] {
+		p, ok := s.pending[pluginID]
+		if !ok {
+			return nil
 		}
-	} else if p.onDrained != nil {
-		p.onDrained()
+		delete(s.pending, pluginID)
+		discarded = &p
+		if pool, ok := s.pools[p.key]; ok {
+			return []drainRequest[T]{{p.key, pool, nil}}
+		}
+		return nil
+	})
+	if discarded != nil && discarded.onRolledBack != nil {
+		discarded.onRolledBack()
 	}
+	return discarded != nil
+}
+
+// SetKillSwitch turns an operator kill switch for pluginID on or off. It is OR'ed with the kill
+// switch from RoutingConfig, so turning it off cannot re-enable a plugin its config kills.
+func (pp *ProcessPool[T]) SetKillSwitch(pluginID string, on bool) {
+	pp.update(func(s *routingState[T]) []drainRequest[T] {
+		if on {
+			s.killed[pluginID] = struct{}{}
+		} else {
+			delete(s.killed, pluginID)
+		}
+		return nil
+	})
+}
+
+// Registered reports whether pluginID has an active pool, i.e. whether Call can reach it.
+func (pp *ProcessPool[T]) Registered(pluginID string) bool {
+	_, ok := pp.state.Load().active[pluginID]
+	return ok
+}
+
+// PoolStatus is a point-in-time view of one VersionedPool, as reported by Status.
+type PoolStatus struct {
+	PluginID   string  `json:"plugin_id"`
+	Version    string  `json:"version"`
+	Role       string  `json:"role"` // "active", "pending" or "draining"
+	Mode       string  `json:"mode"`
+	RolloutPct float64 `json:"rollout_pct,omitempty"`
+	KillSwitch bool    `json:"kill_switch"`
+	Size       int     `json:"size"`
+	Inflight   int64   `json:"inflight"`
+	Waiting    int64   `json:"waiting"`
+}
+
+// Pool roles reported in PoolStatus.
+const (
+	RoleActive   = "active"
+	RolePending  = "pending"
+	RoleDraining = "draining"
+)
+
+// Status returns every pool currently known to the ProcessPool, sorted by plugin ID and version.
+func (pp *ProcessPool[T]) Status() []PoolStatus {
+	s := pp.state.Load()
+	out := make([]PoolStatus, 0, len(s.pools))
+	for key, pool := range s.pools {
+		killSwitch, mode, pct := pp.routing(key.PluginID)
+		_, killed := s.killed[key.PluginID]
+		role := RoleDraining
+		if s.active[key.PluginID] == key {
+			role = RoleActive
+		} else if p, ok := s.pending[key.PluginID]; ok && p.key == key {
+			role = RolePending
+		}
+		if pool.draining.Load() {
+			role = RoleDraining
+		}
+		out = append(out, PoolStatus{
+			PluginID:   key.PluginID,
+			Version:    key.Version,
+			Role:       role,
+			Mode:       mode.String(),
+			RolloutPct: pct,
+			KillSwitch: killSwitch || killed,
+			Size:       pool.Size(),
+			Inflight:   pool.Inflight(),
+			Waiting:    pool.Waiting(),
+		})
+	}
+	slices.SortFunc(out, func(a, b PoolStatus) int {
+		return cmp.Or(cmp.Compare(a.PluginID, b.PluginID), cmp.Compare(a.Version, b.Version))
+	})
+	return out
 }
 
 // Unregister removes the active pool for pluginID and drains it asynchronously. Any pending canary/shadow pool for the same pluginID is also drained.
 // Used for transient stops (crash restarts, config disables) - no tombstone is set. Subsequent Call invocations return ErrPluginNotFound until the plugin re-registers.
 func (pp *ProcessPool[T]) Unregister(pluginID string) {
-	if p, ok := pp.pending[pluginID]; ok {
-		delete(pp.pending, pluginID)
-		if pool, ok := pp.pools[p.key]; ok {
-			go pp.drain(p.key, pool, p.onDrained)
-		}
-	}
-	key, ok := pp.active[pluginID]
-	if !ok {
-		return
-	}
-	delete(pp.active, pluginID)
-	if pool, ok := pp.pools[key]; ok {
-		go pp.drain(key, pool, nil)
-	}
+	pp.update(func(s *routingState[T]) []drainRequest[T] {
+		return s.unroute(pluginID)
+	})
 }
 
 // Remove removes the active pool for pluginID, drains it asynchronously, and tombstones the plugin ID. Any pending canary/shadow pool is also drained.
 // Used when a binary is permanently deleted from disk. Subsequent Call invocations return ErrPluginRemoved.
 func (pp *ProcessPool[T]) Remove(pluginID string) {
-	if p, ok := pp.pending[pluginID]; ok {
-		delete(pp.pending, pluginID)
-		if pool, ok := pp.pools[p.key]; ok {
-			go pp.drain(p.key, pool, p.onDrained)
+	pp.update(func(s *routingState[T]) []drainRequest[T] {
+		s.removed[pluginID] = struct{}{}
+		return s.unroute(pluginID)
+	})
+}
+
+// unroute drops the active and pending entries for pluginID and returns their pools for draining.
+func (s *routingState[T]) unroute(pluginID string) []drainRequest[T] {
+	var drains []drainRequest[T]
+	if p, ok := s.pending[pluginID]; ok {
+		delete(s.pending, pluginID)
+		if pool, ok := s.pools[p.key]; ok {
+			drains = append(drains, drainRequest[T]{p.key, pool, p.onDrained})
 		}
 	}
-	key, ok := pp.active[pluginID]
-	if !ok {
-		pp.removed[pluginID] = struct{}{}
-		return
-	}
-	delete(pp.active, pluginID)
-	pp.removed[pluginID] = struct{}{}
-	if pool, ok := pp.pools[key]; ok {
-		go pp.drain(key, pool, nil)
+	if key, ok := s.active[pluginID]; ok {
+		delete(s.active, pluginID)
+		if pool, ok := s.pools[key]; ok {
+			drains = append(drains, drainRequest[T]{key, pool, nil})
+		}
 	}
+	return drains
 }
 
 // DefaultCanaryHashKey is the call-site key used for consistent-hash canary routing.
@@ -298,14 +586,29 @@ func (pp *ProcessPool[T]) Call(ctx context.Context, id, hashKey string, fn func(
 // when routing returns shadow mode for this plugin. shadowFn must operate on independent
 // state (e.g. a cloned input, a separate result variable) to avoid data races with prodFn.
 // Shadow errors are logged and counted but do not affect the return value.
+//
+// The routing state is loaded once per attempt. If the chosen pool started draining because a
+// rollout swapped it out after the load, the call is retried against the newer state.
 func (pp *ProcessPool[T]) CallWithShadow(ctx context.Context, id, hashKey string, prodFn, shadowFn func(context.Context, T) error) error {
 	if err := pp.checkKillSwitch(id); err != nil {
 		return err
 	}
 
-	key, ok := pp.active[id]
+	policy := pp.queue(id)
+	for {
+		s := pp.state.Load()
+		err := pp.call(ctx, s, id, hashKey, policy, prodFn, shadowFn)
+		if errors.Is(err, ErrPoolDraining) && pp.state.Load() != s {
+			continue
+		}
+		return err
+	}
+}
+
+func (pp *ProcessPool[T]) call(ctx context.Context, s *routingState[T], id, hashKey string, policy QueuePolicy, prodFn, shadowFn func(context.Context, T) error) error {
+	key, ok := s.active[id]
 	if !ok {
-		if _, removed := pp.removed[id]; removed {
+		if _, removed := s.removed[id]; removed {
 			return fmt.Errorf("%w: %s", ErrPluginRemoved, id)
 		}
 		return fmt.Errorf("%w: %s", ErrPluginNotFound, id)
@@ -314,21 +617,22 @@ func (pp *ProcessPool[T]) CallWithShadow(ctx context.Context, id, hashKey string
 	_, mode, rolloutPct := pp.routing(id)
 	switch mode {
 	case RolloutModeCanary:
-		return pp.callCanary(ctx, key, id, hashKey, rolloutPct, prodFn)
+		return pp.callCanary(ctx, s, key, id, hashKey, rolloutPct, policy, prodFn)
 	case RolloutModeShadow:
-		return pp.callShadow(ctx, key, id, prodFn, shadowFn)
+		return pp.callShadow(ctx, s, key, id, policy, prodFn, shadowFn)
 	}
 
-	pool, ok := pp.pools[key]
+	pool, ok := s.pools[key]
 	if !ok {
 		return fmt.Errorf("processpool: pool %s not found", key)
 	}
-	return pp.callPool(ctx, pool, prodFn)
+	return pp.callPool(ctx, pool, policy, prodFn)
 }
 
 func (pp *ProcessPool[T]) checkKillSwitch(id string) error {
 	killSwitch, _, _ := pp.routing(id)
-	if killSwitch {
+	_, killed := pp.state.Load().killed[id]
+	if killSwitch || killed {
 		if pp.metrics != nil {
 			pp.metrics.killSwitches.WithLabelValues(id).Inc()
 		}
@@ -337,9 +641,24 @@ func (pp *ProcessPool[T]) checkKillSwitch(id string) error {
 	return nil
 }
 
+// candidate returns a registered, non-draining pool for pluginID other than the production one.
+func (s *routingState[T]) candidate(prodKey PoolKey, id string) (*VersionedPool[T], bool) {
+	if p, ok := s.pending[id]; ok {
+		if pool, ok := s.pools[p.key]; ok && !pool.draining.Load() {
+			return pool, true
+		}
+	}
+	for k, pool := range s.pools {
+		if k.PluginID == id && k != prodKey && !pool.draining.Load() {
+			return pool, true
+		}
+	}
+	return nil, false
+}
+
 // callCanary routes rolloutPct% of calls (via consistent hash on hashKey) to any
 // non-active pool for the same pluginID. Remaining calls go to the production (active) pool.
-func (pp *ProcessPool[T]) callCanary(ctx context.Context, prodKey PoolKey, id, hashKey string, rolloutPct float64, fn func(context.Context, T) error) error {
+func (pp *ProcessPool[T]) callCanary(ctx context.Context, s *routingState[T], prodKey PoolKey, id, hashKey string, rolloutPct float64, policy QueuePolicy, fn func(context.Context, T) error) error {
 	if hashKey == "" {
 		hashKey = DefaultCanaryHashKey
 	}
@@ -348,69 +667,65 @@ func (pp *ProcessPool[T]) callCanary(ctx context.Context, prodKey PoolKey, id, h
 	pct := float64(h.Sum32()%100) + 1 // 1–100
 
 	if pct <= rolloutPct {
-		// Find a registered non-active pool for the same pluginID.
-		for k, pool := range pp.pools {
-			if k.PluginID == id && k != prodKey {
-				return pp.callPool(ctx, pool, fn)
-			}
+		if pool, ok := s.candidate(prodKey, id); ok {
+			return pp.callPool(ctx, pool, policy, fn)
 		}
 	}
 
-	prodPool, ok := pp.pools[prodKey]
+	prodPool, ok := s.pools[prodKey]
 	if !ok {
 		return fmt.Errorf("processpool: production pool %s not found", prodKey)
 	}
-	return pp.callPool(ctx, prodPool, fn)
+	return pp.callPool(ctx, prodPool, policy, fn)
 }
 
 // callShadow calls prodFn on the production pool, then fires shadowFn on any
 // non-active pool for the same pluginID in a background goroutine.
-func (pp *ProcessPool[T]) callShadow(ctx context.Context, prodKey PoolKey, id string, prodFn, shadowFn func(context.Context, T) error) error {
-	prodPool, ok := pp.pools[prodKey]
+func (pp *ProcessPool[T]) callShadow(ctx context.Context, s *routingState[T], prodKey PoolKey, id string, policy QueuePolicy, prodFn, shadowFn func(context.Context, T) error) error {
+	prodPool, ok := s.pools[prodKey]
 	if !ok {
 		return fmt.Errorf("processpool: production pool %s not found", prodKey)
 	}
 
-	prodErr := pp.callPool(ctx, prodPool, prodFn)
+	prodErr := pp.callPool(ctx, prodPool, policy, prodFn)
+	if errors.Is(prodErr, ErrPoolDraining) {
+		// CallWithShadow may retry on a newer state; fire the shadow only once, on the attempt that ran.
+		return prodErr
+	}
 
 	if shadowFn != nil {
-		// Find a registered non-active pool for the same pluginID.
-		for k, sp := range pp.pools {
-			if k.PluginID == id && k != prodKey {
-				shadowPool := sp
-				go func() {
-					plugin, err := shadowPool.Acquire(ctx)
-					if err != nil {
-						log.Printf("processpool: shadow acquire failed for %s: %v", id, err)
-						return
-					}
-					defer shadowPool.Release(plugin)
-					if err := shadowFn(ctx, plugin); err != nil {
-						log.Printf("processpool: shadow error for %s: %v", id, err)
-						if pp.metrics != nil {
-							pp.metrics.shadowDiffs.WithLabelValues(id).Inc()
-						}
+		if shadowPool, ok := s.candidate(prodKey, id); ok {
+			go func() {
+				w, err := shadowPool.Acquire(ctx, policy)
+				if err != nil {
+					log.Printf("processpool: shadow acquire failed for %s: %v", id, err)
+					return
+				}
+				defer shadowPool.Release(w)
+				if err := shadowFn(ctx, w.Plugin); err != nil {
+					log.Printf("processpool: shadow error for %s: %v", id, err)
+					if pp.metrics != nil {
+						pp.metrics.shadowDiffs.WithLabelValues(id).Inc()
 					}
-				}()
-				break
-			}
+				}
+			}()
 		}
 	}
 
 	return prodErr
 }
 
-func (pp *ProcessPool[T]) callPool(ctx context.Context, pool *VersionedPool[T], fn func(context.Context, T) error) error {
-	plugin, err := pool.Acquire(ctx)
+func (pp *ProcessPool[T]) callPool(ctx context.Context, pool *VersionedPool[T], policy QueuePolicy, fn func(context.Context, T) error) error {
+	w, err := pool.Acquire(ctx, policy)
 	if err != nil {
 		return err
 	}
-	defer pool.Release(plugin)
-	return fn(ctx, plugin)
+	defer pool.Release(w)
+	return fn(ctx, w.Plugin)
 }
 
 // marks the VersionedPool as draining, waits for in-flight calls to finish
-// (up to pp.drainTimeout), removes it from pp.pools, then calls onDrained if set.
+// (up to pp.drainTimeout), stops its idle workers, removes it from the routing state, then calls onDrained if set.
 // For graceful updates, onDrained kills the old subprocess after the last in-flight
 // call completes so no call ever hits a dead gRPC connection.
 func (pp *ProcessPool[T]) drain(key PoolKey, pool *VersionedPool[T], onDrained func()) {
@@ -419,25 +734,36 @@ func (pp *ProcessPool[T]) drain(key PoolKey, pool *VersionedPool[T], onDrained f
 	start := time.Now()
 
 	for time.Now().Before(deadline) {
-		if pool.Inflight() == 0 {
+		if pool.users.Load() == 0 {
 			break
 		}
 		time.Sleep(100 * time.Millisecond)
 	}
 
 	elapsed := time.Since(start).Seconds()
-	if pp.metrics != nil {
-		pp.metrics.drainDuration.WithLabelValues(key.PluginID, key.Version).Observe(elapsed)
-		pp.metrics.poolSize.WithLabelValues(key.PluginID, key.Version).Set(0)
-		pp.metrics.poolInflight.WithLabelValues(key.PluginID, key.Version).Set(0)
-	}
-
 	if pool.Inflight() > 0 {
 		log.Printf("processpool: force-killed pool %s after %.1fs drain (%d in-flight)", key, elapsed, pool.Inflight())
 	} else {
 		log.Printf("processpool: drained pool %s in %.2fs", key, elapsed)
 	}
-	delete(pp.pools, key)
+	pool.closed.Store(true)
+	pool.stopIdle()
+
+	// A same-key re-registration has already put a new pool under key; leave its entry and metrics alone.
+	current := false
+	pp.update(func(s *routingState[T]) []drainRequest[T] {
+		if current = s.pools[key] == pool; current {
+			delete(s.pools, key)
+		}
+		return nil
+	})
+	if pp.metrics != nil {
+		pp.metrics.drainDuration.WithLabelValues(key.PluginID, key.Version).Observe(elapsed)
+	}
+	if pp.metrics != nil && current {
+		pp.metrics.poolSize.WithLabelValues(key.PluginID, key.Version).Set(0)
+		pp.metrics.poolInflight.WithLabelValues(key.PluginID, key.Version).Set(0)
+	}
 
 	if onDrained != nil {
 		onDrained()
@@ -0,0 +1,239 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakePlugin records whether its worker was stopped, so a call landing on a stopped worker is caught.
+type fakePlugin struct {
+	version string
+	stopped atomic.Bool
+}
+
+type fakeFleet struct {
+	mu      sync.Mutex
+	plugins []*fakePlugin
+}
+
+func (f *fakeFleet) workers(version string, n int) []Worker[*fakePlugin] {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ws := make([]Worker[*fakePlugin], n)
+	for i := range ws {
+		p := &fakePlugin{version: version}
+		f.plugins = append(f.plugins, p)
+		ws[i] = Worker[*fakePlugin]{Plugin: p, Stop: func() { p.stopped.Store(true) }}
+	}
+	return ws
+}
+
+func (f *fakeFleet) running() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, p := range f.plugins {
+		if !p.stopped.Load() {
+			n++
+		}
+	}
+	return n
+}
+
+// switchableRouting lets a test change the rollout mode while calls are in flight.
+type switchableRouting struct {
+	mode atomic.Int32
+	pct  atomic.Int32
+}
+
+func (r *switchableRouting) config(string) (bool, RolloutMode, float64) {
+	return false, RolloutMode(r.mode.Load()), float64(r.pct.Load())
+}
+
+func (r *switchableRouting) set(mode RolloutMode, pct int) {
+	r.mode.Store(int32(mode))
+	r.pct.Store(int32(pct))
+}
+
+// hammer runs callers that call pluginID until stop is closed. A call that reaches a stopped
+// worker, or fails with an error not allowed by allowed, is reported on errs.
+func hammer(pp *ProcessPool[*fakePlugin], callers int, stop <-chan struct{}, allowed func(error) bool) (*sync.WaitGroup, chan error, *atomic.Int64) {
+	var wg sync.WaitGroup
+	var calls atomic.Int64
+	errs := make(chan error, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hashKey := fmt.Sprintf("tenant-%d", i)
+			check := func(_ context.Context, p *fakePlugin) error {
+				if p.stopped.Load() {
+					return fmt.Errorf("call reached stopped worker of %s", p.version)
+				}
+				time.Sleep(50 * time.Microsecond)
+				return nil
+			}
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				err := pp.CallWithShadow(context.Background(), "p", hashKey, check, check)
+				calls.Add(1)
+				if err != nil && !allowed(err) {
+					select {
+					case errs <- err:
+					default:
+					}
+					return
+				}
+			}
+		}()
+	}
+	return &wg, errs, &calls
+}
+
+func noErrors(error) bool { return false }
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRolloutTransitionsUnderLoad(t *testing.T) {
+	routing := &switchableRouting{}
+	pp := NewProcessPool[*fakePlugin](routing.config, nil, nil, time.Second)
+	fleet := &fakeFleet{}
+	var drained atomic.Int64
+	onDrained := func() { drained.Add(1) }
+
+	pp.Register(PoolKey{PluginID: "p", Version: "v0"}, fleet.workers("v0", 4), nil, onDrained, nil)
+
+	stop := make(chan struct{})
+	wg, errs, calls := hammer(pp, 16, stop, noErrors)
+	step := func(f func()) {
+		before := calls.Load()
+		f()
+		waitFor(t, "calls to progress", func() bool { return calls.Load() > before+100 })
+	}
+
+	// Blue-green swaps, including a same-version re-registration.
+	for i := 1; i <= 5; i++ {
+		v := fmt.Sprintf("v%d", i)
+		step(func() { pp.Register(PoolKey{PluginID: "p", Version: v}, fleet.workers(v, 4), nil, onDrained, nil) })
+	}
+	step(func() {
+		pp.Register(PoolKey{PluginID: "p", Version: "v5"}, fleet.workers("v5", 2), nil, onDrained, nil)
+	})
+
+	// Canary: stage two versions back to back (the first is superseded), then promote.
+	routing.set(RolloutModeCanary, 50)
+	step(func() {
+		pp.Register(PoolKey{PluginID: "p", Version: "v6"}, fleet.workers("v6", 2), nil, onDrained, nil)
+	})
+	step(func() {
+		pp.Register(PoolKey{PluginID: "p", Version: "v7"}, fleet.workers("v7", 2), nil, onDrained, nil)
+	})
+	step(func() { pp.Promote("p") })
+
+	// Shadow: stage and promote.
+	routing.set(RolloutModeShadow, 0)
+	step(func() {
+		pp.Register(PoolKey{PluginID: "p", Version: "v8"}, fleet.workers("v8", 2), nil, onDrained, nil)
+	})
+	step(func() { pp.Promote("p") })
+
+	close(stop)
+	wg.Wait()
+	select {
+	case err := <-errs:
+		t.Fatalf("call failed during rollout: %v", err)
+	default:
+	}
+
+	// v0..v4, the first v5 pool, v6 (superseded) and v5, v7 (promoted over) have drained.
+	waitFor(t, "replaced pools to drain", func() bool { return drained.Load() == 9 })
+	s := pp.state.Load()
+	if len(s.pools) != 1 || s.active["p"] != (PoolKey{PluginID: "p", Version: "v8"}) || len(s.pending) != 0 {
+		t.Fatalf("unexpected final state: pools=%v active=%v pending=%v", s.pools, s.active, s.pending)
+	}
+	if got := fleet.running(); got != 2 {
+		t.Fatalf("running workers = %d, want 2 (the v8 pool)", got)
+	}
+}
+
+func TestUnregisterAndRemoveUnderLoad(t *testing.T) {
+	routing := &switchableRouting{}
+	pp := NewProcessPool[*fakePlugin](routing.config, nil, nil, time.Second)
+	fleet := &fakeFleet{}
+
+	stop := make(chan struct{})
+	gone := func(err error) bool {
+		return errors.Is(err, ErrPluginNotFound) || errors.Is(err, ErrPluginRemoved)
+	}
+	wg, errs, calls := hammer(pp, 16, stop, gone)
+
+	for i := range 20 {
+		v := fmt.Sprintf("v%d", i)
+		pp.Register(PoolKey{PluginID: "p", Version: v}, fleet.workers(v, 2), nil, nil, nil)
+		before := calls.Load()
+		waitFor(t, "calls to progress", func() bool { return calls.Load() > before+20 })
+		if i%2 == 0 {
+			pp.Unregister("p")
+		} else {
+			pp.Remove("p")
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+	select {
+	case err := <-errs:
+		t.Fatalf("unexpected call error: %v", err)
+	default:
+	}
+
+	if err := pp.Call(context.Background(), "p", "", func(context.Context, *fakePlugin) error { return nil }); !errors.Is(err, ErrPluginRemoved) {
+		t.Fatalf("call after Remove = %v, want ErrPluginRemoved", err)
+	}
+	waitFor(t, "all workers to stop", func() bool { return fleet.running() == 0 })
+	waitFor(t, "pools to leave the state", func() bool { return len(pp.state.Load().pools) == 0 })
+}
+
+func TestRollbackNotifiesOwner(t *testing.T) {
+	routing := &switchableRouting{}
+	routing.set(RolloutModeCanary, 50)
+	pp := NewProcessPool[*fakePlugin](routing.config, nil, nil, time.Second)
+	fleet := &fakeFleet{}
+	var drained, rolledBack atomic.Int64
+
+	pp.Register(PoolKey{PluginID: "p", Version: "v0"}, fleet.workers("v0", 2), nil, nil, nil)
+	pp.Register(PoolKey{PluginID: "p", Version: "v1"}, fleet.workers("v1", 2), nil,
+		func() { drained.Add(1) }, func() { rolledBack.Add(1) })
+	if !pp.Rollback("p") {
+		t.Fatal("Rollback found no staged pool")
+	}
+
+	if got := rolledBack.Load(); got != 1 {
+		t.Fatalf("onRolledBack called %d times, want once", got)
+	}
+	waitFor(t, "the canary to drain", func() bool { return fleet.running() == 2 })
+	if got := drained.Load(); got != 0 {
+		t.Fatalf("onDrained called %d times after a rollback, want the production workers left running", got)
+	}
+	if s := pp.state.Load(); s.active["p"] != (PoolKey{PluginID: "p", Version: "v0"}) || len(s.pending) != 0 {
+		t.Fatalf("unexpected state after rollback: active=%v pending=%v", s.active, s.pending)
+	}
+}
@@ -0,0 +1,98 @@
+package pools
+
+import (
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/harishhary/blink/internal/errors"
+)
+
+// Wrapped by every ShedError, so errors.Is(err, ErrShed) detects a shed call.
+var ErrShed = stderrors.New("plugin call shed")
+
+// ShedPolicy tells the caller what to do with a call the pool refused because the plugin is saturated.
+type ShedPolicy int
+
+const (
+	// ShedFailFast (default): treat the shed call as a plugin error.
+	ShedFailFast ShedPolicy = iota
+	// ShedPassThrough: carry on as if the plugin had not been configured (a matcher passes, an alert stage skips it).
+	ShedPassThrough
+	// ShedDeadLetter: send the alert to the stage's dead-letter topic so it is retried later.
+	ShedDeadLetter
+)
+
+func (p ShedPolicy) String() string {
+	switch p {
+	case ShedFailFast:
+		return "fail-fast"
+	case ShedPassThrough:
+		return "pass-through"
+	case ShedDeadLetter:
+		return "dead-letter"
+	default:
+		return fmt.Sprintf("ShedPolicy(%d)", int(p))
+	}
+}
+
+func (p ShedPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *ShedPolicy) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "fail-fast", "":
+		*p = ShedFailFast
+	case "pass-through":
+		*p = ShedPassThrough
+	case "dead-letter":
+		*p = ShedDeadLetter
+	default:
+		return fmt.Errorf("unknown shed policy %q", string(b))
+	}
+	return nil
+}
+
+// QueuePolicy bounds how callers wait for a free worker of one plugin.
+type QueuePolicy struct {
+	MaxQueue       int           // callers allowed to wait at once; 0 = unbounded
+	AcquireTimeout time.Duration // longest a caller waits; 0 = until its context ends
+	Shed           ShedPolicy    // returned to the caller in the ShedError
+}
+
+// func stub that returns the per-plugin queue policy. Return the zero value for unbounded waiting with fail-fast shedding.
+type QueueConfig func(pluginID string) QueuePolicy
+
+// Shed reasons, also used as the "reason" label of the shed metric.
+const (
+	ShedQueueFull = "queue_full"
+	ShedTimeout   = "timeout"
+)
+
+// ShedError is returned by Call when the pool refuses a call instead of queueing it further.
+// It satisfies errors.Error so pool wrappers can hand it back unchanged through errors.NewE.
+type ShedError struct {
+	*errors.BaseError
+	PluginID string
+	Reason   string
+	Policy   ShedPolicy
+}
+
+func newShedError(pluginID, reason string, policy ShedPolicy) *ShedError {
+	return &ShedError{
+		BaseError: errors.NewF("%s: %s (%s, policy %s)", ErrShed, pluginID, reason, policy),
+		PluginID:  pluginID,
+		Reason:    reason,
+		Policy:    policy,
+	}
+}
+
+func (e *ShedError) Unwrap() error { return ErrShed }
+
+// AsShed reports whether err is a ShedError and returns it.
+func AsShed(err error) (*ShedError, bool) {
+	var shed *ShedError
+	ok := stderrors.As(err, &shed)
+	return shed, ok
+}
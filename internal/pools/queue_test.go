@@ -0,0 +1,48 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAcquireSheds(t *testing.T) {
+	pool := newVersionedPool(PoolKey{PluginID: "p", Version: "1"}, []Worker[int]{{Plugin: 1}}, nil, nil)
+	busy, err := pool.Acquire(context.Background(), QueuePolicy{})
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	_, err = pool.Acquire(context.Background(), QueuePolicy{AcquireTimeout: 10 * time.Millisecond, Shed: ShedDeadLetter})
+	shed, ok := AsShed(err)
+	if !ok || shed.Reason != ShedTimeout || shed.Policy != ShedDeadLetter {
+		t.Fatalf("want timeout shed with dead-letter policy, got %v", err)
+	}
+	if !errors.Is(err, ErrShed) {
+		t.Fatalf("shed error does not wrap ErrShed")
+	}
+
+	// Park one waiter so the queue is full for the next caller.
+	waiter := make(chan error, 1)
+	go func() {
+		w, err := pool.Acquire(context.Background(), QueuePolicy{MaxQueue: 1})
+		if err == nil {
+			pool.Release(w)
+		}
+		waiter <- err
+	}()
+	for pool.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err = pool.Acquire(context.Background(), QueuePolicy{MaxQueue: 1})
+	if shed, ok := AsShed(err); !ok || shed.Reason != ShedQueueFull {
+		t.Fatalf("want queue_full shed, got %v", err)
+	}
+
+	pool.Release(busy)
+	if err := <-waiter; err != nil {
+		t.Fatalf("queued caller: %v", err)
+	}
+}
@@ -7,6 +7,7 @@ type PluginRouting struct {
 	KillSwitch bool
 	Mode       RolloutMode
 	RolloutPct float64
+	Queue      *QueuePolicy // nil uses the defaults passed to QueueConfig
 }
 
 // RoutingTable is a thread-safe map from pluginID to PluginRouting.
@@ -49,3 +50,17 @@ func (t *RoutingTable) Config() RoutingConfig {
 		return r.KillSwitch, r.Mode, r.RolloutPct
 	}
 }
+
+// Returns a QueueConfig closure that reads live from the table, falling back to defaults for
+// plugins without an entry or without a Queue override. Pass this to NewProcessPool.
+func (t *RoutingTable) QueueConfig(defaults QueuePolicy) QueueConfig {
+	return func(pluginID string) QueuePolicy {
+		t.mu.RLock()
+		r, ok := t.entries[pluginID]
+		t.mu.RUnlock()
+		if !ok || r.Queue == nil {
+			return defaults
+		}
+		return *r.Queue
+	}
+}
@@ -0,0 +1,158 @@
+package pools
+
+import (
+	"log"
+	"time"
+)
+
+// MaxWorkers caps the number of workers a single VersionedPool can hold.
+const MaxWorkers = 256
+
+// Worker is one plugin handle served by a VersionedPool, paired with the function that stops its subprocess.
+type Worker[T any] struct {
+	Plugin T
+	Stop   func()
+}
+
+func (w *Worker[T]) stop() {
+	if w.Stop != nil {
+		w.Stop()
+	}
+}
+
+// Scaler is implemented by the owner of the plugin subprocesses (the plugin manager) and lets a
+// VersionedPool grow itself. Shrinking goes through Worker.Stop.
+type Scaler[T any] interface {
+	// Limits returns the current worker bounds. It is read on every evaluation, so limit
+	// changes (e.g. min_procs/max_procs edited in a rule's YAML) apply without a restart.
+	Limits() (minProcs, maxProcs int)
+	// Grow starts one more worker.
+	Grow() (Worker[T], error)
+}
+
+const (
+	scaleInterval = 2 * time.Second
+	// Average time a call spent queued in Acquire over one interval above which the pool grows.
+	scaleUpWait = 5 * time.Millisecond
+	// Consecutive quiet intervals (no queueing, under half the workers busy) before one worker is retired.
+	scaleDownQuiet = 15
+)
+
+// intervalStats is the pool activity observed over one scaleInterval.
+type intervalStats struct {
+	waiting  int64         // callers blocked in Acquire at the end of the interval
+	acquires int64         // successful acquires during the interval
+	waited   time.Duration // time callers spent blocked in Acquire during the interval
+	inflight int64         // calls executing at the end of the interval
+}
+
+func (s intervalStats) saturated() bool {
+	if s.waiting > 0 {
+		return true
+	}
+	return s.acquires > 0 && s.waited/time.Duration(s.acquires) >= scaleUpWait
+}
+
+func (s intervalStats) quiet(size int) bool {
+	return s.waiting == 0 && s.waited == 0 && s.inflight*2 < int64(size)
+}
+
+// scaleStep returns how many workers to add (> 0) or retire (< 0). Limits always win; within them the
+// pool grows as soon as callers queue and shrinks by one worker after quietFor quiet intervals.
+func scaleStep(size, minProcs, maxProcs int, s intervalStats, quietFor int) int {
+	switch {
+	case size < minProcs:
+		return minProcs - size
+	case size > maxProcs:
+		return maxProcs - size
+	case s.saturated() && size < maxProcs:
+		return int(min(max(s.waiting, 1), int64(maxProcs-size)))
+	case quietFor >= scaleDownQuiet && size > minProcs:
+		return -1
+	}
+	return 0
+}
+
+// ClampLimits normalises worker bounds: min is at least 1, max at least min, and both at most MaxWorkers.
+func ClampLimits(minProcs, maxProcs int) (int, int) {
+	minProcs = min(max(minProcs, 1), MaxWorkers)
+	maxProcs = min(max(maxProcs, minProcs), MaxWorkers)
+	return minProcs, maxProcs
+}
+
+// autoscale resizes pool between its scaler's limits until the pool starts draining.
+func (pp *ProcessPool[T]) autoscale(pool *VersionedPool[T]) {
+	t := time.NewTicker(scaleInterval)
+	defer t.Stop()
+
+	key := pool.key
+	var lastAcquires, lastWait int64
+	quietFor := 0
+	for range t.C {
+		if pool.draining.Load() {
+			return
+		}
+
+		acquires, waitNanos := pool.acquires.Load(), pool.waitNanos.Load()
+		s := intervalStats{
+			waiting:  pool.waiting.Load(),
+			acquires: acquires - lastAcquires,
+			waited:   time.Duration(waitNanos - lastWait),
+			inflight: pool.inflight.Load(),
+		}
+		lastAcquires, lastWait = acquires, waitNanos
+
+		size := pool.Size()
+		if s.quiet(size) {
+			quietFor++
+		} else {
+			quietFor = 0
+		}
+
+		minProcs, maxProcs := ClampLimits(pool.scaler.Limits())
+		step := scaleStep(size, minProcs, maxProcs, s, quietFor)
+		switch {
+		case step > 0:
+			pp.grow(pool, step)
+		case step < 0:
+			retired := 0
+			for ; retired < -step && pool.retire(); retired++ {
+			}
+			if retired > 0 {
+				quietFor = 0
+				log.Printf("processpool: scaled %s down to %d worker(s)", key, pool.Size())
+				if pp.metrics != nil {
+					pp.metrics.scaleEvents.WithLabelValues(key.PluginID, "down").Add(float64(retired))
+				}
+			}
+		}
+
+		if pp.metrics != nil {
+			pp.metrics.poolSize.WithLabelValues(key.PluginID, key.Version).Set(float64(pool.Size()))
+			pp.metrics.poolInflight.WithLabelValues(key.PluginID, key.Version).Set(float64(pool.Inflight()))
+		}
+	}
+}
+
+// grow asks the scaler for n more workers. Spawning is slow, so the pool is re-checked after each one.
+func (pp *ProcessPool[T]) grow(pool *VersionedPool[T], n int) {
+	added := 0
+	for ; added < n; added++ {
+		w, err := pool.scaler.Grow()
+		if err != nil {
+			log.Printf("processpool: scale up %s failed: %v", pool.key, err)
+			break
+		}
+		if pool.draining.Load() || pool.Size() >= MaxWorkers {
+			w.stop()
+			break
+		}
+		pool.add(&w)
+	}
+	if added > 0 {
+		log.Printf("processpool: scaled %s up to %d worker(s)", pool.key, pool.Size())
+		if pp.metrics != nil {
+			pp.metrics.scaleEvents.WithLabelValues(pool.key.PluginID, "up").Add(float64(added))
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package pools
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScaleStep(t *testing.T) {
+	busy := intervalStats{acquires: 100, waited: time.Second, inflight: 4}
+	idle := intervalStats{acquires: 10, inflight: 0}
+
+	tests := []struct {
+		name     string
+		size     int
+		min, max int
+		stats    intervalStats
+		quietFor int
+		want     int
+	}{
+		{"raised min grows to min", 1, 3, 5, idle, 0, 2},
+		{"lowered max shrinks to max", 6, 1, 4, busy, 0, -2},
+		{"queued callers grow by queue depth", 2, 1, 8, intervalStats{waiting: 3}, 0, 3},
+		{"growth capped at max", 3, 1, 4, intervalStats{waiting: 10}, 0, 1},
+		{"slow acquires grow by one", 2, 1, 4, busy, 0, 1},
+		{"saturated at max holds", 4, 1, 4, busy, 0, 0},
+		{"quiet but not long enough holds", 3, 1, 4, idle, scaleDownQuiet - 1, 0},
+		{"quiet long enough retires one", 3, 1, 4, idle, scaleDownQuiet, -1},
+		{"quiet at min holds", 1, 1, 4, idle, scaleDownQuiet, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scaleStep(tt.size, tt.min, tt.max, tt.stats, tt.quietFor); got != tt.want {
+				t.Errorf("scaleStep() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClampLimits(t *testing.T) {
+	tests := []struct{ min, max, wantMin, wantMax int }{
+		{0, 0, 1, 1},
+		{2, 0, 2, 2},
+		{1, 4, 1, 4},
+		{1, MaxWorkers + 10, 1, MaxWorkers},
+	}
+	for _, tt := range tests {
+		gotMin, gotMax := ClampLimits(tt.min, tt.max)
+		if gotMin != tt.wantMin || gotMax != tt.wantMax {
+			t.Errorf("ClampLimits(%d, %d) = %d, %d, want %d, %d", tt.min, tt.max, gotMin, gotMax, tt.wantMin, tt.wantMax)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Package readiness backs /health/ready: service components register checks, and the probe
+// passes only when every check does, answering with a JSON report of what is not ready.
+package readiness
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// checkTimeout bounds a single check so one slow dependency cannot hang the probe.
+const checkTimeout = 3 * time.Second
+
+// Checker is implemented by components that can tell whether they are ready to serve.
+type Checker interface {
+	Ready(ctx context.Context) error
+}
+
+// CheckFunc adapts a function to a Checker.
+type CheckFunc func(ctx context.Context) error
+
+func (f CheckFunc) Ready(ctx context.Context) error { return f(ctx) }
+
+// MissingError is returned by a check that is waiting for named items (plugins, topics, ...).
+// The probe lists them in the report's "missing" field.
+type MissingError struct {
+	What string
+	IDs  []string
+}
+
+func (e *MissingError) Error() string {
+	return fmt.Sprintf("%d %s not available: %s", len(e.IDs), e.What, strings.Join(e.IDs, ", "))
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Name    string   `json:"name"`
+	Ready   bool     `json:"ready"`
+	Reason  string   `json:"reason,omitempty"`
+	Missing []string `json:"missing,omitempty"`
+}
+
+// Report is the body served by the probe.
+type Report struct {
+	Ready  bool     `json:"ready"`
+	Checks []Result `json:"checks"`
+}
+
+type namedCheck struct {
+	name  string
+	check Checker
+}
+
+// Probe aggregates readiness checks. It is an http.Handler for /health/ready: 200 when every check
+// passes, 503 otherwise. Checks can be added after the handler is serving; until the first is added
+// the probe reports not ready, so traffic never arrives before the service has wired itself up.
+type Probe struct {
+	mu     sync.RWMutex
+	checks []namedCheck
+}
+
+// Creates a Probe with no checks.
+func New() *Probe {
+	return &Probe{}
+}
+
+// Add registers a check under name.
+func (p *Probe) Add(name string, c Checker) {
+	p.mu.Lock()
+	p.checks = append(p.checks, namedCheck{name, c})
+	p.mu.Unlock()
+}
+
+// Check runs every check concurrently and returns the combined report.
+func (p *Probe) Check(ctx context.Context) Report {
+	p.mu.RLock()
+	checks := slices.Clone(p.checks)
+	p.mu.RUnlock()
+
+	if len(checks) == 0 {
+		return Report{Checks: []Result{{Name: "startup", Reason: "service components not registered yet"}}}
+	}
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+			results[i] = result(c.name, c.check.Ready(cctx))
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Ready: true, Checks: results}
+	for _, r := range results {
+		report.Ready = report.Ready && r.Ready
+	}
+	return report
+}
+
+func result(name string, err error) Result {
+	if err == nil {
+		return Result{Name: name, Ready: true}
+	}
+	r := Result{Name: name, Reason: err.Error()}
+	var missing *MissingError
+	if stderrors.As(err, &missing) {
+		r.Missing = missing.IDs
+	}
+	return r
+}
+
+func (p *Probe) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := p.Check(r.Context())
+	status := http.StatusOK
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// Required returns a check that passes once registered reports true for every ID from required.
+// required is called on every probe so it can follow a live config (e.g. the plugins referenced by
+// the enabled rules of the current registry). Empty IDs are ignored.
+func Required(what string, required func() []string, registered func(id string) bool) Checker {
+	return CheckFunc(func(context.Context) error {
+		var missing []string
+		seen := make(map[string]struct{})
+		for _, id := range required() {
+			if _, dup := seen[id]; dup || id == "" {
+				continue
+			}
+			seen[id] = struct{}{}
+			if !registered(id) {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) > 0 {
+			slices.Sort(missing)
+			return &MissingError{What: what, IDs: missing}
+		}
+		return nil
+	})
+}
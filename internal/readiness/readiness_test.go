@@ -0,0 +1,50 @@
+package readiness
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func serve(t *testing.T, p *Probe) (int, Report) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	var report Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, report
+}
+
+func TestProbeReportsMissingPlugins(t *testing.T) {
+	p := New()
+	if code, _ := serve(t, p); code != http.StatusServiceUnavailable {
+		t.Fatalf("probe without checks = %d, want 503", code)
+	}
+
+	registered := map[string]bool{"a": true}
+	p.Add("broker", CheckFunc(func(context.Context) error { return nil }))
+	p.Add("rules", Required("rule plugins", func() []string {
+		return []string{"c", "a", "", "b", "c"}
+	}, func(id string) bool { return registered[id] }))
+
+	code, report := serve(t, p)
+	if code != http.StatusServiceUnavailable || report.Ready {
+		t.Fatalf("code=%d ready=%v, want 503 not ready", code, report.Ready)
+	}
+	if !report.Checks[0].Ready {
+		t.Fatalf("broker check = %+v, want ready", report.Checks[0])
+	}
+	if got := report.Checks[1].Missing; !slices.Equal(got, []string{"b", "c"}) {
+		t.Fatalf("missing = %v, want [b c]", got)
+	}
+
+	registered["b"], registered["c"] = true, true
+	if code, report := serve(t, p); code != http.StatusOK || !report.Ready {
+		t.Fatalf("code=%d report=%+v, want 200 ready", code, report)
+	}
+}
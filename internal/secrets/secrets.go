@@ -0,0 +1,108 @@
+// Package secrets resolves the secret references declared in plugin sidecars.
+//
+// A reference names exactly one source:
+//
+//	secrets:
+//	  api_token: { env: "GEOIP_API_TOKEN" }
+//	  tls_key:   { file: "/var/run/secrets/geoip/tls.key" }
+//	  db_pass:   { provider: "vault", key: "kv/blink/geoip#password" }
+//
+// env and file are built in. Other providers are registered at startup with Register.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Ref is a single secret reference as written in a sidecar file.
+type Ref struct {
+	Env      string `yaml:"env"`
+	File     string `yaml:"file"`
+	Provider string `yaml:"provider"`
+	Key      string `yaml:"key"`
+}
+
+func (r Ref) String() string {
+	switch {
+	case r.Env != "":
+		return "env:" + r.Env
+	case r.File != "":
+		return "file:" + r.File
+	case r.Provider != "":
+		return r.Provider + ":" + r.Key
+	default:
+		return "<empty>"
+	}
+}
+
+// Provider resolves secret keys against an external store (Vault, a cloud key vault, ...).
+type Provider interface {
+	Resolve(ctx context.Context, key string) (string, error)
+}
+
+// ProviderFunc adapts a plain function to the Provider interface.
+type ProviderFunc func(ctx context.Context, key string) (string, error)
+
+func (f ProviderFunc) Resolve(ctx context.Context, key string) (string, error) { return f(ctx, key) }
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{}
+)
+
+// Register makes a provider available to sidecars under name. Registering the same name twice replaces the previous provider.
+func Register(name string, p Provider) {
+	mu.Lock()
+	providers[name] = p
+	mu.Unlock()
+}
+
+// Resolve returns the plaintext value of ref.
+func Resolve(ctx context.Context, ref Ref) (string, error) {
+	switch {
+	case ref.Env != "":
+		v, ok := os.LookupEnv(ref.Env)
+		if !ok {
+			return "", fmt.Errorf("secrets: environment variable %s is not set", ref.Env)
+		}
+		return v, nil
+	case ref.File != "":
+		b, err := os.ReadFile(ref.File)
+		if err != nil {
+			return "", fmt.Errorf("secrets: read %s: %w", ref.File, err)
+		}
+		// Mounted secrets usually end with a newline that is not part of the value.
+		return strings.TrimRight(string(b), "\r\n"), nil
+	case ref.Provider != "":
+		mu.RLock()
+		p, ok := providers[ref.Provider]
+		mu.RUnlock()
+		if !ok {
+			return "", fmt.Errorf("secrets: unknown provider %q", ref.Provider)
+		}
+		v, err := p.Resolve(ctx, ref.Key)
+		if err != nil {
+			return "", fmt.Errorf("secrets: provider %s: %w", ref.Provider, err)
+		}
+		return v, nil
+	default:
+		return "", fmt.Errorf("secrets: reference must set one of env, file or provider")
+	}
+}
+
+// ResolveAll resolves every reference in refs, keyed by the same names.
+func ResolveAll(ctx context.Context, refs map[string]Ref) (map[string]string, error) {
+	out := make(map[string]string, len(refs))
+	for name, ref := range refs {
+		v, err := Resolve(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("%s (%s): %w", name, ref, err)
+		}
+		out[name] = v
+	}
+	return out, nil
+}
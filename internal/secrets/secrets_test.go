@@ -0,0 +1,59 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	t.Setenv("SECRETS_TEST_TOKEN", "env-value")
+	file := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(file, []byte("file-value\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	Register("test-vault", ProviderFunc(func(_ context.Context, key string) (string, error) {
+		if key == "kv/blink#password" {
+			return "vault-value", nil
+		}
+		return "", errors.New("no such key")
+	}))
+
+	for _, tc := range []struct {
+		ref     Ref
+		want    string
+		wantErr string
+	}{
+		{ref: Ref{Env: "SECRETS_TEST_TOKEN"}, want: "env-value"},
+		{ref: Ref{File: file}, want: "file-value"}, // the trailing newline of a mounted secret is dropped
+		{ref: Ref{Provider: "test-vault", Key: "kv/blink#password"}, want: "vault-value"},
+		{ref: Ref{Env: "SECRETS_TEST_UNSET"}, wantErr: "SECRETS_TEST_UNSET is not set"},
+		{ref: Ref{File: filepath.Join(t.TempDir(), "missing")}, wantErr: "read"},
+		{ref: Ref{Provider: "test-vault", Key: "kv/other"}, wantErr: "provider test-vault: no such key"},
+		{ref: Ref{Provider: "nope", Key: "k"}, wantErr: `unknown provider "nope"`},
+		{ref: Ref{}, wantErr: "must set one of"},
+	} {
+		got, err := Resolve(context.Background(), tc.ref)
+		switch {
+		case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+			t.Errorf("Resolve(%s) = %q, %v; want an error mentioning %q", tc.ref, got, err, tc.wantErr)
+		case tc.wantErr == "" && (err != nil || got != tc.want):
+			t.Errorf("Resolve(%s) = %q, %v; want %q", tc.ref, got, err, tc.want)
+		}
+	}
+}
+
+func TestResolveAll(t *testing.T) {
+	t.Setenv("SECRETS_TEST_A", "a")
+	got, err := ResolveAll(context.Background(), map[string]Ref{"a": {Env: "SECRETS_TEST_A"}})
+	if err != nil || got["a"] != "a" {
+		t.Fatalf("ResolveAll = %v, %v", got, err)
+	}
+	_, err = ResolveAll(context.Background(), map[string]Ref{"a": {Env: "SECRETS_TEST_A"}, "db_pass": {Env: "SECRETS_TEST_UNSET"}})
+	if err == nil || !strings.HasPrefix(err.Error(), "db_pass (env:SECRETS_TEST_UNSET)") {
+		t.Fatalf("ResolveAll error = %v, want it to name the missing secret and its reference", err)
+	}
+}
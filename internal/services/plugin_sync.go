@@ -3,10 +3,14 @@ package services
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"time"
 
 	svcctx "github.com/harishhary/blink/internal/context"
 	"github.com/harishhary/blink/internal/errors"
 
+	"github.com/harishhary/blink/internal/artifacts"
+	"github.com/harishhary/blink/internal/broker/factory"
 	"github.com/harishhary/blink/internal/configuration"
 	"github.com/harishhary/blink/internal/logger"
 	"github.com/harishhary/blink/internal/pluginmgr"
@@ -16,6 +20,7 @@ type PluginSyncService struct {
 	svcctx.ServiceContext
 	serviceName string
 	plugin      pluginmgr.Plugin
+	events      *pluginmgr.BrokerSink // nil when no status topic is configured
 }
 
 // NewPluginSyncService creates a service that starts the plugin manager and waits for
@@ -31,18 +36,64 @@ func NewPluginSyncService(
 	}
 	sc.Logger = logger.New(sc.Name(), "dev")
 
+	plugin := newPluginManager(sc.Logger, os.Getenv(envVar))
+	if cfg := sc.Configuration().Artifacts; cfg.Store != "" {
+		src, err := newArtifactSource(cfg)
+		if err != nil {
+			return nil, err
+		}
+		plugin.SetSource(src, time.Duration(cfg.IntervalSec)*time.Second)
+	}
+	crashLoop := sc.Configuration().CrashLoop
+	plugin.SetCrashLoop(crashLoop.MaxFailures, time.Duration(crashLoop.WindowSec)*time.Second)
+	plugin.SetSecretRotation(time.Duration(sc.Configuration().Secrets.RotationSec) * time.Second)
+	if f := sc.Configuration().Debug.ReattachFile; f != "" {
+		sc.Logger.Info("adopting debugged plugins listed in %s", f)
+		plugin.SetReattachFile(f)
+	}
+
+	var events *pluginmgr.BrokerSink
+	if topic := sc.Configuration().Topics.PluginStatusTopic; topic != "" {
+		b, err := factory.New(sc.Configuration())
+		if err != nil {
+			return nil, err
+		}
+		events = pluginmgr.NewBrokerSink(b.NewWriter(topic), sc.Logger)
+		plugin.SetEventSink(events)
+	}
+
 	return &PluginSyncService{
 		ServiceContext: sc,
 		serviceName:    name,
-		plugin:         newPluginManager(sc.Logger, os.Getenv(envVar)),
+		plugin:         plugin,
+		events:         events,
 	}, nil
 }
 
+// newArtifactSource builds the Source that keeps the plugin directory in line with the artifact manifest.
+func newArtifactSource(cfg configuration.ArtifactsConfig) (pluginmgr.Source, error) {
+	store, err := artifacts.NewStore(cfg.Store, cfg.Token)
+	if err != nil {
+		return nil, err
+	}
+	cacheDir := cfg.CacheDir
+	if cacheDir == "" {
+		cacheDir = filepath.Join(os.TempDir(), "blink-artifacts")
+	}
+	return artifacts.NewSyncer(store, cfg.Manifest, cacheDir)
+}
+
 // Name returns the service name.
 func (s *PluginSyncService) Name() string { return s.serviceName }
 
+// Plugin returns the plugin manager, e.g. for the admin API.
+func (s *PluginSyncService) Plugin() pluginmgr.Plugin { return s.plugin }
+
 // Run starts the plugin manager (if any) and blocks until ctx is cancelled.
 func (s *PluginSyncService) Run(ctx context.Context) errors.Error {
+	if s.events != nil {
+		go s.events.Run(ctx)
+	}
 	if err := s.plugin.Start(ctx); err != nil {
 		s.ErrorF("plugin start error: %v", err)
 	}
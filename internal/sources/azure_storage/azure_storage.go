@@ -1,15 +1,50 @@
 package azure_storage
 
 import (
+	"bytes"
 	"context"
-	"fmt"
+	stderrors "errors"
+	"io"
 	"strings"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
 	"github.com/harishhary/blink/internal/errors"
 )
 
+var (
+	// ErrBlobNotFound is returned for a blob that does not exist.
+	ErrBlobNotFound = stderrors.New("blob not found")
+	// ErrBlobChanged is returned by Open when the blob no longer has the requested ETag.
+	ErrBlobChanged = stderrors.New("blob changed since it was listed")
+)
+
+// BlobInfo describes one blob in a listing.
+type BlobInfo struct {
+	Name            string
+	ETag            string
+	LastModified    time.Time
+	Size            int64
+	ContentEncoding string
+}
+
+// Blobs is the part of the Blob service API the blob source and checkpoint store use, scoped to one
+// container. Client implements it against Azure; DirBlobs serves a local directory instead.
+type Blobs interface {
+	// ListBlobs returns every blob whose name starts with prefix.
+	ListBlobs(ctx context.Context, prefix string) ([]BlobInfo, error)
+	// Open streams a blob from offset, failing with ErrBlobChanged unless it still has etag.
+	// An offset at or past the end yields an empty stream.
+	Open(ctx context.Context, name, etag string, offset int64) (io.ReadCloser, error)
+	// ReadBlob returns a whole blob.
+	ReadBlob(ctx context.Context, name string) ([]byte, error)
+	// WriteBlob creates or replaces a blob.
+	WriteBlob(ctx context.Context, name string, data []byte) error
+}
+
 type Client struct {
 	Configuration
 
@@ -41,56 +76,117 @@ type Entry struct {
 	LastModified time.Time
 }
 
+// List returns the blobs under the directory path.
 func (client *Client) List(path string) ([]Entry, errors.Error) {
-	if err := client.initialize(); err != nil {
-		return nil, err
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	path = strings.TrimLeft(path+"/", "/")
+	blobs, err := client.ListBlobs(ctx, path)
+	if err != nil {
+		return nil, errors.NewE(err)
+	}
+	entries := make([]Entry, len(blobs))
+	for i, b := range blobs {
+		entries[i] = Entry{Name: b.Name, LastModified: b.LastModified}
 	}
+	return entries, nil
+}
 
+// Download returns the whole blob at path.
+func (client *Client) Download(path string) ([]byte, errors.Error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	path = strings.TrimLeft(fmt.Sprintf("%s/", path), "/")
-	pager := client.blobClient.NewListBlobsFlatPager(client.container, &azblob.ListBlobsFlatOptions{
-		Include: azblob.ListBlobsInclude{Metadata: true, Deleted: true},
-		Prefix:  &path,
-	})
+	data, err := client.ReadBlob(ctx, strings.TrimLeft(path, "/"))
+	if err != nil {
+		return nil, errors.NewE(err)
+	}
+	return data, nil
+}
+
+func (client *Client) ListBlobs(ctx context.Context, prefix string) ([]BlobInfo, error) {
+	if err := client.initialize(); err != nil {
+		return nil, err
+	}
+	pager := client.blobClient.NewListBlobsFlatPager(client.container, &azblob.ListBlobsFlatOptions{Prefix: &prefix})
 
-	entries := make([]Entry, 0)
+	var blobs []BlobInfo
 	for pager.More() {
 		page, err := pager.NextPage(ctx)
 		if err != nil {
-			return nil, errors.NewE(err)
+			return nil, err
 		}
-		for _, blob := range page.Segment.BlobItems {
-			entries = append(entries, Entry{
-				Name:         *blob.Name,
-				LastModified: *blob.Properties.LastModified,
-			})
+		for _, item := range page.Segment.BlobItems {
+			if item.Name == nil || item.Properties == nil {
+				continue
+			}
+			info := BlobInfo{Name: *item.Name}
+			p := item.Properties
+			if p.ETag != nil {
+				info.ETag = string(*p.ETag)
+			}
+			if p.LastModified != nil {
+				info.LastModified = *p.LastModified
+			}
+			if p.ContentLength != nil {
+				info.Size = *p.ContentLength
+			}
+			if p.ContentEncoding != nil {
+				info.ContentEncoding = *p.ContentEncoding
+			}
+			blobs = append(blobs, info)
 		}
 	}
-
-	return entries, nil
+	return blobs, nil
 }
 
-func (client *Client) Download(path string) ([]byte, errors.Error) {
+func (client *Client) Open(ctx context.Context, name, etag string, offset int64) (io.ReadCloser, error) {
 	if err := client.initialize(); err != nil {
 		return nil, err
 	}
+	options := &blob.DownloadStreamOptions{Range: blob.HTTPRange{Offset: offset}}
+	if etag != "" {
+		match := azcore.ETag(etag)
+		options.AccessConditions = &blob.AccessConditions{ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfMatch: &match}}
+	}
+	resp, err := client.blobClient.DownloadStream(ctx, client.container, name, options)
+	switch {
+	case err == nil:
+		return resp.Body, nil
+	case offset > 0 && bloberror.HasCode(err, bloberror.InvalidRange):
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+	return nil, blobError(err)
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-
-	path = strings.TrimLeft(fmt.Sprintf("%s/", path), "/")
-	buff := make([]byte, 0)
-	_, err := client.blobClient.DownloadBuffer(ctx, client.container, path, buff,
-		&azblob.DownloadBufferOptions{
-			Progress: func(bytesTransferred int64) {
-				fmt.Println(bytesTransferred)
-			},
-		})
+func (client *Client) ReadBlob(ctx context.Context, name string) ([]byte, error) {
+	if err := client.initialize(); err != nil {
+		return nil, err
+	}
+	resp, err := client.blobClient.DownloadStream(ctx, client.container, name, nil)
 	if err != nil {
-		return nil, errors.NewE(err)
+		return nil, blobError(err)
 	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
 
-	return buff, nil
+func (client *Client) WriteBlob(ctx context.Context, name string, data []byte) error {
+	if err := client.initialize(); err != nil {
+		return err
+	}
+	_, err := client.blobClient.UploadBuffer(ctx, client.container, name, data, nil)
+	return err
+}
+
+// blobError maps the service errors callers act on to ErrBlobNotFound and ErrBlobChanged.
+func blobError(err error) error {
+	switch {
+	case bloberror.HasCode(err, bloberror.BlobNotFound):
+		return ErrBlobNotFound
+	case bloberror.HasCode(err, bloberror.ConditionNotMet):
+		return ErrBlobChanged
+	}
+	return err
 }
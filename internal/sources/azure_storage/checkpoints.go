@@ -0,0 +1,49 @@
+package azure_storage
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// checkpointTimeout bounds one checkpoint read or write; CheckpointStore calls carry no context.
+const checkpointTimeout = 15 * time.Second
+
+// BlobCheckpoints is a sources.CheckpointStore keeping one blob per source under a prefix, so
+// checkpoints survive the loss of the ingestor's volume.
+type BlobCheckpoints struct {
+	blobs  Blobs
+	prefix string
+}
+
+func NewBlobCheckpoints(blobs Blobs, prefix string) *BlobCheckpoints {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &BlobCheckpoints{blobs: blobs, prefix: prefix}
+}
+
+func (b *BlobCheckpoints) name(source string) string {
+	return b.prefix + strings.NewReplacer("/", "_", "\\", "_").Replace(source) + ".checkpoint"
+}
+
+func (b *BlobCheckpoints) Load(source string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), checkpointTimeout)
+	defer cancel()
+	data, err := b.blobs.ReadBlob(ctx, b.name(source))
+	if stderrors.Is(err, ErrBlobNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("load checkpoint for %s: %w", source, err)
+	}
+	return string(data), nil
+}
+
+func (b *BlobCheckpoints) Save(source, checkpoint string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), checkpointTimeout)
+	defer cancel()
+	return b.blobs.WriteBlob(ctx, b.name(source), []byte(checkpoint))
+}
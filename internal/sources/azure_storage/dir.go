@@ -0,0 +1,124 @@
+package azure_storage
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirBlobs serves the files under a local directory as the blobs of one container, named by their
+// slash-separated path relative to it. It stands in for Azure in tests and local runs. The ETag of a
+// file is derived from its modification time and size, so rewriting a file changes it.
+type DirBlobs struct {
+	root string
+}
+
+// NewDirBlobs creates root if needed.
+func NewDirBlobs(root string) (*DirBlobs, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &DirBlobs{root: root}, nil
+}
+
+func (d *DirBlobs) path(name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", fmt.Errorf("invalid blob name %q", name)
+	}
+	return filepath.Join(d.root, filepath.FromSlash(name)), nil
+}
+
+func etag(info fs.FileInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+}
+
+func (d *DirBlobs) ListBlobs(ctx context.Context, prefix string) ([]BlobInfo, error) {
+	var blobs []BlobInfo
+	err := filepath.WalkDir(d.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			return nil // hidden files are WriteBlob's temporaries
+		}
+		rel, err := filepath.Rel(d.root, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		blobs = append(blobs, BlobInfo{Name: name, ETag: etag(info), LastModified: info.ModTime(), Size: info.Size()})
+		return nil
+	})
+	return blobs, err
+}
+
+func (d *DirBlobs) Open(ctx context.Context, name, tag string, offset int64) (io.ReadCloser, error) {
+	path, err := d.path(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if stderrors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err == nil && tag != "" && etag(info) != tag {
+		err = ErrBlobChanged
+	}
+	if err == nil && offset > 0 {
+		_, err = f.Seek(min(offset, info.Size()), io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func (d *DirBlobs) ReadBlob(ctx context.Context, name string) ([]byte, error) {
+	f, err := d.Open(ctx, name, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// WriteBlob replaces the file atomically, as a blob upload would.
+func (d *DirBlobs) WriteBlob(ctx context.Context, name string, data []byte) error {
+	path, err := d.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".blob-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,290 @@
+package azure_storage
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harishhary/blink/internal/errors"
+	"github.com/harishhary/blink/internal/secrets"
+	"github.com/harishhary/blink/internal/sources"
+)
+
+const (
+	defaultPollInterval = time.Minute
+	defaultMinAge       = 30 * time.Second
+)
+
+// SourceConfig is the config block of an "azureblob" entry in the ingestor config.
+type SourceConfig struct {
+	Account    string      `yaml:"account"`
+	AccountKey secrets.Ref `yaml:"account_key"`
+	Container  string      `yaml:"container"`
+	Prefix     string      `yaml:"prefix"`
+	// PollInterval is how long to wait before listing again once every blob has been read (default 1m).
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// MinAge skips blobs modified more recently than this (default 30s), so a blob is only read once its
+	// writer has finished and blobs are seen in the order they were last modified.
+	MinAge time.Duration `yaml:"min_age"`
+	// Compression is auto (gzip for a .gz name or a gzip Content-Encoding), gzip or none.
+	Compression string `yaml:"compression"`
+	// Dir serves blobs from a local directory instead of Azure, for local runs.
+	Dir string `yaml:"dir"`
+}
+
+// position is the source's checkpoint: the blob being read and how far into it. Offset counts
+// decompressed bytes for gzip blobs. Done marks a blob read to the end.
+type position struct {
+	Blob     string    `json:"blob"`
+	ETag     string    `json:"etag"`
+	Offset   int64     `json:"offset"`
+	Modified time.Time `json:"modified"`
+	Done     bool      `json:"done,omitempty"`
+}
+
+// after reports whether b sorts after the blob p names, in (last modified, name) order.
+func (p position) after(b BlobInfo) bool {
+	if !b.LastModified.Equal(p.Modified) {
+		return b.LastModified.After(p.Modified)
+	}
+	return b.Name > p.Blob
+}
+
+// cursor is an open blob being split into lines.
+type cursor struct {
+	info   BlobInfo
+	body   io.ReadCloser
+	lines  *bufio.Reader
+	offset int64
+}
+
+func (c *cursor) close() { c.body.Close() }
+
+// Source reads newline-delimited records (NDJSON or plain lines) from the blobs in a container,
+// oldest first, checkpointing the blob, its ETag and the byte offset reached so a restart resumes
+// mid-blob. A blob rewritten while it was being read is read again from the start; one modified after
+// it was finished is read again in full, as a new blob.
+type Source struct {
+	blobs Blobs
+	cfg   SourceConfig
+
+	cur  *cursor
+	last string     // the checkpoint the last Read returned, which cur continues from
+	todo []BlobInfo // listed blobs not yet read, oldest first
+}
+
+// NewSource builds the Source for an "azureblob" ingestor entry.
+func NewSource(name string, config map[string]any) (sources.Source, errors.Error) {
+	var cfg SourceConfig
+	if err := sources.Decode(config, &cfg); err != nil {
+		return nil, err
+	}
+	switch cfg.Compression {
+	case "":
+		cfg.Compression = "auto"
+	case "auto", "gzip", "none":
+	default:
+		return nil, errors.NewF("azureblob source %s: compression must be auto, gzip or none", name)
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.MinAge == 0 {
+		cfg.MinAge = defaultMinAge
+	}
+
+	if cfg.Dir != "" {
+		blobs, err := NewDirBlobs(cfg.Dir)
+		if err != nil {
+			return nil, errors.NewE(err)
+		}
+		return NewBlobSource(blobs, cfg), nil
+	}
+	if cfg.Account == "" || cfg.Container == "" {
+		return nil, errors.NewF("azureblob source %s: account and container (or dir) are required", name)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	key, err := secrets.Resolve(ctx, cfg.AccountKey)
+	if err != nil {
+		return nil, errors.NewE(err)
+	}
+	return NewBlobSource(New(Configuration{Username: cfg.Account, Password: key}, cfg.Container), cfg), nil
+}
+
+// NewBlobSource reads the blobs served by blobs. cfg must already carry its defaults.
+func NewBlobSource(blobs Blobs, cfg SourceConfig) *Source {
+	return &Source{blobs: blobs, cfg: cfg}
+}
+
+func (s *Source) Read(ctx context.Context, checkpoint string, max int) (sources.Batch, error) {
+	batch := sources.Batch{Checkpoint: checkpoint}
+	if s.cur != nil && checkpoint != s.last {
+		s.reset()
+	}
+	if s.cur == nil {
+		var pos position
+		if checkpoint != "" {
+			if err := json.Unmarshal([]byte(checkpoint), &pos); err != nil {
+				return batch, errors.NewF("invalid azureblob checkpoint %q: %s", checkpoint, err)
+			}
+		}
+		cur, err := s.next(ctx, pos)
+		if err != nil || cur == nil {
+			return batch, err
+		}
+		s.cur = cur
+	}
+
+	cur := s.cur
+	for len(batch.Messages) < max {
+		line, err := cur.lines.ReadBytes('\n')
+		start := cur.offset
+		cur.offset += int64(len(line))
+		if err != nil && !stderrors.Is(err, io.EOF) {
+			s.reset() // the next Read reopens the blob at the last returned checkpoint
+			return sources.Batch{Checkpoint: checkpoint}, err
+		}
+		if record := bytes.TrimRight(line, "\r\n"); len(bytes.TrimSpace(record)) > 0 {
+			batch.Messages = append(batch.Messages, sources.Message{
+				Data: record,
+				Time: cur.info.LastModified,
+				Meta: map[string]string{
+					"container": s.cfg.Container,
+					"blob":      cur.info.Name,
+					"offset":    strconv.FormatInt(start, 10),
+				},
+			})
+		}
+		if err != nil { // io.EOF
+			s.cur = nil
+			cur.close()
+			break
+		}
+	}
+
+	b, _ := json.Marshal(position{
+		Blob:     cur.info.Name,
+		ETag:     cur.info.ETag,
+		Offset:   cur.offset,
+		Modified: cur.info.LastModified,
+		Done:     s.cur == nil,
+	})
+	batch.Checkpoint = string(b)
+	s.last = batch.Checkpoint
+	return batch, nil
+}
+
+// next opens the blob to read after pos: pos's own blob if it was left part-read and has not changed,
+// otherwise the oldest unread blob. It waits for the poll interval and returns nil if there is none.
+func (s *Source) next(ctx context.Context, pos position) (*cursor, error) {
+	if pos.Blob != "" && !pos.Done {
+		s.todo = nil // resuming after a restart or an error: list afresh
+	}
+	if len(s.todo) == 0 {
+		if err := s.list(ctx, pos); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.todo) == 0 {
+		select {
+		case <-ctx.Done():
+		case <-time.After(s.cfg.PollInterval):
+		}
+		return nil, nil
+	}
+
+	info := s.todo[0]
+	s.todo = s.todo[1:]
+	offset := int64(0)
+	if info.Name == pos.Blob && info.ETag == pos.ETag && !pos.Done {
+		offset = pos.Offset
+	}
+	cur, err := s.open(ctx, info, offset)
+	if err != nil {
+		s.todo = nil
+		return nil, err
+	}
+	return cur, nil
+}
+
+// list fills todo with the blobs after pos that are old enough to read, oldest first. A part-read blob
+// is listed again unless it has since been modified, in which case it sorts by its new time.
+func (s *Source) list(ctx context.Context, pos position) error {
+	blobs, err := s.blobs.ListBlobs(ctx, s.cfg.Prefix)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-s.cfg.MinAge)
+	s.todo = s.todo[:0]
+	for _, b := range blobs {
+		resume := b.Name == pos.Blob && b.ETag == pos.ETag && !pos.Done
+		if b.LastModified.After(cutoff) || !(resume || pos.after(b)) {
+			continue
+		}
+		s.todo = append(s.todo, b)
+	}
+	slices.SortFunc(s.todo, func(a, b BlobInfo) int {
+		if c := a.LastModified.Compare(b.LastModified); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return nil
+}
+
+// open starts reading info at offset. Plain blobs are read from offset with a range request; gzip
+// blobs are decompressed from the start and the first offset bytes discarded.
+func (s *Source) open(ctx context.Context, info BlobInfo, offset int64) (*cursor, error) {
+	gz := s.cfg.Compression == "gzip" ||
+		s.cfg.Compression == "auto" && (strings.HasSuffix(info.Name, ".gz") || strings.EqualFold(info.ContentEncoding, "gzip"))
+	from := offset
+	if gz {
+		from = 0
+	}
+	body, err := s.blobs.Open(ctx, info.Name, info.ETag, from)
+	if err != nil {
+		return nil, err
+	}
+	cur := &cursor{info: info, body: body, offset: offset}
+	var r io.Reader = body
+	if gz {
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			body.Close()
+			return nil, errors.NewF("blob %s: %s", info.Name, err)
+		}
+		if _, err := io.CopyN(io.Discard, zr, offset); err != nil && !stderrors.Is(err, io.EOF) {
+			body.Close()
+			return nil, errors.NewF("blob %s: %s", info.Name, err)
+		}
+		r = zr
+	}
+	cur.lines = bufio.NewReaderSize(r, 64<<10)
+	return cur, nil
+}
+
+func (s *Source) reset() {
+	if s.cur != nil {
+		s.cur.close()
+		s.cur = nil
+	}
+	s.todo = nil
+}
+
+func (s *Source) Close() error {
+	s.reset()
+	return nil
+}
+
+func init() {
+	sources.RegisterConstructor("azureblob", NewSource)
+}
@@ -0,0 +1,141 @@
+package azure_storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harishhary/blink/internal/sources"
+)
+
+func writeBlob(t *testing.T, dir, name string, data []byte, modified time.Time) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(s))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newSource builds a fresh source over dir, as a restarted ingestor would.
+func newSource(t *testing.T, dir string) sources.Source {
+	t.Helper()
+	src, err := sources.New("azureblob", "test", map[string]any{"dir": dir, "prefix": "logs/", "poll_interval": "10ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { src.Close() })
+	return src
+}
+
+func read(t *testing.T, src sources.Source, checkpoint string, max int) ([]string, string) {
+	t.Helper()
+	batch, err := src.Read(context.Background(), checkpoint, max)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, m := range batch.Messages {
+		lines = append(lines, string(m.Data))
+	}
+	return lines, batch.Checkpoint
+}
+
+func TestBlobSourceResumesMidBlob(t *testing.T) {
+	dir := t.TempDir()
+	hourAgo := time.Now().Add(-time.Hour)
+	writeBlob(t, dir, "logs/a.ndjson", []byte("{\"n\":1}\n\n{\"n\":2}\r\n{\"n\":3}"), hourAgo)
+	writeBlob(t, dir, "logs/b.log.gz", gzipped(t, "four\nfive\n"), hourAgo.Add(time.Minute))
+	writeBlob(t, dir, "other/c.ndjson", []byte("ignored\n"), hourAgo)
+	writeBlob(t, dir, "logs/new.ndjson", []byte("too recent\n"), time.Now())
+
+	steps := []struct {
+		max  int
+		want []string
+	}{
+		{2, []string{`{"n":1}`, `{"n":2}`}},
+		{10, []string{`{"n":3}`}},
+		{1, []string{"four"}},
+		{10, []string{"five"}},
+		{10, nil},
+	}
+	checkpoint := ""
+	for i, step := range steps {
+		// Every step restarts the source, so each must resume from the checkpoint alone.
+		lines, next := read(t, newSource(t, dir), checkpoint, step.max)
+		if len(lines) != len(step.want) {
+			t.Fatalf("step %d: read %q, want %q", i, lines, step.want)
+		}
+		for j := range lines {
+			if lines[j] != step.want[j] {
+				t.Fatalf("step %d: read %q, want %q", i, lines, step.want)
+			}
+		}
+		checkpoint = next
+	}
+
+	// A long-running source keeps its reader open between batches and reaches the same place.
+	src := newSource(t, dir)
+	var all []string
+	checkpoint = ""
+	for range 4 {
+		lines, next := read(t, src, checkpoint, 2)
+		all, checkpoint = append(all, lines...), next
+	}
+	if len(all) != 5 || all[4] != "five" {
+		t.Fatalf("read %q", all)
+	}
+}
+
+func TestBlobSourceRestartsRewrittenBlob(t *testing.T) {
+	dir := t.TempDir()
+	hourAgo := time.Now().Add(-time.Hour)
+	writeBlob(t, dir, "logs/a.log", []byte("one\ntwo\n"), hourAgo)
+
+	_, checkpoint := read(t, newSource(t, dir), "", 1)
+	writeBlob(t, dir, "logs/a.log", []byte("uno\ndos\n"), hourAgo.Add(time.Minute))
+
+	lines, _ := read(t, newSource(t, dir), checkpoint, 10)
+	if len(lines) != 2 || lines[0] != "uno" {
+		t.Fatalf("read %q after rewrite, want the whole new blob", lines)
+	}
+}
+
+func TestBlobCheckpoints(t *testing.T) {
+	blobs, err := NewDirBlobs(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewBlobCheckpoints(blobs, "checkpoints")
+	if cp, err := store.Load("hub/signins"); err != nil || cp != "" {
+		t.Fatalf("Load before Save = %q, %v; want empty", cp, err)
+	}
+	for _, cp := range []string{"41", "97"} {
+		if err := store.Save("hub/signins", cp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cp, err := store.Load("hub/signins"); err != nil || cp != "97" {
+		t.Fatalf("Load = %q, %v; want 97", cp, err)
+	}
+}
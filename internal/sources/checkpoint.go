@@ -0,0 +1,67 @@
+package sources
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CheckpointStore persists each source's last published checkpoint.
+type CheckpointStore interface {
+	// Load returns the checkpoint saved for source, or "" if there is none.
+	Load(source string) (string, error)
+	// Save records checkpoint as the last one published for source.
+	Save(source, checkpoint string) error
+}
+
+// FileCheckpoints keeps one file per source in a directory, replaced atomically on every Save.
+type FileCheckpoints struct {
+	dir string
+}
+
+// NewFileCheckpoints creates dir if needed.
+func NewFileCheckpoints(dir string) (*FileCheckpoints, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCheckpoints{dir: dir}, nil
+}
+
+func (f *FileCheckpoints) path(source string) string {
+	// Source names come from config; keep them from escaping the directory.
+	return filepath.Join(f.dir, strings.NewReplacer("/", "_", "\\", "_").Replace(source)+".checkpoint")
+}
+
+func (f *FileCheckpoints) Load(source string) (string, error) {
+	b, err := os.ReadFile(f.path(source))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("load checkpoint for %s: %w", source, err)
+	}
+	return string(b), nil
+}
+
+func (f *FileCheckpoints) Save(source, checkpoint string) error {
+	path := f.path(source)
+	tmp, err := os.CreateTemp(f.dir, ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.WriteString(checkpoint); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
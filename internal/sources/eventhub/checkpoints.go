@@ -0,0 +1,193 @@
+package eventhub
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	eventhub "github.com/Azure/azure-sdk-for-go/sdk/messaging/azeventhubs/v2"
+	"github.com/google/uuid"
+)
+
+// FileCheckpointStore keeps partition ownership and checkpoints in a local directory, for running a
+// multi-partition source without a storage account. Claims are atomic within one process only, so every
+// replica sharing a consumer group must use a blob store instead.
+type FileCheckpointStore struct {
+	dir string
+	mu  sync.Mutex // serializes ClaimOwnership's read-compare-write
+}
+
+// NewFileCheckpointStore creates dir if needed.
+func NewFileCheckpointStore(dir string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCheckpointStore{dir: dir}, nil
+}
+
+type ownershipFile struct {
+	OwnerID  string    `json:"owner_id"`
+	ETag     string    `json:"etag"`
+	Modified time.Time `json:"modified"`
+}
+
+type checkpointFile struct {
+	Offset         *string `json:"offset,omitempty"`
+	SequenceNumber *int64  `json:"sequence_number,omitempty"`
+}
+
+// path returns the directory for one kind of record of a consumer group. Names are escaped because
+// consumer groups such as $Default and namespaces may hold characters awkward in paths.
+func (f *FileCheckpointStore) path(kind, namespace, hub, group string) string {
+	return filepath.Join(f.dir, url.PathEscape(namespace), url.PathEscape(hub), url.PathEscape(group), kind)
+}
+
+func (f *FileCheckpointStore) ClaimOwnership(ctx context.Context, partitionOwnership []eventhub.Ownership, options *eventhub.ClaimOwnershipOptions) ([]eventhub.Ownership, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var claimed []eventhub.Ownership
+	for _, o := range partitionOwnership {
+		dir := f.path("ownership", o.FullyQualifiedNamespace, o.EventHubName, o.ConsumerGroup)
+		name := filepath.Join(dir, url.PathEscape(o.PartitionID)+".json")
+		var current ownershipFile
+		err := readJSON(name, &current)
+		switch {
+		case stderrors.Is(err, fs.ErrNotExist):
+			if o.ETag != nil {
+				continue // claimed against a record that no longer exists
+			}
+		case err != nil:
+			return nil, err
+		case o.ETag == nil || string(*o.ETag) != current.ETag:
+			continue // another owner updated it first
+		}
+		next := ownershipFile{OwnerID: o.OwnerID, ETag: uuid.NewString(), Modified: time.Now().UTC()}
+		if err := writeJSON(dir, name, next); err != nil {
+			return nil, err
+		}
+		etag := azcore.ETag(next.ETag)
+		o.ETag, o.LastModifiedTime = &etag, next.Modified
+		claimed = append(claimed, o)
+	}
+	return claimed, nil
+}
+
+func (f *FileCheckpointStore) ListOwnership(ctx context.Context, namespace, hub, group string, options *eventhub.ListOwnershipOptions) ([]eventhub.Ownership, error) {
+	var out []eventhub.Ownership
+	err := f.each(f.path("ownership", namespace, hub, group), func(partition string, data []byte) error {
+		var o ownershipFile
+		if err := json.Unmarshal(data, &o); err != nil {
+			return err
+		}
+		etag := azcore.ETag(o.ETag)
+		out = append(out, eventhub.Ownership{
+			ConsumerGroup:           group,
+			EventHubName:            hub,
+			FullyQualifiedNamespace: namespace,
+			PartitionID:             partition,
+			OwnerID:                 o.OwnerID,
+			LastModifiedTime:        o.Modified,
+			ETag:                    &etag,
+		})
+		return nil
+	})
+	return out, err
+}
+
+func (f *FileCheckpointStore) ListCheckpoints(ctx context.Context, namespace, hub, group string, options *eventhub.ListCheckpointsOptions) ([]eventhub.Checkpoint, error) {
+	var out []eventhub.Checkpoint
+	err := f.each(f.path("checkpoint", namespace, hub, group), func(partition string, data []byte) error {
+		var c checkpointFile
+		if err := json.Unmarshal(data, &c); err != nil {
+			return err
+		}
+		out = append(out, eventhub.Checkpoint{
+			ConsumerGroup:           group,
+			EventHubName:            hub,
+			FullyQualifiedNamespace: namespace,
+			PartitionID:             partition,
+			Offset:                  c.Offset,
+			SequenceNumber:          c.SequenceNumber,
+		})
+		return nil
+	})
+	return out, err
+}
+
+func (f *FileCheckpointStore) SetCheckpoint(ctx context.Context, checkpoint eventhub.Checkpoint, options *eventhub.SetCheckpointOptions) error {
+	dir := f.path("checkpoint", checkpoint.FullyQualifiedNamespace, checkpoint.EventHubName, checkpoint.ConsumerGroup)
+	return writeJSON(dir, filepath.Join(dir, url.PathEscape(checkpoint.PartitionID)+".json"), checkpointFile{
+		Offset:         checkpoint.Offset,
+		SequenceNumber: checkpoint.SequenceNumber,
+	})
+}
+
+// each calls fn with the partition ID and contents of every record file in dir.
+func (f *FileCheckpointStore) each(dir string, fn func(partition string, data []byte) error) error {
+	entries, err := os.ReadDir(dir)
+	if stderrors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		base, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+		partition, err := url.PathUnescape(base)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		if err := fn(partition, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readJSON(name string, v any) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeJSON replaces name atomically, so a crash never leaves a torn record.
+func writeJSON(dir, name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".record-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,117 @@
+package eventhub
+
+import (
+	"context"
+	"testing"
+
+	eventhub "github.com/Azure/azure-sdk-for-go/sdk/messaging/azeventhubs/v2"
+)
+
+const (
+	testNamespace = "blink.servicebus.windows.net"
+	testHub       = "signins"
+	testGroup     = "$Default"
+)
+
+func TestFileCheckpointStoreClaims(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	claim := func(owner string, from *eventhub.Ownership) []eventhub.Ownership {
+		o := eventhub.Ownership{ConsumerGroup: testGroup, EventHubName: testHub, FullyQualifiedNamespace: testNamespace, PartitionID: "0", OwnerID: owner}
+		if from != nil {
+			o.ETag = from.ETag
+		}
+		claimed, err := store.ClaimOwnership(ctx, []eventhub.Ownership{o}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return claimed
+	}
+
+	first := claim("a", nil)
+	if len(first) != 1 || first[0].ETag == nil {
+		t.Fatalf("first claim = %+v", first)
+	}
+	if got := claim("b", nil); len(got) != 0 {
+		t.Fatalf("claim of an owned partition without its ETag succeeded: %+v", got)
+	}
+	renewed := claim("a", &first[0])
+	if len(renewed) != 1 {
+		t.Fatal("renewal with the current ETag failed")
+	}
+	if got := claim("b", &first[0]); len(got) != 0 {
+		t.Fatal("claim with a stale ETag succeeded")
+	}
+
+	owners, err := store.ListOwnership(ctx, testNamespace, testHub, testGroup, nil)
+	if err != nil || len(owners) != 1 || owners[0].OwnerID != "a" || *owners[0].ETag != *renewed[0].ETag {
+		t.Fatalf("ListOwnership = %+v, %v", owners, err)
+	}
+}
+
+func TestProcessorSourceCommit(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := newProcessorSource("signins", New(Configuration{Hostname: testNamespace, EventHubName: testHub, ConsumerGroup: testGroup}), store, eventhub.ProcessorOptions{})
+	src.claim("0")
+	src.release("3", src.claim("3"))
+	src.claim("3")
+
+	if err := src.Commit(ctx, `{"0":{"offset":"100","sequence_number":7},"3":{"offset":"250","sequence_number":12}}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Commit(ctx, `{"0":{"offset":"180","sequence_number":9}}`); err != nil {
+		t.Fatal(err)
+	}
+	checkpoints, err := store.ListCheckpoints(ctx, testNamespace, testHub, testGroup, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]int64{}
+	for _, c := range checkpoints {
+		got[c.PartitionID] = *c.SequenceNumber
+	}
+	if len(got) != 2 || got["0"] != 9 || got["3"] != 12 {
+		t.Fatalf("checkpoints = %v, want 0:9 3:12", got)
+	}
+	if err := src.Commit(ctx, "42"); err == nil {
+		t.Fatal("a single-partition checkpoint was accepted")
+	}
+}
+
+func TestProcessorSourceDropsLostPartitions(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := newProcessorSource("signins", New(Configuration{Hostname: testNamespace, EventHubName: testHub, ConsumerGroup: testGroup}), store, eventhub.ProcessorOptions{})
+
+	kept, lost := src.claim("0"), src.claim("1")
+	src.events <- received{partition: "0", claim: kept, event: &eventhub.ReceivedEventData{Offset: "10", SequenceNumber: 1}}
+	src.events <- received{partition: "1", claim: lost, event: &eventhub.ReceivedEventData{Offset: "20", SequenceNumber: 2}}
+	src.release("1", lost)
+	src.claim("1") // taken back, to be read again from its last checkpoint
+	batch, err := src.read(ctx, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.Messages) != 1 || batch.Messages[0].Meta["partition"] != "0" {
+		t.Fatalf("batch = %+v, want only the event of the partition still read under its claim", batch.Messages)
+	}
+
+	// A partition lost between Read and Commit keeps whatever its new owner committed.
+	src.release("0", kept)
+	if err := src.Commit(ctx, batch.Checkpoint); err != nil {
+		t.Fatal(err)
+	}
+	if checkpoints, err := store.ListCheckpoints(ctx, testNamespace, testHub, testGroup, nil); err != nil || len(checkpoints) != 0 {
+		t.Fatalf("checkpoints = %+v, %v, want none for a lost partition", checkpoints, err)
+	}
+}
@@ -1,9 +1,6 @@
 package eventhub
 
 import (
-	"context"
-	"time"
-
 	eventhub "github.com/Azure/azure-sdk-for-go/sdk/messaging/azeventhubs/v2"
 	"github.com/harishhary/blink/internal/errors"
 )
@@ -37,52 +34,3 @@ func (client *Client) initialize() errors.Error {
 	client.consumerClient = hub
 	return nil
 }
-
-func (client *Client) Receive() ([]byte, errors.Error) {
-	if err := client.initialize(); err != nil {
-		return nil, err
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-
-	partitionClient, err := client.consumerClient.NewPartitionClient(client.Partition, nil)
-	if err != nil {
-		return nil, errors.NewE(err)
-	}
-	defer partitionClient.Close(ctx)
-
-	event, err := partitionClient.ReceiveEvents(ctx, 1, nil)
-	if err != nil {
-		return nil, errors.NewE(err)
-	}
-
-	return event[0].Body, nil
-}
-
-func (client *Client) ReceiveBatch(maxEvents int) ([][]byte, errors.Error) {
-	if err := client.initialize(); err != nil {
-		return nil, err
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-
-	partitionClient, err := client.consumerClient.NewPartitionClient(client.Partition, nil)
-	if err != nil {
-		return nil, errors.NewE(err)
-	}
-	defer partitionClient.Close(ctx)
-
-	events, err := partitionClient.ReceiveEvents(ctx, maxEvents, nil)
-	if err != nil {
-		return nil, errors.NewE(err)
-	}
-
-	data := make([][]byte, len(events))
-	for i, event := range events {
-		data[i] = event.Body
-	}
-
-	return data, nil
-}
@@ -0,0 +1,351 @@
+package eventhub
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"strconv"
+	"sync"
+	"time"
+
+	eventhub "github.com/Azure/azure-sdk-for-go/sdk/messaging/azeventhubs/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/harishhary/blink/internal/errors"
+	"github.com/harishhary/blink/internal/sources"
+)
+
+const (
+	// lagInterval is how often the lag of each owned partition is measured.
+	lagInterval = 30 * time.Second
+	// receiveChunk is the most events a partition hands over at once.
+	receiveChunk = 100
+)
+
+var (
+	partitionsOwned = promauto.NewGaugeVec(prometheus.GaugeOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "eventhub_partitions_owned", Help: "Event Hub partitions this replica currently reads."}, []string{"source"})
+	partitionLag    = promauto.NewGaugeVec(prometheus.GaugeOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "eventhub_partition_lag", Help: "Events enqueued on an owned partition after its last committed checkpoint."}, []string{"source", "partition"})
+)
+
+// received is one event, the partition it came from and the claim on that partition it was read under.
+type received struct {
+	partition string
+	claim     uint64
+	event     *eventhub.ReceivedEventData
+}
+
+// partitionCheckpoint is the last event of one partition in a batch.
+type partitionCheckpoint struct {
+	Offset         string `json:"offset"`
+	SequenceNumber int64  `json:"sequence_number"`
+}
+
+// ProcessorSource reads every partition of an Event Hub. Replicas running the same source share the
+// partitions through its checkpoint store: each claims a balanced share, takes over the partitions of a
+// replica that stops, and starts each partition after its last committed event. Its batch checkpoints
+// name the last event per partition and are committed to the store once published. Events still buffered
+// from a partition this replica has lost are dropped, and so are checkpoints for it: the new owner reads
+// them again from the last committed event.
+type ProcessorSource struct {
+	name    string
+	client  *Client
+	store   eventhub.CheckpointStore
+	options eventhub.ProcessorOptions
+
+	events chan received
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	done    chan struct{}
+	runErr  error
+	running map[string]uint64 // partitions this replica currently reads, by claim
+	claims  uint64            // last claim handed out
+}
+
+func newProcessorSource(name string, client *Client, store eventhub.CheckpointStore, options eventhub.ProcessorOptions) *ProcessorSource {
+	return &ProcessorSource{
+		name:    name,
+		client:  client,
+		store:   store,
+		options: options,
+		events:  make(chan received, receiveChunk),
+		running: make(map[string]uint64),
+	}
+}
+
+// start runs the processor in the background if it is not already running. A processor that stopped
+// with an error reports it once and is started afresh on the next call.
+func (s *ProcessorSource) start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done != nil {
+		select {
+		case <-s.done:
+			err := s.runErr
+			s.done, s.runErr = nil, nil
+			if err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+	if err := s.client.initialize(); err != nil {
+		return err
+	}
+	processor, err := eventhub.NewProcessor(s.client.consumerClient, s.store, &s.options)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel, s.done = cancel, make(chan struct{})
+	go s.run(ctx, processor, s.done)
+	return nil
+}
+
+func (s *ProcessorSource) run(ctx context.Context, processor *eventhub.Processor, done chan struct{}) {
+	defer close(done)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for {
+			pc := processor.NextPartitionClient(ctx)
+			if pc == nil {
+				return // the processor has stopped
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.consume(ctx, pc)
+			}()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		s.trackLag(ctx)
+	}()
+
+	err := processor.Run(ctx)
+	cancel()
+	wg.Wait()
+	if err != nil && !stderrors.Is(err, context.Canceled) {
+		s.mu.Lock()
+		s.runErr = err
+		s.mu.Unlock()
+	}
+}
+
+// consume forwards one partition's events, in order, until the partition is lost or ctx ends.
+func (s *ProcessorSource) consume(ctx context.Context, pc *eventhub.ProcessorPartitionClient) {
+	partition := pc.PartitionID()
+	claim := s.claim(partition)
+	defer func() {
+		s.release(partition, claim)
+		closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = pc.Close(closeCtx)
+		cancel()
+	}()
+
+	for {
+		receiveCtx, cancel := context.WithTimeout(ctx, receiveWait)
+		events, err := pc.ReceiveEvents(receiveCtx, receiveChunk, nil)
+		cancel()
+		for _, e := range events {
+			select {
+			case s.events <- received{partition: partition, claim: claim, event: e}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err != nil && !stderrors.Is(err, context.DeadlineExceeded) {
+			// Ownership lost to another replica, or the link failed: the processor hands the partition
+			// out again on its next load-balancing pass.
+			return
+		}
+	}
+}
+
+// claim records that this replica reads partition and returns the claim its events are read under.
+func (s *ProcessorSource) claim(partition string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.claims++
+	s.running[partition] = s.claims
+	partitionsOwned.WithLabelValues(s.name).Set(float64(len(s.running)))
+	return s.claims
+}
+
+// release records that partition is no longer read under claim. A newer claim on it is left alone.
+func (s *ProcessorSource) release(partition string, claim uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running[partition] != claim {
+		return
+	}
+	delete(s.running, partition)
+	partitionLag.DeleteLabelValues(s.name, partition)
+	partitionsOwned.WithLabelValues(s.name).Set(float64(len(s.running)))
+}
+
+// owns reports whether this replica still reads partition, under claim if it is not zero.
+func (s *ProcessorSource) owns(partition string, claim uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.running[partition]
+	return ok && (claim == 0 || current == claim)
+}
+
+// trackLag measures how far each owned partition's committed checkpoint trails its newest event.
+func (s *ProcessorSource) trackLag(ctx context.Context) {
+	ticker := time.NewTicker(lagInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		s.mu.Lock()
+		owned := make([]string, 0, len(s.running))
+		for p := range s.running {
+			owned = append(owned, p)
+		}
+		s.mu.Unlock()
+		if len(owned) == 0 {
+			continue
+		}
+		cfg := s.client.Configuration
+		checkpoints, err := s.store.ListCheckpoints(ctx, cfg.Hostname, cfg.EventHubName, cfg.ConsumerGroup, nil)
+		if err != nil {
+			continue
+		}
+		committed := make(map[string]int64, len(checkpoints))
+		for _, c := range checkpoints {
+			if c.SequenceNumber != nil {
+				committed[c.PartitionID] = *c.SequenceNumber
+			}
+		}
+		for _, p := range owned {
+			props, err := s.client.consumerClient.GetPartitionProperties(ctx, p, nil)
+			if err != nil {
+				continue
+			}
+			lag := int64(0)
+			if !props.IsEmpty {
+				seq, ok := committed[p]
+				if !ok {
+					seq = props.BeginningSequenceNumber - 1
+				}
+				lag = max(props.LastEnqueuedSequenceNumber-seq, 0)
+			}
+			partitionLag.WithLabelValues(s.name, p).Set(float64(lag))
+		}
+	}
+}
+
+func (s *ProcessorSource) Read(ctx context.Context, checkpoint string, max int) (sources.Batch, error) {
+	if err := s.start(); err != nil {
+		return sources.Batch{Checkpoint: checkpoint}, err
+	}
+	return s.read(ctx, checkpoint, max)
+}
+
+// read collects up to max buffered events into a batch, waiting up to receiveWait for the first.
+func (s *ProcessorSource) read(ctx context.Context, checkpoint string, max int) (sources.Batch, error) {
+	batch := sources.Batch{Checkpoint: checkpoint}
+	wait := time.NewTimer(receiveWait)
+	defer wait.Stop()
+	last := make(map[string]partitionCheckpoint)
+collect:
+	for len(batch.Messages) < max {
+		var r received
+		if len(batch.Messages) == 0 {
+			select { // block for the first event
+			case r = <-s.events:
+			case <-wait.C:
+				return batch, nil
+			case <-ctx.Done():
+				return batch, nil
+			}
+		} else {
+			select { // then take what is already buffered
+			case r = <-s.events:
+			default:
+				break collect
+			}
+		}
+		if !s.owns(r.partition, r.claim) {
+			continue // read under a claim since lost; the partition's owner reads it again
+		}
+		e := r.event
+		m := sources.Message{
+			Data: e.Body,
+			Meta: map[string]string{
+				"partition":       r.partition,
+				"sequence_number": strconv.FormatInt(e.SequenceNumber, 10),
+			},
+		}
+		if e.PartitionKey != nil {
+			m.Key = []byte(*e.PartitionKey)
+		}
+		if e.EnqueuedTime != nil {
+			m.Time = *e.EnqueuedTime
+		}
+		batch.Messages = append(batch.Messages, m)
+		last[r.partition] = partitionCheckpoint{Offset: e.Offset, SequenceNumber: e.SequenceNumber}
+	}
+	b, _ := json.Marshal(last)
+	batch.Checkpoint = string(b)
+	return batch, nil
+}
+
+// Commit records each partition's last published event in the checkpoint store. Partitions this replica
+// no longer reads are skipped, so their new owner's checkpoints are not overwritten.
+func (s *ProcessorSource) Commit(ctx context.Context, checkpoint string) error {
+	var last map[string]partitionCheckpoint
+	if err := json.Unmarshal([]byte(checkpoint), &last); err != nil {
+		return errors.NewF("invalid eventhub checkpoint %q: %s", checkpoint, err)
+	}
+	cfg := s.client.Configuration
+	for partition, c := range last {
+		if !s.owns(partition, 0) {
+			continue
+		}
+		err := s.store.SetCheckpoint(ctx, eventhub.Checkpoint{
+			ConsumerGroup:           cfg.ConsumerGroup,
+			EventHubName:            cfg.EventHubName,
+			FullyQualifiedNamespace: cfg.Hostname,
+			PartitionID:             partition,
+			Offset:                  &c.Offset,
+			SequenceNumber:          &c.SequenceNumber,
+		}, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ProcessorSource) Close() error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	if s.client.consumerClient == nil {
+		return nil
+	}
+	ctx, cancelClose := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelClose()
+	err := s.client.consumerClient.Close(ctx)
+	s.client.consumerClient = nil
+	return err
+}
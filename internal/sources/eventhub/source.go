@@ -0,0 +1,221 @@
+package eventhub
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	eventhub "github.com/Azure/azure-sdk-for-go/sdk/messaging/azeventhubs/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azeventhubs/v2/checkpoints"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+
+	"github.com/harishhary/blink/internal/errors"
+	"github.com/harishhary/blink/internal/secrets"
+	"github.com/harishhary/blink/internal/sources"
+)
+
+// receiveWait bounds how long one Read waits to fill a batch before returning what it has.
+const receiveWait = 5 * time.Second
+
+// SourceConfig is the config block of an "eventhub" entry in the ingestor config. With a partition the
+// source reads just that partition and the ingestor keeps its checkpoint. Without one it reads every
+// partition, balancing them across the replicas that share Checkpoints.
+type SourceConfig struct {
+	Host          string      `yaml:"host"`
+	EventHub      string      `yaml:"event_hub"`
+	ConsumerGroup string      `yaml:"consumer_group"`
+	Partition     string      `yaml:"partition"`
+	Username      string      `yaml:"username"`
+	Password      secrets.Ref `yaml:"password"`
+	// Checkpoints is where a multi-partition source keeps partition ownership and checkpoints.
+	Checkpoints CheckpointConfig `yaml:"checkpoints"`
+	// LoadBalancing is balanced (claim one partition per pass, the default) or greedy.
+	LoadBalancing string `yaml:"load_balancing"`
+	// Start is where a partition without a checkpoint starts: latest (the default) or earliest.
+	Start string `yaml:"start"`
+}
+
+// CheckpointConfig selects a checkpoint store: a local directory for a single replica, or an Azure
+// Storage container shared by every replica.
+type CheckpointConfig struct {
+	Dir        string      `yaml:"dir"`
+	Account    string      `yaml:"account"`
+	AccountKey secrets.Ref `yaml:"account_key"`
+	Container  string      `yaml:"container"`
+}
+
+// Source reads one partition of an Event Hub, checkpointing by sequence number.
+type Source struct {
+	client    *Client
+	partition *eventhub.PartitionClient
+}
+
+// NewSource builds the Source for an "eventhub" ingestor entry.
+func NewSource(name string, config map[string]any) (sources.Source, errors.Error) {
+	var cfg SourceConfig
+	if err := sources.Decode(config, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Host == "" || cfg.EventHub == "" {
+		return nil, errors.NewF("eventhub source %s: host and event_hub are required", name)
+	}
+	if cfg.ConsumerGroup == "" {
+		cfg.ConsumerGroup = eventhub.DefaultConsumerGroup
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	password, err := secrets.Resolve(ctx, cfg.Password)
+	if err != nil {
+		return nil, errors.NewE(err)
+	}
+	client := New(Configuration{
+		Hostname:      cfg.Host,
+		Username:      cfg.Username,
+		Password:      password,
+		Partition:     cfg.Partition,
+		ConsumerGroup: cfg.ConsumerGroup,
+		EventHubName:  cfg.EventHub,
+	})
+	if cfg.Partition != "" {
+		return &Source{client: client}, nil
+	}
+
+	options := eventhub.ProcessorOptions{LoadBalancingStrategy: eventhub.ProcessorStrategyBalanced}
+	switch cfg.LoadBalancing {
+	case "", "balanced":
+	case "greedy":
+		options.LoadBalancingStrategy = eventhub.ProcessorStrategyGreedy
+	default:
+		return nil, errors.NewF("eventhub source %s: load_balancing must be balanced or greedy", name)
+	}
+	switch cfg.Start {
+	case "", "latest":
+		options.StartPositions.Default.Latest = to.Ptr(true)
+	case "earliest":
+		options.StartPositions.Default.Earliest = to.Ptr(true)
+	default:
+		return nil, errors.NewF("eventhub source %s: start must be latest or earliest", name)
+	}
+	store, err := newCheckpointStore(ctx, cfg.Checkpoints)
+	if err != nil {
+		return nil, errors.NewF("eventhub source %s: %s", name, err)
+	}
+	return newProcessorSource(name, client, store, options), nil
+}
+
+// newCheckpointStore builds the store a multi-partition source shares with its other replicas.
+func newCheckpointStore(ctx context.Context, cfg CheckpointConfig) (eventhub.CheckpointStore, error) {
+	switch {
+	case cfg.Dir != "" && cfg.Container != "":
+		return nil, fmt.Errorf("checkpoints takes either dir or an account and container, not both")
+	case cfg.Dir != "":
+		return NewFileCheckpointStore(cfg.Dir)
+	case cfg.Account == "" || cfg.Container == "":
+		return nil, fmt.Errorf("checkpoints needs a dir, or an account and container, when no partition is set")
+	}
+	key, err := secrets.Resolve(ctx, cfg.AccountKey)
+	if err != nil {
+		return nil, err
+	}
+	credential, err := azblob.NewSharedKeyCredential(cfg.Account, key)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("https://%s.blob.core.windows.net/%s", cfg.Account, cfg.Container)
+	containerClient, err := container.NewClientWithSharedKeyCredential(url, credential, nil)
+	if err != nil {
+		return nil, err
+	}
+	return checkpoints.NewBlobStore(containerClient, nil)
+}
+
+// open connects to the partition, starting after the checkpointed sequence number or, on a first run,
+// at the latest event.
+func (s *Source) open(checkpoint string) error {
+	if s.partition != nil {
+		return nil
+	}
+	if err := s.client.initialize(); err != nil {
+		return err
+	}
+	var start eventhub.StartPosition
+	if checkpoint != "" {
+		seq, err := strconv.ParseInt(checkpoint, 10, 64)
+		if err != nil {
+			return errors.NewF("invalid eventhub checkpoint %q: %s", checkpoint, err)
+		}
+		start.SequenceNumber = &seq
+	} else {
+		latest := true
+		start.Latest = &latest
+	}
+	pc, err := s.client.consumerClient.NewPartitionClient(s.client.Partition, &eventhub.PartitionClientOptions{StartPosition: start})
+	if err != nil {
+		return err
+	}
+	s.partition = pc
+	return nil
+}
+
+func (s *Source) Read(ctx context.Context, checkpoint string, max int) (sources.Batch, error) {
+	if err := s.open(checkpoint); err != nil {
+		return sources.Batch{}, err
+	}
+	receiveCtx, cancel := context.WithTimeout(ctx, receiveWait)
+	defer cancel()
+	events, err := s.partition.ReceiveEvents(receiveCtx, max, nil)
+	if err != nil && !stderrors.Is(err, context.DeadlineExceeded) {
+		// The link may be broken; reconnect from the last published checkpoint on the next Read.
+		s.closePartition()
+		return sources.Batch{}, err
+	}
+
+	batch := sources.Batch{Messages: make([]sources.Message, len(events)), Checkpoint: checkpoint}
+	for i, e := range events {
+		m := sources.Message{
+			Data: e.Body,
+			Meta: map[string]string{
+				"partition":       s.client.Partition,
+				"sequence_number": strconv.FormatInt(e.SequenceNumber, 10),
+			},
+		}
+		if e.PartitionKey != nil {
+			m.Key = []byte(*e.PartitionKey)
+		}
+		if e.EnqueuedTime != nil {
+			m.Time = *e.EnqueuedTime
+		}
+		batch.Messages[i] = m
+		batch.Checkpoint = strconv.FormatInt(e.SequenceNumber, 10)
+	}
+	return batch, nil
+}
+
+func (s *Source) closePartition() {
+	if s.partition != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = s.partition.Close(ctx)
+		cancel()
+		s.partition = nil
+	}
+}
+
+func (s *Source) Close() error {
+	s.closePartition()
+	if s.client.consumerClient == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := s.client.consumerClient.Close(ctx)
+	s.client.consumerClient = nil // reconnect if Read is called again
+	return err
+}
+
+func init() {
+	sources.RegisterConstructor("eventhub", NewSource)
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package file
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// fileID identifies a file by device and inode, so a rotated file is recognised after a rename.
+func fileID(path string, info os.FileInfo) string {
+	if sys, ok := info.Sys().(*syscall.Stat_t); ok {
+		return strconv.FormatUint(uint64(sys.Dev), 10) + ":" + strconv.FormatUint(uint64(sys.Ino), 10)
+	}
+	return path
+}
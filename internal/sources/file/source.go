@@ -0,0 +1,377 @@
+// Package file is an ingestor source that tails local files, for development, replaying sample logs
+// and small on-prem deployments:
+//
+//	sources:
+//	  samples:
+//	    type: file
+//	    config:
+//	      path: /data/samples/*.ndjson   # a file, or a glob to pick up new files in a directory
+//	      format: ndjson                 # or lines (the default)
+//
+// Files are tracked by inode, so a log rotated by rename is read to its end before the new file is
+// started, and a file truncated in place is read again from the start. The checkpoint holds every
+// tracked file's offset, so the ingestor's checkpoint store keeps the source's state.
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/harishhary/blink/internal/errors"
+	"github.com/harishhary/blink/internal/sources"
+)
+
+const (
+	defaultPollInterval = time.Second
+	// idleClose is how long a file must go unmodified before its handle is closed. Until then a rename
+	// (rotation) is followed through the open handle.
+	idleClose = 5 * time.Minute
+)
+
+var (
+	invalidLines = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "file_invalid_lines_total", Help: "Lines skipped by ndjson file sources because they are not JSON objects."}, []string{"source"})
+	truncations  = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "file_truncations_total", Help: "Tailed files found shorter than their offset and read again from the start."}, []string{"source"})
+)
+
+// SourceConfig is the config block of a "file" entry in the ingestor config.
+type SourceConfig struct {
+	Path string `yaml:"path"`
+	// Format is lines (every non-empty line is a record) or ndjson (lines that are not JSON objects
+	// are skipped and counted).
+	Format string `yaml:"format"`
+	// Compression is auto (gzip for a .gz name), gzip or none. Gzip files are read once, not tailed.
+	Compression string `yaml:"compression"`
+	// Start is where files present on the source's first run are read from: beginning (the default)
+	// or end. Files that appear later are always read from the beginning.
+	Start string `yaml:"start"`
+	// PollInterval is how often files are checked for new data once every file has been read (default 1s).
+	PollInterval time.Duration `yaml:"poll_interval"`
+}
+
+// fileState is one tracked file in the checkpoint. Offset counts decompressed bytes for gzip files.
+type fileState struct {
+	ID     string `json:"id"`
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
+	Done   bool   `json:"done,omitempty"` // a gzip file read to its end
+}
+
+type tracked struct {
+	fileState
+	gz       bool
+	seen     bool // matched the path on the latest scan
+	modified time.Time
+
+	f     *os.File      // kept open so a file rotated away can still be finished
+	lines *bufio.Reader // gzip files only: the decompressed stream, positioned at Offset
+}
+
+func (t *tracked) close() {
+	if t.f != nil {
+		t.f.Close()
+		t.f, t.lines = nil, nil
+	}
+}
+
+// Source tails the files matching a path.
+type Source struct {
+	name string
+	cfg  SourceConfig
+
+	files map[string]*tracked // by fileID
+	last  string              // the checkpoint the last Read returned, which files continue from
+}
+
+// NewSource builds the Source for a "file" ingestor entry.
+func NewSource(name string, config map[string]any) (sources.Source, errors.Error) {
+	var cfg SourceConfig
+	if err := sources.Decode(config, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Path == "" {
+		return nil, errors.NewF("file source %s: path is required", name)
+	}
+	if _, err := filepath.Match(cfg.Path, ""); err != nil {
+		return nil, errors.NewF("file source %s: invalid path pattern: %s", name, err)
+	}
+	if err := oneOf(name, "format", &cfg.Format, "lines", "ndjson"); err != nil {
+		return nil, err
+	}
+	if err := oneOf(name, "compression", &cfg.Compression, "auto", "gzip", "none"); err != nil {
+		return nil, err
+	}
+	if err := oneOf(name, "start", &cfg.Start, "beginning", "end"); err != nil {
+		return nil, err
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	return &Source{name: name, cfg: cfg}, nil
+}
+
+// oneOf defaults an unset option to the first allowed value and rejects any other.
+func oneOf(name, option string, value *string, allowed ...string) errors.Error {
+	if *value == "" {
+		*value = allowed[0]
+	}
+	if !slices.Contains(allowed, *value) {
+		return errors.NewF("file source %s: %s must be one of %s", name, option, strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+func (s *Source) Read(ctx context.Context, checkpoint string, max int) (sources.Batch, error) {
+	batch := sources.Batch{Checkpoint: checkpoint}
+	if s.files == nil || checkpoint != s.last {
+		if err := s.restore(checkpoint); err != nil {
+			return batch, err
+		}
+	}
+	if err := s.scan(checkpoint == ""); err != nil {
+		return batch, err
+	}
+
+	for _, t := range s.ordered() {
+		if len(batch.Messages) >= max {
+			break
+		}
+		if err := s.read(t, &batch, max); err != nil {
+			t.close()
+			return sources.Batch{Checkpoint: checkpoint}, err
+		}
+	}
+	if len(batch.Messages) == 0 {
+		select {
+		case <-ctx.Done():
+		case <-time.After(s.cfg.PollInterval):
+		}
+	}
+	batch.Checkpoint = s.checkpoint()
+	s.last = batch.Checkpoint
+	return batch, nil
+}
+
+// restore replaces the tracked files with those in checkpoint.
+func (s *Source) restore(checkpoint string) error {
+	for _, t := range s.files {
+		t.close()
+	}
+	s.files = make(map[string]*tracked)
+	if checkpoint == "" {
+		return nil
+	}
+	var states []fileState
+	if err := json.Unmarshal([]byte(checkpoint), &states); err != nil {
+		return errors.NewF("invalid file checkpoint %q: %s", checkpoint, err)
+	}
+	for _, st := range states {
+		s.files[st.ID] = &tracked{fileState: st, gz: s.gzip(st.Path)}
+	}
+	return nil
+}
+
+func (s *Source) gzip(path string) bool {
+	return s.cfg.Compression == "gzip" || s.cfg.Compression == "auto" && strings.HasSuffix(path, ".gz")
+}
+
+// scan matches the path against the filesystem. New files are tracked; a file that no longer matches
+// is finished from its open handle if it has one (it was rotated away) and forgotten otherwise.
+func (s *Source) scan(firstRun bool) error {
+	matches, err := filepath.Glob(s.cfg.Path)
+	if err != nil {
+		return err
+	}
+	for _, t := range s.files {
+		t.seen = false
+	}
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue // removed since the glob, or a directory
+		}
+		id := fileID(path, info)
+		t, ok := s.files[id]
+		if !ok {
+			t = &tracked{fileState: fileState{ID: id}, gz: s.gzip(path)}
+			if firstRun && s.cfg.Start == "end" && !t.gz {
+				t.Offset = info.Size()
+			}
+			s.files[id] = t
+		}
+		t.Path, t.seen, t.modified = path, true, info.ModTime()
+	}
+	for id, t := range s.files {
+		if !t.seen && t.f == nil {
+			delete(s.files, id)
+		}
+	}
+	return nil
+}
+
+// ordered returns the tracked files oldest first, so rotated files are finished before their successors.
+func (s *Source) ordered() []*tracked {
+	files := make([]*tracked, 0, len(s.files))
+	for _, t := range s.files {
+		files = append(files, t)
+	}
+	slices.SortFunc(files, func(a, b *tracked) int {
+		if a.seen != b.seen {
+			if a.seen {
+				return 1
+			}
+			return -1
+		}
+		if c := a.modified.Compare(b.modified); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+	return files
+}
+
+// open opens t's file, checking it is still the file that was scanned.
+func (s *Source) open(t *tracked) error {
+	f, err := os.Open(t.Path)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if fileID(t.Path, info) != t.ID {
+		f.Close()
+		return os.ErrNotExist // replaced since the scan; the next scan tracks the new file
+	}
+	t.f = f
+	return nil
+}
+
+// read appends t's complete lines after its offset to batch.
+func (s *Source) read(t *tracked, batch *sources.Batch, max int) error {
+	if t.Done {
+		return nil
+	}
+	if t.f == nil {
+		if err := s.open(t); err != nil {
+			if stderrors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	var lines *bufio.Reader
+	if t.gz {
+		if t.lines == nil {
+			zr, err := gzip.NewReader(t.f)
+			if err != nil {
+				return err
+			}
+			if _, err := io.CopyN(io.Discard, zr, t.Offset); err != nil && !stderrors.Is(err, io.EOF) {
+				return err
+			}
+			t.lines = bufio.NewReader(zr)
+		}
+		lines = t.lines
+	} else {
+		info, err := t.f.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() < t.Offset {
+			truncations.WithLabelValues(s.name).Inc()
+			t.Offset = 0
+		}
+		if _, err := t.f.Seek(t.Offset, io.SeekStart); err != nil {
+			return err
+		}
+		lines = bufio.NewReader(t.f)
+	}
+
+	for len(batch.Messages) < max {
+		line, err := lines.ReadBytes('\n')
+		if stderrors.Is(err, io.ErrUnexpectedEOF) && t.gz {
+			t.close() // still being written; decompress it again next time
+			return nil
+		}
+		if err != nil && !stderrors.Is(err, io.EOF) {
+			return err
+		}
+		if err != nil && len(line) > 0 && !t.gz && t.seen {
+			break // a partial line still being written: read it once it is complete
+		}
+		start := t.Offset
+		t.Offset += int64(len(line))
+		s.record(t, line, start, batch)
+		if err != nil { // io.EOF
+			switch {
+			case t.gz:
+				t.Done = true
+				t.close()
+			case !t.seen:
+				// Rotated away and read to the end. Writers switch to the new file on rotation, so drop it.
+				t.close()
+				delete(s.files, t.ID)
+			case time.Since(t.modified) > idleClose:
+				t.close()
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func (s *Source) record(t *tracked, line []byte, offset int64, batch *sources.Batch) {
+	rec := bytes.TrimRight(line, "\r\n")
+	trimmed := bytes.TrimSpace(rec)
+	if len(trimmed) == 0 {
+		return
+	}
+	if s.cfg.Format == "ndjson" && (trimmed[0] != '{' || !json.Valid(trimmed)) {
+		invalidLines.WithLabelValues(s.name).Inc()
+		return
+	}
+	batch.Messages = append(batch.Messages, sources.Message{
+		Data: rec,
+		Time: time.Now(),
+		Meta: map[string]string{"path": t.Path, "offset": strconv.FormatInt(offset, 10)},
+	})
+}
+
+func (s *Source) checkpoint() string {
+	states := make([]fileState, 0, len(s.files))
+	for _, t := range s.files {
+		states = append(states, t.fileState)
+	}
+	slices.SortFunc(states, func(a, b fileState) int { return strings.Compare(a.ID, b.ID) })
+	b, _ := json.Marshal(states)
+	return string(b)
+}
+
+func (s *Source) Close() error {
+	for _, t := range s.files {
+		t.close()
+	}
+	s.files = nil
+	return nil
+}
+
+func init() {
+	sources.RegisterConstructor("file", NewSource)
+}
@@ -0,0 +1,118 @@
+package file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/harishhary/blink/internal/sources"
+)
+
+// tail reads one batch and returns its records and checkpoint.
+func tail(t *testing.T, src sources.Source, checkpoint string) ([]string, string) {
+	t.Helper()
+	batch, err := src.Read(context.Background(), checkpoint, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, m := range batch.Messages {
+		lines = append(lines, string(m.Data))
+	}
+	return lines, batch.Checkpoint
+}
+
+func newSource(t *testing.T, config map[string]any) sources.Source {
+	t.Helper()
+	config["poll_interval"] = "1ms"
+	src, err := sources.New("file", "test", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { src.Close() })
+	return src
+}
+
+func appendTo(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expect(t *testing.T, step string, got []string, want ...string) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Fatalf("%s: read %q, want %q", step, got, want)
+	}
+}
+
+func TestTailRotationAndTruncation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendTo(t, path, "one\ntwo\nthr")
+	src := newSource(t, map[string]any{"path": path})
+
+	lines, cp := tail(t, src, "")
+	expect(t, "initial", lines, "one", "two")
+
+	appendTo(t, path, "ee\n")
+	lines, cp = tail(t, src, cp)
+	expect(t, "completed line", lines, "three")
+
+	// Rotate by rename; the writer finishes a line in the old file before switching.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendTo(t, path+".1", "four\n")
+	appendTo(t, path, "five\n")
+	lines, cp = tail(t, src, cp)
+	expect(t, "rotation", lines, "four", "five")
+
+	// A restarted source resumes from the checkpoint alone.
+	appendTo(t, path, "six\n")
+	lines, cp = tail(t, newSource(t, map[string]any{"path": path}), cp)
+	expect(t, "restart", lines, "six")
+
+	// Truncated in place (copytruncate rotation): read again from the start.
+	if err := os.WriteFile(path, []byte("7\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	lines, _ = tail(t, src, cp)
+	expect(t, "truncation", lines, "7")
+}
+
+func TestDirectoryNDJSONAndGzip(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.ndjson"), []byte("{\"n\":1}\nnot json\n[2]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("{\"n\":3}\n{\"n\":4}"))
+	zw.Close()
+	if err := os.WriteFile(filepath.Join(dir, "b.ndjson.gz"), buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := newSource(t, map[string]any{"path": filepath.Join(dir, "*.ndjson*"), "format": "ndjson"})
+
+	lines, cp := tail(t, src, "")
+	slices.Sort(lines)
+	expect(t, "directory", lines, `{"n":1}`, `{"n":3}`, `{"n":4}`)
+
+	// A file that appears later is picked up; finished gzip files are not read again.
+	if err := os.WriteFile(filepath.Join(dir, "c.ndjson"), []byte("{\"n\":5}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	lines, _ = tail(t, newSource(t, map[string]any{"path": filepath.Join(dir, "*.ndjson*"), "format": "ndjson"}), cp)
+	expect(t, "new file", lines, `{"n":5}`)
+}
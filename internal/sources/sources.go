@@ -1,10 +1,85 @@
 package sources
 
-import "github.com/harishhary/blink/internal/errors"
+import (
+	"context"
+	"time"
+
+	"github.com/harishhary/blink/internal/errors"
+	"go.yaml.in/yaml/v4"
+)
 
 type Record map[string]any
 
-type ISources interface {
-	Receive() ([]byte, errors.Error)
-	ReceiveBatch(maxEvents int) ([][]byte, errors.Error)
+// Message is one raw record read from a Source.
+type Message struct {
+	Data []byte
+	// Key is the source's partition key for the record, if it has one. The ingestor uses it as the
+	// broker message key unless the source is configured to key by an event field.
+	Key []byte
+	// Time is when the source received the record; zero if it does not know.
+	Time time.Time
+	// LogType is the log_type the source assigned the record, e.g. from the route it arrived on. It
+	// takes precedence over the source's configured log_type and whatever the record carries itself.
+	LogType string
+	// Meta is source-specific ingest metadata, e.g. the partition and sequence number.
+	Meta map[string]string
+}
+
+// Batch is the result of one Source.Read: the records plus the checkpoint that covers them.
+type Batch struct {
+	Messages   []Message
+	Checkpoint string
+}
+
+// Source is a checkpointed event source run by the event ingestor.
+//
+// The ingestor owns the checkpoint: it passes the last published one to every Read and only saves the
+// Batch's Checkpoint once the batch has been published, so a restarted source resumes after the last
+// published record and delivery is at-least-once. A checkpoint is opaque to everything but the source.
+type Source interface {
+	// Read blocks until records after checkpoint are available or ctx ends, and returns at most max
+	// of them. checkpoint is "" on a source's first run. An empty batch is not an error.
+	Read(ctx context.Context, checkpoint string, max int) (Batch, error)
+	// Close frees the source's connections.
+	Close() error
+}
+
+// Committer is implemented by sources that keep their checkpoints in a store of their own, such as one
+// shared by every replica reading an Event Hub. The ingestor passes such a source "" as its starting
+// checkpoint and, once a batch is published, commits the batch's Checkpoint to the source instead of
+// saving it to its CheckpointStore.
+type Committer interface {
+	Commit(ctx context.Context, checkpoint string) error
+}
+
+// Constructor builds a source from the config block of its entry in the ingestor config.
+type Constructor func(name string, config map[string]any) (Source, errors.Error)
+
+var constructors = make(map[string]Constructor)
+
+// RegisterConstructor makes a source type available to the ingestor config. Source packages call it from init.
+func RegisterConstructor(sourceType string, constructor Constructor) {
+	constructors[sourceType] = constructor
+}
+
+// New builds a source of the registered sourceType.
+func New(sourceType, name string, config map[string]any) (Source, errors.Error) {
+	constructor, ok := constructors[sourceType]
+	if !ok {
+		return nil, errors.NewF("no constructor registered for source type %q", sourceType)
+	}
+	return constructor(name, config)
+}
+
+// Decode copies a source's config block into the struct out points to, using its yaml tags.
+// Unknown keys are rejected so a misspelt option fails at startup instead of being ignored.
+func Decode(config map[string]any, out any) errors.Error {
+	b, err := yaml.Marshal(config)
+	if err != nil {
+		return errors.NewE(err)
+	}
+	if err := yaml.Load(b, out, yaml.WithKnownFields()); err != nil {
+		return errors.NewF("invalid source config: %s", err)
+	}
+	return nil
 }
@@ -0,0 +1,35 @@
+package sources
+
+import (
+	"testing"
+)
+
+func TestFileCheckpoints(t *testing.T) {
+	store, err := NewFileCheckpoints(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp, err := store.Load("hub/signins"); err != nil || cp != "" {
+		t.Fatalf("Load before Save = %q, %v; want empty", cp, err)
+	}
+	for _, cp := range []string{"41", "97"} {
+		if err := store.Save("hub/signins", cp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cp, err := store.Load("hub/signins"); err != nil || cp != "97" {
+		t.Fatalf("Load = %q, %v; want 97", cp, err)
+	}
+}
+
+func TestDecodeRejectsUnknownKeys(t *testing.T) {
+	var cfg struct {
+		Host string `yaml:"host"`
+	}
+	if err := Decode(map[string]any{"host": "example"}, &cfg); err != nil || cfg.Host != "example" {
+		t.Fatalf("Decode = %+v, %v", cfg, err)
+	}
+	if err := Decode(map[string]any{"hots": "example"}, &cfg); err == nil {
+		t.Fatal("misspelt key was accepted")
+	}
+}
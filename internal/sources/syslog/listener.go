@@ -0,0 +1,157 @@
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+)
+
+func (s *Source) serveUDP(ctx context.Context, conn net.PacketConn) error {
+	buf := make([]byte, 65535)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			if stderrors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		if n > s.cfg.MaxMessage {
+			dropped.WithLabelValues(s.name, "oversized").Inc()
+			continue
+		}
+		// One message per datagram; senders may still end it with a newline or NUL.
+		msg := bytes.TrimRight(buf[:n], "\r\n\x00")
+		if len(msg) == 0 {
+			continue
+		}
+		s.enqueue(ctx, bytes.Clone(msg), peer, "udp", false)
+	}
+}
+
+func (s *Source) serveTCP(ctx context.Context, ln net.Listener) error {
+	transport := "tcp"
+	if s.tls != nil {
+		transport = "tls"
+	}
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if stderrors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			var ne net.Error
+			if stderrors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			return err
+		}
+		s.mu.Lock()
+		if ctx.Err() != nil { // accepted as the source was closing, after its connections were closed
+			s.mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(ctx, conn, transport)
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+			conn.Close()
+		}()
+	}
+}
+
+// serveConn reads messages from one stream connection until it closes. Each message is either
+// octet-counted ("LEN SP MSG", RFC 6587 3.4.1) or ends at a newline; syslog messages start with '<',
+// so a leading digit identifies the octet-counted form, message by message.
+func (s *Source) serveConn(ctx context.Context, conn net.Conn, transport string) {
+	r := bufio.NewReaderSize(conn, 64<<10)
+	for {
+		first, err := r.Peek(1)
+		if err != nil {
+			return
+		}
+		var msg []byte
+		if first[0] >= '0' && first[0] <= '9' {
+			msg, err = s.octetCounted(r)
+		} else {
+			msg, err = s.lineFramed(r)
+		}
+		if err != nil {
+			if !stderrors.Is(err, io.EOF) && !stderrors.Is(err, net.ErrClosed) {
+				dropped.WithLabelValues(s.name, "framing").Inc()
+			}
+			return
+		}
+		if msg = bytes.TrimRight(msg, "\r\n\x00"); len(msg) == 0 {
+			continue
+		}
+		if !s.enqueue(ctx, msg, conn.RemoteAddr(), transport, true) {
+			return // shutting down
+		}
+	}
+}
+
+func (s *Source) octetCounted(r *bufio.Reader) ([]byte, error) {
+	prefix, err := r.ReadSlice(' ')
+	if err != nil {
+		return nil, fmt.Errorf("unterminated octet count: %w", err)
+	}
+	n, err := strconv.Atoi(string(prefix[:len(prefix)-1]))
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("invalid octet count %q", prefix)
+	}
+	if n > s.cfg.MaxMessage {
+		// The frame says where the next message starts, so skip this one and stay in sync.
+		dropped.WithLabelValues(s.name, "oversized").Inc()
+		if _, err := r.Discard(n); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// lineFramed reads up to the next newline. A line longer than MaxMessage is dropped up to its newline.
+func (s *Source) lineFramed(r *bufio.Reader) ([]byte, error) {
+	var msg []byte
+	oversized := false
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if !oversized {
+			if len(msg)+len(chunk) > s.cfg.MaxMessage+2 { // room for a CRLF
+				oversized, msg = true, nil
+			} else {
+				msg = append(msg, chunk...)
+			}
+		}
+		switch {
+		case stderrors.Is(err, bufio.ErrBufferFull):
+			continue
+		case err != nil && len(msg) == 0:
+			return nil, err
+		}
+		if oversized {
+			dropped.WithLabelValues(s.name, "oversized").Inc()
+			if err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+		return msg, nil // a last line without a newline is still delivered at EOF
+	}
+}
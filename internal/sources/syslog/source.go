@@ -0,0 +1,340 @@
+// Package syslog is an ingestor source that listens for syslog pushed by network devices, over UDP,
+// TCP or TLS:
+//
+//	sources:
+//	  network:
+//	    type: syslog
+//	    log_type: "syslog:network"         # for messages no rule below matches
+//	    config:
+//	      listen: ":6514"
+//	      protocol: tls                    # udp, tcp or tls
+//	      tls: { cert: /etc/blink/tls/tls.crt, key: /etc/blink/tls/tls.key }
+//	      rules:
+//	        - { hostname: "fw-*", app: "fortigate", log_type: "fortigate:event" }
+//	        - { app: "sshd", log_type: "linux:sshd" }
+//
+// Messages are parsed as RFC 5424 or RFC 3164 into facility, severity, hostname, app_name, proc_id,
+// msg_id, structured_data and message fields. A message that parses as neither is forwarded raw.
+//
+// Syslog has no replay, so the source has no checkpoint. TCP and TLS senders are slowed down rather than
+// dropped when the ingestor falls behind; UDP has no flow control, so datagrams arriving while the
+// queue is full are dropped and counted.
+package syslog
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/harishhary/blink/internal/classifier"
+	"github.com/harishhary/blink/internal/errors"
+	"github.com/harishhary/blink/internal/sources"
+)
+
+const (
+	defaultMaxMessage = 64 << 10
+	defaultQueue      = 10000
+	// receiveWait bounds how long one Read waits for a first message.
+	receiveWait = 5 * time.Second
+)
+
+var (
+	received    = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "syslog_messages_total", Help: "Syslog messages received, by transport."}, []string{"source", "transport"})
+	dropped     = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "syslog_dropped_total", Help: "Syslog messages dropped: UDP datagrams arriving while the queue is full, oversized or badly framed messages."}, []string{"source", "reason"})
+	parseErrors = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "syslog_parse_errors_total", Help: "Syslog messages in neither RFC format, forwarded raw."}, []string{"source"})
+)
+
+// TLSConfig names the PEM files of a TLS listener. With ClientCA, clients must present a certificate
+// it signed.
+type TLSConfig struct {
+	Cert     string `yaml:"cert"`
+	Key      string `yaml:"key"`
+	ClientCA string `yaml:"client_ca"`
+}
+
+// Rule assigns a log_type to the messages whose hostname and app_name match its globs; an empty glob
+// matches anything. The first matching rule wins.
+type Rule struct {
+	Hostname string `yaml:"hostname"`
+	App      string `yaml:"app"`
+	LogType  string `yaml:"log_type"`
+}
+
+func (r Rule) match(fields map[string]any) bool {
+	return glob(r.Hostname, fields["hostname"]) && glob(r.App, fields["app_name"])
+}
+
+func glob(pattern string, v any) bool {
+	if pattern == "" {
+		return true
+	}
+	s, _ := v.(string)
+	ok, _ := path.Match(pattern, s)
+	return ok
+}
+
+// SourceConfig is the config block of a "syslog" entry in the ingestor config.
+type SourceConfig struct {
+	Listen   string    `yaml:"listen"`
+	Protocol string    `yaml:"protocol"`
+	TLS      TLSConfig `yaml:"tls"`
+	// RFC is 5424 or 3164 to accept only that format; by default both are.
+	RFC   string `yaml:"rfc"`
+	Rules []Rule `yaml:"rules"`
+	// MaxMessage is the largest message accepted, in bytes (default 64 KiB).
+	MaxMessage int `yaml:"max_message"`
+	// Queue is how many messages wait for the ingestor before TCP senders are held back and UDP
+	// datagrams dropped (default 10000).
+	Queue int `yaml:"queue"`
+}
+
+// Source receives syslog messages on one listener.
+type Source struct {
+	name string
+	cfg  SourceConfig
+	tls  *tls.Config
+
+	queue chan sources.Message
+
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	addr     net.Addr
+	closers  []func() error
+	conns    map[net.Conn]struct{}
+	serveErr error
+}
+
+// NewSource builds the Source for a "syslog" ingestor entry.
+func NewSource(name string, config map[string]any) (sources.Source, errors.Error) {
+	var cfg SourceConfig
+	if err := sources.Decode(config, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Listen == "" {
+		return nil, errors.NewF("syslog source %s: listen is required", name)
+	}
+	switch cfg.RFC {
+	case "", "5424", "3164":
+	default:
+		return nil, errors.NewF("syslog source %s: rfc must be 5424 or 3164", name)
+	}
+	for i, r := range cfg.Rules {
+		if r.LogType == "" {
+			return nil, errors.NewF("syslog source %s: rule %d has no log_type", name, i+1)
+		}
+		for _, pattern := range []string{r.Hostname, r.App} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, errors.NewF("syslog source %s: rule %d: invalid glob %q", name, i+1, pattern)
+			}
+		}
+	}
+	if cfg.MaxMessage <= 0 {
+		cfg.MaxMessage = defaultMaxMessage
+	}
+	if cfg.Queue <= 0 {
+		cfg.Queue = defaultQueue
+	}
+
+	s := &Source{name: name, cfg: cfg, conns: make(map[net.Conn]struct{})}
+	switch cfg.Protocol {
+	case "udp", "tcp":
+	case "tls":
+		tlsConfig, err := loadTLS(cfg.TLS)
+		if err != nil {
+			return nil, errors.NewF("syslog source %s: %s", name, err)
+		}
+		s.tls = tlsConfig
+	default:
+		return nil, errors.NewF("syslog source %s: protocol must be udp, tcp or tls", name)
+	}
+	s.queue = make(chan sources.Message, cfg.Queue)
+	return s, nil
+}
+
+func loadTLS(cfg TLSConfig) (*tls.Config, error) {
+	if cfg.Cert == "" || cfg.Key == "" {
+		return nil, fmt.Errorf("tls needs cert and key")
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.Cert, cfg.Key)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if cfg.ClientCA != "" {
+		pem, err := os.ReadFile(cfg.ClientCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", cfg.ClientCA)
+		}
+		tlsConfig.ClientCAs, tlsConfig.ClientAuth = pool, tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// listen starts the listener if it is not running. A listener that failed reports its error once and
+// is started afresh on the next call.
+func (s *Source) listen() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.serveErr; err != nil {
+		s.serveErr = nil
+		s.stopLocked()
+		return err
+	}
+	if s.cancel != nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if s.cfg.Protocol == "udp" {
+		conn, err := net.ListenPacket("udp", s.cfg.Listen)
+		if err != nil {
+			cancel()
+			return err
+		}
+		s.addr, s.closers = conn.LocalAddr(), append(s.closers, conn.Close)
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.fail(ctx, s.serveUDP(ctx, conn))
+		}()
+	} else {
+		ln, err := net.Listen("tcp", s.cfg.Listen)
+		if err != nil {
+			cancel()
+			return err
+		}
+		if s.tls != nil {
+			ln = tls.NewListener(ln, s.tls)
+		}
+		s.addr, s.closers = ln.Addr(), append(s.closers, ln.Close)
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.fail(ctx, s.serveTCP(ctx, ln))
+		}()
+	}
+	s.cancel = cancel
+	return nil
+}
+
+// fail records why a listener stopped, unless it was stopped on purpose.
+func (s *Source) fail(ctx context.Context, err error) {
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+	s.mu.Lock()
+	s.serveErr = err
+	s.mu.Unlock()
+}
+
+// enqueue turns a framed message into a record. Stream transports block until the ingestor has room,
+// holding back the sender; UDP drops instead, and enqueue reports whether the message was kept.
+func (s *Source) enqueue(ctx context.Context, data []byte, peer net.Addr, transport string, block bool) bool {
+	received.WithLabelValues(s.name, transport).Inc()
+	m := sources.Message{
+		Data: data,
+		Time: time.Now(),
+		Meta: map[string]string{"transport": transport},
+	}
+	if peer != nil {
+		m.Meta["peer"] = peer.String()
+	}
+	if fields, err := classifier.ParseSyslog(data, s.cfg.RFC); err != nil {
+		parseErrors.WithLabelValues(s.name).Inc()
+	} else {
+		for _, r := range s.cfg.Rules {
+			if r.match(fields) {
+				m.LogType = r.LogType
+				break
+			}
+		}
+		if b, err := json.Marshal(fields); err == nil {
+			m.Data = b
+		}
+	}
+
+	if !block {
+		select {
+		case s.queue <- m:
+			return true
+		default:
+			dropped.WithLabelValues(s.name, "queue_full").Inc()
+			return false
+		}
+	}
+	select {
+	case s.queue <- m:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s *Source) Read(ctx context.Context, checkpoint string, max int) (sources.Batch, error) {
+	batch := sources.Batch{Checkpoint: checkpoint}
+	if err := s.listen(); err != nil {
+		return batch, err
+	}
+	wait := time.NewTimer(receiveWait)
+	defer wait.Stop()
+	select {
+	case m := <-s.queue:
+		batch.Messages = append(batch.Messages, m)
+	case <-wait.C:
+		return batch, nil
+	case <-ctx.Done():
+		return batch, nil
+	}
+	for len(batch.Messages) < max {
+		select {
+		case m := <-s.queue:
+			batch.Messages = append(batch.Messages, m)
+		default:
+			return batch, nil
+		}
+	}
+	return batch, nil
+}
+
+// stopLocked closes the listener and its connections and waits for them to finish. s.mu is held.
+func (s *Source) stopLocked() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	for _, c := range s.closers {
+		c()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait() // connection goroutines take s.mu to deregister
+	s.mu.Lock()
+	s.cancel, s.closers, s.addr = nil, nil, nil
+}
+
+func (s *Source) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopLocked()
+	return nil
+}
+
+func init() {
+	sources.RegisterConstructor("syslog", NewSource)
+}
@@ -0,0 +1,113 @@
+package syslog
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/harishhary/blink/internal/sources"
+)
+
+func start(t *testing.T, config map[string]any) *Source {
+	t.Helper()
+	config["listen"] = "127.0.0.1:0"
+	src, err := NewSource("test", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := src.(*Source)
+	if err := s.listen(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// collect reads until n messages have arrived or a Read comes back empty.
+func collect(t *testing.T, s *Source, n int) []sources.Message {
+	t.Helper()
+	var out []sources.Message
+	for len(out) < n {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		batch, err := s.Read(ctx, "", n-len(out))
+		cancel()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(batch.Messages) == 0 {
+			break
+		}
+		out = append(out, batch.Messages...)
+	}
+	return out
+}
+
+func TestTCPFramingAndRules(t *testing.T) {
+	s := start(t, map[string]any{
+		"protocol": "tcp",
+		"rules":    []any{map[string]any{"hostname": "fw-*", "log_type": "fortigate:event"}},
+	})
+	conn, err := net.Dial("tcp", s.addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	rfc5424 := `<165>1 2026-10-19T08:00:00Z fw-01 fortigate 42 ID47 [meta sequenceId="1"] denied`
+	stream := strconv.Itoa(len(rfc5424)) + " " + rfc5424 + // octet-counted, no delimiter needed
+		"<34>Oct 19 08:00:01 web-1 sshd[811]: Failed password\n" + // newline-framed
+		"not syslog at all\n"
+	if _, err := conn.Write([]byte(stream)); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := collect(t, s, 3)
+	if len(msgs) != 3 {
+		t.Fatalf("received %d messages, want 3", len(msgs))
+	}
+	var fw, ssh map[string]any
+	if err := json.Unmarshal(msgs[0].Data, &fw); err != nil {
+		t.Fatal(err)
+	}
+	if msgs[0].LogType != "fortigate:event" || fw["msg_id"] != "ID47" || fw["severity"] != float64(5) || fw["structured_data"] == nil {
+		t.Errorf("RFC 5424 message = %v, log_type %q", fw, msgs[0].LogType)
+	}
+	if err := json.Unmarshal(msgs[1].Data, &ssh); err != nil {
+		t.Fatal(err)
+	}
+	if msgs[1].LogType != "" || ssh["app_name"] != "sshd" || ssh["hostname"] != "web-1" {
+		t.Errorf("RFC 3164 message = %v, log_type %q", ssh, msgs[1].LogType)
+	}
+	if string(msgs[2].Data) != "not syslog at all" {
+		t.Errorf("unparsed message = %q, want it raw", msgs[2].Data)
+	}
+	if msgs[0].Meta["transport"] != "tcp" || msgs[0].Meta["peer"] == "" {
+		t.Errorf("meta = %v", msgs[0].Meta)
+	}
+}
+
+func TestUDPDropsWhenQueueFull(t *testing.T) {
+	s := start(t, map[string]any{"protocol": "udp", "queue": 1})
+	conn, err := net.Dial("udp", s.addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for range 3 {
+		if _, err := conn.Write([]byte("<13>Oct 19 08:00:00 host app: hello\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(s.queue) < 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond) // let the remaining datagrams be dropped
+
+	if msgs := collect(t, s, 1); len(msgs) != 1 || len(s.queue) != 0 {
+		t.Fatalf("received %d messages and %d queued with a queue of 1, want 1 and 0", len(msgs), len(s.queue))
+	}
+}
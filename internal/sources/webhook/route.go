@@ -0,0 +1,317 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/harishhary/blink/internal/secrets"
+	"github.com/harishhary/blink/internal/sources"
+)
+
+// route handles the requests to one configured path.
+type route struct {
+	src     *Source
+	cfg     RouteConfig
+	secret  []byte
+	hash    func() hash.Hash
+	pointer []string // EventsPointer split into unescaped reference tokens
+	limiter *bucket
+}
+
+func newRoute(ctx context.Context, src *Source, cfg RouteConfig) (*route, error) {
+	if !strings.HasPrefix(cfg.Path, "/") || strings.ContainsAny(cfg.Path, "{} \t") {
+		return nil, fmt.Errorf("path must be a plain path starting with /")
+	}
+	switch cfg.Provider {
+	case "", "generic":
+		cfg.Provider = "generic"
+	case "github":
+		// https://docs.github.com/en/webhooks/using-webhooks/validating-webhook-deliveries
+		defaults(&cfg.Auth.Type, "hmac")
+		defaults(&cfg.Auth.Header, "X-Hub-Signature-256")
+		defaults(&cfg.Auth.Prefix, "sha256=")
+	case "okta":
+		// Okta event hooks send the configured secret in the Authorization header.
+		defaults(&cfg.Auth.Type, "secret")
+		defaults(&cfg.EventsPointer, "/data/events")
+	default:
+		return nil, fmt.Errorf("provider must be github, okta or generic")
+	}
+
+	r := &route{src: src, cfg: cfg}
+	switch cfg.Auth.Type {
+	case "secret", "bearer":
+		defaults(&r.cfg.Auth.Header, "Authorization")
+	case "hmac":
+		if cfg.Auth.Header == "" {
+			return nil, fmt.Errorf("hmac auth needs a header")
+		}
+		switch cfg.Auth.Algorithm {
+		case "", "sha256":
+			r.hash = sha256.New
+		case "sha1":
+			r.hash = sha1.New
+		case "sha512":
+			r.hash = sha512.New
+		default:
+			return nil, fmt.Errorf("auth algorithm must be sha256, sha1 or sha512")
+		}
+		switch cfg.Auth.Encoding {
+		case "", "hex", "base64":
+		default:
+			return nil, fmt.Errorf("auth encoding must be hex or base64")
+		}
+	case "":
+		return nil, fmt.Errorf("auth type is required: secret, hmac or bearer")
+	default:
+		return nil, fmt.Errorf("auth type must be secret, hmac or bearer")
+	}
+	secret, err := secrets.Resolve(ctx, cfg.Auth.Secret)
+	if err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("auth secret is empty")
+	}
+	r.secret = []byte(secret)
+
+	if cfg.EventsPointer != "" {
+		if r.pointer, err = parsePointer(cfg.EventsPointer); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.MaxBody <= 0 {
+		r.cfg.MaxBody = defaultMaxBody
+	}
+	if cfg.Rate < 0 || cfg.Burst < 0 {
+		return nil, fmt.Errorf("rate and burst cannot be negative")
+	}
+	if cfg.Rate > 0 {
+		burst := cfg.Burst
+		if burst == 0 {
+			burst = int(math.Ceil(cfg.Rate))
+		}
+		r.limiter = newBucket(cfg.Rate, burst)
+	}
+	return r, nil
+}
+
+func defaults(value *string, def string) {
+	if *value == "" {
+		*value = def
+	}
+}
+
+func (r *route) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	code, reason := r.serve(w, req)
+	if reason != "" {
+		rejected.WithLabelValues(r.src.name, r.cfg.Path, reason).Inc()
+	}
+	requests.WithLabelValues(r.src.name, r.cfg.Path, strconv.Itoa(code)).Inc()
+}
+
+// serve answers one request and returns its status code and, for a refused request, why.
+func (r *route) serve(w http.ResponseWriter, req *http.Request) (int, string) {
+	if r.limiter != nil {
+		if ok, wait := r.limiter.take(time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			return reply(w, http.StatusTooManyRequests), "rate_limited"
+		}
+	}
+
+	// Okta verifies a new event hook with a GET carrying a challenge to echo back.
+	if challenge := req.Header.Get("X-Okta-Verification-Challenge"); r.cfg.Provider == "okta" && req.Method == http.MethodGet && challenge != "" {
+		if !r.authorized(req, nil) {
+			return reply(w, http.StatusUnauthorized), "unauthorized"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"verification": challenge})
+		return http.StatusOK, ""
+	}
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		return reply(w, http.StatusMethodNotAllowed), "method"
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, r.cfg.MaxBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if stderrors.As(err, &tooLarge) {
+			return reply(w, http.StatusRequestEntityTooLarge), "too_large"
+		}
+		return reply(w, http.StatusBadRequest), "read"
+	}
+	if !r.authorized(req, body) {
+		return reply(w, http.StatusUnauthorized), "unauthorized"
+	}
+	// GitHub pings a new webhook to check it is reachable; there is no event to ingest.
+	if r.cfg.Provider == "github" && req.Header.Get("X-GitHub-Event") == "ping" {
+		return reply(w, http.StatusNoContent), ""
+	}
+
+	events, err := r.events(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return http.StatusBadRequest, "malformed"
+	}
+	msgs := make([]sources.Message, 0, len(events))
+	now := time.Now()
+	for _, evt := range events {
+		msgs = append(msgs, sources.Message{Data: evt, LogType: r.cfg.LogType, Time: now, Meta: r.meta(req)})
+	}
+	if len(msgs) > cap(r.src.queue) {
+		// The request could never fit, so a retry would be refused the same way.
+		return reply(w, http.StatusRequestEntityTooLarge), "too_many_events"
+	}
+	if !r.src.enqueue(msgs) {
+		w.Header().Set("Retry-After", "1")
+		return reply(w, http.StatusTooManyRequests), "queue_full"
+	}
+	return reply(w, http.StatusAccepted), ""
+}
+
+func reply(w http.ResponseWriter, code int) int {
+	w.WriteHeader(code)
+	return code
+}
+
+// authorized checks the request's credentials. body is nil for requests without one.
+func (r *route) authorized(req *http.Request, body []byte) bool {
+	got := req.Header.Get(r.cfg.Auth.Header)
+	switch r.cfg.Auth.Type {
+	case "bearer":
+		token, ok := strings.CutPrefix(got, "Bearer ")
+		return ok && subtle.ConstantTimeCompare([]byte(token), r.secret) == 1
+	case "hmac":
+		sig, ok := strings.CutPrefix(got, r.cfg.Auth.Prefix)
+		if !ok {
+			return false
+		}
+		var decoded []byte
+		var err error
+		if r.cfg.Auth.Encoding == "base64" {
+			decoded, err = base64.StdEncoding.DecodeString(sig)
+		} else {
+			decoded, err = hex.DecodeString(sig)
+		}
+		if err != nil {
+			return false
+		}
+		mac := hmac.New(r.hash, r.secret)
+		mac.Write(body)
+		return hmac.Equal(decoded, mac.Sum(nil))
+	default: // secret
+		return subtle.ConstantTimeCompare([]byte(got), r.secret) == 1
+	}
+}
+
+// events splits a body into its events: the elements of the array at the route's pointer or, without
+// one, of a top-level array, or the body itself if it is an object.
+func (r *route) events(body []byte) ([]json.RawMessage, error) {
+	raw := json.RawMessage(bytes.TrimSpace(body))
+	for _, token := range r.pointer {
+		var next json.RawMessage
+		switch {
+		case bytes.HasPrefix(raw, []byte("{")):
+			var obj map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &obj); err != nil {
+				return nil, fmt.Errorf("body is not JSON: %s", err)
+			}
+			next = obj[token]
+		case bytes.HasPrefix(raw, []byte("[")):
+			var arr []json.RawMessage
+			if err := json.Unmarshal(raw, &arr); err != nil {
+				return nil, fmt.Errorf("body is not JSON: %s", err)
+			}
+			if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(arr) {
+				next = arr[i]
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("body has nothing at %s", r.cfg.EventsPointer)
+		}
+		raw = next
+	}
+
+	switch {
+	case bytes.HasPrefix(raw, []byte("[")):
+		var events []json.RawMessage
+		if err := json.Unmarshal(raw, &events); err != nil {
+			return nil, fmt.Errorf("body is not JSON: %s", err)
+		}
+		return events, nil
+	case r.pointer == nil && bytes.HasPrefix(raw, []byte("{")) && json.Valid(raw):
+		return []json.RawMessage{raw}, nil
+	case r.pointer != nil:
+		return nil, fmt.Errorf("%s is not an array", r.cfg.EventsPointer)
+	default:
+		return nil, fmt.Errorf("body is not a JSON object or array")
+	}
+}
+
+func (r *route) meta(req *http.Request) map[string]string {
+	meta := map[string]string{"route": r.cfg.Path, "peer": req.RemoteAddr}
+	if r.cfg.Provider == "github" {
+		meta["github_event"] = req.Header.Get("X-GitHub-Event")
+		meta["delivery"] = req.Header.Get("X-GitHub-Delivery")
+	}
+	return meta
+}
+
+// parsePointer splits an RFC 6901 JSON pointer into its reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("events_pointer %q must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens, nil
+}
+
+// bucket is a token bucket refilled at rate tokens a second, holding at most burst.
+type bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate float64, burst int) *bucket {
+	return &bucket{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// take spends a token if one is available, and otherwise reports how long until one is.
+func (b *bucket) take(now time.Time) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
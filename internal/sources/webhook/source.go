@@ -0,0 +1,258 @@
+// Package webhook is an ingestor source that receives events pushed over HTTP by SaaS providers that
+// can only deliver webhooks:
+//
+//	sources:
+//	  saas-webhooks:
+//	    type: webhook
+//	    config:
+//	      listen: ":8090"
+//	      routes:
+//	        - path: /github
+//	          provider: github                   # HMAC signature in X-Hub-Signature-256
+//	          log_type: "github:audit"
+//	          auth: { secret: { env: GITHUB_WEBHOOK_SECRET } }
+//	          rate: 20                           # requests per second, beyond which senders get 429
+//	        - path: /okta
+//	          provider: okta                     # answers Okta's verification challenge
+//	          log_type: "okta:system"
+//	          auth: { secret: { env: OKTA_HOOK_SECRET } }   # events read from /data/events
+//	        - path: /ci
+//	          log_type: "ci:audit"
+//	          events_pointer: /records
+//	          auth: { type: bearer, secret: { file: /etc/blink/ci-token } }
+//
+// Every route authenticates its requests: by a shared secret in a header, an HMAC signature of the
+// body or a bearer token, all compared in constant time. The request body is a JSON object (one event)
+// or array (one event each), or holds the events array at the route's events_pointer (RFC 6901).
+//
+// Webhooks have no replay, so the source has no checkpoint. A request is accepted (202) only once all
+// its events are queued for the ingestor; when the queue is full, or a route's rate is exceeded, the
+// sender gets 429 and is expected to retry. A request with more events than the queue holds gets 413.
+package webhook
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/harishhary/blink/internal/errors"
+	"github.com/harishhary/blink/internal/secrets"
+	"github.com/harishhary/blink/internal/sources"
+)
+
+const (
+	defaultMaxBody = 1 << 20
+	defaultQueue   = 10000
+	// receiveWait bounds how long one Read waits for a first event.
+	receiveWait = 5 * time.Second
+)
+
+var (
+	requests = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "webhook_requests_total", Help: "Webhook requests answered, by route and status code."}, []string{"source", "route", "code"})
+	rejected = promauto.NewCounterVec(prometheus.CounterOpts{Namespace: "blink", Subsystem: "event_ingestor", Name: "webhook_rejected_total", Help: "Webhook requests refused: unauthenticated, too large, malformed, rate limited, too many events or queue full."}, []string{"source", "route", "reason"})
+)
+
+// AuthConfig is how a route authenticates requests. Providers fill in everything but the secret.
+type AuthConfig struct {
+	// Type is secret (the header carries the secret itself), hmac (the header carries a signature of
+	// the body) or bearer (an Authorization: Bearer token).
+	Type   string      `yaml:"type"`
+	Secret secrets.Ref `yaml:"secret"`
+	// Header is where the secret or signature is sent: Authorization for secret and bearer auth.
+	Header string `yaml:"header"`
+	// Algorithm is the HMAC hash, sha256 (the default), sha1 or sha512.
+	Algorithm string `yaml:"algorithm"`
+	// Encoding is how the HMAC signature is written, hex (the default) or base64.
+	Encoding string `yaml:"encoding"`
+	// Prefix precedes the signature in the header, e.g. "sha256=".
+	Prefix string `yaml:"prefix"`
+}
+
+// RouteConfig is one webhook endpoint.
+type RouteConfig struct {
+	Path string `yaml:"path"`
+	// Provider is github, okta or generic (the default), and sets the route's auth and events_pointer
+	// defaults and its handling of the provider's handshake requests.
+	Provider string `yaml:"provider"`
+	// LogType is assigned to every event the route receives, whatever log_type the sender put in it;
+	// the ingestor keeps the sender's as _ingest.claimed_log_type.
+	LogType string     `yaml:"log_type"`
+	Auth    AuthConfig `yaml:"auth"`
+	// EventsPointer is a JSON pointer to the array of events in the body.
+	EventsPointer string `yaml:"events_pointer"`
+	// MaxBody is the largest body accepted, in bytes (default 1 MiB); larger requests get 413.
+	MaxBody int64 `yaml:"max_body"`
+	// Rate is the sustained requests per second accepted, Burst how many may arrive at once (default
+	// Rate rounded up). Zero is unlimited.
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// SourceConfig is the config block of a "webhook" entry in the ingestor config.
+type SourceConfig struct {
+	Listen string        `yaml:"listen"`
+	Routes []RouteConfig `yaml:"routes"`
+	// Queue is how many events wait for the ingestor before requests are answered with 429 (default 10000).
+	// A single request with more events than this is answered with 413.
+	Queue int `yaml:"queue"`
+}
+
+// Source serves a set of webhook routes on one listener.
+type Source struct {
+	name    string
+	cfg     SourceConfig
+	handler http.Handler
+
+	// enqueueMu makes queueing a request's events all or nothing.
+	enqueueMu sync.Mutex
+	queue     chan sources.Message
+
+	mu       sync.Mutex
+	server   *http.Server
+	addr     net.Addr
+	done     chan struct{}
+	serveErr error
+}
+
+// NewSource builds the Source for a "webhook" ingestor entry.
+func NewSource(name string, config map[string]any) (sources.Source, errors.Error) {
+	var cfg SourceConfig
+	if err := sources.Decode(config, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Listen == "" {
+		return nil, errors.NewF("webhook source %s: listen is required", name)
+	}
+	if len(cfg.Routes) == 0 {
+		return nil, errors.NewF("webhook source %s: no routes", name)
+	}
+	if cfg.Queue <= 0 {
+		cfg.Queue = defaultQueue
+	}
+
+	s := &Source{name: name, cfg: cfg, queue: make(chan sources.Message, cfg.Queue)}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	mux := http.NewServeMux()
+	paths := make(map[string]bool)
+	for i, rc := range cfg.Routes {
+		r, err := newRoute(ctx, s, rc)
+		if err != nil {
+			return nil, errors.NewF("webhook source %s: route %d: %s", name, i+1, err)
+		}
+		if paths[r.cfg.Path] {
+			return nil, errors.NewF("webhook source %s: path %s is used by two routes", name, r.cfg.Path)
+		}
+		paths[r.cfg.Path] = true
+		mux.Handle(r.cfg.Path, r)
+	}
+	s.handler = mux
+	return s, nil
+}
+
+// enqueue queues every event of one request, or none of them if the queue has no room for all.
+func (s *Source) enqueue(events []sources.Message) bool {
+	s.enqueueMu.Lock()
+	defer s.enqueueMu.Unlock()
+	if cap(s.queue)-len(s.queue) < len(events) {
+		return false
+	}
+	for _, m := range events {
+		s.queue <- m
+	}
+	return true
+}
+
+// listen starts the HTTP server if it is not running. A server that failed reports its error once and
+// is started afresh on the next call.
+func (s *Source) listen() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.serveErr; err != nil {
+		s.serveErr = nil
+		s.stopLocked()
+		return err
+	}
+	if s.server != nil {
+		return nil
+	}
+	ln, err := net.Listen("tcp", s.cfg.Listen)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Handler:           s.handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			s.mu.Lock()
+			s.serveErr = err
+			s.mu.Unlock()
+		}
+	}()
+	s.server, s.addr, s.done = server, ln.Addr(), done
+	return nil
+}
+
+func (s *Source) Read(ctx context.Context, checkpoint string, max int) (sources.Batch, error) {
+	batch := sources.Batch{Checkpoint: checkpoint}
+	if err := s.listen(); err != nil {
+		return batch, err
+	}
+	wait := time.NewTimer(receiveWait)
+	defer wait.Stop()
+	select {
+	case m := <-s.queue:
+		batch.Messages = append(batch.Messages, m)
+	case <-wait.C:
+		return batch, nil
+	case <-ctx.Done():
+		return batch, nil
+	}
+	for len(batch.Messages) < max {
+		select {
+		case m := <-s.queue:
+			batch.Messages = append(batch.Messages, m)
+		default:
+			return batch, nil
+		}
+	}
+	return batch, nil
+}
+
+// stopLocked shuts the server down, letting requests in flight finish for a few seconds. s.mu is held.
+func (s *Source) stopLocked() {
+	if s.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if s.server.Shutdown(ctx) != nil {
+		s.server.Close()
+	}
+	s.mu.Unlock()
+	<-s.done // the serve goroutine takes s.mu to record its error
+	s.mu.Lock()
+	s.server, s.addr, s.done = nil, nil, nil
+}
+
+func (s *Source) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopLocked()
+	return nil
+}
+
+func init() {
+	sources.RegisterConstructor("webhook", NewSource)
+}
@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harishhary/blink/internal/sources"
+)
+
+func newSource(t *testing.T, queue int, routes ...map[string]any) *Source {
+	t.Helper()
+	t.Setenv("HOOK_SECRET", "s3cret")
+	rs := make([]any, len(routes))
+	for i, r := range routes {
+		r["auth"].(map[string]any)["secret"] = map[string]any{"env": "HOOK_SECRET"}
+		rs[i] = r
+	}
+	src, err := NewSource("test", map[string]any{"listen": "127.0.0.1:0", "queue": queue, "routes": rs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src.(*Source)
+}
+
+func send(s *Source, method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func drain(s *Source) []sources.Message {
+	var out []sources.Message
+	for {
+		select {
+		case m := <-s.queue:
+			out = append(out, m)
+		default:
+			return out
+		}
+	}
+}
+
+func TestGitHubSignature(t *testing.T) {
+	s := newSource(t, 10, map[string]any{"path": "/github", "provider": "github", "log_type": "github:audit", "auth": map[string]any{}})
+	body := `{"action":"created","log_type":"okta:system","repository":{"name":"blink"}}`
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(body))
+	signed := map[string]string{"X-Hub-Signature-256": "sha256=" + hex.EncodeToString(mac.Sum(nil)), "X-GitHub-Event": "repository"}
+
+	if rec := send(s, "POST", "/github", body, map[string]string{"X-Hub-Signature-256": "sha256=00"}); rec.Code != http.StatusUnauthorized {
+		t.Errorf("bad signature: status %d, want 401", rec.Code)
+	}
+	if rec := send(s, "POST", "/github", body, signed); rec.Code != http.StatusAccepted {
+		t.Fatalf("signed: status %d, want 202", rec.Code)
+	}
+	msgs := drain(s)
+	if len(msgs) != 1 || msgs[0].Meta["github_event"] != "repository" {
+		t.Fatalf("queued %v, want the one repository event", msgs)
+	}
+	var evt map[string]any
+	if err := json.Unmarshal(msgs[0].Data, &evt); err != nil || evt["action"] != "created" || msgs[0].LogType != "github:audit" {
+		t.Errorf("event = %s with log_type %q, want the body with the route's log_type github:audit", msgs[0].Data, msgs[0].LogType)
+	}
+
+	signed["X-GitHub-Event"] = "ping"
+	if rec := send(s, "POST", "/github", body, signed); rec.Code != http.StatusNoContent || len(drain(s)) != 0 {
+		t.Errorf("ping: status %d, want 204 and nothing ingested", rec.Code)
+	}
+}
+
+func TestOktaChallengeAndPointer(t *testing.T) {
+	s := newSource(t, 10, map[string]any{"path": "/okta", "provider": "okta", "auth": map[string]any{}})
+	auth := map[string]string{"Authorization": "s3cret", "X-Okta-Verification-Challenge": "abc"}
+
+	rec := send(s, "GET", "/okta", "", auth)
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"verification":"abc"}` {
+		t.Errorf("challenge: %d %s", rec.Code, rec.Body)
+	}
+	body := `{"eventType":"com.okta.event_hook","data":{"events":[{"uuid":"1"},{"uuid":"2"}]}}`
+	if rec := send(s, "POST", "/okta", body, auth); rec.Code != http.StatusAccepted {
+		t.Fatalf("events: status %d, want 202", rec.Code)
+	}
+	if msgs := drain(s); len(msgs) != 2 || string(msgs[1].Data) != `{"uuid":"2"}` {
+		t.Errorf("queued %v, want the two events under /data/events", msgs)
+	}
+	if rec := send(s, "POST", "/okta", `{"data":{}}`, auth); rec.Code != http.StatusBadRequest {
+		t.Errorf("no events array: status %d, want 400", rec.Code)
+	}
+}
+
+func TestLimitsAndBackpressure(t *testing.T) {
+	s := newSource(t, 2,
+		map[string]any{"path": "/limited", "rate": 1, "burst": 1, "auth": map[string]any{"type": "bearer"}},
+		map[string]any{"path": "/small", "max_body": 16, "auth": map[string]any{"type": "bearer"}},
+	)
+	auth := map[string]string{"Authorization": "Bearer s3cret"}
+
+	if rec := send(s, "POST", "/limited", `{}`, auth); rec.Code != http.StatusAccepted {
+		t.Fatalf("first request: status %d, want 202", rec.Code)
+	}
+	if rec := send(s, "POST", "/limited", `{}`, auth); rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("over the rate: status %d, want 429 with Retry-After", rec.Code)
+	}
+	if rec := send(s, "POST", "/small", `[{"padding":"xxxxxxxx"}]`, auth); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large body: status %d, want 413", rec.Code)
+	}
+	// More events than the whole queue holds can never be accepted, so retrying would not help.
+	if rec := send(s, "POST", "/small", `[{},{},{}]`, auth); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("more events than the queue holds: status %d, want 413", rec.Code)
+	}
+	// One slot left in the queue: a request with two events is refused whole until the queue drains.
+	if rec := send(s, "POST", "/small", `[{},{}]`, auth); rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("queue full: status %d, want 429 with Retry-After", rec.Code)
+	}
+	if n := len(drain(s)); n != 1 {
+		t.Errorf("queued %d events, want 1", n)
+	}
+	if rec := send(s, "POST", "/small", `[{},{}]`, auth); rec.Code != http.StatusAccepted {
+		t.Errorf("retry after the queue drained: status %d, want 202", rec.Code)
+	}
+}
+
+func TestServesOverHTTP(t *testing.T) {
+	s := newSource(t, 10, map[string]any{"path": "/ci", "auth": map[string]any{"type": "bearer"}})
+	defer s.Close()
+	if err := s.listen(); err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest("POST", "http://"+s.addr.String()+"/ci", strings.NewReader(`[{"job":1}]`))
+	req.Header.Set("Authorization", "Bearer s3cret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("status %d, want 202", resp.StatusCode)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	batch, err := s.Read(ctx, "", 10)
+	if err != nil || len(batch.Messages) != 1 || string(batch.Messages[0].Data) != `{"job":1}` {
+		t.Fatalf("read %v, %v; want the posted event", batch.Messages, err)
+	}
+}
@@ -155,9 +155,24 @@ func (a *Alert) OutputDict() map[string]any {
 		"source_service":   a.SourceService,
 		"staged":           a.Staged,
 	}
+	if observables := a.ObservableValues(); len(observables) > 0 {
+		output["observables"] = observables
+	}
 	return output
 }
 
+// ObservableValues resolves each of the rule's observables against the event, keyed by its path.
+// Observables the event does not carry are left out.
+func (a *Alert) ObservableValues() map[string]any {
+	values := make(map[string]any)
+	for _, o := range a.Rule.Observables() {
+		if v, ok := a.Event.Lookup(o.Name()); ok {
+			values[o.Name()] = v
+		}
+	}
+	return values
+}
+
 // Returns a simple representation of the alert
 func (a *Alert) String() string {
 	return fmt.Sprintf("<Alert %s triggered from %s>", a.AlertID, a.Rule.Name())
@@ -198,7 +213,8 @@ func (a *Alert) CanMerge(other *Alert) bool {
 	}
 
 	for _, key := range a.Rule.MergeByKeys() {
-		if a.Event.GetFirstKey(key, "n/a") != other.Event.GetFirstKey(key, "n/a2") {
+		// Wildcard keys resolve to slices, which != cannot compare.
+		if !reflect.DeepEqual(a.Event.Resolve(key, "n/a"), other.Event.Resolve(key, "n/a2")) {
 			return false
 		}
 	}
@@ -0,0 +1,71 @@
+package enrichments_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/messaging"
+	"github.com/harishhary/blink/internal/pluginmgr"
+	"github.com/harishhary/blink/pkg/alerts"
+	"github.com/harishhary/blink/pkg/enrichments"
+	"github.com/harishhary/blink/pkg/hostapi"
+)
+
+type staticHost map[string]hostapi.IndicatorHit
+
+func (h staticHost) LookupIndicators(_ context.Context, values ...string) ([]hostapi.IndicatorHit, error) {
+	var hits []hostapi.IndicatorHit
+	for _, v := range values {
+		if hit, ok := h[v]; ok {
+			hits = append(hits, hit)
+		}
+	}
+	return hits, nil
+}
+
+// TestHostAPI runs an enrichment that looks indicators up through the host API served over the
+// go-plugin broker.
+func TestHostAPI(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	dir := t.TempDir()
+	build := exec.Command("go", "build", "-o", filepath.Join(dir, "ioc_lookup"), "github.com/harishhary/blink/pkg/enrichments/testdata/ioc_lookup")
+	build.Stdout, build.Stderr = os.Stdout, os.Stderr
+	if err := build.Run(); err != nil {
+		t.Fatalf("build plugin: %v", err)
+	}
+
+	registered := make(chan enrichments.IEnrichment, 1)
+	notify := func(msg messaging.Message) {
+		if m, ok := msg.(pluginmgr.RegisterMessage[enrichments.IEnrichment]); ok {
+			registered <- m.Items[0]
+		}
+	}
+	host := staticHost{"203.0.113.7": {Value: "203.0.113.7", Type: "ip", Indicator: "203.0.113.7", Source: "abuse-feed"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mgr := enrichments.NewManager(logger.New("enrichments-host-test", "dev"), notify, dir, host)
+	if err := mgr.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	var e enrichments.IEnrichment
+	select {
+	case e = <-registered:
+	case <-time.After(15 * time.Second):
+		t.Fatal("timed out waiting for the enrichment to register")
+	}
+	alert := &alerts.Alert{Event: map[string]any{"src_ip": "203.0.113.7"}}
+	if err := e.Enrich(ctx, alert); err != nil {
+		t.Fatalf("Enrich: %v", err)
+	}
+	if got := alert.Event["intel_source"]; got != "abuse-feed" {
+		t.Errorf("intel_source = %v, want the host's hit", got)
+	}
+}
@@ -10,15 +10,25 @@ import (
 
 	"github.com/harishhary/blink/internal/pluginmgr"
 	"github.com/harishhary/blink/pkg/enrichments/rpc_enrichments"
+	"github.com/harishhary/blink/pkg/hostapi"
 )
 
-type EnrichmentAdapter struct{}
+type EnrichmentAdapter struct {
+	// Host, when set, is served to every enrichment process over its go-plugin broker.
+	Host hostapi.Host
+}
+
+func (l *EnrichmentAdapter) PluginKey() string  { return "enrichment" }
+func (l *EnrichmentAdapter) MagicValue() string { return "enrichment" }
 
-func (l *EnrichmentAdapter) PluginKey() string         { return "enrichment" }
-func (l *EnrichmentAdapter) MagicValue() string        { return "enrichment_v1" }
-func (l *EnrichmentAdapter) GRPCPlugin() plugin.Plugin { return &enrichmentPlugin{} }
+func (l *EnrichmentAdapter) Protocols() map[int]plugin.Plugin {
+	return map[int]plugin.Plugin{1: &enrichmentPlugin{host: l.Host}}
+}
 
-func (l *EnrichmentAdapter) Handshake(ctx context.Context, raw interface{}, _ string, hash string) (IEnrichment, pluginmgr.PluginLifecycle, string, string, error) {
+func (l *EnrichmentAdapter) Handshake(ctx context.Context, raw interface{}, protocol int, _ string, hash string, cfg pluginmgr.ResolvedConfig) (IEnrichment, pluginmgr.PluginLifecycle, string, string, error) {
+	if protocol != 1 {
+		return nil, nil, "", "", fmt.Errorf("unsupported plugin protocol v%d", protocol)
+	}
 	rpc, ok := raw.(rpc_enrichments.EnrichmentClient)
 	if !ok {
 		return nil, nil, "", "", fmt.Errorf("dispense: unexpected type %T", raw)
@@ -31,8 +41,12 @@ func (l *EnrichmentAdapter) Handshake(ctx context.Context, raw interface{}, _ st
 		return nil, nil, "", "", fmt.Errorf("metadata: %w", err)
 	}
 
+	req := &rpc_enrichments.InitRequest{Params: cfg.Params, Secrets: cfg.Secrets}
+	if hosted, ok := raw.(*hostedClient); ok {
+		req.HostBrokerId = hosted.hostID
+	}
 	initCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	_, err = rpc.Init(initCtx, &rpc_enrichments.Empty{})
+	_, err = rpc.Init(initCtx, req)
 	cancel()
 	if err != nil {
 		return nil, nil, "", "", fmt.Errorf("init: %w", err)
@@ -42,9 +56,14 @@ func (l *EnrichmentAdapter) Handshake(ctx context.Context, raw interface{}, _ st
 	return e, &enrichmentLifecycle{rpc: rpc}, meta.GetId(), meta.GetName(), nil
 }
 
+// Config reads params and secret references from the optional <binary>.yaml sidecar.
+func (l *EnrichmentAdapter) Config(binPath string) (pluginmgr.PluginConfig, error) {
+	return pluginmgr.LoadSidecarConfig(binPath)
+}
+
 func (l *EnrichmentAdapter) IsEnabled(_ *pluginmgr.PluginHandle) bool { return true }
 
-func (l *EnrichmentAdapter) Workers(_ string) int { return 1 }
+func (l *EnrichmentAdapter) Workers(_ string) (int, int) { return 1, 1 }
 
 type enrichmentLifecycle struct {
 	rpc rpc_enrichments.EnrichmentClient
@@ -60,9 +79,22 @@ func (l *enrichmentLifecycle) Shutdown(ctx context.Context) error {
 	return err
 }
 
-type enrichmentPlugin struct{ plugin.NetRPCUnsupportedPlugin }
+type enrichmentPlugin struct {
+	plugin.NetRPCUnsupportedPlugin
+	host hostapi.Host
+}
+
+// hostedClient is the client of an enrichment process the host API is served to, under hostID.
+type hostedClient struct {
+	rpc_enrichments.EnrichmentClient
+	hostID uint32
+}
 
 func (p *enrichmentPlugin) GRPCServer(_ *plugin.GRPCBroker, _ *grpc.Server) error { return nil }
-func (p *enrichmentPlugin) GRPCClient(_ context.Context, _ *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
-	return rpc_enrichments.NewEnrichmentClient(c), nil
+func (p *enrichmentPlugin) GRPCClient(_ context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	client := rpc_enrichments.NewEnrichmentClient(c)
+	if p.host == nil {
+		return client, nil
+	}
+	return &hostedClient{EnrichmentClient: client, hostID: hostapi.Serve(broker, p.host)}, nil
 }
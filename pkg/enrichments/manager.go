@@ -3,10 +3,12 @@ package enrichments
 import (
 	"github.com/harishhary/blink/internal/logger"
 	"github.com/harishhary/blink/internal/pluginmgr"
+	"github.com/harishhary/blink/pkg/hostapi"
 )
 
 var enrichmentManagerMetrics = pluginmgr.NewPluginManagerMetrics("enrichmentsvc")
 
-func NewManager(log *logger.Logger, notify pluginmgr.Notify, dir string) pluginmgr.Plugin {
-	return pluginmgr.NewPluginManager[IEnrichment](log, notify, dir, &EnrichmentAdapter{}, enrichmentManagerMetrics)
+// NewManager runs the enrichment binaries in dir. host, if not nil, is served to them as the host API.
+func NewManager(log *logger.Logger, notify pluginmgr.Notify, dir string, host hostapi.Host) *pluginmgr.PluginManager[IEnrichment] {
+	return pluginmgr.NewPluginManager[IEnrichment](log, notify, dir, &EnrichmentAdapter{Host: host}, enrichmentManagerMetrics)
 }
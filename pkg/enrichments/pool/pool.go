@@ -17,9 +17,16 @@ type Pool struct {
 	*internal.ProcessPool[enrichments.IEnrichment]
 }
 
+// DefaultQueue is the queue policy of enrichments whose sidecar does not set one. Enrichment is best
+// applied late rather than never, so shed alerts go to the dead-letter topic.
+var DefaultQueue = internal.QueuePolicy{Shed: internal.ShedDeadLetter}
+
+// NewPool returns an empty pool whose per-plugin queue policies are read from routing, which the
+// plugin manager fills from the sidecars (see pluginmgr.PluginManager.SetRouting).
 func NewPool(routing *internal.RoutingTable, drainTimeout time.Duration) *Pool {
+	queue := routing.QueueConfig(DefaultQueue)
 	return &Pool{
-		ProcessPool: internal.NewProcessPool[enrichments.IEnrichment](routing.Config(), internal.NewPoolMetrics("enrichments"), drainTimeout),
+		ProcessPool: internal.NewProcessPool[enrichments.IEnrichment](routing.Config(), queue, internal.NewPoolMetrics("enrichments"), drainTimeout),
 	}
 }
 
@@ -43,18 +50,19 @@ func (p *Pool) Enrich(ctx context.Context, enrichmentID string, alert *alerts.Al
 }
 
 func (p *Pool) Sync(msg messaging.Message) {
-	register := func(onDrained func(), items []enrichments.IEnrichment, maxProcs int) {
-		version := items[0].Checksum()
+	register := func(onDrained, onRolledBack func(), workers []internal.Worker[enrichments.IEnrichment], scaler internal.Scaler[enrichments.IEnrichment]) {
+		item := workers[0].Plugin
+		version := item.Checksum()
 		if version == "" {
 			version = "1.0.0"
 		}
-		p.Register(internal.PoolKey{PluginID: items[0].Id(), Version: version}, items, maxProcs, onDrained)
+		p.Register(internal.PoolKey{PluginID: item.Id(), Version: version}, workers, scaler, onDrained, onRolledBack)
 	}
 	switch m := msg.(type) {
 	case pluginmgr.RegisterMessage[enrichments.IEnrichment]:
-		register(nil, m.Items, m.MaxProcs)
+		register(nil, nil, m.Workers(), m.Scaler)
 	case pluginmgr.UpdateMessage[enrichments.IEnrichment]:
-		register(m.OnDrained, m.Items, m.MaxProcs)
+		register(m.OnDrained, m.OnRolledBack, m.Workers(), m.Scaler)
 	case pluginmgr.UnregisterMessage[enrichments.IEnrichment]:
 		p.Unregister(m.ItemID)
 	case pluginmgr.RemoveMessage[enrichments.IEnrichment]:
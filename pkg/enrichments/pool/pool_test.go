@@ -0,0 +1,85 @@
+package pool_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harishhary/blink/internal/logger"
+	"github.com/harishhary/blink/internal/messaging"
+	"github.com/harishhary/blink/internal/pluginmgr"
+	internal "github.com/harishhary/blink/internal/pools"
+	"github.com/harishhary/blink/pkg/alerts"
+	"github.com/harishhary/blink/pkg/enrichments"
+	"github.com/harishhary/blink/pkg/enrichments/pool"
+)
+
+// TestSidecarQueueSheds runs an enrichment whose sidecar bounds how long a call waits for its worker,
+// and checks that a call finding the worker busy is shed with the stage's default policy.
+func TestSidecarQueueSheds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "ioc_lookup")
+	build := exec.Command("go", "build", "-o", bin, "github.com/harishhary/blink/pkg/enrichments/testdata/ioc_lookup")
+	build.Stdout, build.Stderr = os.Stdout, os.Stderr
+	if err := build.Run(); err != nil {
+		t.Fatalf("build plugin: %v", err)
+	}
+	if err := os.WriteFile(bin+".yaml", []byte("acquire_timeout: 50ms\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	routing := internal.NewRoutingTable()
+	p := pool.NewPool(routing, 0)
+	registered := make(chan string, 1)
+	notify := func(msg messaging.Message) {
+		p.Sync(msg)
+		if m, ok := msg.(pluginmgr.RegisterMessage[enrichments.IEnrichment]); ok {
+			registered <- m.Items[0].Id()
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mgr := enrichments.NewManager(logger.New("enrichments-pool-test", "dev"), notify, dir, nil)
+	mgr.SetRouting(routing, pool.DefaultQueue)
+	if err := mgr.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	var id string
+	select {
+	case id = <-registered:
+	case <-time.After(15 * time.Second):
+		t.Fatal("timed out waiting for the enrichment to register")
+	}
+	queue := routing.QueueConfig(pool.DefaultQueue)
+	for deadline := time.Now().Add(5 * time.Second); queue(id).AcquireTimeout != 50*time.Millisecond; {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue policy = %+v, want the sidecar's acquire_timeout", queue(id))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	held, release := make(chan struct{}), make(chan struct{})
+	go p.Call(ctx, id, "", func(context.Context, enrichments.IEnrichment) error {
+		close(held)
+		<-release
+		return nil
+	})
+	<-held
+	defer close(release)
+
+	_, _, err := p.Enrich(ctx, id, &alerts.Alert{Event: map[string]any{"src_ip": "203.0.113.7"}}, "")
+	shed, ok := internal.AsShed(err)
+	if !ok {
+		t.Fatalf("Enrich with the only worker busy = %v, want it shed", err)
+	}
+	if shed.Reason != internal.ShedTimeout || shed.Policy != internal.ShedDeadLetter {
+		t.Errorf("shed %s with policy %s, want %s with the stage's default %s", shed.Reason, shed.Policy, internal.ShedTimeout, internal.ShedDeadLetter)
+	}
+}
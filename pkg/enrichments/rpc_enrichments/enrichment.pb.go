@@ -141,6 +141,66 @@ func (x *EnrichmentMetadata) GetVersion() string {
 	return ""
 }
 
+type InitRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Params        map[string]string      `protobuf:"bytes,1,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Secrets       map[string]string      `protobuf:"bytes,2,rep,name=secrets,proto3" json:"secrets,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	HostBrokerId  uint32                 `protobuf:"varint,3,opt,name=host_broker_id,json=hostBrokerId,proto3" json:"host_broker_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InitRequest) Reset() {
+	*x = InitRequest{}
+	mi := &file_enrichment_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitRequest) ProtoMessage() {}
+
+func (x *InitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_enrichment_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitRequest.ProtoReflect.Descriptor instead.
+func (*InitRequest) Descriptor() ([]byte, []int) {
+	return file_enrichment_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *InitRequest) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *InitRequest) GetSecrets() map[string]string {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
+func (x *InitRequest) GetHostBrokerId() uint32 {
+	if x != nil {
+		return x.HostBrokerId
+	}
+	return 0
+}
+
 type Alert struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Json          []byte                 `protobuf:"bytes,1,opt,name=json,proto3" json:"json,omitempty"`
@@ -150,7 +210,7 @@ type Alert struct {
 
 func (x *Alert) Reset() {
 	*x = Alert{}
-	mi := &file_enrichment_proto_msgTypes[2]
+	mi := &file_enrichment_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -162,7 +222,7 @@ func (x *Alert) String() string {
 func (*Alert) ProtoMessage() {}
 
 func (x *Alert) ProtoReflect() protoreflect.Message {
-	mi := &file_enrichment_proto_msgTypes[2]
+	mi := &file_enrichment_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -175,7 +235,7 @@ func (x *Alert) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Alert.ProtoReflect.Descriptor instead.
 func (*Alert) Descriptor() ([]byte, []int) {
-	return file_enrichment_proto_rawDescGZIP(), []int{2}
+	return file_enrichment_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Alert) GetJson() []byte {
@@ -194,7 +254,7 @@ type EnrichRequest struct {
 
 func (x *EnrichRequest) Reset() {
 	*x = EnrichRequest{}
-	mi := &file_enrichment_proto_msgTypes[3]
+	mi := &file_enrichment_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -206,7 +266,7 @@ func (x *EnrichRequest) String() string {
 func (*EnrichRequest) ProtoMessage() {}
 
 func (x *EnrichRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_enrichment_proto_msgTypes[3]
+	mi := &file_enrichment_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -219,7 +279,7 @@ func (x *EnrichRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EnrichRequest.ProtoReflect.Descriptor instead.
 func (*EnrichRequest) Descriptor() ([]byte, []int) {
-	return file_enrichment_proto_rawDescGZIP(), []int{3}
+	return file_enrichment_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *EnrichRequest) GetAlert() *Alert {
@@ -238,7 +298,7 @@ type EnrichResponse struct {
 
 func (x *EnrichResponse) Reset() {
 	*x = EnrichResponse{}
-	mi := &file_enrichment_proto_msgTypes[4]
+	mi := &file_enrichment_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -250,7 +310,7 @@ func (x *EnrichResponse) String() string {
 func (*EnrichResponse) ProtoMessage() {}
 
 func (x *EnrichResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_enrichment_proto_msgTypes[4]
+	mi := &file_enrichment_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -263,7 +323,7 @@ func (x *EnrichResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EnrichResponse.ProtoReflect.Descriptor instead.
 func (*EnrichResponse) Descriptor() ([]byte, []int) {
-	return file_enrichment_proto_rawDescGZIP(), []int{4}
+	return file_enrichment_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *EnrichResponse) GetAlert() *Alert {
@@ -286,17 +346,27 @@ const file_enrichment_proto_rawDesc = "" +
 	"\aenabled\x18\x04 \x01(\bR\aenabled\x12\x1d\n" +
 	"\n" +
 	"depends_on\x18\x05 \x03(\tR\tdependsOn\x12\x18\n" +
-	"\aversion\x18\x06 \x01(\tR\aversion\"\x1b\n" +
+	"\aversion\x18\x06 \x01(\tR\aversion\"\xa9\x02\n" +
+	"\vInitRequest\x12<\n" +
+	"\x06params\x18\x01 \x03(\v2$.enrichments.InitRequest.ParamsEntryR\x06params\x12?\n" +
+	"\asecrets\x18\x02 \x03(\v2%.enrichments.InitRequest.SecretsEntryR\asecrets\x12$\n" +
+	"\x0ehost_broker_id\x18\x03 \x01(\rR\fhostBrokerId\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a:\n" +
+	"\fSecretsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x1b\n" +
 	"\x05Alert\x12\x12\n" +
 	"\x04json\x18\x01 \x01(\fR\x04json\"9\n" +
 	"\rEnrichRequest\x12(\n" +
 	"\x05alert\x18\x01 \x01(\v2\x12.enrichments.AlertR\x05alert\":\n" +
 	"\x0eEnrichResponse\x12(\n" +
-	"\x05alert\x18\x01 \x01(\v2\x12.enrichments.AlertR\x05alert2\xa7\x02\n" +
+	"\x05alert\x18\x01 \x01(\v2\x12.enrichments.AlertR\x05alert2\xad\x02\n" +
 	"\n" +
 	"Enrichment\x12B\n" +
-	"\vGetMetadata\x12\x12.enrichments.Empty\x1a\x1f.enrichments.EnrichmentMetadata\x12.\n" +
-	"\x04Init\x12\x12.enrichments.Empty\x1a\x12.enrichments.Empty\x12A\n" +
+	"\vGetMetadata\x12\x12.enrichments.Empty\x1a\x1f.enrichments.EnrichmentMetadata\x124\n" +
+	"\x04Init\x12\x18.enrichments.InitRequest\x1a\x12.enrichments.Empty\x12A\n" +
 	"\x06Enrich\x12\x1a.enrichments.EnrichRequest\x1a\x1b.enrichments.EnrichResponse\x122\n" +
 	"\bShutdown\x12\x12.enrichments.Empty\x1a\x12.enrichments.Empty\x12.\n" +
 	"\x04Ping\x12\x12.enrichments.Empty\x1a\x12.enrichments.EmptyB\"Z rpc_enrichments/;rpc_enrichmentsb\x06proto3"
@@ -313,32 +383,37 @@ func file_enrichment_proto_rawDescGZIP() []byte {
 	return file_enrichment_proto_rawDescData
 }
 
-var file_enrichment_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_enrichment_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_enrichment_proto_goTypes = []any{
 	(*Empty)(nil),              // 0: enrichments.Empty
 	(*EnrichmentMetadata)(nil), // 1: enrichments.EnrichmentMetadata
-	(*Alert)(nil),              // 2: enrichments.Alert
-	(*EnrichRequest)(nil),      // 3: enrichments.EnrichRequest
-	(*EnrichResponse)(nil),     // 4: enrichments.EnrichResponse
+	(*InitRequest)(nil),        // 2: enrichments.InitRequest
+	(*Alert)(nil),              // 3: enrichments.Alert
+	(*EnrichRequest)(nil),      // 4: enrichments.EnrichRequest
+	(*EnrichResponse)(nil),     // 5: enrichments.EnrichResponse
+	nil,                        // 6: enrichments.InitRequest.ParamsEntry
+	nil,                        // 7: enrichments.InitRequest.SecretsEntry
 }
 var file_enrichment_proto_depIdxs = []int32{
-	2, // 0: enrichments.EnrichRequest.alert:type_name -> enrichments.Alert
-	2, // 1: enrichments.EnrichResponse.alert:type_name -> enrichments.Alert
-	0, // 2: enrichments.Enrichment.GetMetadata:input_type -> enrichments.Empty
-	0, // 3: enrichments.Enrichment.Init:input_type -> enrichments.Empty
-	3, // 4: enrichments.Enrichment.Enrich:input_type -> enrichments.EnrichRequest
-	0, // 5: enrichments.Enrichment.Shutdown:input_type -> enrichments.Empty
-	0, // 6: enrichments.Enrichment.Ping:input_type -> enrichments.Empty
-	1, // 7: enrichments.Enrichment.GetMetadata:output_type -> enrichments.EnrichmentMetadata
-	0, // 8: enrichments.Enrichment.Init:output_type -> enrichments.Empty
-	4, // 9: enrichments.Enrichment.Enrich:output_type -> enrichments.EnrichResponse
-	0, // 10: enrichments.Enrichment.Shutdown:output_type -> enrichments.Empty
-	0, // 11: enrichments.Enrichment.Ping:output_type -> enrichments.Empty
-	7, // [7:12] is the sub-list for method output_type
-	2, // [2:7] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	6, // 0: enrichments.InitRequest.params:type_name -> enrichments.InitRequest.ParamsEntry
+	7, // 1: enrichments.InitRequest.secrets:type_name -> enrichments.InitRequest.SecretsEntry
+	3, // 2: enrichments.EnrichRequest.alert:type_name -> enrichments.Alert
+	3, // 3: enrichments.EnrichResponse.alert:type_name -> enrichments.Alert
+	0, // 4: enrichments.Enrichment.GetMetadata:input_type -> enrichments.Empty
+	2, // 5: enrichments.Enrichment.Init:input_type -> enrichments.InitRequest
+	4, // 6: enrichments.Enrichment.Enrich:input_type -> enrichments.EnrichRequest
+	0, // 7: enrichments.Enrichment.Shutdown:input_type -> enrichments.Empty
+	0, // 8: enrichments.Enrichment.Ping:input_type -> enrichments.Empty
+	1, // 9: enrichments.Enrichment.GetMetadata:output_type -> enrichments.EnrichmentMetadata
+	0, // 10: enrichments.Enrichment.Init:output_type -> enrichments.Empty
+	5, // 11: enrichments.Enrichment.Enrich:output_type -> enrichments.EnrichResponse
+	0, // 12: enrichments.Enrichment.Shutdown:output_type -> enrichments.Empty
+	0, // 13: enrichments.Enrichment.Ping:output_type -> enrichments.Empty
+	9, // [9:14] is the sub-list for method output_type
+	4, // [4:9] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_enrichment_proto_init() }
@@ -352,7 +427,7 @@ func file_enrichment_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_enrichment_proto_rawDesc), len(file_enrichment_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -31,7 +31,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type EnrichmentClient interface {
 	GetMetadata(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*EnrichmentMetadata, error)
-	Init(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	Init(ctx context.Context, in *InitRequest, opts ...grpc.CallOption) (*Empty, error)
 	Enrich(ctx context.Context, in *EnrichRequest, opts ...grpc.CallOption) (*EnrichResponse, error)
 	Shutdown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
@@ -55,7 +55,7 @@ func (c *enrichmentClient) GetMetadata(ctx context.Context, in *Empty, opts ...g
 	return out, nil
 }
 
-func (c *enrichmentClient) Init(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+func (c *enrichmentClient) Init(ctx context.Context, in *InitRequest, opts ...grpc.CallOption) (*Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, Enrichment_Init_FullMethodName, in, out, cOpts...)
@@ -100,7 +100,7 @@ func (c *enrichmentClient) Ping(ctx context.Context, in *Empty, opts ...grpc.Cal
 // for forward compatibility.
 type EnrichmentServer interface {
 	GetMetadata(context.Context, *Empty) (*EnrichmentMetadata, error)
-	Init(context.Context, *Empty) (*Empty, error)
+	Init(context.Context, *InitRequest) (*Empty, error)
 	Enrich(context.Context, *EnrichRequest) (*EnrichResponse, error)
 	Shutdown(context.Context, *Empty) (*Empty, error)
 	Ping(context.Context, *Empty) (*Empty, error)
@@ -117,7 +117,7 @@ type UnimplementedEnrichmentServer struct{}
 func (UnimplementedEnrichmentServer) GetMetadata(context.Context, *Empty) (*EnrichmentMetadata, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMetadata not implemented")
 }
-func (UnimplementedEnrichmentServer) Init(context.Context, *Empty) (*Empty, error) {
+func (UnimplementedEnrichmentServer) Init(context.Context, *InitRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Init not implemented")
 }
 func (UnimplementedEnrichmentServer) Enrich(context.Context, *EnrichRequest) (*EnrichResponse, error) {
@@ -169,7 +169,7 @@ func _Enrichment_GetMetadata_Handler(srv interface{}, ctx context.Context, dec f
 }
 
 func _Enrichment_Init_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
+	in := new(InitRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -181,7 +181,7 @@ func _Enrichment_Init_Handler(srv interface{}, ctx context.Context, dec func(int
 		FullMethod: Enrichment_Init_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(EnrichmentServer).Init(ctx, req.(*Empty))
+		return srv.(EnrichmentServer).Init(ctx, req.(*InitRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -11,12 +11,18 @@ import (
 
 	"github.com/harishhary/blink/internal/errors"
 	"github.com/harishhary/blink/pkg/enrichments/rpc_enrichments"
+	"github.com/harishhary/blink/pkg/hostapi"
+	"github.com/harishhary/blink/pkg/pluginconfig"
+	"github.com/harishhary/blink/pkg/plugindebug"
+	"github.com/harishhary/blink/pkg/pluginlog"
 )
 
 const (
+	// ProtocolVersion is the highest plugin protocol this SDK speaks. The host negotiates the highest
+	// version both sides support, so binaries keep working with hosts that still run older versions.
 	ProtocolVersion = 1
 	MagicKey        = "BLINK_PLUGIN"
-	MagicValue      = "enrichment_v1"
+	MagicValue      = "enrichment" // the same for every protocol version; the versions are negotiated
 	DefaultTimeout  = 5 * time.Second
 )
 
@@ -45,9 +51,23 @@ type BaseEnrichment struct{}
 func (BaseEnrichment) Init() error     { return nil }
 func (BaseEnrichment) Shutdown() error { return nil }
 
+// Configurable is optionally implemented by a EnrichmentPlugin that takes params or secrets from its sidecar.
+// Configure is called with the resolved values right before Init. A config change starts fresh workers, so it runs once per process.
+type Configurable interface {
+	Configure(cfg pluginconfig.Config) error
+}
+
+// HostAware is optionally implemented by a EnrichmentPlugin that queries its host, e.g. to look values up
+// in the host's threat-intel indicators. SetHost is called before Configure and Init, and only when the
+// host serves the API.
+type HostAware interface {
+	SetHost(host hostapi.Host)
+}
+
 type server struct {
 	rpc_enrichments.UnimplementedEnrichmentServer
 	enrichment EnrichmentPlugin
+	broker     *plugin.GRPCBroker
 }
 
 func (s *server) GetMetadata(_ context.Context, _ *rpc_enrichments.Empty) (*rpc_enrichments.EnrichmentMetadata, error) {
@@ -62,7 +82,19 @@ func (s *server) GetMetadata(_ context.Context, _ *rpc_enrichments.Empty) (*rpc_
 	}, nil
 }
 
-func (s *server) Init(_ context.Context, _ *rpc_enrichments.Empty) (*rpc_enrichments.Empty, error) {
+func (s *server) Init(_ context.Context, req *rpc_enrichments.InitRequest) (*rpc_enrichments.Empty, error) {
+	if h, ok := s.enrichment.(HostAware); ok && req.GetHostBrokerId() != 0 {
+		host, err := hostapi.Dial(s.broker, req.GetHostBrokerId())
+		if err != nil {
+			return nil, err
+		}
+		h.SetHost(host)
+	}
+	if c, ok := s.enrichment.(Configurable); ok {
+		if err := c.Configure(pluginconfig.New(req.GetParams(), req.GetSecrets())); err != nil {
+			return nil, err
+		}
+	}
 	return &rpc_enrichments.Empty{}, s.enrichment.Init()
 }
 
@@ -95,8 +127,8 @@ type pluginImpl struct {
 	enrichment EnrichmentPlugin
 }
 
-func (p *pluginImpl) GRPCServer(_ *plugin.GRPCBroker, s *grpc.Server) error {
-	rpc_enrichments.RegisterEnrichmentServer(s, &server{enrichment: p.enrichment})
+func (p *pluginImpl) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	rpc_enrichments.RegisterEnrichmentServer(s, &server{enrichment: p.enrichment, broker: broker})
 	return nil
 }
 
@@ -104,17 +136,31 @@ func (p *pluginImpl) GRPCClient(_ context.Context, _ *plugin.GRPCBroker, c *grpc
 	return rpc_enrichments.NewEnrichmentClient(c), nil
 }
 
-func Serve(e EnrichmentPlugin) {
-	os.Setenv("GODEBUG", "madvdontneed=1")
-	plugin.Serve(&plugin.ServeConfig{
+func serveConfig(e EnrichmentPlugin) *plugin.ServeConfig {
+	return &plugin.ServeConfig{
 		HandshakeConfig: plugin.HandshakeConfig{
 			ProtocolVersion:  ProtocolVersion,
 			MagicCookieKey:   MagicKey,
 			MagicCookieValue: MagicValue,
 		},
 		GRPCServer: plugin.DefaultGRPCServer,
-		Plugins: map[string]plugin.Plugin{
-			"enrichment": &pluginImpl{enrichment: e},
+		VersionedPlugins: map[int]plugin.PluginSet{
+			1: {"enrichment": &pluginImpl{enrichment: e}},
 		},
-	})
+	}
+}
+
+func Serve(e EnrichmentPlugin) {
+	os.Setenv("GODEBUG", "madvdontneed=1")
+	pluginlog.RedirectStdLog()
+	plugin.Serve(serveConfig(e))
+}
+
+// ServeDebug serves e from this process rather than as a host-spawned subprocess, so it can run under a
+// debugger. name is the binary's file name in the host's plugin directory; the host adopts the running
+// enrichment in its place through the reattach file. ServeDebug returns once ctx is cancelled or the process
+// is interrupted.
+func ServeDebug(ctx context.Context, name string, e EnrichmentPlugin) error {
+	pluginlog.RedirectStdLog()
+	return plugindebug.Serve(ctx, name, serveConfig(e))
 }
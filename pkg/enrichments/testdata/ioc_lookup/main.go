@@ -0,0 +1,39 @@
+// ioc_lookup is the enrichment plugin the host API test runs: it looks the alert's src_ip up through
+// the host and copies the hit's source into the alert.
+package main
+
+import (
+	"context"
+
+	"github.com/harishhary/blink/internal/errors"
+	"github.com/harishhary/blink/pkg/enrichments/sdk"
+	"github.com/harishhary/blink/pkg/hostapi"
+)
+
+type lookup struct {
+	sdk.BaseEnrichment
+	host hostapi.Host
+}
+
+func (l *lookup) SetHost(host hostapi.Host) { l.host = host }
+
+func (l *lookup) Metadata() sdk.EnrichmentMetadata {
+	return sdk.EnrichmentMetadata{ID: "ioc-lookup", Name: "ioc-lookup", Enabled: true, Version: "1.0.0"}
+}
+
+func (l *lookup) Enrich(ctx context.Context, alert map[string]any) (map[string]any, errors.Error) {
+	if l.host == nil {
+		return nil, errors.New("no host API")
+	}
+	ip, _ := alert["src_ip"].(string)
+	hits, err := l.host.LookupIndicators(ctx, ip)
+	if err != nil {
+		return nil, errors.NewE(err)
+	}
+	for _, h := range hits {
+		alert["intel_source"] = h.Source
+	}
+	return alert, nil
+}
+
+func main() { sdk.Serve(&lookup{}) }
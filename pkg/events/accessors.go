@@ -0,0 +1,205 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMissing is wrapped by the typed accessors when a path matches nothing.
+var ErrMissing = errors.New("field missing")
+
+// FieldError reports a path that is missing, malformed or holds a value of the wrong type.
+type FieldError struct {
+	Path  string
+	Value any    // the value found; nil if the path matched nothing
+	Want  string // the requested type
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	if e.Value == nil {
+		return fmt.Sprintf("%s: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("%s: cannot read %v (%T) as %s: %v", e.Path, e.Value, e.Value, e.Want, e.Err)
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// value resolves path with Lookup for a typed accessor.
+func (e Event) value(path, want string) (any, error) {
+	p, err := CompilePath(path)
+	if err != nil {
+		return nil, &FieldError{Path: path, Want: want, Err: err}
+	}
+	v, ok := e.lookup(p)
+	if !ok {
+		return nil, &FieldError{Path: path, Want: want, Err: ErrMissing}
+	}
+	return v, nil
+}
+
+// GetString returns the value at path as a string. Numbers and booleans are formatted.
+func (e Event) GetString(path string) (string, error) {
+	v, err := e.value(path, "string")
+	if err != nil {
+		return "", err
+	}
+	switch x := v.(type) {
+	case string:
+		return x, nil
+	case bool, int, int64, float64, json.Number:
+		return fmt.Sprint(x), nil
+	}
+	return "", &FieldError{Path: path, Value: v, Want: "string", Err: errors.ErrUnsupported}
+}
+
+// GetInt returns the value at path as an integer. Strings are parsed; floats must be whole numbers.
+func (e Event) GetInt(path string) (int64, error) {
+	v, err := e.value(path, "integer")
+	if err != nil {
+		return 0, err
+	}
+	switch x := v.(type) {
+	case int:
+		return int64(x), nil
+	case int64:
+		return x, nil
+	case float64:
+		if x == math.Trunc(x) && math.Abs(x) < 1<<63 {
+			return int64(x), nil
+		}
+		err = fmt.Errorf("not a whole number")
+	case json.Number:
+		var n int64
+		if n, err = x.Int64(); err == nil {
+			return n, nil
+		}
+	case string:
+		var n int64
+		if n, err = strconv.ParseInt(strings.TrimSpace(x), 10, 64); err == nil {
+			return n, nil
+		}
+	default:
+		err = errors.ErrUnsupported
+	}
+	return 0, &FieldError{Path: path, Value: v, Want: "integer", Err: err}
+}
+
+// GetFloat returns the value at path as a float. Strings are parsed.
+func (e Event) GetFloat(path string) (float64, error) {
+	v, err := e.value(path, "float")
+	if err != nil {
+		return 0, err
+	}
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case int:
+		return float64(x), nil
+	case int64:
+		return float64(x), nil
+	case json.Number:
+		var f float64
+		if f, err = x.Float64(); err == nil {
+			return f, nil
+		}
+	case string:
+		var f float64
+		if f, err = strconv.ParseFloat(strings.TrimSpace(x), 64); err == nil {
+			return f, nil
+		}
+	default:
+		err = errors.ErrUnsupported
+	}
+	return 0, &FieldError{Path: path, Value: v, Want: "float", Err: err}
+}
+
+// GetBool returns the value at path as a boolean. Strings are parsed with strconv.ParseBool.
+func (e Event) GetBool(path string) (bool, error) {
+	v, err := e.value(path, "boolean")
+	if err != nil {
+		return false, err
+	}
+	switch x := v.(type) {
+	case bool:
+		return x, nil
+	case string:
+		var b bool
+		if b, err = strconv.ParseBool(strings.TrimSpace(x)); err == nil {
+			return b, nil
+		}
+	default:
+		err = errors.ErrUnsupported
+	}
+	return false, &FieldError{Path: path, Value: v, Want: "boolean", Err: err}
+}
+
+// timeLayouts are tried in order for string timestamps.
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05Z07:00", "2006-01-02 15:04:05", time.RFC1123Z, time.RFC1123}
+
+// GetTime returns the value at path as a time. Strings may be RFC 3339 or a few common variants
+// (without a zone they are taken as UTC); numbers and numeric strings are Unix epochs in seconds, or
+// in milliseconds when too large to be seconds.
+func (e Event) GetTime(path string) (time.Time, error) {
+	v, err := e.value(path, "time")
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch x := v.(type) {
+	case time.Time:
+		return x, nil
+	case string:
+		s := strings.TrimSpace(x)
+		for _, layout := range timeLayouts {
+			if t, perr := time.Parse(layout, s); perr == nil {
+				return t, nil
+			}
+		}
+		if f, perr := strconv.ParseFloat(s, 64); perr == nil {
+			return epoch(f), nil
+		}
+		err = fmt.Errorf("unrecognised time format")
+	case float64:
+		return epoch(x), nil
+	case int64:
+		return epoch(float64(x)), nil
+	case int:
+		return epoch(float64(x)), nil
+	default:
+		err = errors.ErrUnsupported
+	}
+	return time.Time{}, &FieldError{Path: path, Value: v, Want: "time", Err: err}
+}
+
+// epoch converts a Unix time to UTC. Values from 1e12 up are milliseconds: as seconds they would be
+// past the year 33000.
+func epoch(f float64) time.Time {
+	if math.Abs(f) >= 1e12 {
+		return time.UnixMilli(int64(f)).UTC()
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+}
+
+// GetIP returns the value at path as an IP address. IPv4-mapped IPv6 addresses are unmapped.
+func (e Event) GetIP(path string) (netip.Addr, error) {
+	v, err := e.value(path, "IP address")
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return netip.Addr{}, &FieldError{Path: path, Value: v, Want: "IP address", Err: errors.ErrUnsupported}
+	}
+	addr, err := netip.ParseAddr(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Addr{}, &FieldError{Path: path, Value: v, Want: "IP address", Err: err}
+	}
+	return addr.Unmap(), nil
+}
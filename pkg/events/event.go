@@ -7,11 +7,76 @@ import (
 
 type Event map[string]any
 
+// NormalizedKey holds the common-schema view of the event that the event matcher attaches when a
+// mapping exists for its log_type. The vendor fields around it are left as they arrived.
+const NormalizedKey = "_normalized"
+
+// AssetsKey holds the inventory assets the event mentions, attached by the event matcher and the alert
+// tagger: a list of objects with the field and value that matched and the asset's id, kind, owner,
+// criticality and tags.
+const AssetsKey = "_assets"
+
+// IOCKey holds the threat-intel indicators the event's values hit, attached by the event matcher: a list
+// of objects with the field and value that hit and the indicator's type, value, confidence, source,
+// expiry, description and labels.
+const IOCKey = "_ioc"
+
+// Normalized returns the event's normalized view, if it has one.
+func (e Event) Normalized() (Event, bool) {
+	switch v := e[NormalizedKey].(type) {
+	case Event:
+		return v, true
+	case map[string]any:
+		return v, true
+	}
+	return nil, false
+}
+
+// Lookup resolves a rule key (req_subkeys, merge_by_keys, observables) written in the Path syntax:
+// first against the original event, then against its normalized view. A wildcard path yields every
+// match as a []any. A malformed path matches nothing; rule configs are validated when they load.
+func (e Event) Lookup(key string) (any, bool) {
+	p, err := CompilePath(key)
+	if err != nil {
+		return nil, false
+	}
+	return e.lookup(p)
+}
+
+func (e Event) lookup(p Path) (any, bool) {
+	for _, view := range []Event{e, e.normalizedOrNil()} {
+		if view == nil {
+			continue
+		}
+		if !p.Wildcard() {
+			if v, ok := p.Get(view); ok {
+				return v, true
+			}
+		} else if all := p.All(view); len(all) > 0 {
+			return all, true
+		}
+	}
+	return nil, false
+}
+
+func (e Event) normalizedOrNil() Event {
+	view, _ := e.Normalized()
+	return view
+}
+
+// Resolve is Lookup with a default, the way merge_by_keys are compared.
+func (e Event) Resolve(key string, defaultValue any) any {
+	if v, ok := e.Lookup(key); ok {
+		return v
+	}
+	return defaultValue
+}
+
 // getMergedKeys retrieves merge keys from a Event
 func (e Event) GetMergedKeys(keys []string) map[string]any {
 	mergeKeys := make(map[string]any)
 	for _, key := range keys {
-		mergeKeys[key] = e.GetFirstKey(key, "N/A")
+		mergeKeys[key] = e.Resolve(key, "N/A")
 	}
 	return mergeKeys
 }
@@ -51,8 +116,11 @@ func (e Event) ComputeDiff(common map[string]any) map[string]any {
 }
 
 func (e Event) DeepGet(keys []string, defaultValue any) any {
-	var current any = e
+	var current any = map[string]any(e)
 	for _, key := range keys {
+		if nested, ok := current.(Event); ok {
+			current = map[string]any(nested)
+		}
 		if dict, ok := current.(map[string]any); ok {
 			if value, found := dict[key]; found {
 				current = value
@@ -106,7 +174,7 @@ func (e Event) DeepWalk(keys []string, defaultValue any, returnVal string) any {
 		return defaultValue
 	}
 
-	walk(e, keys)
+	walk(map[string]any(e), keys)
 	foundList := []any{}
 	for key := range found {
 		foundList = append(foundList, key)
@@ -138,6 +206,10 @@ func (e Event) Get(key string, defaultValue any) any {
 	return defaultValue
 }
 
+// GetFirstKey returns a value stored under key anywhere in the event.
+//
+// Deprecated: when the key occurs more than once the match depends on map iteration order. Use Lookup
+// with a path.
 func (e Event) GetFirstKey(key string, defaultValue any) any {
 	keys := e.GetKeys(key, 1)
 	if len(keys) > 0 {
@@ -0,0 +1,210 @@
+package events
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Path is a compiled field path. The syntax is shared by req_subkeys, merge_by_keys, observables and the
+// typed accessors:
+//
+//	user.name             nested object fields
+//	records[0].ip         an array element; negative indexes count from the end ([-1] is the last)
+//	records[*].ip         every element of an array
+//	tags.*                every value of an object or array
+//	labels["app.kubernetes.io/name"]   a key containing dots or brackets
+//
+// Wildcards visit object keys in sorted order, so the first match of a path is the same on every run.
+type Path struct {
+	raw      string
+	segs     []segment
+	wildcard bool
+}
+
+type segKind uint8
+
+const (
+	segKey segKind = iota
+	segIndex
+	segWildcard
+)
+
+type segment struct {
+	kind  segKind
+	key   string
+	index int
+}
+
+var pathCache sync.Map // string -> Path
+
+// CompilePath parses a path. Paths come from a small set of rule and mapping configs, so compiled paths
+// are cached for the life of the process and each distinct path is parsed once.
+func CompilePath(raw string) (Path, error) {
+	if p, ok := pathCache.Load(raw); ok {
+		return p.(Path), nil
+	}
+	p, err := parsePath(raw)
+	if err != nil {
+		return Path{}, err
+	}
+	pathCache.Store(raw, p)
+	return p, nil
+}
+
+func parsePath(raw string) (Path, error) {
+	p := Path{raw: raw}
+	if raw == "" {
+		return p, fmt.Errorf("empty path")
+	}
+	s := raw
+	expectKey := true // at the start or after a dot, a bare key or * must follow
+	for len(s) > 0 {
+		switch {
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if strings.HasPrefix(s, `["`) {
+				end = strings.Index(s, `"]`)
+				if end < 0 {
+					return p, fmt.Errorf("path %q: unterminated [\"key\"]", raw)
+				}
+				p.segs = append(p.segs, segment{kind: segKey, key: s[2:end]})
+				s = s[end+2:]
+				expectKey = false
+				continue
+			}
+			if end < 0 {
+				return p, fmt.Errorf("path %q: unterminated [", raw)
+			}
+			inner := s[1:end]
+			if inner == "*" {
+				p.segs = append(p.segs, segment{kind: segWildcard})
+				p.wildcard = true
+			} else {
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return p, fmt.Errorf("path %q: index %q is not an integer or *", raw, inner)
+				}
+				p.segs = append(p.segs, segment{kind: segIndex, index: n})
+			}
+			s = s[end+1:]
+			expectKey = false
+		case s[0] == '.':
+			if expectKey {
+				return p, fmt.Errorf("path %q: empty field name", raw)
+			}
+			s = s[1:]
+			expectKey = true
+			if s == "" {
+				return p, fmt.Errorf("path %q: trailing dot", raw)
+			}
+		default:
+			if !expectKey {
+				return p, fmt.Errorf("path %q: expected . or [ before %q", raw, s)
+			}
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if name := s[:end]; name == "*" {
+				p.segs = append(p.segs, segment{kind: segWildcard})
+				p.wildcard = true
+			} else {
+				p.segs = append(p.segs, segment{kind: segKey, key: name})
+			}
+			s = s[end:]
+			expectKey = false
+		}
+	}
+	return p, nil
+}
+
+func (p Path) String() string { return p.raw }
+
+// Wildcard reports whether the path can match more than one value.
+func (p Path) Wildcard() bool { return p.wildcard }
+
+// Get returns the first non-nil value the path matches in e. A path without brackets or wildcards also
+// matches a top-level key spelt exactly like it, for events that arrive with flattened dotted keys.
+func (p Path) Get(e Event) (any, bool) {
+	var found any
+	walk(map[string]any(e), p.segs, func(v any) bool {
+		found = v
+		return false
+	})
+	if found == nil && len(p.segs) > 1 && !strings.ContainsAny(p.raw, "[*") {
+		if v, ok := e[p.raw]; ok && v != nil {
+			return v, true
+		}
+	}
+	return found, found != nil
+}
+
+// All returns every non-nil value the path matches in e, in a deterministic order.
+func (p Path) All(e Event) []any {
+	var out []any
+	walk(map[string]any(e), p.segs, func(v any) bool {
+		out = append(out, v)
+		return true
+	})
+	if out == nil {
+		if v, ok := p.Get(e); ok {
+			out = []any{v}
+		}
+	}
+	return out
+}
+
+// walk calls emit for each non-nil match of segs under v until emit returns false. It reports whether
+// the walk should continue.
+func walk(v any, segs []segment, emit func(any) bool) bool {
+	if v == nil {
+		return true
+	}
+	if len(segs) == 0 {
+		return emit(v)
+	}
+	if e, ok := v.(Event); ok {
+		v = map[string]any(e)
+	}
+	seg, rest := segs[0], segs[1:]
+	switch seg.kind {
+	case segKey:
+		if m, ok := v.(map[string]any); ok {
+			return walk(m[seg.key], rest, emit)
+		}
+	case segIndex:
+		if a, ok := v.([]any); ok {
+			i := seg.index
+			if i < 0 {
+				i += len(a)
+			}
+			if i >= 0 && i < len(a) {
+				return walk(a[i], rest, emit)
+			}
+		}
+	case segWildcard:
+		switch c := v.(type) {
+		case map[string]any:
+			keys := make([]string, 0, len(c))
+			for k := range c {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+			for _, k := range keys {
+				if !walk(c[k], rest, emit) {
+					return false
+				}
+			}
+		case []any:
+			for _, item := range c {
+				if !walk(item, rest, emit) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
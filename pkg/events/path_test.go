@@ -0,0 +1,92 @@
+package events
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testEvent() Event {
+	return Event{
+		"user": map[string]any{"name": "alice", "id": "42"},
+		"records": []any{
+			map[string]any{"ip": "10.0.0.1"},
+			map[string]any{"ip": "10.0.0.2"},
+		},
+		"hosts":       map[string]any{"b": map[string]any{"ip": "10.0.1.2"}, "a": map[string]any{"ip": "10.0.1.1"}},
+		"labels":      map[string]any{"app.kubernetes.io/name": "api"},
+		"source.ip":   "192.0.2.1",
+		"ok":          "true",
+		"at":          "2026-10-19T08:00:00Z",
+		"epoch_ms":    1.7608e12,
+		NormalizedKey: map[string]any{"src_endpoint": map[string]any{"ip": "::ffff:10.9.9.9"}},
+	}
+}
+
+func TestPathGet(t *testing.T) {
+	e := testEvent()
+	tests := []struct {
+		path string
+		want any
+	}{
+		{"user.name", "alice"},
+		{"records[1].ip", "10.0.0.2"},
+		{"records[-1].ip", "10.0.0.2"},
+		{"records[*].ip", []any{"10.0.0.1", "10.0.0.2"}},
+		{"hosts.*.ip", []any{"10.0.1.1", "10.0.1.2"}}, // keys visited in sorted order
+		{`labels["app.kubernetes.io/name"]`, "api"},
+		{"source.ip", "192.0.2.1"},             // flattened dotted key
+		{"src_endpoint.ip", "::ffff:10.9.9.9"}, // normalized view
+		{"records[5].ip", nil},
+		{"user.name.first", nil},
+	}
+	for _, tt := range tests {
+		got, ok := e.Lookup(tt.path)
+		if tt.want == nil {
+			if ok {
+				t.Errorf("Lookup(%s) = %v, want no match", tt.path, got)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Lookup(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCompilePathErrors(t *testing.T) {
+	for _, bad := range []string{"", ".a", "a.", "a..b", "a[", "a[x]", `a["b`, "a[0]b"} {
+		if _, err := CompilePath(bad); err == nil {
+			t.Errorf("CompilePath(%q) accepted", bad)
+		}
+	}
+}
+
+func TestTypedAccessors(t *testing.T) {
+	e := testEvent()
+	if n, err := e.GetInt("user.id"); err != nil || n != 42 {
+		t.Errorf("GetInt = %d, %v", n, err)
+	}
+	if b, err := e.GetBool("ok"); err != nil || !b {
+		t.Errorf("GetBool = %v, %v", b, err)
+	}
+	if ts, err := e.GetTime("at"); err != nil || !ts.Equal(time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("GetTime = %v, %v", ts, err)
+	}
+	if ts, err := e.GetTime("epoch_ms"); err != nil || ts.Year() != 2025 {
+		t.Errorf("GetTime(epoch_ms) = %v, %v", ts, err)
+	}
+	if ip, err := e.GetIP("src_endpoint.ip"); err != nil || ip != netip.MustParseAddr("10.9.9.9") {
+		t.Errorf("GetIP = %v, %v", ip, err)
+	}
+
+	var fieldErr *FieldError
+	if _, err := e.GetInt("user.name"); !errors.As(err, &fieldErr) || fieldErr.Value != "alice" {
+		t.Errorf("GetInt(user.name) error = %v", err)
+	}
+	if _, err := e.GetString("user.missing"); !errors.Is(err, ErrMissing) {
+		t.Errorf("GetString(user.missing) error = %v, want ErrMissing", err)
+	}
+}
@@ -15,11 +15,17 @@ import (
 // FormatterAdapter implements pluginmgr.PluginAdapter[IFormatter].
 type FormatterAdapter struct{}
 
-func (l *FormatterAdapter) PluginKey() string         { return "formatter" }
-func (l *FormatterAdapter) MagicValue() string        { return "formatter_v1" }
-func (l *FormatterAdapter) GRPCPlugin() plugin.Plugin { return &formatterPlugin{} }
+func (l *FormatterAdapter) PluginKey() string  { return "formatter" }
+func (l *FormatterAdapter) MagicValue() string { return "formatter" }
 
-func (l *FormatterAdapter) Handshake(ctx context.Context, raw interface{}, _ string, hash string) (IFormatter, pluginmgr.PluginLifecycle, string, string, error) {
+func (l *FormatterAdapter) Protocols() map[int]plugin.Plugin {
+	return map[int]plugin.Plugin{1: &formatterPlugin{}}
+}
+
+func (l *FormatterAdapter) Handshake(ctx context.Context, raw interface{}, protocol int, _ string, hash string, cfg pluginmgr.ResolvedConfig) (IFormatter, pluginmgr.PluginLifecycle, string, string, error) {
+	if protocol != 1 {
+		return nil, nil, "", "", fmt.Errorf("unsupported plugin protocol v%d", protocol)
+	}
 	rpc, ok := raw.(rpc_formatters.FormatterClient)
 	if !ok {
 		return nil, nil, "", "", fmt.Errorf("dispense: unexpected type %T", raw)
@@ -33,7 +39,7 @@ func (l *FormatterAdapter) Handshake(ctx context.Context, raw interface{}, _ str
 	}
 
 	initCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	_, err = rpc.Init(initCtx, &rpc_formatters.Empty{})
+	_, err = rpc.Init(initCtx, &rpc_formatters.InitRequest{Params: cfg.Params, Secrets: cfg.Secrets})
 	cancel()
 	if err != nil {
 		return nil, nil, "", "", fmt.Errorf("init: %w", err)
@@ -43,9 +49,14 @@ func (l *FormatterAdapter) Handshake(ctx context.Context, raw interface{}, _ str
 	return f, &formatterLifecycle{rpc: rpc}, meta.GetId(), meta.GetName(), nil
 }
 
+// Config reads params and secret references from the optional <binary>.yaml sidecar.
+func (l *FormatterAdapter) Config(binPath string) (pluginmgr.PluginConfig, error) {
+	return pluginmgr.LoadSidecarConfig(binPath)
+}
+
 func (l *FormatterAdapter) IsEnabled(_ *pluginmgr.PluginHandle) bool { return true }
 
-func (l *FormatterAdapter) Workers(_ string) int { return 1 }
+func (l *FormatterAdapter) Workers(_ string) (int, int) { return 1, 1 }
 
 type formatterLifecycle struct {
 	rpc rpc_formatters.FormatterClient
@@ -7,6 +7,6 @@ import (
 
 var formatterManagerMetrics = pluginmgr.NewPluginManagerMetrics("formatters")
 
-func NewManager(log *logger.Logger, notify pluginmgr.Notify, dir string) pluginmgr.Plugin {
+func NewManager(log *logger.Logger, notify pluginmgr.Notify, dir string) *pluginmgr.PluginManager[IFormatter] {
 	return pluginmgr.NewPluginManager[IFormatter](log, notify, dir, &FormatterAdapter{}, formatterManagerMetrics)
 }
@@ -17,9 +17,16 @@ type Pool struct {
 	*internal.ProcessPool[formatters.IFormatter]
 }
 
+// DefaultQueue is the queue policy of formatters whose sidecar does not set one. Shed alerts go to the
+// dead-letter topic so they are formatted on retry.
+var DefaultQueue = internal.QueuePolicy{Shed: internal.ShedDeadLetter}
+
+// NewPool returns an empty pool whose per-plugin queue policies are read from routing, which the
+// plugin manager fills from the sidecars (see pluginmgr.PluginManager.SetRouting).
 func NewPool(routing *internal.RoutingTable, drainTimeout time.Duration) *Pool {
+	queue := routing.QueueConfig(DefaultQueue)
 	return &Pool{
-		ProcessPool: internal.NewProcessPool[formatters.IFormatter](routing.Config(), internal.NewPoolMetrics("formatters"), drainTimeout),
+		ProcessPool: internal.NewProcessPool[formatters.IFormatter](routing.Config(), queue, internal.NewPoolMetrics("formatters"), drainTimeout),
 	}
 }
 
@@ -49,18 +56,19 @@ func (p *Pool) Format(ctx context.Context, formatterID string, alert *alerts.Ale
 }
 
 func (p *Pool) Sync(msg messaging.Message) {
-	register := func(onDrained func(), items []formatters.IFormatter, maxProcs int) {
-		version := items[0].Checksum()
+	register := func(onDrained, onRolledBack func(), workers []internal.Worker[formatters.IFormatter], scaler internal.Scaler[formatters.IFormatter]) {
+		item := workers[0].Plugin
+		version := item.Checksum()
 		if version == "" {
 			version = "1.0.0"
 		}
-		p.Register(internal.PoolKey{PluginID: items[0].Id(), Version: version}, items, maxProcs, onDrained)
+		p.Register(internal.PoolKey{PluginID: item.Id(), Version: version}, workers, scaler, onDrained, onRolledBack)
 	}
 	switch m := msg.(type) {
 	case pluginmgr.RegisterMessage[formatters.IFormatter]:
-		register(nil, m.Items, m.MaxProcs)
+		register(nil, nil, m.Workers(), m.Scaler)
 	case pluginmgr.UpdateMessage[formatters.IFormatter]:
-		register(m.OnDrained, m.Items, m.MaxProcs)
+		register(m.OnDrained, m.OnRolledBack, m.Workers(), m.Scaler)
 	case pluginmgr.UnregisterMessage[formatters.IFormatter]:
 		p.Unregister(m.ItemID)
 	case pluginmgr.RemoveMessage[formatters.IFormatter]:
@@ -125,6 +125,58 @@ func (x *FormatterMetadata) GetEnabled() bool {
 	return false
 }
 
+type InitRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Params        map[string]string      `protobuf:"bytes,1,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`   // sidecar params
+	Secrets       map[string]string      `protobuf:"bytes,2,rep,name=secrets,proto3" json:"secrets,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // resolved secret values
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InitRequest) Reset() {
+	*x = InitRequest{}
+	mi := &file_formatter_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitRequest) ProtoMessage() {}
+
+func (x *InitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_formatter_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitRequest.ProtoReflect.Descriptor instead.
+func (*InitRequest) Descriptor() ([]byte, []int) {
+	return file_formatter_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *InitRequest) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *InitRequest) GetSecrets() map[string]string {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
 type FormatRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AlertJson     []byte                 `protobuf:"bytes,1,opt,name=alert_json,json=alertJson,proto3" json:"alert_json,omitempty"` // JSON-encoded alerts.Alert
@@ -134,7 +186,7 @@ type FormatRequest struct {
 
 func (x *FormatRequest) Reset() {
 	*x = FormatRequest{}
-	mi := &file_formatter_proto_msgTypes[2]
+	mi := &file_formatter_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -146,7 +198,7 @@ func (x *FormatRequest) String() string {
 func (*FormatRequest) ProtoMessage() {}
 
 func (x *FormatRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_formatter_proto_msgTypes[2]
+	mi := &file_formatter_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -159,7 +211,7 @@ func (x *FormatRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FormatRequest.ProtoReflect.Descriptor instead.
 func (*FormatRequest) Descriptor() ([]byte, []int) {
-	return file_formatter_proto_rawDescGZIP(), []int{2}
+	return file_formatter_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *FormatRequest) GetAlertJson() []byte {
@@ -178,7 +230,7 @@ type FormatResponse struct {
 
 func (x *FormatResponse) Reset() {
 	*x = FormatResponse{}
-	mi := &file_formatter_proto_msgTypes[3]
+	mi := &file_formatter_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -190,7 +242,7 @@ func (x *FormatResponse) String() string {
 func (*FormatResponse) ProtoMessage() {}
 
 func (x *FormatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_formatter_proto_msgTypes[3]
+	mi := &file_formatter_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -203,7 +255,7 @@ func (x *FormatResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FormatResponse.ProtoReflect.Descriptor instead.
 func (*FormatResponse) Descriptor() ([]byte, []int) {
-	return file_formatter_proto_rawDescGZIP(), []int{3}
+	return file_formatter_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *FormatResponse) GetResultJson() []byte {
@@ -224,16 +276,25 @@ const file_formatter_proto_rawDesc = "" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x18\n" +
-	"\aenabled\x18\x04 \x01(\bR\aenabled\".\n" +
+	"\aenabled\x18\x04 \x01(\bR\aenabled\"\x81\x02\n" +
+	"\vInitRequest\x12;\n" +
+	"\x06params\x18\x01 \x03(\v2#.formatters.InitRequest.ParamsEntryR\x06params\x12>\n" +
+	"\asecrets\x18\x02 \x03(\v2$.formatters.InitRequest.SecretsEntryR\asecrets\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a:\n" +
+	"\fSecretsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\".\n" +
 	"\rFormatRequest\x12\x1d\n" +
 	"\n" +
 	"alert_json\x18\x01 \x01(\fR\talertJson\"1\n" +
 	"\x0eFormatResponse\x12\x1f\n" +
 	"\vresult_json\x18\x01 \x01(\fR\n" +
-	"resultJson2\x9b\x02\n" +
+	"resultJson2\xa1\x02\n" +
 	"\tFormatter\x12?\n" +
-	"\vGetMetadata\x12\x11.formatters.Empty\x1a\x1d.formatters.FormatterMetadata\x12,\n" +
-	"\x04Init\x12\x11.formatters.Empty\x1a\x11.formatters.Empty\x12?\n" +
+	"\vGetMetadata\x12\x11.formatters.Empty\x1a\x1d.formatters.FormatterMetadata\x122\n" +
+	"\x04Init\x12\x17.formatters.InitRequest\x1a\x11.formatters.Empty\x12?\n" +
 	"\x06Format\x12\x19.formatters.FormatRequest\x1a\x1a.formatters.FormatResponse\x120\n" +
 	"\bShutdown\x12\x11.formatters.Empty\x1a\x11.formatters.Empty\x12,\n" +
 	"\x04Ping\x12\x11.formatters.Empty\x1a\x11.formatters.EmptyB;Z9github.com/harishhary/blink/pkg/formatters/rpc_formattersb\x06proto3"
@@ -250,29 +311,34 @@ func file_formatter_proto_rawDescGZIP() []byte {
 	return file_formatter_proto_rawDescData
 }
 
-var file_formatter_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_formatter_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_formatter_proto_goTypes = []any{
 	(*Empty)(nil),             // 0: formatters.Empty
 	(*FormatterMetadata)(nil), // 1: formatters.FormatterMetadata
-	(*FormatRequest)(nil),     // 2: formatters.FormatRequest
-	(*FormatResponse)(nil),    // 3: formatters.FormatResponse
+	(*InitRequest)(nil),       // 2: formatters.InitRequest
+	(*FormatRequest)(nil),     // 3: formatters.FormatRequest
+	(*FormatResponse)(nil),    // 4: formatters.FormatResponse
+	nil,                       // 5: formatters.InitRequest.ParamsEntry
+	nil,                       // 6: formatters.InitRequest.SecretsEntry
 }
 var file_formatter_proto_depIdxs = []int32{
-	0, // 0: formatters.Formatter.GetMetadata:input_type -> formatters.Empty
-	0, // 1: formatters.Formatter.Init:input_type -> formatters.Empty
-	2, // 2: formatters.Formatter.Format:input_type -> formatters.FormatRequest
-	0, // 3: formatters.Formatter.Shutdown:input_type -> formatters.Empty
-	0, // 4: formatters.Formatter.Ping:input_type -> formatters.Empty
-	1, // 5: formatters.Formatter.GetMetadata:output_type -> formatters.FormatterMetadata
-	0, // 6: formatters.Formatter.Init:output_type -> formatters.Empty
-	3, // 7: formatters.Formatter.Format:output_type -> formatters.FormatResponse
-	0, // 8: formatters.Formatter.Shutdown:output_type -> formatters.Empty
-	0, // 9: formatters.Formatter.Ping:output_type -> formatters.Empty
-	5, // [5:10] is the sub-list for method output_type
-	0, // [0:5] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	5, // 0: formatters.InitRequest.params:type_name -> formatters.InitRequest.ParamsEntry
+	6, // 1: formatters.InitRequest.secrets:type_name -> formatters.InitRequest.SecretsEntry
+	0, // 2: formatters.Formatter.GetMetadata:input_type -> formatters.Empty
+	2, // 3: formatters.Formatter.Init:input_type -> formatters.InitRequest
+	3, // 4: formatters.Formatter.Format:input_type -> formatters.FormatRequest
+	0, // 5: formatters.Formatter.Shutdown:input_type -> formatters.Empty
+	0, // 6: formatters.Formatter.Ping:input_type -> formatters.Empty
+	1, // 7: formatters.Formatter.GetMetadata:output_type -> formatters.FormatterMetadata
+	0, // 8: formatters.Formatter.Init:output_type -> formatters.Empty
+	4, // 9: formatters.Formatter.Format:output_type -> formatters.FormatResponse
+	0, // 10: formatters.Formatter.Shutdown:output_type -> formatters.Empty
+	0, // 11: formatters.Formatter.Ping:output_type -> formatters.Empty
+	7, // [7:12] is the sub-list for method output_type
+	2, // [2:7] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_formatter_proto_init() }
@@ -286,7 +352,7 @@ func file_formatter_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_formatter_proto_rawDesc), len(file_formatter_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -31,7 +31,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type FormatterClient interface {
 	GetMetadata(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*FormatterMetadata, error)
-	Init(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	Init(ctx context.Context, in *InitRequest, opts ...grpc.CallOption) (*Empty, error)
 	Format(ctx context.Context, in *FormatRequest, opts ...grpc.CallOption) (*FormatResponse, error)
 	Shutdown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
@@ -55,7 +55,7 @@ func (c *formatterClient) GetMetadata(ctx context.Context, in *Empty, opts ...gr
 	return out, nil
 }
 
-func (c *formatterClient) Init(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+func (c *formatterClient) Init(ctx context.Context, in *InitRequest, opts ...grpc.CallOption) (*Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, Formatter_Init_FullMethodName, in, out, cOpts...)
@@ -100,7 +100,7 @@ func (c *formatterClient) Ping(ctx context.Context, in *Empty, opts ...grpc.Call
 // for forward compatibility.
 type FormatterServer interface {
 	GetMetadata(context.Context, *Empty) (*FormatterMetadata, error)
-	Init(context.Context, *Empty) (*Empty, error)
+	Init(context.Context, *InitRequest) (*Empty, error)
 	Format(context.Context, *FormatRequest) (*FormatResponse, error)
 	Shutdown(context.Context, *Empty) (*Empty, error)
 	Ping(context.Context, *Empty) (*Empty, error)
@@ -117,7 +117,7 @@ type UnimplementedFormatterServer struct{}
 func (UnimplementedFormatterServer) GetMetadata(context.Context, *Empty) (*FormatterMetadata, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMetadata not implemented")
 }
-func (UnimplementedFormatterServer) Init(context.Context, *Empty) (*Empty, error) {
+func (UnimplementedFormatterServer) Init(context.Context, *InitRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Init not implemented")
 }
 func (UnimplementedFormatterServer) Format(context.Context, *FormatRequest) (*FormatResponse, error) {
@@ -169,7 +169,7 @@ func _Formatter_GetMetadata_Handler(srv interface{}, ctx context.Context, dec fu
 }
 
 func _Formatter_Init_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
+	in := new(InitRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -181,7 +181,7 @@ func _Formatter_Init_Handler(srv interface{}, ctx context.Context, dec func(inte
 		FullMethod: Formatter_Init_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(FormatterServer).Init(ctx, req.(*Empty))
+		return srv.(FormatterServer).Init(ctx, req.(*InitRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -10,12 +10,17 @@ import (
 
 	"github.com/harishhary/blink/internal/errors"
 	"github.com/harishhary/blink/pkg/formatters/rpc_formatters"
+	"github.com/harishhary/blink/pkg/pluginconfig"
+	"github.com/harishhary/blink/pkg/plugindebug"
+	"github.com/harishhary/blink/pkg/pluginlog"
 )
 
 const (
+	// ProtocolVersion is the highest plugin protocol this SDK speaks. The host negotiates the highest
+	// version both sides support, so binaries keep working with hosts that still run older versions.
 	ProtocolVersion = 1
 	MagicKey        = "BLINK_PLUGIN"
-	MagicValue      = "formatter_v1"
+	MagicValue      = "formatter" // the same for every protocol version; the versions are negotiated
 )
 
 type FormatterMetadata struct {
@@ -38,6 +43,12 @@ type BaseFormatter struct{}
 func (BaseFormatter) Init() error     { return nil }
 func (BaseFormatter) Shutdown() error { return nil }
 
+// Configurable is optionally implemented by a FormatterPlugin that takes params or secrets from its sidecar.
+// Configure is called with the resolved values right before Init. A config change starts fresh workers, so it runs once per process.
+type Configurable interface {
+	Configure(cfg pluginconfig.Config) error
+}
+
 type server struct {
 	rpc_formatters.UnimplementedFormatterServer
 	formatter FormatterPlugin
@@ -53,7 +64,12 @@ func (s *server) GetMetadata(_ context.Context, _ *rpc_formatters.Empty) (*rpc_f
 	}, nil
 }
 
-func (s *server) Init(_ context.Context, _ *rpc_formatters.Empty) (*rpc_formatters.Empty, error) {
+func (s *server) Init(_ context.Context, req *rpc_formatters.InitRequest) (*rpc_formatters.Empty, error) {
+	if c, ok := s.formatter.(Configurable); ok {
+		if err := c.Configure(pluginconfig.New(req.GetParams(), req.GetSecrets())); err != nil {
+			return nil, err
+		}
+	}
 	return &rpc_formatters.Empty{}, s.formatter.Init()
 }
 
@@ -95,17 +111,31 @@ func (p *pluginImpl) GRPCClient(_ context.Context, _ *plugin.GRPCBroker, c *grpc
 	return rpc_formatters.NewFormatterClient(c), nil
 }
 
-func Serve(f FormatterPlugin) {
-	os.Setenv("GODEBUG", "madvdontneed=1")
-	plugin.Serve(&plugin.ServeConfig{
+func serveConfig(f FormatterPlugin) *plugin.ServeConfig {
+	return &plugin.ServeConfig{
 		HandshakeConfig: plugin.HandshakeConfig{
 			ProtocolVersion:  ProtocolVersion,
 			MagicCookieKey:   MagicKey,
 			MagicCookieValue: MagicValue,
 		},
 		GRPCServer: plugin.DefaultGRPCServer,
-		Plugins: map[string]plugin.Plugin{
-			"formatter": &pluginImpl{formatter: f},
+		VersionedPlugins: map[int]plugin.PluginSet{
+			1: {"formatter": &pluginImpl{formatter: f}},
 		},
-	})
+	}
+}
+
+func Serve(f FormatterPlugin) {
+	os.Setenv("GODEBUG", "madvdontneed=1")
+	pluginlog.RedirectStdLog()
+	plugin.Serve(serveConfig(f))
+}
+
+// ServeDebug serves f from this process rather than as a host-spawned subprocess, so it can run under a
+// debugger. name is the binary's file name in the host's plugin directory; the host adopts the running
+// formatter in its place through the reattach file. ServeDebug returns once ctx is cancelled or the process
+// is interrupted.
+func ServeDebug(ctx context.Context, name string, f FormatterPlugin) error {
+	pluginlog.RedirectStdLog()
+	return plugindebug.Serve(ctx, name, serveConfig(f))
 }
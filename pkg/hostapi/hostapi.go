@@ -0,0 +1,124 @@
+// Package hostapi is the API a plugin host serves back to the plugins it runs. The host starts it on the
+// go-plugin broker of each plugin process and passes the broker ID in the plugin's Init request; the SDK
+// dials it and hands the plugin a Host.
+package hostapi
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/harishhary/blink/pkg/hostapi/rpc_hostapi"
+)
+
+// ErrNoIndicators is returned by LookupIndicators when the host has no threat-intel feeds loaded.
+var ErrNoIndicators = stderrors.New("hostapi: the host has no indicator feeds")
+
+// Host is what a plugin can ask of its host.
+type Host interface {
+	// LookupIndicators matches each value, whatever observable it is, against the host's live
+	// threat-intel indicators and returns the hits in the order of values. Values that hit nothing are
+	// left out.
+	LookupIndicators(ctx context.Context, values ...string) ([]IndicatorHit, error)
+}
+
+// IndicatorHit is a value that hit an indicator of compromise.
+type IndicatorHit struct {
+	Value       string // as looked up
+	Type        string // ip, cidr, domain, url, hash or email
+	Indicator   string // the indicator's value, e.g. the network or parent domain that value falls under
+	Confidence  int    // 0-100
+	Source      string
+	Expires     time.Time // zero: never
+	Description string
+	Labels      []string
+}
+
+// Serve starts host on broker for one plugin process and returns the broker ID the plugin dials. It
+// serves until the broker closes with the plugin's connection.
+func Serve(broker *plugin.GRPCBroker, host Host) uint32 {
+	id := broker.NextId()
+	go broker.AcceptAndServe(id, func(opts []grpc.ServerOption) *grpc.Server {
+		s := grpc.NewServer(opts...)
+		rpc_hostapi.RegisterHostServer(s, &server{host: host})
+		return s
+	})
+	return id
+}
+
+// Dial connects a plugin to the Host its host serves under id.
+func Dial(broker *plugin.GRPCBroker, id uint32) (Host, error) {
+	conn, err := broker.Dial(id)
+	if err != nil {
+		return nil, err
+	}
+	return &client{rpc: rpc_hostapi.NewHostClient(conn)}, nil
+}
+
+type server struct {
+	rpc_hostapi.UnimplementedHostServer
+	host Host
+}
+
+func (s *server) LookupIndicators(ctx context.Context, req *rpc_hostapi.LookupIndicatorsRequest) (*rpc_hostapi.LookupIndicatorsResponse, error) {
+	hits, err := s.host.LookupIndicators(ctx, req.GetValues()...)
+	if stderrors.Is(err, ErrNoIndicators) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp := &rpc_hostapi.LookupIndicatorsResponse{Hits: make([]*rpc_hostapi.IndicatorHit, len(hits))}
+	for i, h := range hits {
+		var expires int64
+		if !h.Expires.IsZero() {
+			expires = h.Expires.Unix()
+		}
+		resp.Hits[i] = &rpc_hostapi.IndicatorHit{
+			Value:       h.Value,
+			Type:        h.Type,
+			Indicator:   h.Indicator,
+			Confidence:  int32(h.Confidence),
+			Source:      h.Source,
+			ExpiresUnix: expires,
+			Description: h.Description,
+			Labels:      h.Labels,
+		}
+	}
+	return resp, nil
+}
+
+type client struct {
+	rpc rpc_hostapi.HostClient
+}
+
+func (c *client) LookupIndicators(ctx context.Context, values ...string) ([]IndicatorHit, error) {
+	resp, err := c.rpc.LookupIndicators(ctx, &rpc_hostapi.LookupIndicatorsRequest{Values: values})
+	if status.Code(err) == codes.FailedPrecondition {
+		return nil, ErrNoIndicators
+	}
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]IndicatorHit, len(resp.GetHits()))
+	for i, h := range resp.GetHits() {
+		hits[i] = IndicatorHit{
+			Value:       h.GetValue(),
+			Type:        h.GetType(),
+			Indicator:   h.GetIndicator(),
+			Confidence:  int(h.GetConfidence()),
+			Source:      h.GetSource(),
+			Description: h.GetDescription(),
+			Labels:      h.GetLabels(),
+		}
+		if e := h.GetExpiresUnix(); e != 0 {
+			hits[i].Expires = time.Unix(e, 0).UTC()
+		}
+	}
+	return hits, nil
+}
@@ -0,0 +1,286 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v7.34.0
+// source: hostapi.proto
+
+package rpc_hostapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type LookupIndicatorsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []string               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupIndicatorsRequest) Reset() {
+	*x = LookupIndicatorsRequest{}
+	mi := &file_hostapi_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupIndicatorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupIndicatorsRequest) ProtoMessage() {}
+
+func (x *LookupIndicatorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hostapi_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupIndicatorsRequest.ProtoReflect.Descriptor instead.
+func (*LookupIndicatorsRequest) Descriptor() ([]byte, []int) {
+	return file_hostapi_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LookupIndicatorsRequest) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type IndicatorHit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Indicator     string                 `protobuf:"bytes,3,opt,name=indicator,proto3" json:"indicator,omitempty"`
+	Confidence    int32                  `protobuf:"varint,4,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Source        string                 `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	ExpiresUnix   int64                  `protobuf:"varint,6,opt,name=expires_unix,json=expiresUnix,proto3" json:"expires_unix,omitempty"`
+	Description   string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
+	Labels        []string               `protobuf:"bytes,8,rep,name=labels,proto3" json:"labels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndicatorHit) Reset() {
+	*x = IndicatorHit{}
+	mi := &file_hostapi_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndicatorHit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndicatorHit) ProtoMessage() {}
+
+func (x *IndicatorHit) ProtoReflect() protoreflect.Message {
+	mi := &file_hostapi_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndicatorHit.ProtoReflect.Descriptor instead.
+func (*IndicatorHit) Descriptor() ([]byte, []int) {
+	return file_hostapi_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *IndicatorHit) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *IndicatorHit) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *IndicatorHit) GetIndicator() string {
+	if x != nil {
+		return x.Indicator
+	}
+	return ""
+}
+
+func (x *IndicatorHit) GetConfidence() int32 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *IndicatorHit) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *IndicatorHit) GetExpiresUnix() int64 {
+	if x != nil {
+		return x.ExpiresUnix
+	}
+	return 0
+}
+
+func (x *IndicatorHit) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *IndicatorHit) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type LookupIndicatorsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hits          []*IndicatorHit        `protobuf:"bytes,1,rep,name=hits,proto3" json:"hits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupIndicatorsResponse) Reset() {
+	*x = LookupIndicatorsResponse{}
+	mi := &file_hostapi_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupIndicatorsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupIndicatorsResponse) ProtoMessage() {}
+
+func (x *LookupIndicatorsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hostapi_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupIndicatorsResponse.ProtoReflect.Descriptor instead.
+func (*LookupIndicatorsResponse) Descriptor() ([]byte, []int) {
+	return file_hostapi_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LookupIndicatorsResponse) GetHits() []*IndicatorHit {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+var File_hostapi_proto protoreflect.FileDescriptor
+
+const file_hostapi_proto_rawDesc = "" +
+	"\n" +
+	"\rhostapi.proto\x12\ahostapi\"1\n" +
+	"\x17LookupIndicatorsRequest\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\tR\x06values\"\xeb\x01\n" +
+	"\fIndicatorHit\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1c\n" +
+	"\tindicator\x18\x03 \x01(\tR\tindicator\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x04 \x01(\x05R\n" +
+	"confidence\x12\x16\n" +
+	"\x06source\x18\x05 \x01(\tR\x06source\x12!\n" +
+	"\fexpires_unix\x18\x06 \x01(\x03R\vexpiresUnix\x12 \n" +
+	"\vdescription\x18\a \x01(\tR\vdescription\x12\x16\n" +
+	"\x06labels\x18\b \x03(\tR\x06labels\"E\n" +
+	"\x18LookupIndicatorsResponse\x12)\n" +
+	"\x04hits\x18\x01 \x03(\v2\x15.hostapi.IndicatorHitR\x04hits2_\n" +
+	"\x04Host\x12W\n" +
+	"\x10LookupIndicators\x12 .hostapi.LookupIndicatorsRequest\x1a!.hostapi.LookupIndicatorsResponseB\x1aZ\x18rpc_hostapi/;rpc_hostapib\x06proto3"
+
+var (
+	file_hostapi_proto_rawDescOnce sync.Once
+	file_hostapi_proto_rawDescData []byte
+)
+
+func file_hostapi_proto_rawDescGZIP() []byte {
+	file_hostapi_proto_rawDescOnce.Do(func() {
+		file_hostapi_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_hostapi_proto_rawDesc), len(file_hostapi_proto_rawDesc)))
+	})
+	return file_hostapi_proto_rawDescData
+}
+
+var file_hostapi_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_hostapi_proto_goTypes = []any{
+	(*LookupIndicatorsRequest)(nil),  // 0: hostapi.LookupIndicatorsRequest
+	(*IndicatorHit)(nil),             // 1: hostapi.IndicatorHit
+	(*LookupIndicatorsResponse)(nil), // 2: hostapi.LookupIndicatorsResponse
+}
+var file_hostapi_proto_depIdxs = []int32{
+	1, // 0: hostapi.LookupIndicatorsResponse.hits:type_name -> hostapi.IndicatorHit
+	0, // 1: hostapi.Host.LookupIndicators:input_type -> hostapi.LookupIndicatorsRequest
+	2, // 2: hostapi.Host.LookupIndicators:output_type -> hostapi.LookupIndicatorsResponse
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_hostapi_proto_init() }
+func file_hostapi_proto_init() {
+	if File_hostapi_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_hostapi_proto_rawDesc), len(file_hostapi_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_hostapi_proto_goTypes,
+		DependencyIndexes: file_hostapi_proto_depIdxs,
+		MessageInfos:      file_hostapi_proto_msgTypes,
+	}.Build()
+	File_hostapi_proto = out.File
+	file_hostapi_proto_goTypes = nil
+	file_hostapi_proto_depIdxs = nil
+}
@@ -14,11 +14,17 @@ import (
 
 type MatcherAdapter struct{}
 
-func (l *MatcherAdapter) PluginKey() string         { return "matcher" }
-func (l *MatcherAdapter) MagicValue() string        { return "matcher_v1" }
-func (l *MatcherAdapter) GRPCPlugin() plugin.Plugin { return &matcherPlugin{} }
+func (l *MatcherAdapter) PluginKey() string  { return "matcher" }
+func (l *MatcherAdapter) MagicValue() string { return "matcher" }
 
-func (l *MatcherAdapter) Handshake(ctx context.Context, raw interface{}, _ string, hash string) (Matcher, pluginmgr.PluginLifecycle, string, string, error) {
+func (l *MatcherAdapter) Protocols() map[int]plugin.Plugin {
+	return map[int]plugin.Plugin{1: &matcherPlugin{}}
+}
+
+func (l *MatcherAdapter) Handshake(ctx context.Context, raw interface{}, protocol int, _ string, hash string, cfg pluginmgr.ResolvedConfig) (Matcher, pluginmgr.PluginLifecycle, string, string, error) {
+	if protocol != 1 {
+		return nil, nil, "", "", fmt.Errorf("unsupported plugin protocol v%d", protocol)
+	}
 	rpc, ok := raw.(rpc_matchers.MatcherClient)
 	if !ok {
 		return nil, nil, "", "", fmt.Errorf("dispense: unexpected type %T", raw)
@@ -32,7 +38,7 @@ func (l *MatcherAdapter) Handshake(ctx context.Context, raw interface{}, _ strin
 	}
 
 	initCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	_, err = rpc.Init(initCtx, &rpc_matchers.Empty{})
+	_, err = rpc.Init(initCtx, &rpc_matchers.InitRequest{Params: cfg.Params, Secrets: cfg.Secrets})
 	cancel()
 	if err != nil {
 		return nil, nil, "", "", fmt.Errorf("init: %w", err)
@@ -42,9 +48,14 @@ func (l *MatcherAdapter) Handshake(ctx context.Context, raw interface{}, _ strin
 	return m, &matcherLifecycle{rpc: rpc}, meta.GetId(), meta.GetName(), nil
 }
 
+// Config reads params and secret references from the optional <binary>.yaml sidecar.
+func (l *MatcherAdapter) Config(binPath string) (pluginmgr.PluginConfig, error) {
+	return pluginmgr.LoadSidecarConfig(binPath)
+}
+
 func (l *MatcherAdapter) IsEnabled(_ *pluginmgr.PluginHandle) bool { return true }
 
-func (l *MatcherAdapter) Workers(_ string) int { return 1 }
+func (l *MatcherAdapter) Workers(_ string) (int, int) { return 1, 1 }
 
 type matcherLifecycle struct{ rpc rpc_matchers.MatcherClient }
 
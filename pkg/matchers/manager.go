@@ -7,6 +7,6 @@ import (
 
 var matcherManagerMetrics = pluginmgr.NewPluginManagerMetrics("matchersvc")
 
-func NewManager(log *logger.Logger, notify pluginmgr.Notify, dir string) pluginmgr.Plugin {
+func NewManager(log *logger.Logger, notify pluginmgr.Notify, dir string) *pluginmgr.PluginManager[Matcher] {
 	return pluginmgr.NewPluginManager[Matcher](log, notify, dir, &MatcherAdapter{}, matcherManagerMetrics)
 }
@@ -16,9 +16,16 @@ type Pool struct {
 	*internal.ProcessPool[matchers.Matcher]
 }
 
+// DefaultQueue is the queue policy of matchers whose sidecar does not set one. A shed matcher passes:
+// routing an event to one rule too many is cheaper than dropping it.
+var DefaultQueue = internal.QueuePolicy{Shed: internal.ShedPassThrough}
+
+// NewPool returns an empty pool whose per-plugin queue policies are read from routing, which the
+// plugin manager fills from the sidecars (see pluginmgr.PluginManager.SetRouting).
 func NewPool(routing *internal.RoutingTable, drainTimeout time.Duration) *Pool {
+	queue := routing.QueueConfig(DefaultQueue)
 	return &Pool{
-		ProcessPool: internal.NewProcessPool[matchers.Matcher](routing.Config(), internal.NewPoolMetrics("matchers"), drainTimeout),
+		ProcessPool: internal.NewProcessPool[matchers.Matcher](routing.Config(), queue, internal.NewPoolMetrics("matchers"), drainTimeout),
 	}
 }
 
@@ -42,18 +49,19 @@ func (p *Pool) Match(ctx context.Context, matcherID string, event events.Event,
 
 // Handles plugin lifecycle messages from the plugin manager bus, registering or deregistering matchers in the pool.
 func (p *Pool) Sync(msg messaging.Message) {
-	register := func(onDrained func(), items []matchers.Matcher, maxProcs int) {
-		version := items[0].Checksum()
+	register := func(onDrained, onRolledBack func(), workers []internal.Worker[matchers.Matcher], scaler internal.Scaler[matchers.Matcher]) {
+		item := workers[0].Plugin
+		version := item.Checksum()
 		if version == "" {
 			version = "1.0.0"
 		}
-		p.Register(internal.PoolKey{PluginID: items[0].Id(), Version: version}, items, maxProcs, onDrained)
+		p.Register(internal.PoolKey{PluginID: item.Id(), Version: version}, workers, scaler, onDrained, onRolledBack)
 	}
 	switch m := msg.(type) {
 	case pluginmgr.RegisterMessage[matchers.Matcher]:
-		register(nil, m.Items, m.MaxProcs)
+		register(nil, nil, m.Workers(), m.Scaler)
 	case pluginmgr.UpdateMessage[matchers.Matcher]:
-		register(m.OnDrained, m.Items, m.MaxProcs)
+		register(m.OnDrained, m.OnRolledBack, m.Workers(), m.Scaler)
 	case pluginmgr.UnregisterMessage[matchers.Matcher]:
 		p.Unregister(m.ItemID)
 	case pluginmgr.RemoveMessage[matchers.Matcher]:
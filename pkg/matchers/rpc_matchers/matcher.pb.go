@@ -141,6 +141,58 @@ func (x *MatcherMetadata) GetVersion() string {
 	return ""
 }
 
+type InitRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Params        map[string]string      `protobuf:"bytes,1,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Secrets       map[string]string      `protobuf:"bytes,2,rep,name=secrets,proto3" json:"secrets,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InitRequest) Reset() {
+	*x = InitRequest{}
+	mi := &file_matcher_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitRequest) ProtoMessage() {}
+
+func (x *InitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_matcher_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitRequest.ProtoReflect.Descriptor instead.
+func (*InitRequest) Descriptor() ([]byte, []int) {
+	return file_matcher_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *InitRequest) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *InitRequest) GetSecrets() map[string]string {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
 type Event struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Json          []byte                 `protobuf:"bytes,1,opt,name=json,proto3" json:"json,omitempty"`
@@ -150,7 +202,7 @@ type Event struct {
 
 func (x *Event) Reset() {
 	*x = Event{}
-	mi := &file_matcher_proto_msgTypes[2]
+	mi := &file_matcher_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -162,7 +214,7 @@ func (x *Event) String() string {
 func (*Event) ProtoMessage() {}
 
 func (x *Event) ProtoReflect() protoreflect.Message {
-	mi := &file_matcher_proto_msgTypes[2]
+	mi := &file_matcher_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -175,7 +227,7 @@ func (x *Event) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Event.ProtoReflect.Descriptor instead.
 func (*Event) Descriptor() ([]byte, []int) {
-	return file_matcher_proto_rawDescGZIP(), []int{2}
+	return file_matcher_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Event) GetJson() []byte {
@@ -194,7 +246,7 @@ type MatchRequest struct {
 
 func (x *MatchRequest) Reset() {
 	*x = MatchRequest{}
-	mi := &file_matcher_proto_msgTypes[3]
+	mi := &file_matcher_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -206,7 +258,7 @@ func (x *MatchRequest) String() string {
 func (*MatchRequest) ProtoMessage() {}
 
 func (x *MatchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_matcher_proto_msgTypes[3]
+	mi := &file_matcher_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -219,7 +271,7 @@ func (x *MatchRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MatchRequest.ProtoReflect.Descriptor instead.
 func (*MatchRequest) Descriptor() ([]byte, []int) {
-	return file_matcher_proto_rawDescGZIP(), []int{3}
+	return file_matcher_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *MatchRequest) GetEvent() *Event {
@@ -238,7 +290,7 @@ type MatchResponse struct {
 
 func (x *MatchResponse) Reset() {
 	*x = MatchResponse{}
-	mi := &file_matcher_proto_msgTypes[4]
+	mi := &file_matcher_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -250,7 +302,7 @@ func (x *MatchResponse) String() string {
 func (*MatchResponse) ProtoMessage() {}
 
 func (x *MatchResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_matcher_proto_msgTypes[4]
+	mi := &file_matcher_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -263,7 +315,7 @@ func (x *MatchResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MatchResponse.ProtoReflect.Descriptor instead.
 func (*MatchResponse) Descriptor() ([]byte, []int) {
-	return file_matcher_proto_rawDescGZIP(), []int{4}
+	return file_matcher_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *MatchResponse) GetMatched() bool {
@@ -285,16 +337,25 @@ const file_matcher_proto_rawDesc = "" +
 	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x18\n" +
 	"\aenabled\x18\x04 \x01(\bR\aenabled\x12\x16\n" +
 	"\x06global\x18\x05 \x01(\bR\x06global\x12\x18\n" +
-	"\aversion\x18\x06 \x01(\tR\aversion\"\x1b\n" +
+	"\aversion\x18\x06 \x01(\tR\aversion\"\xfd\x01\n" +
+	"\vInitRequest\x129\n" +
+	"\x06params\x18\x01 \x03(\v2!.matchers.InitRequest.ParamsEntryR\x06params\x12<\n" +
+	"\asecrets\x18\x02 \x03(\v2\".matchers.InitRequest.SecretsEntryR\asecrets\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a:\n" +
+	"\fSecretsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x1b\n" +
 	"\x05Event\x12\x12\n" +
 	"\x04json\x18\x01 \x01(\fR\x04json\"5\n" +
 	"\fMatchRequest\x12%\n" +
 	"\x05event\x18\x01 \x01(\v2\x0f.matchers.EventR\x05event\")\n" +
 	"\rMatchResponse\x12\x18\n" +
-	"\amatched\x18\x01 \x01(\bR\amatched2\x80\x02\n" +
+	"\amatched\x18\x01 \x01(\bR\amatched2\x86\x02\n" +
 	"\aMatcher\x129\n" +
-	"\vGetMetadata\x12\x0f.matchers.Empty\x1a\x19.matchers.MatcherMetadata\x12(\n" +
-	"\x04Init\x12\x0f.matchers.Empty\x1a\x0f.matchers.Empty\x128\n" +
+	"\vGetMetadata\x12\x0f.matchers.Empty\x1a\x19.matchers.MatcherMetadata\x12.\n" +
+	"\x04Init\x12\x15.matchers.InitRequest\x1a\x0f.matchers.Empty\x128\n" +
 	"\x05Match\x12\x16.matchers.MatchRequest\x1a\x17.matchers.MatchResponse\x12,\n" +
 	"\bShutdown\x12\x0f.matchers.Empty\x1a\x0f.matchers.Empty\x12(\n" +
 	"\x04Ping\x12\x0f.matchers.Empty\x1a\x0f.matchers.EmptyB\x1cZ\x1arpc_matchers/;rpc_matchersb\x06proto3"
@@ -311,31 +372,36 @@ func file_matcher_proto_rawDescGZIP() []byte {
 	return file_matcher_proto_rawDescData
 }
 
-var file_matcher_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_matcher_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_matcher_proto_goTypes = []any{
 	(*Empty)(nil),           // 0: matchers.Empty
 	(*MatcherMetadata)(nil), // 1: matchers.MatcherMetadata
-	(*Event)(nil),           // 2: matchers.Event
-	(*MatchRequest)(nil),    // 3: matchers.MatchRequest
-	(*MatchResponse)(nil),   // 4: matchers.MatchResponse
+	(*InitRequest)(nil),     // 2: matchers.InitRequest
+	(*Event)(nil),           // 3: matchers.Event
+	(*MatchRequest)(nil),    // 4: matchers.MatchRequest
+	(*MatchResponse)(nil),   // 5: matchers.MatchResponse
+	nil,                     // 6: matchers.InitRequest.ParamsEntry
+	nil,                     // 7: matchers.InitRequest.SecretsEntry
 }
 var file_matcher_proto_depIdxs = []int32{
-	2, // 0: matchers.MatchRequest.event:type_name -> matchers.Event
-	0, // 1: matchers.Matcher.GetMetadata:input_type -> matchers.Empty
-	0, // 2: matchers.Matcher.Init:input_type -> matchers.Empty
-	3, // 3: matchers.Matcher.Match:input_type -> matchers.MatchRequest
-	0, // 4: matchers.Matcher.Shutdown:input_type -> matchers.Empty
-	0, // 5: matchers.Matcher.Ping:input_type -> matchers.Empty
-	1, // 6: matchers.Matcher.GetMetadata:output_type -> matchers.MatcherMetadata
-	0, // 7: matchers.Matcher.Init:output_type -> matchers.Empty
-	4, // 8: matchers.Matcher.Match:output_type -> matchers.MatchResponse
-	0, // 9: matchers.Matcher.Shutdown:output_type -> matchers.Empty
-	0, // 10: matchers.Matcher.Ping:output_type -> matchers.Empty
-	6, // [6:11] is the sub-list for method output_type
-	1, // [1:6] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	6, // 0: matchers.InitRequest.params:type_name -> matchers.InitRequest.ParamsEntry
+	7, // 1: matchers.InitRequest.secrets:type_name -> matchers.InitRequest.SecretsEntry
+	3, // 2: matchers.MatchRequest.event:type_name -> matchers.Event
+	0, // 3: matchers.Matcher.GetMetadata:input_type -> matchers.Empty
+	2, // 4: matchers.Matcher.Init:input_type -> matchers.InitRequest
+	4, // 5: matchers.Matcher.Match:input_type -> matchers.MatchRequest
+	0, // 6: matchers.Matcher.Shutdown:input_type -> matchers.Empty
+	0, // 7: matchers.Matcher.Ping:input_type -> matchers.Empty
+	1, // 8: matchers.Matcher.GetMetadata:output_type -> matchers.MatcherMetadata
+	0, // 9: matchers.Matcher.Init:output_type -> matchers.Empty
+	5, // 10: matchers.Matcher.Match:output_type -> matchers.MatchResponse
+	0, // 11: matchers.Matcher.Shutdown:output_type -> matchers.Empty
+	0, // 12: matchers.Matcher.Ping:output_type -> matchers.Empty
+	8, // [8:13] is the sub-list for method output_type
+	3, // [3:8] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_matcher_proto_init() }
@@ -349,7 +415,7 @@ func file_matcher_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_matcher_proto_rawDesc), len(file_matcher_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -31,7 +31,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type MatcherClient interface {
 	GetMetadata(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*MatcherMetadata, error)
-	Init(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	Init(ctx context.Context, in *InitRequest, opts ...grpc.CallOption) (*Empty, error)
 	Match(ctx context.Context, in *MatchRequest, opts ...grpc.CallOption) (*MatchResponse, error)
 	Shutdown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
@@ -55,7 +55,7 @@ func (c *matcherClient) GetMetadata(ctx context.Context, in *Empty, opts ...grpc
 	return out, nil
 }
 
-func (c *matcherClient) Init(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+func (c *matcherClient) Init(ctx context.Context, in *InitRequest, opts ...grpc.CallOption) (*Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, Matcher_Init_FullMethodName, in, out, cOpts...)
@@ -100,7 +100,7 @@ func (c *matcherClient) Ping(ctx context.Context, in *Empty, opts ...grpc.CallOp
 // for forward compatibility.
 type MatcherServer interface {
 	GetMetadata(context.Context, *Empty) (*MatcherMetadata, error)
-	Init(context.Context, *Empty) (*Empty, error)
+	Init(context.Context, *InitRequest) (*Empty, error)
 	Match(context.Context, *MatchRequest) (*MatchResponse, error)
 	Shutdown(context.Context, *Empty) (*Empty, error)
 	Ping(context.Context, *Empty) (*Empty, error)
@@ -117,7 +117,7 @@ type UnimplementedMatcherServer struct{}
 func (UnimplementedMatcherServer) GetMetadata(context.Context, *Empty) (*MatcherMetadata, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMetadata not implemented")
 }
-func (UnimplementedMatcherServer) Init(context.Context, *Empty) (*Empty, error) {
+func (UnimplementedMatcherServer) Init(context.Context, *InitRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Init not implemented")
 }
 func (UnimplementedMatcherServer) Match(context.Context, *MatchRequest) (*MatchResponse, error) {
@@ -169,7 +169,7 @@ func _Matcher_GetMetadata_Handler(srv interface{}, ctx context.Context, dec func
 }
 
 func _Matcher_Init_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
+	in := new(InitRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -181,7 +181,7 @@ func _Matcher_Init_Handler(srv interface{}, ctx context.Context, dec func(interf
 		FullMethod: Matcher_Init_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MatcherServer).Init(ctx, req.(*Empty))
+		return srv.(MatcherServer).Init(ctx, req.(*InitRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
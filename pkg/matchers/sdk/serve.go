@@ -12,12 +12,17 @@ import (
 	"github.com/harishhary/blink/internal/errors"
 	"github.com/harishhary/blink/pkg/events"
 	"github.com/harishhary/blink/pkg/matchers/rpc_matchers"
+	"github.com/harishhary/blink/pkg/pluginconfig"
+	"github.com/harishhary/blink/pkg/plugindebug"
+	"github.com/harishhary/blink/pkg/pluginlog"
 )
 
 const (
+	// ProtocolVersion is the highest plugin protocol this SDK speaks. The host negotiates the highest
+	// version both sides support, so binaries keep working with hosts that still run older versions.
 	ProtocolVersion = 1
 	MagicKey        = "BLINK_PLUGIN"
-	MagicValue      = "matcher_v1"
+	MagicValue      = "matcher" // the same for every protocol version; the versions are negotiated
 	DefaultTimeout  = 5 * time.Second
 )
 
@@ -47,6 +52,12 @@ type BaseMatcher struct{}
 func (BaseMatcher) Init() error     { return nil }
 func (BaseMatcher) Shutdown() error { return nil }
 
+// Configurable is optionally implemented by a MatcherPlugin that takes params or secrets from its sidecar.
+// Configure is called with the resolved values right before Init. A config change starts fresh workers, so it runs once per process.
+type Configurable interface {
+	Configure(cfg pluginconfig.Config) error
+}
+
 // server wraps a MatcherPlugin and serves the gRPC MatcherServer interface.
 type server struct {
 	rpc_matchers.UnimplementedMatcherServer
@@ -65,7 +76,12 @@ func (s *server) GetMetadata(_ context.Context, _ *rpc_matchers.Empty) (*rpc_mat
 	}, nil
 }
 
-func (s *server) Init(_ context.Context, _ *rpc_matchers.Empty) (*rpc_matchers.Empty, error) {
+func (s *server) Init(_ context.Context, req *rpc_matchers.InitRequest) (*rpc_matchers.Empty, error) {
+	if c, ok := s.matcher.(Configurable); ok {
+		if err := c.Configure(pluginconfig.New(req.GetParams(), req.GetSecrets())); err != nil {
+			return nil, err
+		}
+	}
 	return &rpc_matchers.Empty{}, s.matcher.Init()
 }
 
@@ -103,17 +119,31 @@ func (p *pluginImpl) GRPCClient(_ context.Context, _ *plugin.GRPCBroker, c *grpc
 	return rpc_matchers.NewMatcherClient(c), nil
 }
 
-func Serve(m MatcherPlugin) {
-	os.Setenv("GODEBUG", "madvdontneed=1")
-	plugin.Serve(&plugin.ServeConfig{
+func serveConfig(m MatcherPlugin) *plugin.ServeConfig {
+	return &plugin.ServeConfig{
 		HandshakeConfig: plugin.HandshakeConfig{
 			ProtocolVersion:  ProtocolVersion,
 			MagicCookieKey:   MagicKey,
 			MagicCookieValue: MagicValue,
 		},
 		GRPCServer: plugin.DefaultGRPCServer,
-		Plugins: map[string]plugin.Plugin{
-			"matcher": &pluginImpl{matcher: m},
+		VersionedPlugins: map[int]plugin.PluginSet{
+			1: {"matcher": &pluginImpl{matcher: m}},
 		},
-	})
+	}
+}
+
+func Serve(m MatcherPlugin) {
+	os.Setenv("GODEBUG", "madvdontneed=1")
+	pluginlog.RedirectStdLog()
+	plugin.Serve(serveConfig(m))
+}
+
+// ServeDebug serves m from this process rather than as a host-spawned subprocess, so it can run under a
+// debugger. name is the binary's file name in the host's plugin directory; the host adopts the running
+// matcher in its place through the reattach file. ServeDebug returns once ctx is cancelled or the process
+// is interrupted.
+func ServeDebug(ctx context.Context, name string, m MatcherPlugin) error {
+	pluginlog.RedirectStdLog()
+	return plugindebug.Serve(ctx, name, serveConfig(m))
 }
@@ -0,0 +1,178 @@
+// Package pluginconfig is the plugin-side view of the params and secrets the host sends in Init.
+//
+// Plugins opt in by implementing the Configurable interface of their SDK:
+//
+//	type geoip struct {
+//		sdk.BaseEnrichment
+//		settings struct {
+//			Endpoint string        `param:"endpoint"`
+//			Timeout  time.Duration `param:"timeout,optional"`
+//			Token    string        `secret:"api_token"`
+//		}
+//	}
+//
+//	func (g *geoip) Configure(cfg pluginconfig.Config) error {
+//		return cfg.Decode(&g.settings)
+//	}
+package pluginconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the params and resolved secrets for one plugin instance.
+type Config struct {
+	params  map[string]string
+	secrets map[string]string
+}
+
+func New(params, secrets map[string]string) Config {
+	return Config{params: params, secrets: secrets}
+}
+
+// Returns the raw param value and whether it was set.
+func (c Config) Param(name string) (string, bool) {
+	v, ok := c.params[name]
+	return v, ok
+}
+
+// Returns the resolved secret value and whether it was set.
+func (c Config) Secret(name string) (string, bool) {
+	v, ok := c.secrets[name]
+	return v, ok
+}
+
+// Returns the param value, or def when it is not set.
+func (c Config) String(name, def string) string {
+	if v, ok := c.params[name]; ok {
+		return v
+	}
+	return def
+}
+
+// Returns the param parsed as an int, or def when it is not set.
+func (c Config) Int(name string, def int) (int, error) {
+	v, ok := c.params[name]
+	if !ok {
+		return def, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return def, fmt.Errorf("param %s: %w", name, err)
+	}
+	return i, nil
+}
+
+// Returns the param parsed as a bool, or def when it is not set.
+func (c Config) Bool(name string, def bool) (bool, error) {
+	v, ok := c.params[name]
+	if !ok {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def, fmt.Errorf("param %s: %w", name, err)
+	}
+	return b, nil
+}
+
+// Returns the param parsed with time.ParseDuration, or def when it is not set.
+func (c Config) Duration(name string, def time.Duration) (time.Duration, error) {
+	v, ok := c.params[name]
+	if !ok {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return def, fmt.Errorf("param %s: %w", name, err)
+	}
+	return d, nil
+}
+
+// Decode fills the exported fields of the struct pointed to by v from `param:"name"` and `secret:"name"` tags.
+// A missing value is an error unless the tag carries ",optional". Supported kinds are string, bool, ints,
+// floats and time.Duration.
+func (c Config) Decode(v any) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("pluginconfig: Decode needs a pointer to a struct, got %T", v)
+	}
+	value = value.Elem()
+	ttype := value.Type()
+
+	for i := 0; i < ttype.NumField(); i++ {
+		fieldType := ttype.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		source, tag := c.params, fieldType.Tag.Get("param")
+		if tag == "" {
+			source, tag = c.secrets, fieldType.Tag.Get("secret")
+		}
+		if tag == "" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		name, optional := parts[0], len(parts) > 1 && parts[1] == "optional"
+		raw, ok := source[name]
+		if !ok {
+			if optional {
+				continue
+			}
+			return fmt.Errorf("pluginconfig: %s is required", name)
+		}
+		if err := setField(value.Field(i), raw); err != nil {
+			return fmt.Errorf("pluginconfig: %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, raw string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package pluginconfig
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	cfg := New(
+		map[string]string{"endpoint": "https://geo.example", "timeout": "2s", "retries": "3", "verbose": "true", "ratio": "0.5", "port": "8443"},
+		map[string]string{"api_token": "s3cret"},
+	)
+	var settings struct {
+		Endpoint string        `param:"endpoint"`
+		Timeout  time.Duration `param:"timeout"`
+		Retries  int           `param:"retries"`
+		Verbose  bool          `param:"verbose"`
+		Ratio    float64       `param:"ratio"`
+		Port     uint16        `param:"port"`
+		Region   string        `param:"region,optional"`
+		Token    string        `secret:"api_token"`
+		internal string        `param:"endpoint"` // unexported: left alone
+	}
+	settings.Region = "eu"
+	if err := cfg.Decode(&settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings.Endpoint != "https://geo.example" || settings.Timeout != 2*time.Second || settings.Retries != 3 ||
+		!settings.Verbose || settings.Ratio != 0.5 || settings.Port != 8443 || settings.Token != "s3cret" {
+		t.Errorf("decoded %+v", settings)
+	}
+	if settings.Region != "eu" || settings.internal != "" {
+		t.Errorf("decoded %+v, want an unset optional param and unexported fields untouched", settings)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	cfg := New(map[string]string{"retries": "many", "port": "70000"}, nil)
+	for _, tc := range []struct {
+		v    any
+		want string
+	}{
+		{&struct {
+			Token string `secret:"api_token"`
+		}{}, "api_token is required"},
+		{&struct {
+			Retries int `param:"retries"`
+		}{}, "retries: strconv.ParseInt"},
+		{&struct {
+			Port uint16 `param:"port"`
+		}{}, "port: strconv.ParseUint"},
+		{&struct {
+			Retries []string `param:"retries"`
+		}{}, "unsupported type slice"},
+		{struct{}{}, "needs a pointer to a struct"},
+	} {
+		if err := cfg.Decode(tc.v); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Decode(%T) = %v, want an error mentioning %q", tc.v, err, tc.want)
+		}
+	}
+}
+
+func TestTypedGetters(t *testing.T) {
+	cfg := New(map[string]string{"n": "7", "on": "yes"}, nil)
+	if n, err := cfg.Int("n", 1); n != 7 || err != nil {
+		t.Errorf("Int(n) = %d, %v", n, err)
+	}
+	if n, err := cfg.Int("missing", 1); n != 1 || err != nil {
+		t.Errorf("Int(missing) = %d, %v, want the default", n, err)
+	}
+	if _, err := cfg.Bool("on", false); err == nil {
+		t.Error("Bool accepted yes")
+	}
+}
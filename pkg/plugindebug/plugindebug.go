@@ -0,0 +1,156 @@
+// Package plugindebug runs a plugin binary outside the host, e.g. under a debugger, and describes how the
+// host adopts it. Each SDK wraps Serve as ServeDebug:
+//
+//	func main() {
+//		if err := sdk.ServeDebug(context.Background(), "brute_force", &bruteForce{}); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// The plugin prints its reattach entry on stdout and, when PLUGIN_REATTACH_FILE is set, adds it to that
+// file. A host started with the same PLUGIN_REATTACH_FILE connects to the running plugin instead of
+// spawning the binary of that name, and drops back to the binary once the entry is gone.
+package plugindebug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/hashicorp/go-plugin"
+)
+
+// ReattachFileEnv names the reattach file on both sides: the plugin adds its entry to it, the host reads it.
+const ReattachFileEnv = "PLUGIN_REATTACH_FILE"
+
+// Reattach is the JSON form of a plugin.ReattachConfig.
+type Reattach struct {
+	Protocol        string `json:"protocol"`
+	ProtocolVersion int    `json:"protocol_version"`
+	Pid             int    `json:"pid"`
+	Network         string `json:"network"`
+	Addr            string `json:"addr"`
+}
+
+// File maps plugin binary names, as they appear in the host's plugin directory, to running plugins.
+type File map[string]Reattach
+
+func fromConfig(c *plugin.ReattachConfig) Reattach {
+	return Reattach{
+		Protocol:        string(c.Protocol),
+		ProtocolVersion: c.ProtocolVersion,
+		Pid:             c.Pid,
+		Network:         c.Addr.Network(),
+		Addr:            c.Addr.String(),
+	}
+}
+
+// Config converts r for plugin.ClientConfig.Reattach. The result is in test mode, so killing the client
+// never kills the plugin: its lifetime belongs to whoever started it.
+func (r Reattach) Config() (*plugin.ReattachConfig, error) {
+	var addr net.Addr
+	var err error
+	switch r.Network {
+	case "unix":
+		addr, err = net.ResolveUnixAddr(r.Network, r.Addr)
+	case "tcp":
+		addr, err = net.ResolveTCPAddr(r.Network, r.Addr)
+	default:
+		err = fmt.Errorf("unsupported network %q", r.Network)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ReattachConfig{
+		Protocol:        plugin.Protocol(r.Protocol),
+		ProtocolVersion: r.ProtocolVersion,
+		Pid:             r.Pid,
+		Addr:            addr,
+		Test:            true,
+	}, nil
+}
+
+// ReadFile reads a reattach file. A missing file holds no entries.
+func ReadFile(path string) (File, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return File{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f := File{}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// update applies fn to the file at path and writes it back atomically.
+func update(path string, fn func(File)) error {
+	f, err := ReadFile(path)
+	if err != nil {
+		return err
+	}
+	fn(f)
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Serve serves cfg from this process until ctx is cancelled or the process is interrupted, printing the
+// reattach entry for the binary called name once the plugin is listening. Serve sets cfg.Test.
+func Serve(ctx context.Context, name string, cfg *plugin.ServeConfig) error {
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	reattachCh := make(chan *plugin.ReattachConfig, 1)
+	closeCh := make(chan struct{})
+	cfg.Test = &plugin.ServeTestConfig{Context: ctx, ReattachConfigCh: reattachCh, CloseCh: closeCh}
+	go plugin.Serve(cfg)
+
+	var r Reattach
+	select {
+	case c := <-reattachCh:
+		r = fromConfig(c)
+	case <-closeCh:
+		return errors.New("plugin server exited before it was listening")
+	}
+
+	entry, err := json.Marshal(File{name: r})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Plugin %q is listening for debugging. Reattach entry:\n\n\t%s\n\n", name, entry)
+	if path := os.Getenv(ReattachFileEnv); path != "" {
+		if err := update(path, func(f File) { f[name] = r }); err != nil {
+			return fmt.Errorf("add reattach entry: %w", err)
+		}
+		fmt.Printf("Added to %s; the host adopts the plugin on its next reconcile.\n", path)
+		defer func() {
+			// Hand the binary back to the host. The entry is only ours while it still names this process.
+			_ = update(path, func(f File) {
+				if f[name] == r {
+					delete(f, name)
+				}
+			})
+		}()
+	} else {
+		fmt.Printf("Add it to the file named by the host's %s.\n", ReattachFileEnv)
+	}
+
+	<-closeCh
+	return nil
+}
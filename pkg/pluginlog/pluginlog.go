@@ -0,0 +1,44 @@
+// Package pluginlog is the plugin-side logger. Records are written to the plugin's stderr as JSON,
+// which the host parses into service log lines tagged with the plugin's type, name, ID, version and
+// worker index, so rule authors never have to identify themselves:
+//
+//	func (r *bruteForce) Evaluate(ctx context.Context, event events.Event) (bool, errors.Error) {
+//		pluginlog.Debug("evaluating", "user", event["user"])
+//		...
+//	}
+//
+// Every SDK's Serve also routes the standard log package through here, so plain log.Printf lines
+// arrive as INFO records (or at the level of a leading [DEBUG], [WARN] or [ERROR] tag).
+package pluginlog
+
+import (
+	"log"
+	"os"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// std is bound to the process's original stderr. go-plugin replaces os.Stderr once Serve starts,
+// but the host reads the original descriptor for log records.
+var std = hclog.New(&hclog.LoggerOptions{
+	Output:      os.Stderr,
+	Level:       hclog.Trace,
+	JSONFormat:  true,
+	DisableTime: true, // the host stamps records on arrival
+})
+
+// Logger returns the structured logger, e.g. to derive one with fixed fields via With.
+func Logger() hclog.Logger { return std }
+
+func Trace(msg string, kv ...any) { std.Trace(msg, kv...) }
+func Debug(msg string, kv ...any) { std.Debug(msg, kv...) }
+func Info(msg string, kv ...any)  { std.Info(msg, kv...) }
+func Warn(msg string, kv ...any)  { std.Warn(msg, kv...) }
+func Error(msg string, kv ...any) { std.Error(msg, kv...) }
+
+// RedirectStdLog sends the standard log package through Logger. Called by every SDK's Serve.
+func RedirectStdLog() {
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(std.StandardWriter(&hclog.StandardLoggerOptions{InferLevels: true}))
+}
@@ -0,0 +1,37 @@
+package rules
+
+import (
+	"context"
+
+	"github.com/harishhary/blink/internal/errors"
+	"github.com/harishhary/blink/pkg/events"
+	"github.com/harishhary/blink/pkg/rules/config"
+)
+
+// EvaluateFunc is the logic of a built-in rule. cfg is the rule's current YAML sidecar, for its params.
+type EvaluateFunc func(ctx context.Context, cfg *config.RuleMetadata, event events.Event) (bool, errors.Error)
+
+// builtinRule is a rule implemented in the executor itself rather than by a plugin binary.
+type builtinRule struct {
+	sidecarRule
+	evaluate EvaluateFunc
+}
+
+// NewBuiltin returns a rule evaluated in-process by evaluate, for registering with the rule manager's
+// Register. Like a plugin binary it is configured by the YAML sidecar whose file_name is fileName: the
+// sidecar gives its ID, routing, scoring and params and can disable it. version stands in for the
+// binary checksum, so bumping it rolls the rule out as a new generation.
+func NewBuiltin(fileName string, watcher *config.Watcher, version string, evaluate EvaluateFunc) Rule {
+	return &builtinRule{
+		sidecarRule: sidecarRule{cfgWatcher: watcher, fileName: fileName, checksum: version},
+		evaluate:    evaluate,
+	}
+}
+
+func (r *builtinRule) Evaluate(ctx context.Context, event events.Event) (bool, errors.Error) {
+	cfg := r.cfg()
+	if cfg == nil {
+		return false, nil
+	}
+	return r.evaluate(ctx, cfg, event)
+}
@@ -19,12 +19,22 @@
 //	merge_by_keys: ["source_ip", "username"]
 //	merge_window_mins: 60
 //	req_subkeys: ["source_ip"]
+//	normalized: false       # true evaluates the rule against the common-schema view of the event
 //	tags: ["t1078", "initial-access"]
 //	dispatchers: ["pagerduty", "slack"]
 //	formatters: ["json-summary"]
 //	enrichments: ["geoip"]
 //	tuning_rules: ["noisy-hosts"]
 //	references: ["https://attack.mitre.org/techniques/T1110/"]
+//	min_procs: 1   # workers kept running; the pool grows towards max_procs under load
+//	max_procs: 4
+//	max_queue: 100          # evaluations allowed to wait for a free worker
+//	acquire_timeout: "250ms"
+//	shed_policy: "fail-fast" # or "pass-through", "dead-letter"
+//	params:
+//	  threshold: "5"
+//	secrets:
+//	  ldap_password: { env: "BRUTE_FORCE_LDAP_PASSWORD" }
 
 package config
 
@@ -38,6 +48,8 @@ import (
 	"time"
 
 	internal "github.com/harishhary/blink/internal/pools"
+	"github.com/harishhary/blink/internal/secrets"
+	"github.com/harishhary/blink/pkg/events"
 	"github.com/harishhary/blink/pkg/scoring"
 	"go.yaml.in/yaml/v4"
 )
@@ -74,6 +86,9 @@ type RuleMetadata struct {
 	LogTypesField   []string `yaml:"log_types"`
 	MatchersField   []string `yaml:"matchers"`
 	ReqSubkeysField []string `yaml:"req_subkeys"`
+	// NormalizedField makes the executor hand the rule the event's normalized view instead of the
+	// vendor fields. The alert still carries the original event.
+	NormalizedField bool `yaml:"normalized"`
 
 	// Merging
 	MergeByKeysField     []string `yaml:"merge_by_keys"`
@@ -102,6 +117,15 @@ type RuleMetadata struct {
 	MinProcsField   int     `yaml:"min_procs"`
 	MaxProcsField   int     `yaml:"max_procs"`
 
+	// Queueing and load shedding
+	MaxQueueField       int    `yaml:"max_queue"`
+	AcquireTimeoutField string `yaml:"acquire_timeout"`
+	ShedPolicyField     string `yaml:"shed_policy"`
+
+	// Plugin configuration - sent to the rule binary in Init.
+	ParamsField  map[string]string      `yaml:"params"`
+	SecretsField map[string]secrets.Ref `yaml:"secrets"`
+
 	// Parsed scoring values - populated by Load(); not read from YAML directly.
 	severity        scoring.Severity
 	confidence      scoring.Confidence
@@ -110,6 +134,9 @@ type RuleMetadata struct {
 
 	// Parsed rollout mode - populated by resolveRollout().
 	rolloutMode internal.RolloutMode
+
+	// Parsed queue policy - populated by resolveQueue().
+	queuePolicy internal.QueuePolicy
 }
 
 // Load reads and validates a single YAML sidecar file, returning a *RuleMetadata
@@ -139,6 +166,9 @@ func New(c RuleMetadata) (*RuleMetadata, error) {
 	if err := c.resolveRollout(); err != nil {
 		return nil, err
 	}
+	if err := c.resolveQueue(); err != nil {
+		return nil, err
+	}
 	return &c, nil
 }
 
@@ -151,6 +181,40 @@ func (c *RuleMetadata) resolveRollout() error {
 	return c.rolloutMode.UnmarshalText([]byte(c.ModeField))
 }
 
+// resolveQueue parses the queueing fields into the typed queuePolicy field.
+func (c *RuleMetadata) resolveQueue() error {
+	c.queuePolicy = internal.QueuePolicy{MaxQueue: c.MaxQueueField}
+	if c.AcquireTimeoutField != "" {
+		d, err := time.ParseDuration(c.AcquireTimeoutField)
+		if err != nil {
+			return fmt.Errorf("acquire_timeout: %w", err)
+		}
+		c.queuePolicy.AcquireTimeout = d
+	}
+	return c.queuePolicy.Shed.UnmarshalText([]byte(c.ShedPolicyField))
+}
+
+// resolvePaths compiles every field path the rule names (see events.Path), so a malformed path fails
+// the load instead of silently never matching. Compiled paths are cached for evaluation.
+func (c *RuleMetadata) resolvePaths() error {
+	for _, group := range []struct {
+		field string
+		paths []string
+	}{{"req_subkeys", c.ReqSubkeysField}, {"merge_by_keys", c.MergeByKeysField}} {
+		for _, p := range group.paths {
+			if _, err := events.CompilePath(p); err != nil {
+				return fmt.Errorf("%s: %w", group.field, err)
+			}
+		}
+	}
+	for _, o := range c.ObservablesField {
+		if _, err := events.CompilePath(o.NameVal); err != nil {
+			return fmt.Errorf("observables: %w", err)
+		}
+	}
+	return nil
+}
+
 // resolveScoring parses the string scoring fields to their typed equivalents
 // and computes the risk score.
 func (c *RuleMetadata) resolveScoring() error {
@@ -192,6 +256,14 @@ func (c *RuleMetadata) resolve(path string, raw []byte) error {
 		return err
 	}
 
+	if err := c.resolveQueue(); err != nil {
+		return err
+	}
+
+	if err := c.resolvePaths(); err != nil {
+		return err
+	}
+
 	// Default file_name to the YAML file's base name (without extension).
 	if c.FileNameField == "" {
 		base := filepath.Base(path)
@@ -222,6 +294,7 @@ func (c *RuleMetadata) MergeWindowMins() time.Duration {
 	return time.Duration(c.MergeWindowMinsField) * time.Minute
 }
 func (c *RuleMetadata) ReqSubkeys() []string                { return c.ReqSubkeysField }
+func (c *RuleMetadata) Normalized() bool                    { return c.NormalizedField }
 func (c *RuleMetadata) Signal() bool                        { return c.SignalField }
 func (c *RuleMetadata) SignalThreshold() scoring.Confidence { return c.signalThreshold }
 func (c *RuleMetadata) Tags() []string                      { return c.TagsField }
@@ -241,12 +314,19 @@ func (c *RuleMetadata) RolloutPct() float64               { return c.RolloutPctF
 func (c *RuleMetadata) RolloutMode() internal.RolloutMode { return c.rolloutMode }
 func (c *RuleMetadata) MinProcs() int                     { return c.MinProcsField }
 func (c *RuleMetadata) MaxProcs() int                     { return c.MaxProcsField }
+func (c *RuleMetadata) QueuePolicy() internal.QueuePolicy { return c.queuePolicy }
+
+// Plugin configuration accessors.
+func (c *RuleMetadata) Params() map[string]string       { return c.ParamsField }
+func (c *RuleMetadata) Secrets() map[string]secrets.Ref { return c.SecretsField }
 
 type Registry struct {
 	byName     map[string]*RuleMetadata
 	byID       map[string]*RuleMetadata
 	byFileName map[string]*RuleMetadata
 	all        []*RuleMetadata
+	generation uint64 // set by the Watcher that publishes the registry
+	loadedAt   time.Time
 }
 
 func NewRegistry(dir string) (*Registry, error) {
@@ -259,6 +339,7 @@ func NewRegistry(dir string) (*Registry, error) {
 		byName:     make(map[string]*RuleMetadata),
 		byID:       make(map[string]*RuleMetadata),
 		byFileName: make(map[string]*RuleMetadata),
+		loadedAt:   time.Now(),
 	}
 
 	var errs []string
@@ -296,6 +377,23 @@ func (r *Registry) ByFileName(fileName string) *RuleMetadata { return r.byFileNa
 
 func (r *Registry) Len() int { return len(r.all) }
 
+// Enabled returns the enabled rules.
+func (r *Registry) Enabled() []*RuleMetadata {
+	var out []*RuleMetadata
+	for _, m := range r.all {
+		if m.Enabled() {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// Generation counts the registries a Watcher has published, starting at 1; 0 for one built directly.
+func (r *Registry) Generation() uint64 { return r.generation }
+
+// LoadedAt is when the registry was read from disk.
+func (r *Registry) LoadedAt() time.Time { return r.loadedAt }
+
 // An empty log_types list means the rule applies to all log types.
 func (r *Registry) RulesForLogType(logType string) []*RuleMetadata {
 	var result []*RuleMetadata
@@ -3,13 +3,13 @@ package rules
 
 import "github.com/harishhary/blink/pkg/events"
 
-// Checks that every required subkey is present in the event. Takes Metadata since it only needs static config: Enabled, ReqSubkeys.
+// Checks that every required subkey is present in the event, either as a top-level field or as a path in its normalized view. Takes Metadata since it only needs static config: Enabled, ReqSubkeys.
 func DefaultSubKeysInEvent(r Metadata, event events.Event) bool {
 	if !r.Enabled() {
 		return false
 	}
 	for _, k := range r.ReqSubkeys() {
-		if event.Get(k, nil) == nil {
+		if _, ok := event.Lookup(k); !ok {
 			return false
 		}
 	}
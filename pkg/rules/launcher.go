@@ -18,12 +18,18 @@ type RuleAdapter struct {
 	Watcher *config.Watcher
 }
 
-func (l *RuleAdapter) PluginKey() string         { return "rule" }
-func (l *RuleAdapter) MagicValue() string        { return "rule_v1" }
-func (l *RuleAdapter) GRPCPlugin() plugin.Plugin { return &rulePlugin{} }
+func (l *RuleAdapter) PluginKey() string  { return "rule" }
+func (l *RuleAdapter) MagicValue() string { return "rule" }
+
+func (l *RuleAdapter) Protocols() map[int]plugin.Plugin {
+	return map[int]plugin.Plugin{1: &rulePlugin{}}
+}
 
 // Connects to the rule subprocess, reads the YAML sidecar for its metadata, calls Init, and returns a ready rpcRule. The rule binary's basename must match the YAML file_name field.
-func (l *RuleAdapter) Handshake(ctx context.Context, raw interface{}, binPath string, hash string) (Rule, pluginmgr.PluginLifecycle, string, string, error) {
+func (l *RuleAdapter) Handshake(ctx context.Context, raw interface{}, protocol int, binPath string, hash string, params pluginmgr.ResolvedConfig) (Rule, pluginmgr.PluginLifecycle, string, string, error) {
+	if protocol != 1 {
+		return nil, nil, "", "", fmt.Errorf("unsupported plugin protocol v%d", protocol)
+	}
 	rpc, ok := raw.(rpc_rules.RuleClient)
 	if !ok {
 		return nil, nil, "", "", fmt.Errorf("dispense: unexpected type %T", raw)
@@ -36,7 +42,7 @@ func (l *RuleAdapter) Handshake(ctx context.Context, raw interface{}, binPath st
 	}
 
 	initCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	_, err := rpc.Init(initCtx, &rpc_rules.Empty{})
+	_, err := rpc.Init(initCtx, &rpc_rules.InitRequest{Params: params.Params, Secrets: params.Secrets})
 	cancel()
 	if err != nil {
 		return nil, nil, "", "", fmt.Errorf("init: %w", err)
@@ -46,6 +52,15 @@ func (l *RuleAdapter) Handshake(ctx context.Context, raw interface{}, binPath st
 	return rule, &ruleLifecycle{rpc: rpc}, cfg.Id(), cfg.Name(), nil
 }
 
+// Config returns the params and secret references from the rule's YAML sidecar.
+func (l *RuleAdapter) Config(binPath string) (pluginmgr.PluginConfig, error) {
+	cfg := l.Watcher.Current().ByFileName(helpers.BinaryBaseName(binPath))
+	if cfg == nil {
+		return pluginmgr.PluginConfig{}, nil
+	}
+	return pluginmgr.PluginConfig{Params: cfg.Params(), Secrets: cfg.Secrets()}, nil
+}
+
 // IsEnabled reports whether the rule's YAML sidecar still exists and is enabled.
 // Called during every reconcile func so process-zombies (binary running but YAML removed/disabled) are stopped without waiting for a binary change.
 func (l *RuleAdapter) IsEnabled(h *pluginmgr.PluginHandle) bool {
@@ -53,12 +68,14 @@ func (l *RuleAdapter) IsEnabled(h *pluginmgr.PluginHandle) bool {
 	return cfg != nil && cfg.Enabled()
 }
 
-func (l *RuleAdapter) Workers(binPath string) int {
+// Workers returns the rule's min_procs/max_procs from the live registry, so editing them in YAML resizes the pool without a restart.
+// min_procs defaults to 1 and max_procs to min_procs.
+func (l *RuleAdapter) Workers(binPath string) (int, int) {
 	cfg := l.Watcher.Current().ByFileName(helpers.BinaryBaseName(binPath))
-	if cfg == nil || cfg.MaxProcs() <= 0 {
-		return 1
+	if cfg == nil {
+		return 1, 1
 	}
-	return cfg.MaxProcs()
+	return cfg.MinProcs(), cfg.MaxProcs()
 }
 
 type ruleLifecycle struct {
@@ -26,8 +26,15 @@ func NewPool(watcher *config.Watcher, drainTimeout time.Duration) *Pool {
 		}
 		return meta.KillSwitch(), meta.RolloutMode(), meta.RolloutPct()
 	}
+	queue := func(id string) internal.QueuePolicy {
+		meta := watcher.Current().ByID(id)
+		if meta == nil {
+			return internal.QueuePolicy{}
+		}
+		return meta.QueuePolicy()
+	}
 	return &Pool{
-		ProcessPool: internal.NewProcessPool[rules.Rule](routing, internal.NewPoolMetrics("rules"), drainTimeout),
+		ProcessPool: internal.NewProcessPool[rules.Rule](routing, queue, internal.NewPoolMetrics("rules"), drainTimeout),
 		watcher:     watcher,
 	}
 }
@@ -54,10 +61,10 @@ func (p *Pool) Sync(msg messaging.Message) {
 	switch m := msg.(type) {
 	case pluginmgr.RegisterMessage[rules.Rule]:
 		r := m.Items[0]
-		p.Register(internal.PoolKey{PluginID: r.Id(), Version: r.Version()}, m.Items, m.MaxProcs, nil)
+		p.Register(internal.PoolKey{PluginID: r.Id(), Version: r.Version()}, m.Workers(), m.Scaler, nil, nil)
 	case pluginmgr.UpdateMessage[rules.Rule]:
 		r := m.Items[0]
-		p.Register(internal.PoolKey{PluginID: r.Id(), Version: r.Version()}, m.Items, m.MaxProcs, m.OnDrained)
+		p.Register(internal.PoolKey{PluginID: r.Id(), Version: r.Version()}, m.Workers(), m.Scaler, m.OnDrained, m.OnRolledBack)
 	case pluginmgr.UnregisterMessage[rules.Rule]:
 		p.Unregister(m.ItemID)
 	case pluginmgr.RemoveMessage[rules.Rule]:
@@ -12,224 +12,228 @@ import (
 	"github.com/harishhary/blink/pkg/scoring"
 )
 
-// This is the executor-side wrapper for a live rule subprocess.
-type rpcRule struct {
-	client     rpc_rules.RuleClient
+// sidecarRule reads a rule's metadata from its YAML sidecar on every call, so edits apply without
+// restarting the rule. It is everything a Rule needs but Evaluate.
+type sidecarRule struct {
 	cfgWatcher *config.Watcher
 	fileName   string
 	checksum   string // SHA-256 ofthe binary
 }
 
+// This is the executor-side wrapper for a live rule subprocess.
+type rpcRule struct {
+	sidecarRule
+	client rpc_rules.RuleClient
+}
+
 func newRpcRule(fileName string, client rpc_rules.RuleClient, watcher *config.Watcher, checksum string) *rpcRule {
 	return &rpcRule{
-		client:     client,
-		cfgWatcher: watcher,
-		fileName:   fileName,
-		checksum:   checksum,
+		sidecarRule: sidecarRule{cfgWatcher: watcher, fileName: fileName, checksum: checksum},
+		client:      client,
 	}
 }
 
-func (r *rpcRule) cfg() *config.RuleMetadata {
+func (r *sidecarRule) cfg() *config.RuleMetadata {
 	if r.cfgWatcher == nil {
 		return nil
 	}
 	return r.cfgWatcher.Current().ByFileName(r.fileName)
 }
 
-func (r *rpcRule) Id() string {
+func (r *sidecarRule) Id() string {
 	if c := r.cfg(); c != nil {
 		return c.Id()
 	}
 	return ""
 }
 
-func (r *rpcRule) Name() string {
+func (r *sidecarRule) Name() string {
 	if c := r.cfg(); c != nil {
 		return c.Name()
 	}
 	return r.fileName
 }
 
-func (r *rpcRule) Enabled() bool {
+func (r *sidecarRule) Enabled() bool {
 	c := r.cfg()
 	return c != nil && c.Enabled()
 }
 
-func (r *rpcRule) Description() string {
+func (r *sidecarRule) Description() string {
 	if c := r.cfg(); c != nil {
 		return c.Description()
 	}
 	return ""
 }
 
-func (r *rpcRule) FileName() string {
+func (r *sidecarRule) FileName() string {
 	return r.fileName
 }
 
-func (r *rpcRule) DisplayName() string {
+func (r *sidecarRule) DisplayName() string {
 	if c := r.cfg(); c != nil {
 		return c.DisplayName()
 	}
 	return ""
 }
 
-func (r *rpcRule) References() []string {
+func (r *sidecarRule) References() []string {
 	if c := r.cfg(); c != nil {
 		return c.References()
 	}
 	return nil
 }
 
-func (r *rpcRule) Severity() scoring.Severity {
+func (r *sidecarRule) Severity() scoring.Severity {
 	if c := r.cfg(); c != nil {
 		return c.Severity()
 	}
 	return scoring.SeverityInfo
 }
 
-func (r *rpcRule) Confidence() scoring.Confidence {
+func (r *sidecarRule) Confidence() scoring.Confidence {
 	if c := r.cfg(); c != nil {
 		return c.Confidence()
 	}
 	return scoring.ConfidenceVeryLow
 }
 
-func (r *rpcRule) RiskScore() scoring.RiskScore {
+func (r *sidecarRule) RiskScore() scoring.RiskScore {
 	if c := r.cfg(); c != nil {
 		return c.RiskScore()
 	}
 	return scoring.ComputeRiskScore(scoring.ConfidenceVeryLow, scoring.SeverityInfo)
 }
 
-func (r *rpcRule) MergeByKeys() []string {
+func (r *sidecarRule) MergeByKeys() []string {
 	if c := r.cfg(); c != nil {
 		return c.MergeByKeys()
 	}
 	return nil
 }
 
-func (r *rpcRule) MergeWindowMins() time.Duration {
+func (r *sidecarRule) MergeWindowMins() time.Duration {
 	if c := r.cfg(); c != nil {
 		return c.MergeWindowMins()
 	}
 	return 0
 }
 
-func (r *rpcRule) ReqSubkeys() []string {
+func (r *sidecarRule) ReqSubkeys() []string {
 	if c := r.cfg(); c != nil {
 		return c.ReqSubkeys()
 	}
 	return nil
 }
 
-func (r *rpcRule) Signal() bool {
+func (r *sidecarRule) Signal() bool {
 	if c := r.cfg(); c != nil {
 		return c.Signal()
 	}
 	return false
 }
 
-func (r *rpcRule) SignalThreshold() scoring.Confidence {
+func (r *sidecarRule) SignalThreshold() scoring.Confidence {
 	if c := r.cfg(); c != nil {
 		return c.SignalThreshold()
 	}
 	return scoring.ConfidenceVeryLow
 }
 
-func (r *rpcRule) Tags() []string {
+func (r *sidecarRule) Tags() []string {
 	if c := r.cfg(); c != nil {
 		return c.Tags()
 	}
 	return nil
 }
 
-func (r *rpcRule) Dispatchers() []string {
+func (r *sidecarRule) Dispatchers() []string {
 	if c := r.cfg(); c != nil {
 		return c.Dispatchers()
 	}
 	return nil
 }
 
-func (r *rpcRule) LogTypes() []string {
+func (r *sidecarRule) LogTypes() []string {
 	if c := r.cfg(); c != nil {
 		return c.LogTypes()
 	}
 	return nil
 }
 
-func (r *rpcRule) Observables() []Observables {
+func (r *sidecarRule) Observables() []Observables {
 	return nil
 }
 
-func (r *rpcRule) Matchers() []string {
+func (r *sidecarRule) Matchers() []string {
 	if c := r.cfg(); c != nil {
 		return c.Matchers()
 	}
 	return nil
 }
 
-func (r *rpcRule) Formatters() []string {
+func (r *sidecarRule) Formatters() []string {
 	if c := r.cfg(); c != nil {
 		return c.Formatters()
 	}
 	return nil
 }
 
-func (r *rpcRule) Enrichments() []string {
+func (r *sidecarRule) Enrichments() []string {
 	if c := r.cfg(); c != nil {
 		return c.Enrichments()
 	}
 	return nil
 }
 
-func (r *rpcRule) TuningRules() []string {
+func (r *sidecarRule) TuningRules() []string {
 	if c := r.cfg(); c != nil {
 		return c.TuningRules()
 	}
 	return nil
 }
 
-func (r *rpcRule) Version() string {
+func (r *sidecarRule) Version() string {
 	if c := r.cfg(); c != nil {
 		return c.Version()
 	}
 	return ""
 }
 
-func (r *rpcRule) Checksum() string {
+func (r *sidecarRule) Checksum() string {
 	return r.checksum
 }
 
 // --- Optional capability interfaces ---
 
-func (r *rpcRule) AlertTitle(_ events.Event) string {
+func (r *sidecarRule) AlertTitle(_ events.Event) string {
 	if c := r.cfg(); c != nil {
 		return c.Name()
 	}
 	return r.fileName
 }
 
-func (r *rpcRule) AlertDescription(_ events.Event) string {
+func (r *sidecarRule) AlertDescription(_ events.Event) string {
 	if c := r.cfg(); c != nil {
 		return c.Description()
 	}
 	return ""
 }
 
-func (r *rpcRule) AlertContext(_ events.Event) map[string]any {
+func (r *sidecarRule) AlertContext(_ events.Event) map[string]any {
 	return nil
 }
 
-func (r *rpcRule) DynamicSeverity(_ events.Event) scoring.Severity {
+func (r *sidecarRule) DynamicSeverity(_ events.Event) scoring.Severity {
 	return r.Severity()
 }
 
-func (r *rpcRule) Dedup(_ events.Event) []string {
+func (r *sidecarRule) Dedup(_ events.Event) []string {
 	return r.MergeByKeys()
 }
 
 // SubKeyFilter uses the YAML config (via cfg()) so the subprocess is not invoked.
-func (r *rpcRule) SubKeysInEvent(event events.Event) bool {
+func (r *sidecarRule) SubKeysInEvent(event events.Event) bool {
 	return DefaultSubKeysInEvent(r, event)
 }
 
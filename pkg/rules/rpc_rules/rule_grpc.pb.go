@@ -19,7 +19,6 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Rule_GetMetadata_FullMethodName   = "/rules.Rule/GetMetadata"
 	Rule_Init_FullMethodName          = "/rules.Rule/Init"
 	Rule_Evaluate_FullMethodName      = "/rules.Rule/Evaluate"
 	Rule_EvaluateBatch_FullMethodName = "/rules.Rule/EvaluateBatch"
@@ -31,8 +30,7 @@ const (
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type RuleClient interface {
-	GetMetadata(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Metadata, error)
-	Init(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	Init(ctx context.Context, in *InitRequest, opts ...grpc.CallOption) (*Empty, error)
 	Evaluate(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*EvaluateResponse, error)
 	EvaluateBatch(ctx context.Context, in *EvaluateBatchRequest, opts ...grpc.CallOption) (*EvaluateBatchResponse, error)
 	Shutdown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
@@ -47,17 +45,7 @@ func NewRuleClient(cc grpc.ClientConnInterface) RuleClient {
 	return &ruleClient{cc}
 }
 
-func (c *ruleClient) GetMetadata(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Metadata, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(Metadata)
-	err := c.cc.Invoke(ctx, Rule_GetMetadata_FullMethodName, in, out, cOpts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
-}
-
-func (c *ruleClient) Init(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+func (c *ruleClient) Init(ctx context.Context, in *InitRequest, opts ...grpc.CallOption) (*Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, Rule_Init_FullMethodName, in, out, cOpts...)
@@ -111,8 +99,7 @@ func (c *ruleClient) Ping(ctx context.Context, in *Empty, opts ...grpc.CallOptio
 // All implementations must embed UnimplementedRuleServer
 // for forward compatibility.
 type RuleServer interface {
-	GetMetadata(context.Context, *Empty) (*Metadata, error)
-	Init(context.Context, *Empty) (*Empty, error)
+	Init(context.Context, *InitRequest) (*Empty, error)
 	Evaluate(context.Context, *EvaluateRequest) (*EvaluateResponse, error)
 	EvaluateBatch(context.Context, *EvaluateBatchRequest) (*EvaluateBatchResponse, error)
 	Shutdown(context.Context, *Empty) (*Empty, error)
@@ -127,10 +114,7 @@ type RuleServer interface {
 // pointer dereference when methods are called.
 type UnimplementedRuleServer struct{}
 
-func (UnimplementedRuleServer) GetMetadata(context.Context, *Empty) (*Metadata, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetMetadata not implemented")
-}
-func (UnimplementedRuleServer) Init(context.Context, *Empty) (*Empty, error) {
+func (UnimplementedRuleServer) Init(context.Context, *InitRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Init not implemented")
 }
 func (UnimplementedRuleServer) Evaluate(context.Context, *EvaluateRequest) (*EvaluateResponse, error) {
@@ -166,26 +150,8 @@ func RegisterRuleServer(s grpc.ServiceRegistrar, srv RuleServer) {
 	s.RegisterService(&Rule_ServiceDesc, srv)
 }
 
-func _Rule_GetMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(RuleServer).GetMetadata(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: Rule_GetMetadata_FullMethodName,
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(RuleServer).GetMetadata(ctx, req.(*Empty))
-	}
-	return interceptor(ctx, in, info, handler)
-}
-
 func _Rule_Init_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
+	in := new(InitRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -197,7 +163,7 @@ func _Rule_Init_Handler(srv interface{}, ctx context.Context, dec func(interface
 		FullMethod: Rule_Init_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(RuleServer).Init(ctx, req.(*Empty))
+		return srv.(RuleServer).Init(ctx, req.(*InitRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -281,10 +247,6 @@ var Rule_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "rules.Rule",
 	HandlerType: (*RuleServer)(nil),
 	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "GetMetadata",
-			Handler:    _Rule_GetMetadata_Handler,
-		},
 		{
 			MethodName: "Init",
 			Handler:    _Rule_Init_Handler,
@@ -11,13 +11,18 @@ import (
 
 	"github.com/harishhary/blink/internal/errors"
 	"github.com/harishhary/blink/pkg/events"
+	"github.com/harishhary/blink/pkg/pluginconfig"
+	"github.com/harishhary/blink/pkg/plugindebug"
+	"github.com/harishhary/blink/pkg/pluginlog"
 	"github.com/harishhary/blink/pkg/rules/rpc_rules"
 )
 
 const (
+	// ProtocolVersion is the highest plugin protocol this SDK speaks. The host negotiates the highest
+	// version both sides support, so binaries keep working with hosts that still run older versions.
 	ProtocolVersion = 1
 	MagicKey        = "BLINK_PLUGIN"
-	MagicValue      = "rule_v1"
+	MagicValue      = "rule" // the same for every protocol version; the versions are negotiated
 	DefaultTimeout  = 5 * time.Second
 )
 
@@ -40,13 +45,24 @@ type BaseRule struct{}
 func (BaseRule) Init() error     { return nil }
 func (BaseRule) Shutdown() error { return nil }
 
+// Configurable is optionally implemented by a RulePlugin that takes params or secrets from its sidecar.
+// Configure is called with the resolved values right before Init. A config change starts fresh workers, so it runs once per process.
+type Configurable interface {
+	Configure(cfg pluginconfig.Config) error
+}
+
 // server wraps a RulePlugin and serves the gRPC RuleServer interface.
 type server struct {
 	rpc_rules.UnimplementedRuleServer
 	rule RulePlugin
 }
 
-func (s *server) Init(_ context.Context, _ *rpc_rules.Empty) (*rpc_rules.Empty, error) {
+func (s *server) Init(_ context.Context, req *rpc_rules.InitRequest) (*rpc_rules.Empty, error) {
+	if c, ok := s.rule.(Configurable); ok {
+		if err := c.Configure(pluginconfig.New(req.GetParams(), req.GetSecrets())); err != nil {
+			return nil, err
+		}
+	}
 	return &rpc_rules.Empty{}, s.rule.Init()
 }
 
@@ -100,17 +116,31 @@ func (p *pluginImpl) GRPCClient(_ context.Context, _ *plugin.GRPCBroker, c *grpc
 	return rpc_rules.NewRuleClient(c), nil
 }
 
-func Serve(r RulePlugin) {
-	os.Setenv("GODEBUG", "madvdontneed=1")
-	plugin.Serve(&plugin.ServeConfig{
+func serveConfig(r RulePlugin) *plugin.ServeConfig {
+	return &plugin.ServeConfig{
 		HandshakeConfig: plugin.HandshakeConfig{
 			ProtocolVersion:  ProtocolVersion,
 			MagicCookieKey:   MagicKey,
 			MagicCookieValue: MagicValue,
 		},
 		GRPCServer: plugin.DefaultGRPCServer,
-		Plugins: map[string]plugin.Plugin{
-			"rule": &pluginImpl{rule: r},
+		VersionedPlugins: map[int]plugin.PluginSet{
+			1: {"rule": &pluginImpl{rule: r}},
 		},
-	})
+	}
+}
+
+func Serve(r RulePlugin) {
+	os.Setenv("GODEBUG", "madvdontneed=1")
+	pluginlog.RedirectStdLog()
+	plugin.Serve(serveConfig(r))
+}
+
+// ServeDebug serves r from this process rather than as a host-spawned subprocess, so it can run under a
+// debugger. name is the binary's file name in the host's plugin directory; the host adopts the running
+// rule in its place through the reattach file. ServeDebug returns once ctx is cancelled or the process
+// is interrupted.
+func ServeDebug(ctx context.Context, name string, r RulePlugin) error {
+	pluginlog.RedirectStdLog()
+	return plugindebug.Serve(ctx, name, serveConfig(r))
 }
@@ -14,11 +14,17 @@ import (
 
 type TuningRuleAdapter struct{}
 
-func (l *TuningRuleAdapter) PluginKey() string         { return "tuning_rule" }
-func (l *TuningRuleAdapter) MagicValue() string        { return "tuning_rule_v1" }
-func (l *TuningRuleAdapter) GRPCPlugin() plugin.Plugin { return &tuningPlugin{} }
+func (l *TuningRuleAdapter) PluginKey() string  { return "tuning_rule" }
+func (l *TuningRuleAdapter) MagicValue() string { return "tuning_rule" }
 
-func (l *TuningRuleAdapter) Handshake(ctx context.Context, raw interface{}, _ string, hash string) (TuningRule, pluginmgr.PluginLifecycle, string, string, error) {
+func (l *TuningRuleAdapter) Protocols() map[int]plugin.Plugin {
+	return map[int]plugin.Plugin{1: &tuningPlugin{}}
+}
+
+func (l *TuningRuleAdapter) Handshake(ctx context.Context, raw interface{}, protocol int, _ string, hash string, cfg pluginmgr.ResolvedConfig) (TuningRule, pluginmgr.PluginLifecycle, string, string, error) {
+	if protocol != 1 {
+		return nil, nil, "", "", fmt.Errorf("unsupported plugin protocol v%d", protocol)
+	}
 	rpc, ok := raw.(rpc_tuning_rules.TuningRuleClient)
 	if !ok {
 		return nil, nil, "", "", fmt.Errorf("dispense: unexpected type %T", raw)
@@ -32,7 +38,7 @@ func (l *TuningRuleAdapter) Handshake(ctx context.Context, raw interface{}, _ st
 	}
 
 	initCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	_, err = rpc.Init(initCtx, &rpc_tuning_rules.Empty{})
+	_, err = rpc.Init(initCtx, &rpc_tuning_rules.InitRequest{Params: cfg.Params, Secrets: cfg.Secrets})
 	cancel()
 	if err != nil {
 		return nil, nil, "", "", fmt.Errorf("init: %w", err)
@@ -43,10 +49,15 @@ func (l *TuningRuleAdapter) Handshake(ctx context.Context, raw interface{}, _ st
 }
 
 // IsEnabled always returns true - tuning rules have no YAML sidecar.
+// Config reads params and secret references from the optional <binary>.yaml sidecar.
+func (l *TuningRuleAdapter) Config(binPath string) (pluginmgr.PluginConfig, error) {
+	return pluginmgr.LoadSidecarConfig(binPath)
+}
+
 func (l *TuningRuleAdapter) IsEnabled(_ *pluginmgr.PluginHandle) bool { return true }
 
 // Workers always returns 1 - no YAML sidecar to configure parallelism.
-func (l *TuningRuleAdapter) Workers(_ string) int { return 1 }
+func (l *TuningRuleAdapter) Workers(_ string) (int, int) { return 1, 1 }
 
 type tuningLifecycle struct {
 	rpc rpc_tuning_rules.TuningRuleClient
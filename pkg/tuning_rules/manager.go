@@ -7,6 +7,6 @@ import (
 
 var tuningManagerMetrics = pluginmgr.NewPluginManagerMetrics("tuning_rules")
 
-func NewManager(log *logger.Logger, notify pluginmgr.Notify, dir string) pluginmgr.Plugin {
+func NewManager(log *logger.Logger, notify pluginmgr.Notify, dir string) *pluginmgr.PluginManager[TuningRule] {
 	return pluginmgr.NewPluginManager[TuningRule](log, notify, dir, &TuningRuleAdapter{}, tuningManagerMetrics)
 }
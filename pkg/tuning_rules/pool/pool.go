@@ -16,9 +16,16 @@ type Pool struct {
 	*internal.ProcessPool[tuning.TuningRule]
 }
 
+// DefaultQueue is the queue policy of tuning rules whose sidecar does not set one. Shed alerts go to the
+// dead-letter topic so tuning is not silently skipped.
+var DefaultQueue = internal.QueuePolicy{Shed: internal.ShedDeadLetter}
+
+// NewPool returns an empty pool whose per-plugin queue policies are read from routing, which the
+// plugin manager fills from the sidecars (see pluginmgr.PluginManager.SetRouting).
 func NewPool(routing *internal.RoutingTable, drainTimeout time.Duration) *Pool {
+	queue := routing.QueueConfig(DefaultQueue)
 	return &Pool{
-		ProcessPool: internal.NewProcessPool[tuning.TuningRule](routing.Config(), internal.NewPoolMetrics("tuning_rules"), drainTimeout),
+		ProcessPool: internal.NewProcessPool[tuning.TuningRule](routing.Config(), queue, internal.NewPoolMetrics("tuning_rules"), drainTimeout),
 	}
 }
 
@@ -38,18 +45,19 @@ func (p *Pool) Tune(ctx context.Context, tuningRuleID string, alert alerts.Alert
 
 // Handles plugin lifecycle messages from the plugin manager bus, registering or deregistering tuning rules in the pool.
 func (p *Pool) Sync(msg messaging.Message) {
-	register := func(onDrained func(), items []tuning.TuningRule, maxProcs int) {
-		version := items[0].Checksum()
+	register := func(onDrained, onRolledBack func(), workers []internal.Worker[tuning.TuningRule], scaler internal.Scaler[tuning.TuningRule]) {
+		item := workers[0].Plugin
+		version := item.Checksum()
 		if version == "" {
 			version = "1.0.0"
 		}
-		p.Register(internal.PoolKey{PluginID: items[0].Id(), Version: version}, items, maxProcs, onDrained)
+		p.Register(internal.PoolKey{PluginID: item.Id(), Version: version}, workers, scaler, onDrained, onRolledBack)
 	}
 	switch m := msg.(type) {
 	case pluginmgr.RegisterMessage[tuning.TuningRule]:
-		register(nil, m.Items, m.MaxProcs)
+		register(nil, nil, m.Workers(), m.Scaler)
 	case pluginmgr.UpdateMessage[tuning.TuningRule]:
-		register(m.OnDrained, m.Items, m.MaxProcs)
+		register(m.OnDrained, m.OnRolledBack, m.Workers(), m.Scaler)
 	case pluginmgr.UnregisterMessage[tuning.TuningRule]:
 		p.Unregister(m.ItemID)
 	case pluginmgr.RemoveMessage[tuning.TuningRule]:
@@ -149,6 +149,58 @@ func (x *TuningMetadata) GetConfidence() string {
 	return ""
 }
 
+type InitRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Params        map[string]string      `protobuf:"bytes,1,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`   // sidecar params
+	Secrets       map[string]string      `protobuf:"bytes,2,rep,name=secrets,proto3" json:"secrets,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // resolved secret values
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InitRequest) Reset() {
+	*x = InitRequest{}
+	mi := &file_tuning_rule_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitRequest) ProtoMessage() {}
+
+func (x *InitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tuning_rule_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitRequest.ProtoReflect.Descriptor instead.
+func (*InitRequest) Descriptor() ([]byte, []int) {
+	return file_tuning_rule_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *InitRequest) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *InitRequest) GetSecrets() map[string]string {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
 type TuneRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AlertJson     []byte                 `protobuf:"bytes,1,opt,name=alert_json,json=alertJson,proto3" json:"alert_json,omitempty"` // JSON-encoded alerts.Alert
@@ -158,7 +210,7 @@ type TuneRequest struct {
 
 func (x *TuneRequest) Reset() {
 	*x = TuneRequest{}
-	mi := &file_tuning_rule_proto_msgTypes[2]
+	mi := &file_tuning_rule_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -170,7 +222,7 @@ func (x *TuneRequest) String() string {
 func (*TuneRequest) ProtoMessage() {}
 
 func (x *TuneRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_tuning_rule_proto_msgTypes[2]
+	mi := &file_tuning_rule_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -183,7 +235,7 @@ func (x *TuneRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TuneRequest.ProtoReflect.Descriptor instead.
 func (*TuneRequest) Descriptor() ([]byte, []int) {
-	return file_tuning_rule_proto_rawDescGZIP(), []int{2}
+	return file_tuning_rule_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *TuneRequest) GetAlertJson() []byte {
@@ -202,7 +254,7 @@ type TuneResponse struct {
 
 func (x *TuneResponse) Reset() {
 	*x = TuneResponse{}
-	mi := &file_tuning_rule_proto_msgTypes[3]
+	mi := &file_tuning_rule_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -214,7 +266,7 @@ func (x *TuneResponse) String() string {
 func (*TuneResponse) ProtoMessage() {}
 
 func (x *TuneResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_tuning_rule_proto_msgTypes[3]
+	mi := &file_tuning_rule_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -227,7 +279,7 @@ func (x *TuneResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TuneResponse.ProtoReflect.Descriptor instead.
 func (*TuneResponse) Descriptor() ([]byte, []int) {
-	return file_tuning_rule_proto_rawDescGZIP(), []int{3}
+	return file_tuning_rule_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *TuneResponse) GetApplies() bool {
@@ -252,16 +304,25 @@ const file_tuning_rule_proto_rawDesc = "" +
 	"\trule_type\x18\x06 \x01(\x05R\bruleType\x12\x1e\n" +
 	"\n" +
 	"confidence\x18\a \x01(\tR\n" +
-	"confidence\",\n" +
+	"confidence\"\x85\x02\n" +
+	"\vInitRequest\x12=\n" +
+	"\x06params\x18\x01 \x03(\v2%.tuning_rules.InitRequest.ParamsEntryR\x06params\x12@\n" +
+	"\asecrets\x18\x02 \x03(\v2&.tuning_rules.InitRequest.SecretsEntryR\asecrets\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a:\n" +
+	"\fSecretsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\",\n" +
 	"\vTuneRequest\x12\x1d\n" +
 	"\n" +
 	"alert_json\x18\x01 \x01(\fR\talertJson\"(\n" +
 	"\fTuneResponse\x12\x18\n" +
-	"\aapplies\x18\x01 \x01(\bR\aapplies2\xa7\x02\n" +
+	"\aapplies\x18\x01 \x01(\bR\aapplies2\xad\x02\n" +
 	"\n" +
 	"TuningRule\x12@\n" +
-	"\vGetMetadata\x12\x13.tuning_rules.Empty\x1a\x1c.tuning_rules.TuningMetadata\x120\n" +
-	"\x04Init\x12\x13.tuning_rules.Empty\x1a\x13.tuning_rules.Empty\x12=\n" +
+	"\vGetMetadata\x12\x13.tuning_rules.Empty\x1a\x1c.tuning_rules.TuningMetadata\x126\n" +
+	"\x04Init\x12\x19.tuning_rules.InitRequest\x1a\x13.tuning_rules.Empty\x12=\n" +
 	"\x04Tune\x12\x19.tuning_rules.TuneRequest\x1a\x1a.tuning_rules.TuneResponse\x124\n" +
 	"\bShutdown\x12\x13.tuning_rules.Empty\x1a\x13.tuning_rules.Empty\x120\n" +
 	"\x04Ping\x12\x13.tuning_rules.Empty\x1a\x13.tuning_rules.EmptyB?Z=github.com/harishhary/blink/pkg/tuning_rules/rpc_tuning_rulesb\x06proto3"
@@ -278,29 +339,34 @@ func file_tuning_rule_proto_rawDescGZIP() []byte {
 	return file_tuning_rule_proto_rawDescData
 }
 
-var file_tuning_rule_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_tuning_rule_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_tuning_rule_proto_goTypes = []any{
 	(*Empty)(nil),          // 0: tuning_rules.Empty
 	(*TuningMetadata)(nil), // 1: tuning_rules.TuningMetadata
-	(*TuneRequest)(nil),    // 2: tuning_rules.TuneRequest
-	(*TuneResponse)(nil),   // 3: tuning_rules.TuneResponse
+	(*InitRequest)(nil),    // 2: tuning_rules.InitRequest
+	(*TuneRequest)(nil),    // 3: tuning_rules.TuneRequest
+	(*TuneResponse)(nil),   // 4: tuning_rules.TuneResponse
+	nil,                    // 5: tuning_rules.InitRequest.ParamsEntry
+	nil,                    // 6: tuning_rules.InitRequest.SecretsEntry
 }
 var file_tuning_rule_proto_depIdxs = []int32{
-	0, // 0: tuning_rules.TuningRule.GetMetadata:input_type -> tuning_rules.Empty
-	0, // 1: tuning_rules.TuningRule.Init:input_type -> tuning_rules.Empty
-	2, // 2: tuning_rules.TuningRule.Tune:input_type -> tuning_rules.TuneRequest
-	0, // 3: tuning_rules.TuningRule.Shutdown:input_type -> tuning_rules.Empty
-	0, // 4: tuning_rules.TuningRule.Ping:input_type -> tuning_rules.Empty
-	1, // 5: tuning_rules.TuningRule.GetMetadata:output_type -> tuning_rules.TuningMetadata
-	0, // 6: tuning_rules.TuningRule.Init:output_type -> tuning_rules.Empty
-	3, // 7: tuning_rules.TuningRule.Tune:output_type -> tuning_rules.TuneResponse
-	0, // 8: tuning_rules.TuningRule.Shutdown:output_type -> tuning_rules.Empty
-	0, // 9: tuning_rules.TuningRule.Ping:output_type -> tuning_rules.Empty
-	5, // [5:10] is the sub-list for method output_type
-	0, // [0:5] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	5, // 0: tuning_rules.InitRequest.params:type_name -> tuning_rules.InitRequest.ParamsEntry
+	6, // 1: tuning_rules.InitRequest.secrets:type_name -> tuning_rules.InitRequest.SecretsEntry
+	0, // 2: tuning_rules.TuningRule.GetMetadata:input_type -> tuning_rules.Empty
+	2, // 3: tuning_rules.TuningRule.Init:input_type -> tuning_rules.InitRequest
+	3, // 4: tuning_rules.TuningRule.Tune:input_type -> tuning_rules.TuneRequest
+	0, // 5: tuning_rules.TuningRule.Shutdown:input_type -> tuning_rules.Empty
+	0, // 6: tuning_rules.TuningRule.Ping:input_type -> tuning_rules.Empty
+	1, // 7: tuning_rules.TuningRule.GetMetadata:output_type -> tuning_rules.TuningMetadata
+	0, // 8: tuning_rules.TuningRule.Init:output_type -> tuning_rules.Empty
+	4, // 9: tuning_rules.TuningRule.Tune:output_type -> tuning_rules.TuneResponse
+	0, // 10: tuning_rules.TuningRule.Shutdown:output_type -> tuning_rules.Empty
+	0, // 11: tuning_rules.TuningRule.Ping:output_type -> tuning_rules.Empty
+	7, // [7:12] is the sub-list for method output_type
+	2, // [2:7] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_tuning_rule_proto_init() }
@@ -314,7 +380,7 @@ func file_tuning_rule_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tuning_rule_proto_rawDesc), len(file_tuning_rule_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -31,7 +31,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type TuningRuleClient interface {
 	GetMetadata(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*TuningMetadata, error)
-	Init(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	Init(ctx context.Context, in *InitRequest, opts ...grpc.CallOption) (*Empty, error)
 	Tune(ctx context.Context, in *TuneRequest, opts ...grpc.CallOption) (*TuneResponse, error)
 	Shutdown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
@@ -55,7 +55,7 @@ func (c *tuningRuleClient) GetMetadata(ctx context.Context, in *Empty, opts ...g
 	return out, nil
 }
 
-func (c *tuningRuleClient) Init(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+func (c *tuningRuleClient) Init(ctx context.Context, in *InitRequest, opts ...grpc.CallOption) (*Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, TuningRule_Init_FullMethodName, in, out, cOpts...)
@@ -100,7 +100,7 @@ func (c *tuningRuleClient) Ping(ctx context.Context, in *Empty, opts ...grpc.Cal
 // for forward compatibility.
 type TuningRuleServer interface {
 	GetMetadata(context.Context, *Empty) (*TuningMetadata, error)
-	Init(context.Context, *Empty) (*Empty, error)
+	Init(context.Context, *InitRequest) (*Empty, error)
 	Tune(context.Context, *TuneRequest) (*TuneResponse, error)
 	Shutdown(context.Context, *Empty) (*Empty, error)
 	Ping(context.Context, *Empty) (*Empty, error)
@@ -117,7 +117,7 @@ type UnimplementedTuningRuleServer struct{}
 func (UnimplementedTuningRuleServer) GetMetadata(context.Context, *Empty) (*TuningMetadata, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMetadata not implemented")
 }
-func (UnimplementedTuningRuleServer) Init(context.Context, *Empty) (*Empty, error) {
+func (UnimplementedTuningRuleServer) Init(context.Context, *InitRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Init not implemented")
 }
 func (UnimplementedTuningRuleServer) Tune(context.Context, *TuneRequest) (*TuneResponse, error) {
@@ -169,7 +169,7 @@ func _TuningRule_GetMetadata_Handler(srv interface{}, ctx context.Context, dec f
 }
 
 func _TuningRule_Init_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
+	in := new(InitRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -181,7 +181,7 @@ func _TuningRule_Init_Handler(srv interface{}, ctx context.Context, dec func(int
 		FullMethod: TuningRule_Init_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TuningRuleServer).Init(ctx, req.(*Empty))
+		return srv.(TuningRuleServer).Init(ctx, req.(*InitRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -9,13 +9,18 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/harishhary/blink/internal/errors"
+	"github.com/harishhary/blink/pkg/pluginconfig"
+	"github.com/harishhary/blink/pkg/plugindebug"
+	"github.com/harishhary/blink/pkg/pluginlog"
 	"github.com/harishhary/blink/pkg/tuning_rules/rpc_tuning_rules"
 )
 
 const (
+	// ProtocolVersion is the highest plugin protocol this SDK speaks. The host negotiates the highest
+	// version both sides support, so binaries keep working with hosts that still run older versions.
 	ProtocolVersion = 1
 	MagicKey        = "BLINK_PLUGIN"
-	MagicValue      = "tuning_rule_v1"
+	MagicValue      = "tuning_rule" // the same for every protocol version; the versions are negotiated
 )
 
 // TuningMetadata holds the static properties returned by TuningRulePlugin.Metadata().
@@ -41,6 +46,12 @@ type BaseTuningRule struct{}
 func (BaseTuningRule) Init() error     { return nil }
 func (BaseTuningRule) Shutdown() error { return nil }
 
+// Configurable is optionally implemented by a TuningRulePlugin that takes params or secrets from its sidecar.
+// Configure is called with the resolved values right before Init. A config change starts fresh workers, so it runs once per process.
+type Configurable interface {
+	Configure(cfg pluginconfig.Config) error
+}
+
 // server wraps a TuningRulePlugin and serve the gRPC TuningRuleServer interface.
 type server struct {
 	rpc_tuning_rules.UnimplementedTuningRuleServer
@@ -60,7 +71,12 @@ func (s *server) GetMetadata(_ context.Context, _ *rpc_tuning_rules.Empty) (*rpc
 	}, nil
 }
 
-func (s *server) Init(_ context.Context, _ *rpc_tuning_rules.Empty) (*rpc_tuning_rules.Empty, error) {
+func (s *server) Init(_ context.Context, req *rpc_tuning_rules.InitRequest) (*rpc_tuning_rules.Empty, error) {
+	if c, ok := s.rule.(Configurable); ok {
+		if err := c.Configure(pluginconfig.New(req.GetParams(), req.GetSecrets())); err != nil {
+			return nil, err
+		}
+	}
 	return &rpc_tuning_rules.Empty{}, s.rule.Init()
 }
 
@@ -98,17 +114,31 @@ func (p *pluginImpl) GRPCClient(_ context.Context, _ *plugin.GRPCBroker, c *grpc
 	return rpc_tuning_rules.NewTuningRuleClient(c), nil
 }
 
-func Serve(r TuningRulePlugin) {
-	os.Setenv("GODEBUG", "madvdontneed=1")
-	plugin.Serve(&plugin.ServeConfig{
+func serveConfig(r TuningRulePlugin) *plugin.ServeConfig {
+	return &plugin.ServeConfig{
 		HandshakeConfig: plugin.HandshakeConfig{
 			ProtocolVersion:  ProtocolVersion,
 			MagicCookieKey:   MagicKey,
 			MagicCookieValue: MagicValue,
 		},
 		GRPCServer: plugin.DefaultGRPCServer,
-		Plugins: map[string]plugin.Plugin{
-			"tuning_rule": &pluginImpl{rule: r},
+		VersionedPlugins: map[int]plugin.PluginSet{
+			1: {"tuning_rule": &pluginImpl{rule: r}},
 		},
-	})
+	}
+}
+
+func Serve(r TuningRulePlugin) {
+	os.Setenv("GODEBUG", "madvdontneed=1")
+	pluginlog.RedirectStdLog()
+	plugin.Serve(serveConfig(r))
+}
+
+// ServeDebug serves r from this process rather than as a host-spawned subprocess, so it can run under a
+// debugger. name is the binary's file name in the host's plugin directory; the host adopts the running
+// tuning rule in its place through the reattach file. ServeDebug returns once ctx is cancelled or the process
+// is interrupted.
+func ServeDebug(ctx context.Context, name string, r TuningRulePlugin) error {
+	pluginlog.RedirectStdLog()
+	return plugindebug.Serve(ctx, name, serveConfig(r))
 }